@@ -0,0 +1,75 @@
+package ethstore
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	AccountingTypeConsensusReward = "consensus_reward"
+	AccountingTypeExecutionReward = "execution_reward"
+)
+
+// AccountingRecord is the income of a withdrawal address of a type during a day.
+type AccountingRecord struct {
+	Date    string
+	Asset   string
+	Amount  decimal.Decimal
+	Type    string
+	Address string
+}
+
+// AccountingRecords returns the income records of the withdrawal groups of the given days, the amounts are in Eth.
+// Consensus rewards are always reported (they can be negative), execution rewards only if there were any. Withdrawals
+// are not reported because they are not tracked by the calculation.
+func AccountingRecords(days []*Day) ([]AccountingRecord, error) {
+	gweiPerEth := decimal.NewFromInt(1e9)
+	weiPerEth := decimal.NewFromInt(1e18)
+	records := []AccountingRecord{}
+	for _, d := range days {
+		if len(d.WithdrawalGroups) == 0 {
+			return nil, fmt.Errorf("day %v has no withdrawal groups", d.Day)
+		}
+		date := d.DayTime.UTC().Format("2006-01-02")
+		for _, g := range d.WithdrawalGroups {
+			records = append(records, AccountingRecord{
+				Date:    date,
+				Asset:   "ETH",
+				Amount:  g.ConsensusRewardsGwei.Div(gweiPerEth),
+				Type:    AccountingTypeConsensusReward,
+				Address: g.WithdrawalAddress,
+			})
+			if g.TxFeesSumWei.IsZero() {
+				continue
+			}
+			records = append(records, AccountingRecord{
+				Date:    date,
+				Asset:   "ETH",
+				Amount:  g.TxFeesSumWei.Div(weiPerEth),
+				Type:    AccountingTypeExecutionReward,
+				Address: g.WithdrawalAddress,
+			})
+		}
+	}
+	return records, nil
+}
+
+// WriteAccountingCSV writes the records as csv with the columns date, asset, amount, type and address.
+func WriteAccountingCSV(w io.Writer, records []AccountingRecord) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"date", "asset", "amount", "type", "address"})
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		err = cw.Write([]string{r.Date, r.Asset, r.Amount.String(), r.Type, r.Address})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
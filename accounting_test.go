@@ -0,0 +1,40 @@
+package ethstore
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAccountingRecords(t *testing.T) {
+	day := &Day{
+		Day:     decimal.NewFromInt(10),
+		DayTime: time.Date(2020, 12, 11, 12, 0, 23, 0, time.UTC),
+		WithdrawalGroups: []WithdrawalGroup{
+			{WithdrawalAddress: "0x0000000000000000000000000000000000000001", ConsensusRewardsGwei: decimal.NewFromInt(2500000), TxFeesSumWei: decimal.NewFromInt(1e15)},
+			{WithdrawalAddress: "0x0000000000000000000000000000000000000002", ConsensusRewardsGwei: decimal.NewFromInt(-1000), TxFeesSumWei: decimal.Zero},
+		},
+	}
+	records, err := AccountingRecords([]*Day{day})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = WriteAccountingCSV(&buf, records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "date,asset,amount,type,address\n" +
+		"2020-12-11,ETH,0.0025,consensus_reward,0x0000000000000000000000000000000000000001\n" +
+		"2020-12-11,ETH,0.001,execution_reward,0x0000000000000000000000000000000000000001\n" +
+		"2020-12-11,ETH,-0.000001,consensus_reward,0x0000000000000000000000000000000000000002\n"
+	if buf.String() != expected {
+		t.Errorf("wrong csv:\n%v\nexpected:\n%v", buf.String(), expected)
+	}
+
+	if _, err := AccountingRecords([]*Day{{Day: decimal.NewFromInt(11)}}); err == nil {
+		t.Errorf("expected error for day without withdrawal groups")
+	}
+}
@@ -0,0 +1,141 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+type ActivationQueue struct {
+	ActiveValidators  decimal.Decimal `json:"activeValidators"`
+	PendingValidators decimal.Decimal `json:"pendingValidators"`
+	// ChurnLimit is the number of validators that are activated per epoch, since electra the churn is a balance
+	// and ChurnLimitGwei is set instead.
+	ChurnLimit     decimal.Decimal `json:"churnLimit"`
+	ChurnLimitGwei decimal.Decimal `json:"churnLimitGwei"`
+	WaitEpochs     decimal.Decimal `json:"waitEpochs"`
+	WaitDuration   time.Duration   `json:"waitDuration"`
+	ForwardApr     decimal.Decimal `json:"forwardApr"`
+}
+
+// GetActivationQueue estimates how long a deposit made at the start of the given day would wait in the
+// entry-queue and which apr it could expect once the queued validators have diluted the rewards. Since electra the
+// deposits are queued in the pending deposits of the state before they create a validator, those deposits are not
+// part of the validators of the state and are not counted.
+func GetActivationQueue(ctx context.Context, address string, day *Day) (*ActivationQueue, error) {
+	client, err := newConsClient(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	apiSpec, err := client.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slotsPerEpoch, err := getSpecUint64(apiSpec, "SLOTS_PER_EPOCH")
+	if err != nil {
+		return nil, err
+	}
+	firstSlot := uint64(day.StartEpoch.IntPart()) * slotsPerEpoch
+	validators, err := GetValidators(ctx, client, fmt.Sprintf("%d", firstSlot))
+	if err != nil {
+		return nil, err
+	}
+	return estimateActivationQueue(apiSpec, validators, day)
+}
+
+func estimateActivationQueue(apiSpec map[string]interface{}, validators map[phase0.ValidatorIndex]*v1.Validator, day *Day) (*ActivationQueue, error) {
+	slotsPerEpoch, err := getSpecUint64(apiSpec, "SLOTS_PER_EPOCH")
+	if err != nil {
+		return nil, err
+	}
+	secondsPerSlot, err := getSpecDuration(apiSpec, "SECONDS_PER_SLOT")
+	if err != nil {
+		return nil, err
+	}
+	minChurnLimit, err := getSpecUint64(apiSpec, "MIN_PER_EPOCH_CHURN_LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	churnLimitQuotient, err := getSpecUint64(apiSpec, "CHURN_LIMIT_QUOTIENT")
+	if err != nil {
+		return nil, err
+	}
+
+	var active, pending uint64
+	var activeBalanceGwei, pendingBalanceGwei phase0.Gwei
+	for _, val := range validators {
+		if val.Status.IsActive() {
+			active++
+			activeBalanceGwei += val.Validator.EffectiveBalance
+		} else if val.Status.IsPending() {
+			pending++
+			pendingBalanceGwei += val.Validator.EffectiveBalance
+		}
+	}
+
+	var churnLimit, churnLimitGwei, waitEpochs uint64
+	if minChurnLimitGwei, err := getSpecUint64(apiSpec, "MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA"); err == nil {
+		// since electra the churn is a balance in gwei that is rounded down to the effective balance increment
+		maxChurnLimitGwei, err := getSpecUint64(apiSpec, "MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT")
+		if err != nil {
+			return nil, err
+		}
+		increment, err := getSpecUint64(apiSpec, "EFFECTIVE_BALANCE_INCREMENT")
+		if err != nil {
+			return nil, err
+		}
+		churnLimitGwei = uint64(activeBalanceGwei) / churnLimitQuotient
+		if churnLimitGwei < minChurnLimitGwei {
+			churnLimitGwei = minChurnLimitGwei
+		}
+		if churnLimitGwei > maxChurnLimitGwei {
+			churnLimitGwei = maxChurnLimitGwei
+		}
+		if increment > 0 {
+			churnLimitGwei -= churnLimitGwei % increment
+		}
+		if churnLimitGwei == 0 {
+			return nil, fmt.Errorf("zero churn limit")
+		}
+		waitEpochs = (uint64(pendingBalanceGwei) + churnLimitGwei - 1) / churnLimitGwei
+	} else {
+		churnLimit = active / churnLimitQuotient
+		if churnLimit < minChurnLimit {
+			churnLimit = minChurnLimit
+		}
+		// since deneb the activation-churn is capped separately from the exit-churn
+		if maxActivationChurnLimit, err := getSpecUint64(apiSpec, "MAX_PER_EPOCH_ACTIVATION_CHURN_LIMIT"); err == nil && churnLimit > maxActivationChurnLimit {
+			churnLimit = maxActivationChurnLimit
+		}
+		if churnLimit == 0 {
+			return nil, fmt.Errorf("zero churn limit")
+		}
+		waitEpochs = (pending + churnLimit - 1) / churnLimit
+	}
+
+	// consensus rewards scale with 1/sqrt(totalBalance) while execution rewards are shared by all validators,
+	// so both parts of the apr are diluted differently by the validators that are about to be activated
+	forwardApr := day.Apr
+	if activeBalanceGwei > 0 && !day.TotalRewardsWei.IsZero() {
+		ratio := float64(activeBalanceGwei) / float64(activeBalanceGwei+pendingBalanceGwei)
+		consensusShare := gweiToWei(day.ConsensusRewardsGwei).Div(day.TotalRewardsWei)
+		consensusApr := day.Apr.Mul(consensusShare)
+		executionApr := day.Apr.Sub(consensusApr)
+		forwardApr = consensusApr.Mul(decimal.NewFromFloat(math.Sqrt(ratio))).Add(executionApr.Mul(decimal.NewFromFloat(ratio)))
+	}
+
+	return &ActivationQueue{
+		ActiveValidators:  decimal.NewFromInt(int64(active)),
+		PendingValidators: decimal.NewFromInt(int64(pending)),
+		ChurnLimit:        decimal.NewFromInt(int64(churnLimit)),
+		ChurnLimitGwei:    decimal.NewFromInt(int64(churnLimitGwei)),
+		WaitEpochs:        decimal.NewFromInt(int64(waitEpochs)),
+		WaitDuration:      time.Duration(waitEpochs*slotsPerEpoch) * secondsPerSlot,
+		ForwardApr:        forwardApr,
+	}, nil
+}
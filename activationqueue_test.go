@@ -0,0 +1,95 @@
+package ethstore
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+func TestEstimateActivationQueue(t *testing.T) {
+	// 100 active validators with 32 eth and 25 pending validators with 32 eth, a churn limit of 4 per epoch
+	// results in a wait of ceil(25/4) = 7 epochs. the pending validators increase the total balance by 25%,
+	// which dilutes the consensus part of the apr by sqrt(0.8) and the execution part by 0.8.
+	apiSpec := map[string]interface{}{
+		"SLOTS_PER_EPOCH":           uint64(32),
+		"SECONDS_PER_SLOT":          12 * time.Second,
+		"MIN_PER_EPOCH_CHURN_LIMIT": uint64(4),
+		"CHURN_LIMIT_QUOTIENT":      uint64(65536),
+	}
+	validators := map[phase0.ValidatorIndex]*v1.Validator{}
+	for i := 0; i < 125; i++ {
+		status := v1.ValidatorStateActiveOngoing
+		if i >= 100 {
+			status = v1.ValidatorStatePendingQueued
+		}
+		validators[phase0.ValidatorIndex(i)] = &v1.Validator{
+			Index:     phase0.ValidatorIndex(i),
+			Status:    status,
+			Validator: &phase0.Validator{EffectiveBalance: 32e9},
+		}
+	}
+	day := &Day{
+		Apr:                  decimal.NewFromFloat(0.04),
+		ConsensusRewardsGwei: decimal.NewFromInt(3e9),
+		TotalRewardsWei:      decimal.NewFromInt(4e18),
+	}
+
+	q, err := estimateActivationQueue(apiSpec, validators, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.ActiveValidators.IntPart() != 100 {
+		t.Errorf("wrong ActiveValidators: %v != %v", q.ActiveValidators, 100)
+	}
+	if q.PendingValidators.IntPart() != 25 {
+		t.Errorf("wrong PendingValidators: %v != %v", q.PendingValidators, 25)
+	}
+	if q.ChurnLimit.IntPart() != 4 {
+		t.Errorf("wrong ChurnLimit: %v != %v", q.ChurnLimit, 4)
+	}
+	if q.WaitEpochs.IntPart() != 7 {
+		t.Errorf("wrong WaitEpochs: %v != %v", q.WaitEpochs, 7)
+	}
+	if q.WaitDuration != 7*32*12*time.Second {
+		t.Errorf("wrong WaitDuration: %v != %v", q.WaitDuration, 7*32*12*time.Second)
+	}
+	// 0.03*sqrt(0.8) + 0.01*0.8
+	expectedApr := decimal.NewFromFloat(0.0348328157)
+	if q.ForwardApr.Sub(expectedApr).Abs().GreaterThan(decimal.NewFromFloat(1e-9)) {
+		t.Errorf("wrong ForwardApr: %v != %v", q.ForwardApr, expectedApr)
+	}
+
+	apiSpec["MAX_PER_EPOCH_ACTIVATION_CHURN_LIMIT"] = uint64(2)
+	q, err = estimateActivationQueue(apiSpec, validators, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.WaitEpochs.IntPart() != 13 {
+		t.Errorf("wrong WaitEpochs with capped activation churn: %v != %v", q.WaitEpochs, 13)
+	}
+
+	apiSpec["MIN_PER_EPOCH_CHURN_LIMIT"] = uint64(0)
+	delete(apiSpec, "MAX_PER_EPOCH_ACTIVATION_CHURN_LIMIT")
+	if _, err := estimateActivationQueue(apiSpec, validators, day); err == nil {
+		t.Errorf("expected error for zero churn limit")
+	}
+
+	// since electra the churn is a balance: 128 eth per epoch take ceil(800/128) = 7 epochs for the 25 pending
+	// validators
+	apiSpec["MIN_PER_EPOCH_CHURN_LIMIT_ELECTRA"] = uint64(128e9)
+	apiSpec["MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT"] = uint64(256e9)
+	apiSpec["EFFECTIVE_BALANCE_INCREMENT"] = uint64(1e9)
+	q, err = estimateActivationQueue(apiSpec, validators, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.ChurnLimitGwei.IntPart() != 128e9 || !q.ChurnLimit.IsZero() {
+		t.Errorf("wrong churn limit since electra: %v gwei, %v validators", q.ChurnLimitGwei, q.ChurnLimit)
+	}
+	if q.WaitEpochs.IntPart() != 7 {
+		t.Errorf("wrong WaitEpochs since electra: %v != %v", q.WaitEpochs, 7)
+	}
+}
@@ -0,0 +1,239 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	AlertAprBounds        = "aprBounds"
+	AlertAprDeviation     = "aprDeviation"
+	AlertLargeDeposit     = "largeDeposit"
+	AlertMissedSlotStreak = "missedSlotStreak"
+	AlertSlashing         = "slashing"
+)
+
+var alertRules *AlertRules
+var alertRulesMu = sync.Mutex{}
+var alertNotifiers []Notifier
+var alertNotifiersMu = sync.Mutex{}
+
+// AlertRules holds the thresholds of the alerts, a threshold of 0 disables its alert.
+type AlertRules struct {
+	// AprDeviation is the relative deviation of the apr from the apr of the previous day, e.g. 0.2 for 20%.
+	AprDeviation decimal.Decimal
+	// DepositGwei is the amount a single deposit included in a block of the day must exceed.
+	DepositGwei phase0.Gwei
+	// MissedSlotStreak is the number of consecutive missed slots.
+	MissedSlotStreak uint64
+	// SlashingWatchList holds the validators whose slashings are alerted as soon as the block including the slashing
+	// has been processed.
+	SlashingWatchList map[phase0.ValidatorIndex]bool
+}
+
+// Alert describes a day that violates an alert rule.
+type Alert struct {
+	Day     uint64 `json:"day"`
+	Slot    uint64 `json:"slot,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// Delivered is set if the alert has been delivered to the alert notifiers while the day was calculated.
+	Delivered bool `json:"delivered,omitempty"`
+}
+
+// Notifier delivers alerts, e.g. to a chat or a paging service.
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// SetAlertRules sets the rules of the alerts that are checked while calculating a day, nil disables the alerts.
+func SetAlertRules(r *AlertRules) {
+	alertRulesMu.Lock()
+	defer alertRulesMu.Unlock()
+	alertRules = r
+}
+
+func GetAlertRules() *AlertRules {
+	alertRulesMu.Lock()
+	defer alertRulesMu.Unlock()
+	return alertRules
+}
+
+// SetAlertNotifiers sets the notifiers that alerts which must not wait for the end of the day (e.g. slashings) are
+// delivered to while calculating a day, these alerts are marked as delivered.
+func SetAlertNotifiers(notifiers ...Notifier) {
+	alertNotifiersMu.Lock()
+	defer alertNotifiersMu.Unlock()
+	alertNotifiers = notifiers
+}
+
+func GetAlertNotifiers() []Notifier {
+	alertNotifiersMu.Lock()
+	defer alertNotifiersMu.Unlock()
+	return alertNotifiers
+}
+
+// deliverAlerts delivers the alerts to the alert notifiers and marks them as delivered, errors of the notifiers are
+// logged.
+func deliverAlerts(ctx context.Context, alerts []Alert) {
+	notifiers := GetAlertNotifiers()
+	if len(notifiers) == 0 {
+		return
+	}
+	for i := range alerts {
+		for _, n := range notifiers {
+			if err := n.Notify(ctx, alerts[i]); err != nil {
+				log.Printf("error notifying alert of day %v: %v", alerts[i].Day, err)
+			}
+		}
+		alerts[i].Delivered = true
+	}
+}
+
+// depositAlert returns an alert if the amount of the deposit exceeds the threshold.
+func (r *AlertRules) depositAlert(day, slot uint64, d *phase0.Deposit) *Alert {
+	if r == nil || r.DepositGwei == 0 || d.Data.Amount <= r.DepositGwei {
+		return nil
+	}
+	return &Alert{
+		Day:     day,
+		Slot:    slot,
+		Rule:    AlertLargeDeposit,
+		Message: fmt.Sprintf("deposit of %v Gwei for %#x at slot %v exceeds %v Gwei", d.Data.Amount, d.Data.PublicKey, slot, r.DepositGwei),
+	}
+}
+
+// missedSlotStreakAlerts returns an alert for every streak of consecutive missed slots that reaches the threshold.
+func (r *AlertRules) missedSlotStreakAlerts(day uint64, missedSlots []uint64) []Alert {
+	if r == nil || r.MissedSlotStreak == 0 || len(missedSlots) == 0 {
+		return nil
+	}
+	slots := append([]uint64{}, missedSlots...)
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+	alerts := []Alert{}
+	streakStart := 0
+	for i := 1; i <= len(slots); i++ {
+		if i < len(slots) && slots[i] == slots[i-1]+1 {
+			continue
+		}
+		if streak := uint64(i - streakStart); streak >= r.MissedSlotStreak {
+			alerts = append(alerts, Alert{
+				Day:     day,
+				Slot:    slots[streakStart],
+				Rule:    AlertMissedSlotStreak,
+				Message: fmt.Sprintf("%v consecutive missed slots from slot %v to %v", streak, slots[streakStart], slots[i-1]),
+			})
+		}
+		streakStart = i
+	}
+	return alerts
+}
+
+// slashingAlerts returns an alert for every validator of the watch list that is slashed by one of the slashings
+// included in the block at the given slot.
+func (r *AlertRules) slashingAlerts(day, slot uint64, proposerSlashings []*phase0.ProposerSlashing, attesterSlashings []*phase0.AttesterSlashing) []Alert {
+	if r == nil || len(r.SlashingWatchList) == 0 {
+		return nil
+	}
+	var alerts []Alert
+	for _, s := range proposerSlashings {
+		index := s.SignedHeader1.Message.ProposerIndex
+		if !r.SlashingWatchList[index] {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Day:     day,
+			Slot:    slot,
+			Rule:    AlertSlashing,
+			Message: fmt.Sprintf("validator %v is slashed for proposing two blocks at slot %v by the block at slot %v", index, s.SignedHeader1.Message.Slot, slot),
+		})
+	}
+	for _, s := range attesterSlashings {
+		// the slashed validators are those that signed both attestations
+		attested := make(map[uint64]bool, len(s.Attestation1.AttestingIndices))
+		for _, index := range s.Attestation1.AttestingIndices {
+			attested[index] = true
+		}
+		for _, index := range s.Attestation2.AttestingIndices {
+			if !attested[index] || !r.SlashingWatchList[phase0.ValidatorIndex(index)] {
+				continue
+			}
+			alerts = append(alerts, Alert{
+				Day:     day,
+				Slot:    slot,
+				Rule:    AlertSlashing,
+				Message: fmt.Sprintf("validator %v is slashed for conflicting attestations of target epochs %v and %v by the block at slot %v", index, s.Attestation1.Data.Target.Epoch, s.Attestation2.Data.Target.Epoch, slot),
+			})
+		}
+	}
+	return alerts
+}
+
+// AprAlert returns an alert if the apr of the day deviates from the apr of the previous day by more than the threshold.
+func (r *AlertRules) AprAlert(d, previous *Day) *Alert {
+	if r == nil || !r.AprDeviation.IsPositive() || previous == nil || previous.Apr.IsZero() {
+		return nil
+	}
+	deviation := d.Apr.Sub(previous.Apr).Div(previous.Apr).Abs()
+	if deviation.LessThanOrEqual(r.AprDeviation) {
+		return nil
+	}
+	return &Alert{
+		Day:     uint64(d.Day.IntPart()),
+		Rule:    AlertAprDeviation,
+		Message: fmt.Sprintf("apr %v deviates by %v%% from apr %v of day %v", d.Apr.StringFixed(9), deviation.Mul(decimal.NewFromInt(100)).StringFixed(2), previous.Apr.StringFixed(9), previous.Day),
+	}
+}
+
+// LogNotifier logs alerts.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, a Alert) error {
+	log.Printf("alert: day: %v, rule: %v, %v", a.Day, a.Rule, a.Message)
+	return nil
+}
+
+// WebhookNotifier posts alerts as json to an url.
+type WebhookNotifier struct {
+	URL string
+}
+
+func (n WebhookNotifier) Notify(ctx context.Context, a Alert) error {
+	if err := postJson(ctx, n.URL, a); err != nil {
+		return fmt.Errorf("error posting alert to webhook: %w", err)
+	}
+	return nil
+}
+
+// postJson posts v as json to the given url and expects a 2xx status.
+func postJson(ctx context.Context, url string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, GetConsTimeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("status %v", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+func TestMissedSlotStreakAlerts(t *testing.T) {
+	rules := &AlertRules{MissedSlotStreak: 3}
+	alerts := rules.missedSlotStreakAlerts(10, []uint64{105, 100, 101, 102, 110, 111, 106, 107, 120})
+	if len(alerts) != 2 {
+		t.Fatalf("wrong number of alerts: %v != %v (%+v)", len(alerts), 2, alerts)
+	}
+	if alerts[0].Slot != 100 || alerts[1].Slot != 105 || alerts[0].Rule != AlertMissedSlotStreak {
+		t.Errorf("wrong alerts: %+v", alerts)
+	}
+	if alerts := (&AlertRules{}).missedSlotStreakAlerts(10, []uint64{1, 2, 3}); len(alerts) != 0 {
+		t.Errorf("alerts of disabled rule: %+v", alerts)
+	}
+	var noRules *AlertRules
+	if alerts := noRules.missedSlotStreakAlerts(10, []uint64{1, 2, 3}); len(alerts) != 0 {
+		t.Errorf("alerts without rules: %+v", alerts)
+	}
+}
+
+// recordingNotifier records the alerts it is notified of.
+type recordingNotifier struct {
+	alerts []Alert
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, a Alert) error {
+	n.alerts = append(n.alerts, a)
+	return nil
+}
+
+func TestSlashingAlerts(t *testing.T) {
+	rules := &AlertRules{SlashingWatchList: map[phase0.ValidatorIndex]bool{3: true, 7: true}}
+	proposerSlashings := []*phase0.ProposerSlashing{
+		{SignedHeader1: &phase0.SignedBeaconBlockHeader{Message: &phase0.BeaconBlockHeader{Slot: 90, ProposerIndex: 3}}},
+		{SignedHeader1: &phase0.SignedBeaconBlockHeader{Message: &phase0.BeaconBlockHeader{Slot: 91, ProposerIndex: 4}}},
+	}
+	attestation := func(epoch phase0.Epoch, indices ...uint64) *phase0.IndexedAttestation {
+		return &phase0.IndexedAttestation{AttestingIndices: indices, Data: &phase0.AttestationData{Target: &phase0.Checkpoint{Epoch: epoch}}}
+	}
+	// only validators that signed both attestations are slashed
+	attesterSlashings := []*phase0.AttesterSlashing{
+		{Attestation1: attestation(2, 1, 6, 7), Attestation2: attestation(2, 3, 6, 7)},
+	}
+	alerts := rules.slashingAlerts(10, 100, proposerSlashings, attesterSlashings)
+	if len(alerts) != 2 {
+		t.Fatalf("wrong number of alerts: %v != %v (%+v)", len(alerts), 2, alerts)
+	}
+	for _, a := range alerts {
+		if a.Day != 10 || a.Slot != 100 || a.Rule != AlertSlashing {
+			t.Errorf("wrong alert: %+v", a)
+		}
+	}
+	var noRules *AlertRules
+	if alerts := noRules.slashingAlerts(10, 100, proposerSlashings, attesterSlashings); len(alerts) != 0 {
+		t.Errorf("alerts without rules: %+v", alerts)
+	}
+
+	n := &recordingNotifier{}
+	SetAlertNotifiers(n)
+	defer SetAlertNotifiers()
+	deliverAlerts(context.Background(), alerts)
+	if len(n.alerts) != 2 || !alerts[0].Delivered || !alerts[1].Delivered {
+		t.Errorf("alerts not delivered: %+v", alerts)
+	}
+}
+
+func TestAprAlert(t *testing.T) {
+	rules := &AlertRules{AprDeviation: decimal.NewFromFloat(0.2)}
+	previous := &Day{Day: decimal.NewFromInt(9), Apr: decimal.NewFromFloat(0.05)}
+	tests := []struct {
+		apr   float64
+		alert bool
+	}{
+		{0.05, false},
+		{0.059, false},
+		{0.061, true},
+		{0.039, true},
+	}
+	for _, tt := range tests {
+		a := rules.AprAlert(&Day{Day: decimal.NewFromInt(10), Apr: decimal.NewFromFloat(tt.apr)}, previous)
+		if (a != nil) != tt.alert {
+			t.Errorf("wrong alert for apr %v: %+v", tt.apr, a)
+		}
+		if a != nil && (a.Day != 10 || a.Rule != AlertAprDeviation) {
+			t.Errorf("wrong alert: %+v", a)
+		}
+	}
+	if a := rules.AprAlert(&Day{Apr: decimal.NewFromFloat(0.1)}, nil); a != nil {
+		t.Errorf("alert without previous day: %+v", a)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	received := []Alert{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var a Alert
+		if err := json.Unmarshal(body, &a); err != nil {
+			t.Errorf("invalid alert: %s", body)
+		}
+		received = append(received, a)
+		if a.Day == 11 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	n := WebhookNotifier{URL: server.URL}
+	if err := n.Notify(context.Background(), Alert{Day: 10, Rule: AlertLargeDeposit, Message: "test"}); err != nil {
+		t.Error(err)
+	}
+	if err := n.Notify(context.Background(), Alert{Day: 11, Rule: AlertLargeDeposit, Message: "test"}); err == nil {
+		t.Errorf("expected error for status 500")
+	}
+	if len(received) != 2 || received[0].Day != 10 || received[0].Message != "test" {
+		t.Errorf("wrong alerts received: %+v", received)
+	}
+}
+
+func TestAlertsOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	SetAlertRules(&AlertRules{DepositGwei: 10e9, MissedSlotStreak: 1})
+	defer SetAlertRules(nil)
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposits := 0
+	for _, a := range day.Alerts {
+		if a.Rule == AlertLargeDeposit {
+			deposits++
+		}
+	}
+	if deposits == 0 {
+		t.Errorf("no large deposit alerts: %+v", day.Alerts)
+	}
+	for i := 1; i < len(day.Alerts); i++ {
+		if day.Alerts[i].Slot < day.Alerts[i-1].Slot {
+			t.Errorf("alerts not sorted by slot: %+v", day.Alerts)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrAnomalousDay is returned when a day is not published because its apr is outside of the apr bounds.
+var ErrAnomalousDay = errors.New("anomalous day")
+
+var aprBounds *AprBounds
+var aprBoundsMu = sync.Mutex{}
+
+// AprBounds are the sanity bounds of the apr of a day. An apr outside of them is more likely caused by a bug or by
+// wrong data of a node than by the network, such a day is marked as anomalous and alerted instead of published.
+type AprBounds struct {
+	// Min is the minimum apr, e.g. 0 to catch negative aprs.
+	Min decimal.Decimal
+	// Max is the maximum apr, e.g. 0.2 for 20%.
+	Max decimal.Decimal
+}
+
+// SetAprBounds sets the sanity bounds of the apr that are checked after a day is calculated, nil disables the check.
+func SetAprBounds(b *AprBounds) {
+	aprBoundsMu.Lock()
+	defer aprBoundsMu.Unlock()
+	aprBounds = b
+}
+
+func GetAprBounds() *AprBounds {
+	aprBoundsMu.Lock()
+	defer aprBoundsMu.Unlock()
+	return aprBounds
+}
+
+// ParseAprBounds parses apr bounds of the format "min:max", e.g. "0:0.2" for an apr between 0 and 20%. An empty
+// string returns nil.
+func ParseAprBounds(s string) (*AprBounds, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid apr bounds %q, expected min:max", s)
+	}
+	minStr, maxStr := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	min, err := decimal.NewFromString(minStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minimum apr %q: %w", minStr, err)
+	}
+	max, err := decimal.NewFromString(maxStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maximum apr %q: %w", maxStr, err)
+	}
+	if max.LessThanOrEqual(min) {
+		return nil, fmt.Errorf("maximum apr %v is not above minimum apr %v", max, min)
+	}
+	return &AprBounds{Min: min, Max: max}, nil
+}
+
+// aprAlert returns an alert if the apr of the day is outside of the bounds.
+func (b *AprBounds) aprAlert(d *Day) *Alert {
+	if b == nil || (d.Apr.GreaterThanOrEqual(b.Min) && d.Apr.LessThanOrEqual(b.Max)) {
+		return nil
+	}
+	return &Alert{
+		Day:     uint64(d.Day.IntPart()),
+		Rule:    AlertAprBounds,
+		Message: fmt.Sprintf("apr %v is outside of the bounds %v to %v, the day is not published", d.Apr.StringFixed(9), b.Min, b.Max),
+	}
+}
@@ -0,0 +1,78 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/gobitfly/eth.store/fixture"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseAprBounds(t *testing.T) {
+	b, err := ParseAprBounds("0:0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.Min.IsZero() || !b.Max.Equal(decimal.NewFromFloat(0.2)) {
+		t.Errorf("wrong apr bounds: %v %v", b.Min, b.Max)
+	}
+	if b, err := ParseAprBounds(""); b != nil || err != nil {
+		t.Errorf("expected no apr bounds: %v %v", b, err)
+	}
+	for _, s := range []string{"0.2", "a:0.2", "0:b", "0.2:0.1", "0:0.1:0.2"} {
+		if _, err := ParseAprBounds(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestAprBounds(t *testing.T) {
+	f, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bnServer, elServer := f.Servers()
+	defer bnServer.Close()
+	defer elServer.Close()
+	n := &recordingNotifier{}
+	SetAlertNotifiers(n)
+	defer SetAlertNotifiers()
+
+	SetAprBounds(&AprBounds{Min: decimal.Zero, Max: decimal.NewFromFloat(0.2)})
+	defer SetAprBounds(nil)
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.Anomalous || len(n.alerts) != 0 {
+		t.Errorf("day within the bounds is anomalous: %v %v", day.Apr, n.alerts)
+	}
+
+	// the apr of the fixture is above 1%
+	SetAprBounds(&AprBounds{Min: decimal.Zero, Max: decimal.NewFromFloat(0.01)})
+	day, _, err = Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.Anomalous || !day.Apr.Equal(f.Expected.Apr) {
+		t.Errorf("day outside of the bounds is not anomalous: %v %v", day.Anomalous, day.Apr)
+	}
+	if len(n.alerts) != 1 || n.alerts[0].Rule != AlertAprBounds || n.alerts[0].Day != 10 {
+		t.Fatalf("wrong alerts: %+v", n.alerts)
+	}
+	if len(day.Alerts) == 0 || day.Alerts[0].Rule != AlertAprBounds || !day.Alerts[0].Delivered {
+		t.Errorf("wrong alerts of the day: %+v", day.Alerts)
+	}
+
+	// the backfill does not store the anomalous day
+	store := NewMemoryStore()
+	err = Backfill(context.Background(), bnServer.URL, elServer.URL, store, 10, 10, filepath.Join(t.TempDir(), "progress.json"), 10, nil)
+	if !errors.Is(err, ErrAnomalousDay) {
+		t.Errorf("expected ErrAnomalousDay, got %v", err)
+	}
+	if _, err := store.Get(context.Background(), 10); !errors.Is(err, ErrDayNotFound) {
+		t.Errorf("anomalous day was stored: %v", err)
+	}
+}
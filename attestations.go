@@ -0,0 +1,194 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"golang.org/x/sync/errgroup"
+)
+
+var attestationProvider AttestationProvider
+var attestationProviderMu = sync.Mutex{}
+
+// AttestationProvider provides the attestation effectiveness (0 to 1) of validators with the given
+// effective balances in an epoch.
+type AttestationProvider interface {
+	AttestationEffectiveness(ctx context.Context, epoch phase0.Epoch, effectiveBalances map[phase0.ValidatorIndex]phase0.Gwei) (map[phase0.ValidatorIndex]float64, error)
+}
+
+// SetAttestationProvider enables the attestation effectiveness enrichment of Calculate, nil disables it.
+func SetAttestationProvider(p AttestationProvider) {
+	attestationProviderMu.Lock()
+	defer attestationProviderMu.Unlock()
+	attestationProvider = p
+}
+
+func GetAttestationProvider() AttestationProvider {
+	attestationProviderMu.Lock()
+	defer attestationProviderMu.Unlock()
+	return attestationProvider
+}
+
+// RewardsAttestationProvider derives the attestation effectiveness from the attestation rewards api of a
+// consensus node as the ratio of the actual to the ideal head, target and source rewards.
+type RewardsAttestationProvider struct {
+	Address string
+}
+
+func NewRewardsAttestationProvider(address string) *RewardsAttestationProvider {
+	return &RewardsAttestationProvider{Address: address}
+}
+
+type attestationRewardsResponse struct {
+	Data struct {
+		IdealRewards []struct {
+			EffectiveBalance string `json:"effective_balance"`
+			Head             string `json:"head"`
+			Target           string `json:"target"`
+			Source           string `json:"source"`
+		} `json:"ideal_rewards"`
+		TotalRewards []struct {
+			ValidatorIndex string `json:"validator_index"`
+			Head           string `json:"head"`
+			Target         string `json:"target"`
+			Source         string `json:"source"`
+			Inactivity     string `json:"inactivity"`
+		} `json:"total_rewards"`
+	} `json:"data"`
+}
+
+func (p *RewardsAttestationProvider) attestationRewards(ctx context.Context, epoch phase0.Epoch, effectiveBalances map[phase0.ValidatorIndex]phase0.Gwei) (*attestationRewardsResponse, error) {
+	reqIndices := make([]string, 0, len(effectiveBalances))
+	for idx := range effectiveBalances {
+		reqIndices = append(reqIndices, fmt.Sprintf("%d", idx))
+	}
+	reqBody, err := json.Marshal(reqIndices)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, calculateOptionsOf(ctx).getConsTimeout())
+	defer cancel()
+	url := fmt.Sprintf("%s/eth/v1/beacon/rewards/attestations/%d", strings.TrimSuffix(p.Address, "/"), epoch)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting attestation rewards for epoch %v: %w", epoch, err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error requesting attestation rewards for epoch %v: status %v: %s", epoch, res.StatusCode, body)
+	}
+	var rewards attestationRewardsResponse
+	err = json.Unmarshal(body, &rewards)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing attestation rewards for epoch %v: %w", epoch, err)
+	}
+	return &rewards, nil
+}
+
+func (p *RewardsAttestationProvider) AttestationEffectiveness(ctx context.Context, epoch phase0.Epoch, effectiveBalances map[phase0.ValidatorIndex]phase0.Gwei) (map[phase0.ValidatorIndex]float64, error) {
+	rewards, err := p.attestationRewards(ctx, epoch, effectiveBalances)
+	if err != nil {
+		return nil, err
+	}
+
+	idealRewardsByEffectiveBalance := map[string]int64{}
+	for _, r := range rewards.Data.IdealRewards {
+		idealRewardsByEffectiveBalance[r.EffectiveBalance] = parseInt(r.Head) + parseInt(r.Target) + parseInt(r.Source)
+	}
+
+	result := make(map[phase0.ValidatorIndex]float64, len(effectiveBalances))
+	for _, r := range rewards.Data.TotalRewards {
+		idx, err := strconv.ParseUint(r.ValidatorIndex, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing attestation rewards for epoch %v: %w", epoch, err)
+		}
+		effectiveBalance, exists := effectiveBalances[phase0.ValidatorIndex(idx)]
+		if !exists {
+			continue
+		}
+		result[phase0.ValidatorIndex(idx)] = 0
+		// rewards are negative if the attestation was missed, effectiveness is capped at 0 in that case
+		actual := parseInt(r.Head) + parseInt(r.Target) + parseInt(r.Source)
+		ideal := idealRewardsByEffectiveBalance[fmt.Sprintf("%d", effectiveBalance)]
+		if actual > 0 && ideal > 0 {
+			result[phase0.ValidatorIndex(idx)] = float64(actual) / float64(ideal)
+		}
+	}
+	return result, nil
+}
+
+func (p *RewardsAttestationProvider) InactivityPenalties(ctx context.Context, epoch phase0.Epoch, effectiveBalances map[phase0.ValidatorIndex]phase0.Gwei) (phase0.Gwei, error) {
+	rewards, err := p.attestationRewards(ctx, epoch, effectiveBalances)
+	if err != nil {
+		return 0, err
+	}
+	var penalties phase0.Gwei
+	for _, r := range rewards.Data.TotalRewards {
+		idx, err := strconv.ParseUint(r.ValidatorIndex, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing attestation rewards for epoch %v: %w", epoch, err)
+		}
+		if _, exists := effectiveBalances[phase0.ValidatorIndex(idx)]; !exists {
+			continue
+		}
+		if inactivity := parseInt(r.Inactivity); inactivity < 0 {
+			penalties += phase0.Gwei(-inactivity)
+		}
+	}
+	return penalties, nil
+}
+
+func parseInt(s string) int64 {
+	v, _ := strconv.ParseInt(s, 10, 64)
+	return v
+}
+
+// getAttestationEffectiveness returns the average attestation effectiveness of every given validator over the given epochs.
+func getAttestationEffectiveness(ctx context.Context, p AttestationProvider, firstEpoch, lastEpoch uint64, effectiveBalances map[phase0.ValidatorIndex]phase0.Gwei, concurrency int) (map[phase0.ValidatorIndex]float64, error) {
+	sums := make(map[phase0.ValidatorIndex]float64, len(effectiveBalances))
+	sumsMu := sync.Mutex{}
+	g := new(errgroup.Group)
+	g.SetLimit(concurrency)
+	for e := firstEpoch; e <= lastEpoch; e++ {
+		e := e
+		g.Go(func() error {
+			effectiveness, err := p.AttestationEffectiveness(ctx, phase0.Epoch(e), effectiveBalances)
+			if err != nil {
+				return err
+			}
+			sumsMu.Lock()
+			defer sumsMu.Unlock()
+			for idx := range effectiveBalances {
+				sums[idx] += effectiveness[idx]
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	epochs := float64(lastEpoch - firstEpoch + 1)
+	for idx := range sums {
+		sums[idx] /= epochs
+	}
+	return sums, nil
+}
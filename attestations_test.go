@@ -0,0 +1,62 @@
+package ethstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestRewardsAttestationProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/eth/v1/beacon/rewards/attestations/100" {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"data":{"ideal_rewards":[
+			{"effective_balance":"31000000000","head":"2000","target":"4000","source":"2000","inclusion_delay":"0","inactivity":"0"},
+			{"effective_balance":"32000000000","head":"2500","target":"5000","source":"2500","inclusion_delay":"0","inactivity":"0"}
+		],"total_rewards":[
+			{"validator_index":"1","head":"2500","target":"5000","source":"2500","inclusion_delay":"0","inactivity":"0"},
+			{"validator_index":"2","head":"0","target":"5000","source":"2500","inclusion_delay":"0","inactivity":"0"},
+			{"validator_index":"3","head":"0","target":"-5000","source":"-2500","inclusion_delay":"0","inactivity":"-1200"},
+			{"validator_index":"4","head":"2000","target":"4000","source":"2000","inclusion_delay":"0","inactivity":"0"},
+			{"validator_index":"5","head":"2500","target":"5000","source":"2500","inclusion_delay":"0","inactivity":"-800"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	p := NewRewardsAttestationProvider(server.URL)
+	effectiveBalances := map[phase0.ValidatorIndex]phase0.Gwei{1: 32e9, 2: 32e9, 3: 32e9, 4: 31e9}
+	effectiveness, err := p.AttestationEffectiveness(context.Background(), 100, effectiveBalances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[phase0.ValidatorIndex]float64{1: 1, 2: 0.75, 3: 0, 4: 1}
+	if len(effectiveness) != len(expected) {
+		t.Errorf("wrong number of validators: %v != %v", len(effectiveness), len(expected))
+	}
+	for idx, e := range expected {
+		if effectiveness[idx] != e {
+			t.Errorf("wrong effectiveness of validator %v: %v != %v", idx, effectiveness[idx], e)
+		}
+	}
+
+	// validator 5 is not part of the requested validators, so only the penalty of validator 3 is accounted
+	penalties, err := p.InactivityPenalties(context.Background(), 100, effectiveBalances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if penalties != 1200 {
+		t.Errorf("wrong inactivity penalties: %v != %v", penalties, 1200)
+	}
+
+	avg, err := getAttestationEffectiveness(context.Background(), p, 100, 100, effectiveBalances, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg[2] != 0.75 {
+		t.Errorf("wrong average effectiveness of validator 2: %v != %v", avg[2], 0.75)
+	}
+}
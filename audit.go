@@ -0,0 +1,103 @@
+package ethstore
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"time"
+)
+
+// AuditRecord records a calculation of a day in the audit log.
+type AuditRecord struct {
+	Time        time.Time   `json:"time"`
+	Day         uint64      `json:"day"`
+	Trigger     string      `json:"trigger"`
+	User        string      `json:"user"`
+	Host        string      `json:"host"`
+	ConsAddress string      `json:"consAddress"`
+	ExecAddress string      `json:"execAddress"`
+	Duration    string      `json:"duration"`
+	ResultHash  string      `json:"resultHash,omitempty"`
+	InputHash   string      `json:"inputHash,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	Provenance  *Provenance `json:"provenance"`
+}
+
+// NewAuditRecord returns the audit record of a calculation of a day that started at the given time and returned the
+// given day or error. Credentials and query parameters of the addresses are redacted.
+func NewAuditRecord(day uint64, trigger, consAddress, execAddress string, start time.Time, d *Day, calcErr error) (*AuditRecord, error) {
+	r := &AuditRecord{
+		Time:        start.UTC(),
+		Day:         day,
+		Trigger:     trigger,
+		ConsAddress: redactAddress(consAddress),
+		ExecAddress: redactAddress(execAddress),
+		Duration:    time.Since(start).Round(time.Millisecond).String(),
+		Provenance:  BuildInfo(),
+	}
+	if u, err := user.Current(); err == nil {
+		r.User = u.Username
+	}
+	if h, err := os.Hostname(); err == nil {
+		r.Host = h
+	}
+	if calcErr != nil {
+		r.Error = calcErr.Error()
+		return r, nil
+	}
+	if d != nil {
+		h, err := ResultHash(d)
+		if err != nil {
+			return nil, err
+		}
+		r.ResultHash = h
+		r.InputHash = d.InputHash
+	}
+	return r, nil
+}
+
+// ResultHash returns the sha256 hash of the json-encoding of the given day.
+func ResultHash(d *Day) (string, error) {
+	dayJson, err := json.Marshal(d)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling day: %w", err)
+	}
+	return fmt.Sprintf("%#x", sha256.Sum256(dayJson)), nil
+}
+
+// AppendAuditRecord appends the record as a json-line to the audit log at the given path, existing records are never
+// modified.
+func AppendAuditRecord(path string, r *AuditRecord) error {
+	recordJson, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("error marshaling audit record: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	_, err = f.Write(append(recordJson, '\n'))
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// redactAddress removes the credentials and query parameters (e.g. api keys) of an endpoint address.
+func redactAddress(address string) string {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return address
+	}
+	if u.User != nil {
+		u.User = url.User("redacted")
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "redacted"
+	}
+	return u.String()
+}
@@ -0,0 +1,103 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+// BackfillProgress is the progress of a backfill that is written to a file after every day, so that an interrupted
+// backfill resumes where it stopped. NextDay is the first day that has not been stored yet and Checkpoint holds the
+// partial calculation of NextDay if the backfill was cancelled while calculating it.
+type BackfillProgress struct {
+	NextDay    uint64      `json:"nextDay"`
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+}
+
+// ReadBackfillProgress returns the progress recorded in the given file or nil if the file does not exist.
+func ReadBackfillProgress(path string) (*BackfillProgress, error) {
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading backfill progress: %w", err)
+	}
+	p := &BackfillProgress{}
+	err = json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing backfill progress: %w", err)
+	}
+	return p, nil
+}
+
+// WriteBackfillProgress writes the progress to the given file, the file is replaced atomically.
+func WriteBackfillProgress(path string, p *BackfillProgress) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(path, data)
+	if err != nil {
+		return fmt.Errorf("error writing backfill progress: %w", err)
+	}
+	return nil
+}
+
+// Backfill calculates the days [from, to] one after another like Calculate, puts them into the store and records the
+// progress in progressFile after every day. If progressFile holds the progress of an earlier backfill the days before
+// its NextDay are skipped, and a day that was cancelled halfway is resumed from its checkpoint. When ctx is cancelled
+// during a day, the partial result is recorded before the error is returned. onDay, if not nil, is called with every
+// stored day and its per-validator results. The backfill stops with ErrAnomalousDay at a day whose apr is outside of
+// the apr bounds.
+func Backfill(ctx context.Context, bnAddress, elAddress string, store ResultStore, from, to uint64, progressFile string, concurrency int, onDay func(d *Day, validatorDays map[uint64]*Day)) error {
+	progress, err := ReadBackfillProgress(progressFile)
+	if err != nil {
+		return err
+	}
+	if progress == nil || progress.NextDay < from {
+		progress = &BackfillProgress{NextDay: from}
+	} else if progress.NextDay > from {
+		log.Printf("resuming backfill at day %v", progress.NextDay)
+	}
+	for day := progress.NextDay; day <= to; day++ {
+		var d *Day
+		var validatorDays map[uint64]*Day
+		if cp := progress.Checkpoint; cp != nil && cp.Day == day {
+			log.Printf("resuming day %v after %v processed slots", day, len(cp.ProcessedSlots))
+			d, validatorDays, err = Resume(ctx, bnAddress, elAddress, cp, concurrency)
+		} else {
+			d, validatorDays, err = Calculate(ctx, bnAddress, elAddress, fmt.Sprintf("%d", day), concurrency)
+		}
+		var partial *PartialResultError
+		if errors.As(err, &partial) {
+			progress.Checkpoint = partial.Checkpoint
+			if err := WriteBackfillProgress(progressFile, progress); err != nil {
+				log.Printf("error recording checkpoint of day %v: %v", day, err)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error calculating day %v: %w", day, err)
+		}
+		if d.Anomalous {
+			return fmt.Errorf("%w: not storing day %v with apr %v", ErrAnomalousDay, day, d.Apr.StringFixed(9))
+		}
+		err = store.Put(ctx, d)
+		if err != nil {
+			return fmt.Errorf("error storing day %v: %w", day, err)
+		}
+		progress = &BackfillProgress{NextDay: day + 1}
+		err = WriteBackfillProgress(progressFile, progress)
+		if err != nil {
+			return err
+		}
+		if onDay != nil {
+			onDay(d, validatorDays)
+		}
+	}
+	return nil
+}
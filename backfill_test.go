@@ -0,0 +1,89 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBackfill(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var blockRequests uint64
+	var cancelAfterRequests uint32
+	bnServer, elServer := newEthstoreMockServers(t, func(r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/eth/v2/beacon/blocks/") {
+			return
+		}
+		if slot, _ := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/eth/v2/beacon/blocks/"), 10, 64); slot < 10*225*32 {
+			return
+		}
+		if atomic.AddUint64(&blockRequests, 1) == 2000 && atomic.LoadUint32(&cancelAfterRequests) == 1 {
+			cancel()
+		}
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	expected, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreUint64(&blockRequests, 0)
+	atomic.StoreUint32(&cancelAfterRequests, 1)
+
+	// the interrupted day is recorded as checkpoint
+	store := NewMemoryStore()
+	progressFile := filepath.Join(t.TempDir(), "backfill.json")
+	err = Backfill(ctx, bnServer.URL, elServer.URL, store, 10, 10, progressFile, 10, nil)
+	var partial *PartialResultError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected PartialResultError, got: %v", err)
+	}
+	progress, err := ReadBackfillProgress(progressFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress == nil || progress.NextDay != 10 || progress.Checkpoint == nil || len(progress.Checkpoint.ProcessedSlots) == 0 {
+		t.Fatalf("wrong progress after interruption: %+v", progress)
+	}
+	processed := len(progress.Checkpoint.ProcessedSlots)
+
+	// the day is resumed from the checkpoint
+	atomic.StoreUint32(&cancelAfterRequests, 0)
+	atomic.StoreUint64(&blockRequests, 0)
+	storedDays := 0
+	onDay := func(d *Day, validatorDays map[uint64]*Day) {
+		storedDays++
+	}
+	err = Backfill(context.Background(), bnServer.URL, elServer.URL, store, 10, 10, progressFile, 10, onDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests := atomic.LoadUint64(&blockRequests); requests != uint64(7200-processed) {
+		t.Errorf("wrong number of block requests after resume: %v != %v", requests, 7200-processed)
+	}
+	d, err := store.Get(context.Background(), 10)
+	if err != nil || !d.Apr.Equal(expected.Apr) {
+		t.Errorf("wrong stored day: %+v, %v", d, err)
+	}
+	progress, err = ReadBackfillProgress(progressFile)
+	if err != nil || progress.NextDay != 11 || progress.Checkpoint != nil {
+		t.Errorf("wrong progress after backfill: %+v, %v", progress, err)
+	}
+
+	// completed days are not calculated again
+	atomic.StoreUint64(&blockRequests, 0)
+	err = Backfill(context.Background(), bnServer.URL, elServer.URL, store, 10, 10, progressFile, 10, onDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests := atomic.LoadUint64(&blockRequests); requests != 0 || storedDays != 1 {
+		t.Errorf("completed day calculated again: %v block requests, %v stored days", requests, storedDays)
+	}
+}
@@ -0,0 +1,76 @@
+package ethstore
+
+import (
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+var expectedParticipation = decimal.NewFromInt(1)
+var expectedParticipationMu = sync.Mutex{}
+
+// SetExpectedParticipation sets the share of the active balance that is assumed to participate in consensus for the
+// consensus baseline, 1 assumes that all validators perform all of their duties.
+func SetExpectedParticipation(p decimal.Decimal) {
+	expectedParticipationMu.Lock()
+	defer expectedParticipationMu.Unlock()
+	expectedParticipation = p
+}
+
+func GetExpectedParticipation() decimal.Decimal {
+	expectedParticipationMu.Lock()
+	defer expectedParticipationMu.Unlock()
+	return expectedParticipation
+}
+
+// integerSquareRoot is integer_squareroot of the consensus specs.
+func integerSquareRoot(n uint64) uint64 {
+	x := n
+	y := (x + 1) / 2
+	for y < x {
+		x = y
+		y = (x + n/x) / 2
+	}
+	return x
+}
+
+// consensusBaseline holds the spec parameters of the base reward of a day.
+type consensusBaseline struct {
+	baseRewardPerIncrement    uint64
+	effectiveBalanceIncrement uint64
+	epochs                    uint64
+	participation             decimal.Decimal
+}
+
+// newConsensusBaseline computes the base reward per increment (EFFECTIVE_BALANCE_INCREMENT * BASE_REWARD_FACTOR /
+// integer_squareroot(total active balance)), which is the consensus reward of an increment of effective balance per
+// epoch if all duties are performed with full participation.
+func newConsensusBaseline(totalActiveBalanceGwei phase0.Gwei, baseRewardFactor, effectiveBalanceIncrement, epochs uint64, participation decimal.Decimal) *consensusBaseline {
+	b := &consensusBaseline{
+		effectiveBalanceIncrement: effectiveBalanceIncrement,
+		epochs:                    epochs,
+		participation:             participation,
+	}
+	if sqrt := integerSquareRoot(uint64(totalActiveBalanceGwei)); sqrt > 0 {
+		b.baseRewardPerIncrement = effectiveBalanceIncrement * baseRewardFactor / sqrt
+	}
+	return b
+}
+
+// expectedRewardsGwei returns the expected consensus rewards of the day of the given effective balance.
+func (b *consensusBaseline) expectedRewardsGwei(effectiveBalanceGwei phase0.Gwei) decimal.Decimal {
+	if b.effectiveBalanceIncrement == 0 {
+		return decimal.Zero
+	}
+	increments := uint64(effectiveBalanceGwei) / b.effectiveBalanceIncrement
+	return decimal.NewFromInt(int64(increments * b.baseRewardPerIncrement * b.epochs)).Mul(b.participation)
+}
+
+// baselineRatio returns the ratio of the actual to the expected consensus rewards, it is 0 if no rewards are expected.
+func baselineRatio(actualGwei, expectedGwei decimal.Decimal) decimal.Decimal {
+	if !expectedGwei.IsPositive() {
+		return decimal.Zero
+	}
+	return actualGwei.Div(expectedGwei)
+}
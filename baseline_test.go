@@ -0,0 +1,59 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIntegerSquareRoot(t *testing.T) {
+	for n, sqrt := range map[uint64]uint64{0: 0, 1: 1, 3: 1, 4: 2, 15: 3, 16: 4, 10923834000000000: 104517146} {
+		if r := integerSquareRoot(n); r != sqrt {
+			t.Errorf("wrong integer square root of %v: %v != %v", n, r, sqrt)
+		}
+	}
+}
+
+func TestConsensusBaseline(t *testing.T) {
+	// total active balance of mainnet day 497
+	b := newConsensusBaseline(10923834000000000, 64, 1e9, 225, decimal.NewFromInt(1))
+	if b.baseRewardPerIncrement != 612 {
+		t.Fatalf("wrong base reward per increment: %v != %v", b.baseRewardPerIncrement, 612)
+	}
+	if e := b.expectedRewardsGwei(32e9); !e.Equal(decimal.NewFromInt(32 * 612 * 225)) {
+		t.Errorf("wrong expected rewards: %v", e)
+	}
+	// effective balance that is not a multiple of the increment is rounded down
+	b = newConsensusBaseline(10923834000000000, 64, 1e9, 225, decimal.RequireFromString("0.5"))
+	if e := b.expectedRewardsGwei(32.5e9); !e.Equal(decimal.NewFromInt(16 * 612 * 225)) {
+		t.Errorf("wrong expected rewards with half participation: %v", e)
+	}
+	if r := baselineRatio(decimal.NewFromInt(3), decimal.NewFromInt(4)); !r.Equal(decimal.RequireFromString("0.75")) {
+		t.Errorf("wrong ratio: %v", r)
+	}
+	if r := baselineRatio(decimal.NewFromInt(3), decimal.Zero); !r.IsZero() {
+		t.Errorf("wrong ratio without expected rewards: %v", r)
+	}
+}
+
+func TestConsensusBaselineOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, validatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.ConsensusBaselineGwei.IsPositive() || !day.ConsensusBaselineRatio.Equal(day.ConsensusRewardsGwei.Div(day.ConsensusBaselineGwei)) {
+		t.Errorf("wrong consensus baseline: %v, ratio: %v", day.ConsensusBaselineGwei, day.ConsensusBaselineRatio)
+	}
+	sum := decimal.Zero
+	for _, d := range validatorDays {
+		sum = sum.Add(d.ConsensusBaselineGwei)
+	}
+	if !sum.Equal(day.ConsensusBaselineGwei) {
+		t.Errorf("consensus baselines of the validators do not add up: %v != %v", sum, day.ConsensusBaselineGwei)
+	}
+}
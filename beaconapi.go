@@ -0,0 +1,109 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// getBeaconJson requests the given path from the beacon api and decodes the response into dst,
+// it returns false without an error if the beacon node responds with 404.
+func getBeaconJson(ctx context.Context, address, path string, dst interface{}) (bool, error) {
+	status, _, body, err := getBeacon(ctx, address, path, "application/json")
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusNotFound {
+		return false, nil
+	}
+	if status != http.StatusOK {
+		return false, fmt.Errorf("status %v: %s", status, body)
+	}
+	err = decodeResponse(ctx, path, func(check func() error) error {
+		return unmarshalJSON(body, dst, check)
+	})
+	if errors.Is(err, ErrDecodeTimeout) || ctx.Err() != nil {
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("error parsing response: %w", err)
+	}
+	return true, nil
+}
+
+// getBeacon requests the given path from the beacon api with the given Accept header and returns the status,
+// the header and the body of the response, the size of the body is limited by the maximum response size. Responses
+// for finalized slots are taken from and added to the response cache of the address.
+func getBeacon(ctx context.Context, address, path, accept string) (int, http.Header, []byte, error) {
+	cache := getResponseCache(address)
+	if header, body, ok := cache.response(path, accept); ok {
+		return http.StatusOK, header, body, nil
+	}
+	status, header, body, err := requestBeacon(ctx, address, path, accept)
+	if err == nil && status == http.StatusOK && len(body) > 0 {
+		cache.setResponse(path, header, body)
+	}
+	return status, header, body, err
+}
+
+// requestBeacon requests the path from the consensus node at the given address, if the address belongs to a failover
+// pool a failed request is retried on the other endpoints of the pool. Requests that failed with a transient error are
+// retried according to the retry policy.
+func requestBeacon(ctx context.Context, address, path, accept string) (status int, header http.Header, body []byte, err error) {
+	withRetries(ctx, path, func() (int, error) {
+		status, header, body, err = requestBeaconOnce(ctx, address, path, accept)
+		return status, err
+	})
+	return status, header, body, err
+}
+
+func requestBeaconOnce(ctx context.Context, address, path, accept string) (int, http.Header, []byte, error) {
+	release, err := acquireRequest(ctx)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer release()
+	if getter := getBeaconAPIGetter(ctx); getter != nil {
+		ctx, cancel := context.WithTimeout(ctx, calculateOptionsOf(ctx).getConsTimeout())
+		defer cancel()
+		return getter.GetBeaconAPI(ctx, path, accept)
+	}
+	endpoints := failoverEndpoints(address)
+	for i, endpoint := range endpoints {
+		status, header, body, err := requestBeaconEndpoint(ctx, endpoint, path, accept)
+		if i == len(endpoints)-1 || !retryOnNextEndpoint(ctx, status, err) {
+			if err == nil && status < http.StatusInternalServerError {
+				failoverAnswered(address, endpoint)
+			}
+			return status, header, body, err
+		}
+		if err == nil {
+			err = fmt.Errorf("status %v: %s", status, body)
+		}
+		log.Printf("error requesting %v from consensus node %v, trying next endpoint: %v", path, endpoint, err)
+	}
+	return 0, nil, nil, fmt.Errorf("no endpoints for %v", address)
+}
+
+func requestBeaconEndpoint(ctx context.Context, endpoint, path, accept string) (int, http.Header, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, calculateOptionsOf(ctx).getConsTimeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+path, nil)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	req.Header.Set("Accept", accept)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer res.Body.Close()
+	body, err := readResponse(path, res.ContentLength, res.Body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return res.StatusCode, res.Header, body, nil
+}
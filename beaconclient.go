@@ -0,0 +1,99 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	eth2http "github.com/attestantio/go-eth2-client/http"
+)
+
+// BeaconClient is the interface to the consensus node that the calculation of a day uses. HTTPBeaconClient, which
+// Calculate connects to the node with, implements it, other implementations can e.g. cache responses, wrap nodes
+// without the standard beacon API or serve test data. The few remaining requests of the beacon API that are not part
+// of BeaconClient (e.g. the sync status and the orphaned blocks) are sent to the address of the client unless it
+// implements BeaconAPIGetter.
+type BeaconClient interface {
+	eth2client.Service
+	eth2client.SpecProvider
+	eth2client.GenesisTimeProvider
+	eth2client.BeaconBlockHeadersProvider
+	eth2client.BeaconStateRootProvider
+	eth2client.ValidatorsProvider
+	eth2client.ValidatorBalancesProvider
+	eth2client.FinalityProvider
+	eth2client.NodeVersionProvider
+	eth2client.DepositContractProvider
+	BeaconBlockProvider
+}
+
+// BeaconBlockProvider provides the blocks of any fork, including the withdrawals and the blob sidecars of the fork
+// that added them.
+type BeaconBlockProvider interface {
+	// BeaconBlock returns the block at the slot, it returns nil without an error if the slot was missed.
+	BeaconBlock(ctx context.Context, slot uint64) (*SignedBeaconBlock, error)
+	// BlindedBeaconBlock returns the blinded block at the slot, it returns nil without an error if the slot was
+	// missed.
+	BlindedBeaconBlock(ctx context.Context, slot uint64) (*SignedBlindedBeaconBlock, error)
+	// BlobSidecars returns the blob sidecars of the block at the slot, it returns nil without an error if they are
+	// not available, e.g. because they have been pruned.
+	BlobSidecars(ctx context.Context, slot uint64) ([]BlobSidecar, error)
+}
+
+// HTTPBeaconClient is the BeaconClient of a consensus node with the standard beacon API, the blocks are requested from
+// the address of the http client of go-eth2-client.
+type HTTPBeaconClient struct {
+	*eth2http.Service
+}
+
+// NewHTTPBeaconClient connects to the consensus node at the given address, the address may list several nodes
+// separated by commas that are used as a failover pool.
+func NewHTTPBeaconClient(ctx context.Context, address string) (*HTTPBeaconClient, error) {
+	return newConsClient(ctx, address)
+}
+
+func (c *HTTPBeaconClient) BeaconBlock(ctx context.Context, slot uint64) (*SignedBeaconBlock, error) {
+	return getSignedBeaconBlock(ctx, c.Address(), slot)
+}
+
+func (c *HTTPBeaconClient) BlindedBeaconBlock(ctx context.Context, slot uint64) (*SignedBlindedBeaconBlock, error) {
+	return getBlindedBlock(ctx, c.Address(), slot)
+}
+
+func (c *HTTPBeaconClient) BlobSidecars(ctx context.Context, slot uint64) ([]BlobSidecar, error) {
+	return getBlobSidecars(ctx, c.Address(), slot)
+}
+
+// BeaconAPIGetter serves the requests of the beacon API that are not part of BeaconClient, it returns the status, the
+// header and the body of the response to a GET request of the given path with the given Accept header.
+type BeaconAPIGetter interface {
+	GetBeaconAPI(ctx context.Context, path, accept string) (int, http.Header, []byte, error)
+}
+
+type beaconAPIGetterKey struct{}
+
+// withBeaconAPIGetter routes the beacon API requests made with the returned context through the client if it
+// implements BeaconAPIGetter. The getter belongs to the calculation of the context, so clients of different
+// calculations never share it, even if their addresses are the same.
+func withBeaconAPIGetter(ctx context.Context, client BeaconClient) context.Context {
+	getter, ok := client.(BeaconAPIGetter)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, beaconAPIGetterKey{}, getter)
+}
+
+func getBeaconAPIGetter(ctx context.Context) BeaconAPIGetter {
+	getter, _ := ctx.Value(beaconAPIGetterKey{}).(BeaconAPIGetter)
+	return getter
+}
+
+// CalculateWithClient calculates a day like Calculate but uses the given client for the consensus node instead of
+// connecting to it by address. Calls are not coalesced with other calculations of the same day.
+func CalculateWithClient(ctx context.Context, client BeaconClient, elAddress, dayStr string, concurrency int) (*Day, map[uint64]*Day, error) {
+	if client == nil {
+		return nil, nil, fmt.Errorf("no beacon client")
+	}
+	return CalculateWithOptions(ctx, "", elAddress, dayStr, WithBeaconClient(client), WithConcurrency(concurrency))
+}
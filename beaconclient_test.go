@@ -0,0 +1,90 @@
+package ethstore
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// testBeaconClient serves the beacon API of a mocked node under an address that can not be requested directly.
+type testBeaconClient struct {
+	*HTTPBeaconClient
+	url      string
+	requests int32
+}
+
+func (c *testBeaconClient) Address() string {
+	return "test://beacon"
+}
+
+func (c *testBeaconClient) GetBeaconAPI(ctx context.Context, path, accept string) (int, http.Header, []byte, error) {
+	atomic.AddInt32(&c.requests, 1)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	req.Header.Set("Accept", accept)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return res.StatusCode, res.Header, body, nil
+}
+
+func TestCalculateWithClient(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	expected, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := newConsClient(context.Background(), bnServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &testBeaconClient{HTTPBeaconClient: service, url: bnServer.URL}
+	day, validatorDays, err := CalculateWithClient(context.Background(), client, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.Apr.Equal(expected.Apr) || !day.TotalRewardsWei.Equal(expected.TotalRewardsWei) || !day.Validators.Equal(expected.Validators) {
+		t.Errorf("wrong day: apr: %v != %v, totalRewardsWei: %v != %v, validators: %v != %v", day.Apr, expected.Apr, day.TotalRewardsWei, expected.TotalRewardsWei, day.Validators, expected.Validators)
+	}
+	if len(validatorDays) != int(expected.Validators.IntPart()) {
+		t.Errorf("wrong number of validator days: %v", len(validatorDays))
+	}
+	// the blocks can only be requested through the client
+	if n := atomic.LoadInt32(&client.requests); n < 7200 {
+		t.Errorf("expected the blocks to be requested through the client, got %v requests", n)
+	}
+
+	// the requests of a calculation only go through its own client, even if another client has the same address
+	other := &testBeaconClient{HTTPBeaconClient: service, url: bnServer.URL}
+	requests := atomic.LoadInt32(&client.requests)
+	if _, _, err := CalculateWithClient(context.Background(), other, elServer.URL, "10", 10); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&client.requests); n != requests || atomic.LoadInt32(&other.requests) < 7200 {
+		t.Errorf("requests went through the wrong client: %v, %v", n-requests, atomic.LoadInt32(&other.requests))
+	}
+	if _, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&client.requests) != requests {
+		t.Errorf("requests of a calculation by address went through a client")
+	}
+
+	if _, _, err := CalculateWithClient(context.Background(), nil, elServer.URL, "10", 10); err == nil {
+		t.Errorf("expected error without client")
+	}
+}
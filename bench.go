@@ -0,0 +1,134 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	ethhttp "github.com/attestantio/go-eth2-client/http"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+)
+
+// BenchResult holds the throughput of a beacon node for the requests Calculate makes and the predicted duration of
+// the calculation of a day.
+type BenchResult struct {
+	Validators                int           `json:"validators"`
+	StateDuration             time.Duration `json:"stateDuration"`
+	Blocks                    int           `json:"blocks"`
+	SequentialBlocksPerSecond float64       `json:"sequentialBlocksPerSecond"`
+	ConcurrentBlocksPerSecond float64       `json:"concurrentBlocksPerSecond"`
+	Concurrency               int           `json:"concurrency"`
+	SSZSupported              bool          `json:"sszSupported"`
+	SlotsPerDay               uint64        `json:"slotsPerDay"`
+	PredictedDayDuration      time.Duration `json:"predictedDayDuration"`
+}
+
+// Bench measures the throughput of the beacon node at the given address for the request mix of Calculate: downloading
+// the validator-set of the finalized state and fetching the given number of blocks before the finalized block, once
+// sequentially and once with the given concurrency.
+func Bench(ctx context.Context, address string, blocks, concurrency int) (*BenchResult, error) {
+	if blocks < 1 || concurrency < 1 {
+		return nil, fmt.Errorf("blocks and concurrency must be positive")
+	}
+	service, err := ethhttp.New(ctx, ethhttp.WithAddress(address), ethhttp.WithTimeout(GetConsTimeout()), ethhttp.WithLogLevel(zerolog.WarnLevel))
+	if err != nil {
+		return nil, err
+	}
+	client := &HTTPBeaconClient{Service: service.(*ethhttp.Service)}
+	apiSpec, err := client.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secondsPerSlot, err := getSpecDuration(apiSpec, "SECONDS_PER_SLOT")
+	if err != nil {
+		return nil, err
+	}
+	finalizedHeader, err := client.BeaconBlockHeader(ctx, "finalized")
+	if err != nil {
+		return nil, err
+	}
+	finalizedSlot := uint64(finalizedHeader.Header.Message.Slot)
+	if finalizedSlot < uint64(2*blocks) {
+		return nil, fmt.Errorf("not enough finalized slots to fetch %v blocks twice (finalized slot: %v)", blocks, finalizedSlot)
+	}
+
+	res := &BenchResult{
+		Blocks:      blocks,
+		Concurrency: concurrency,
+		SlotsPerDay: uint64(24 * time.Hour / secondsPerSlot),
+	}
+
+	start := time.Now()
+	validators, err := client.Validators(ctx, fmt.Sprintf("%d", finalizedSlot), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validators: %w", err)
+	}
+	res.StateDuration = time.Since(start)
+	res.Validators = len(validators)
+
+	clientName := getBeaconClientName(ctx, client)
+	fetchBlock := func(slot uint64) error {
+		block, err := getSignedBeaconBlock(ctx, address, slot)
+		_, err = normalizeBlockResponse(clientName, block, err)
+		return err
+	}
+
+	// the sequential and the concurrent run fetch different blocks to not measure the cache of the node
+	start = time.Now()
+	for slot := finalizedSlot - uint64(blocks); slot < finalizedSlot; slot++ {
+		if err := fetchBlock(slot); err != nil {
+			return nil, fmt.Errorf("error getting block %v: %w", slot, err)
+		}
+	}
+	res.SequentialBlocksPerSecond = float64(blocks) / time.Since(start).Seconds()
+
+	start = time.Now()
+	g := new(errgroup.Group)
+	g.SetLimit(concurrency)
+	fetched := int64(0)
+	for slot := finalizedSlot - uint64(2*blocks); slot < finalizedSlot-uint64(blocks); slot++ {
+		slot := slot
+		g.Go(func() error {
+			if err := fetchBlock(slot); err != nil {
+				return fmt.Errorf("error getting block %v: %w", slot, err)
+			}
+			atomic.AddInt64(&fetched, 1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	res.ConcurrentBlocksPerSecond = float64(fetched) / time.Since(start).Seconds()
+
+	res.SSZSupported, err = supportsSSZ(ctx, address, finalizedSlot)
+	if err != nil {
+		return nil, err
+	}
+
+	// a day needs the validator-set at its start and at its end and the blocks of all of its slots
+	res.PredictedDayDuration = 2*res.StateDuration + time.Duration(float64(res.SlotsPerDay)/res.ConcurrentBlocksPerSecond*float64(time.Second))
+	return res, nil
+}
+
+// supportsSSZ checks whether the beacon node responds with ssz-encoded blocks if requested.
+func supportsSSZ(ctx context.Context, address string, slot uint64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, GetConsTimeout())
+	defer cancel()
+	url := fmt.Sprintf("%s/eth/v2/beacon/blocks/%d", strings.TrimSuffix(address, "/"), slot)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("error requesting ssz block: %w", err)
+	}
+	defer res.Body.Close()
+	return res.StatusCode == http.StatusOK && strings.HasPrefix(res.Header.Get("Content-Type"), "application/octet-stream"), nil
+}
@@ -0,0 +1,84 @@
+package ethstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBench(t *testing.T) {
+	for _, ssz := range []bool{false, true} {
+		blockRequests := int64(0)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/eth/v1/node/version":
+				w.Write([]byte(`{"data":{"version":"Lighthouse/v2.3.1-564d7da/x86_64-linux"}}`))
+				return
+			case "/eth/v1/beacon/genesis":
+				w.Write([]byte(`{"data":{"genesis_time":"1606824023","genesis_validators_root":"0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95","genesis_fork_version":"0x00000000"}}`))
+				return
+			case "/eth/v1/config/spec":
+				w.Write([]byte(`{"data":{"SECONDS_PER_SLOT":"12","SLOTS_PER_EPOCH":"32"}}`))
+				return
+			case "/eth/v1/config/deposit_contract":
+				w.Write([]byte(`{"data":{"chain_id":"1","address":"0x00000000219ab540356cbb839cbe05303d7705fa"}}`))
+				return
+			case "/eth/v1/config/fork_schedule":
+				w.Write([]byte(`{"data":[{"previous_version":"0x00000000","current_version":"0x00000000","epoch":"0"}]}`))
+				return
+			case "/eth/v1/beacon/headers/finalized":
+				w.Write([]byte(`{"data":{"root":"0x3aee29bcfa7a9fdf01394a3dce74ae063c89023df71867ad1555f1e494d138ee","canonical":true,"header":{"message":{"slot":"100","proposer_index":"1","parent_root":"0x4a451b6a4962bcbd619ee1f0b6a7d85dded49f049877de325122e21350e5d6f2","state_root":"0xf12219d8bcdb7ed125da01e4f7aa30754bff2c9fc0bf57dd728c0b02bb847a92","body_root":"0x31f4433e6e260a0fac6e80ad3f9df1998fbbab269408601a6da7a5d32ccbb258"},"signature":"0x8ccb90ff41ec1f82975fb12384f3d44194b27403f1454e878e9c07c9951df33968556e2ce0dfb8ce42e2e0bbac8c80e211d35d01617712292805bc8d9ac2e3429f821953cfc1dbb9d9ea359cd37b39850f4e29c81fc3d67e150985c609d4e826"}}}`))
+				return
+			case "/eth/v1/beacon/states/100/validators":
+				w.Write([]byte(`{"data":[{"index":"0","balance":"32000000000","status":"active_ongoing","validator":{"pubkey":"0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","withdrawal_credentials":"0x0000000000000000000000000000000000000000000000000000000000000000","effective_balance":"32000000000","slashed":false,"activation_eligibility_epoch":"0","activation_epoch":"0","exit_epoch":"18446744073709551615","withdrawable_epoch":"18446744073709551615"}}]}`))
+				return
+			}
+			if strings.HasPrefix(r.URL.Path, "/eth/v2/beacon/blocks/") {
+				if r.Header.Get("Accept") == "application/octet-stream" {
+					if ssz {
+						w.Header().Set("Content-Type", "application/octet-stream")
+					} else {
+						w.Header().Set("Content-Type", "application/json")
+					}
+					return
+				}
+				atomic.AddInt64(&blockRequests, 1)
+				// every slot is missed, which is a valid response for the benchmark
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"code":404,"message":"NOT_FOUND: beacon block"}`))
+				return
+			}
+			t.Errorf("unexpected request: %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+
+		res, err := Bench(context.Background(), server.URL, 20, 4)
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Validators != 1 {
+			t.Errorf("wrong number of validators: %v != %v", res.Validators, 1)
+		}
+		// the client requests block 0 on startup
+		if blockRequests < 40 {
+			t.Errorf("wrong number of block requests: %v < %v", blockRequests, 40)
+		}
+		if res.SlotsPerDay != 7200 {
+			t.Errorf("wrong slots per day: %v != %v", res.SlotsPerDay, 7200)
+		}
+		if res.SSZSupported != ssz {
+			t.Errorf("wrong ssz support: %v != %v", res.SSZSupported, ssz)
+		}
+		if res.SequentialBlocksPerSecond <= 0 || res.ConcurrentBlocksPerSecond <= 0 || res.PredictedDayDuration <= res.StateDuration {
+			t.Errorf("invalid measurements: %+v", res)
+		}
+	}
+
+	if _, err := Bench(context.Background(), "http://localhost:0", 0, 1); err == nil {
+		t.Errorf("expected error for non-positive blocks")
+	}
+}
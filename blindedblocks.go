@@ -0,0 +1,154 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+)
+
+// executionBlock holds the fields of an execution payload that are needed to calculate the tx fees of a block,
+// it is either taken from a full block or from the payload header of a blinded block and the execution node.
+type executionBlock struct {
+	BlockNumber   uint64
+	BlockHash     common.Hash
+	FeeRecipient  common.Address
+	GasUsed       uint64
+	GasLimit      uint64
+	BaseFeePerGas *big.Int
+	TxHashes      []common.Hash
+	// TxDecodeErrors is the number of transactions of a full block that could not be decoded
+	TxDecodeErrors int
+	// LastTxValue is the value of the last transaction of a full block, it is nil for blinded blocks
+	LastTxValue *big.Int
+	// Withdrawals are the withdrawals of capella and later blocks, they are nil for blocks of earlier forks
+	Withdrawals []Withdrawal
+}
+
+// executionBlockFromPayload decodes the transactions of an execution payload, the hash of a transaction that can not
+// be decoded (e.g. of an unknown tx type) is the hash of its raw encoding. The withdrawals are those of the capella
+// fields of the block.
+func executionBlockFromPayload(payload *bellatrix.ExecutionPayload, withdrawals []Withdrawal) *executionBlock {
+	txHashes := make([]common.Hash, 0, len(payload.Transactions))
+	decodeErrors := 0
+	var lastTxValue *big.Int
+	for i, tx := range payload.Transactions {
+		var decTx gethTypes.Transaction
+		err := decTx.UnmarshalBinary([]byte(tx))
+		if err != nil {
+			decodeErrors++
+			txHashes = append(txHashes, crypto.Keccak256Hash(tx))
+			continue
+		}
+		txHashes = append(txHashes, decTx.Hash())
+		if i == len(payload.Transactions)-1 {
+			lastTxValue = decTx.Value()
+		}
+	}
+	return &executionBlock{
+		BlockNumber:    payload.BlockNumber,
+		BlockHash:      common.Hash(payload.BlockHash),
+		FeeRecipient:   common.Address(payload.FeeRecipient),
+		GasUsed:        payload.GasUsed,
+		GasLimit:       payload.GasLimit,
+		BaseFeePerGas:  baseFeePerGasToBigInt(payload.BaseFeePerGas),
+		TxHashes:       txHashes,
+		TxDecodeErrors: decodeErrors,
+		LastTxValue:    lastTxValue,
+		Withdrawals:    withdrawals,
+	}
+}
+
+// executionBlockFromHeader completes the payload header of a blinded block with the tx hashes and the withdrawals of
+// the execution block with the same hash.
+func executionBlockFromHeader(ctx context.Context, elClient *gethRPC.Client, header *bellatrix.ExecutionPayloadHeader) (*executionBlock, error) {
+	var res struct {
+		Hash         common.Hash   `json:"hash"`
+		Transactions []common.Hash `json:"transactions"`
+		Withdrawals  []struct {
+			Index          hexutil.Uint64 `json:"index"`
+			ValidatorIndex hexutil.Uint64 `json:"validatorIndex"`
+			Address        common.Address `json:"address"`
+			Amount         hexutil.Uint64 `json:"amount"`
+		} `json:"withdrawals"`
+	}
+	err := elClient.CallContext(ctx, &res, "eth_getBlockByHash", common.Hash(header.BlockHash).Hex(), false)
+	if err != nil {
+		return nil, fmt.Errorf("error getting execution block %#x: %w", header.BlockHash, err)
+	}
+	if res.Hash != common.Hash(header.BlockHash) {
+		return nil, fmt.Errorf("execution block %#x not found", header.BlockHash)
+	}
+	var withdrawals []Withdrawal
+	if res.Withdrawals != nil {
+		// the amounts of the execution node are in gwei as well
+		withdrawals = make([]Withdrawal, 0, len(res.Withdrawals))
+		for _, w := range res.Withdrawals {
+			withdrawals = append(withdrawals, Withdrawal{
+				Index:          uint64(w.Index),
+				ValidatorIndex: phase0.ValidatorIndex(w.ValidatorIndex),
+				Address:        bellatrix.ExecutionAddress(w.Address),
+				AmountGwei:     phase0.Gwei(w.Amount),
+			})
+		}
+	}
+	return &executionBlock{
+		BlockNumber:   header.BlockNumber,
+		BlockHash:     common.Hash(header.BlockHash),
+		FeeRecipient:  common.Address(header.FeeRecipient),
+		GasUsed:       header.GasUsed,
+		GasLimit:      header.GasLimit,
+		BaseFeePerGas: baseFeePerGasToBigInt(header.BaseFeePerGas),
+		TxHashes:      res.Transactions,
+		Withdrawals:   withdrawals,
+	}, nil
+}
+
+// baseFeePerGasToBigInt converts the little-endian base fee per gas of an execution payload.
+func baseFeePerGasToBigInt(baseFeePerGas [32]byte) *big.Int {
+	var baseFeePerGasBEBytes [32]byte
+	for i := 0; i < 32; i++ {
+		baseFeePerGasBEBytes[i] = baseFeePerGas[32-1-i]
+	}
+	return new(big.Int).SetBytes(baseFeePerGasBEBytes[:])
+}
+
+// getBlindedBlock requests the blinded block at the given slot, it returns nil without an error if there is no block at the slot.
+func getBlindedBlock(ctx context.Context, address string, slot uint64) (*SignedBlindedBeaconBlock, error) {
+	var data struct {
+		Version string          `json:"version"`
+		Data    json.RawMessage `json:"data"`
+	}
+	found, err := getBeaconJson(ctx, address, fmt.Sprintf("/eth/v1/beacon/blinded_blocks/%d", slot), &data)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting blinded block at slot %v: %w", slot, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	if len(data.Data) == 0 || string(data.Data) == "null" {
+		return nil, nil
+	}
+	// blinded blocks only differ from full blocks since bellatrix, so only those are requested as a fallback. The
+	// fields of capella and deneb blinded blocks are a superset of the fields of bellatrix blinded blocks.
+	switch data.Version {
+	case "bellatrix", "capella", "deneb":
+	default:
+		return nil, fmt.Errorf("unsupported version of blinded block at slot %v: %v", slot, data.Version)
+	}
+	var block v1.SignedBlindedBeaconBlock
+	err = json.Unmarshal(padSyncCommitteeBits(data.Data), &block)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing blinded block at slot %v: %w", slot, err)
+	}
+	return &SignedBlindedBeaconBlock{SignedBlindedBeaconBlock: &block, Fork: data.Version}, nil
+}
@@ -0,0 +1,172 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+	"github.com/gobitfly/eth.store/fixture"
+)
+
+func TestBlindedBlockFallback(t *testing.T) {
+	blockHash := common.HexToHash("0x8145108c4ba0bd6507019ee9ef1eaa225daa0fd220bfea44f5e1d3b58c313875")
+	txHash := common.HexToHash("0xa515aea9c1b298c2947454902af1738af230030553943ba5cc738cbabfca9a4e")
+	header := &bellatrix.ExecutionPayloadHeader{
+		BlockNumber: 1663387,
+		GasUsed:     230800,
+		ExtraData:   []byte{},
+	}
+	copy(header.BlockHash[:], blockHash[:])
+	feeRecipient := common.HexToAddress("0x388c818ca8b9251b393131c08a736a67ccb19297")
+	copy(header.FeeRecipient[:], feeRecipient[:])
+	header.BaseFeePerGas[0] = 10
+	block := &v1.SignedBlindedBeaconBlock{
+		Message: &v1.BlindedBeaconBlock{
+			Slot:          100,
+			ProposerIndex: 7,
+			Body: &v1.BlindedBeaconBlockBody{
+				ETH1Data:               &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+				Graffiti:               make([]byte, 32),
+				ProposerSlashings:      []*phase0.ProposerSlashing{},
+				AttesterSlashings:      []*phase0.AttesterSlashing{},
+				Attestations:           []*phase0.Attestation{},
+				Deposits:               []*phase0.Deposit{},
+				VoluntaryExits:         []*phase0.SignedVoluntaryExit{},
+				SyncAggregate:          &altair.SyncAggregate{SyncCommitteeBits: make([]byte, 64)},
+				ExecutionPayloadHeader: header,
+			},
+		},
+	}
+	blockJson, err := json.Marshal(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bnServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/beacon/blinded_blocks/100":
+			w.Write([]byte(fmt.Sprintf(`{"version":"bellatrix","data":%s}`, blockJson)))
+		case "/eth/v1/beacon/blinded_blocks/101":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND: beacon block at slot 101"}`))
+		case "/eth/v1/beacon/blinded_blocks/102":
+			w.Write([]byte(`{"version":"altair","data":{}}`))
+		case "/eth/v1/beacon/blinded_blocks/103":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			t.Errorf("unexpected request: %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer bnServer.Close()
+
+	elServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"eth_getBlockByHash"`) {
+			t.Errorf("unexpected request: %s", body)
+		}
+		if strings.Contains(string(body), blockHash.Hex()) {
+			w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"result":{"hash":"%s","number":"0x19619b","transactions":["%s"]}}`, blockHash.Hex(), txHash.Hex())))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+	}))
+	defer elServer.Close()
+	elClient, err := gethRPC.Dial(elServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blinded, err := getBlindedBlock(context.Background(), bnServer.URL, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blinded == nil || blinded.Message.ProposerIndex != 7 {
+		t.Fatalf("wrong blinded block: %+v", blinded)
+	}
+	exec, err := executionBlockFromHeader(context.Background(), elClient, blinded.Message.Body.ExecutionPayloadHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec.BlockNumber != 1663387 || exec.GasUsed != 230800 || exec.BlockHash != blockHash || exec.FeeRecipient != feeRecipient {
+		t.Errorf("wrong execution block: %+v", exec)
+	}
+	if exec.BaseFeePerGas.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("wrong BaseFeePerGas: %v != %v", exec.BaseFeePerGas, 10)
+	}
+	if len(exec.TxHashes) != 1 || exec.TxHashes[0] != txHash {
+		t.Errorf("wrong TxHashes: %v != %v", exec.TxHashes, []common.Hash{txHash})
+	}
+
+	// the execution node does not know the block
+	unknownHeader := *header
+	unknownHeader.BlockHash[0] = 0
+	if _, err := executionBlockFromHeader(context.Background(), elClient, &unknownHeader); err == nil {
+		t.Errorf("expected error for unknown execution block")
+	}
+
+	blinded, err = getBlindedBlock(context.Background(), bnServer.URL, 101)
+	if err != nil || blinded != nil {
+		t.Errorf("expected missed slot, got %v (err: %v)", blinded, err)
+	}
+	if _, err := getBlindedBlock(context.Background(), bnServer.URL, 102); err == nil {
+		t.Errorf("expected error for altair blinded block")
+	}
+	if _, err := getBlindedBlock(context.Background(), bnServer.URL, 103); err == nil {
+		t.Errorf("expected error for status 500")
+	}
+}
+
+func TestBlindedBlockCanceled(t *testing.T) {
+	f, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the full blocks are unavailable and the execution node does not know the blocks of the blinded blocks
+	bn, el := f.BeaconHandler(), f.ExecutionHandler()
+	bnServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/eth/v2/beacon/blocks/") {
+			http.Error(w, `{"code":500,"message":"payload unavailable"}`, http.StatusInternalServerError)
+			return
+		}
+		bn.ServeHTTP(w, r)
+	}))
+	defer bnServer.Close()
+	elServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), `"eth_getBlockByHash"`) {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		el.ServeHTTP(w, r)
+	}))
+	defer elServer.Close()
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+	defer SetRetryPolicy(DefaultRetryPolicy)
+
+	// the retries of the execution blocks stop with the calculation
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := Calculate(ctx, bnServer.URL, elServer.URL, "10", 10); err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("retries continued after the calculation was canceled: %v", elapsed)
+	}
+}
@@ -0,0 +1,152 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// gasPerBlob is the blob gas consumed by every blob of a block (EIP-4844).
+const gasPerBlob = 1 << 17
+
+var blobVerification bool
+var blobVerificationMu = sync.Mutex{}
+
+// SetBlobVerification enables cross-checking the blob gas accounting of deneb blocks against their blob sidecars.
+func SetBlobVerification(enabled bool) {
+	blobVerificationMu.Lock()
+	defer blobVerificationMu.Unlock()
+	blobVerification = enabled
+}
+
+func GetBlobVerification() bool {
+	blobVerificationMu.Lock()
+	defer blobVerificationMu.Unlock()
+	return blobVerification
+}
+
+// BlobDiscrepancy describes a block whose blob gas accounting does not match its blob sidecars.
+type BlobDiscrepancy struct {
+	Slot   uint64 `json:"slot"`
+	Reason string `json:"reason"`
+}
+
+// BlobSidecar is a blob sidecar of a deneb block without the blob and its proofs.
+type BlobSidecar struct {
+	Index         uint64
+	Slot          uint64
+	KZGCommitment KZGCommitment
+}
+
+type blobSidecarsResponse struct {
+	Data []struct {
+		Index             string `json:"index"`
+		KzgCommitment     string `json:"kzg_commitment"`
+		SignedBlockHeader struct {
+			Message struct {
+				Slot string `json:"slot"`
+			} `json:"message"`
+		} `json:"signed_block_header"`
+	} `json:"data"`
+}
+
+// getBlobSidecars requests the blob sidecars of the block at the given slot, it returns nil without an error if they
+// are not available.
+func getBlobSidecars(ctx context.Context, address string, slot uint64) ([]BlobSidecar, error) {
+	var res blobSidecarsResponse
+	found, err := getBeaconJson(ctx, address, fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", slot), &res)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting blob sidecars at slot %v: %w", slot, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	sidecars := make([]BlobSidecar, 0, len(res.Data))
+	for _, s := range res.Data {
+		var sidecar BlobSidecar
+		sidecar.Index, err = strconv.ParseUint(s.Index, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blob sidecar index: %w", err)
+		}
+		sidecar.Slot, err = strconv.ParseUint(s.SignedBlockHeader.Message.Slot, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slot of blob sidecar %v: %w", sidecar.Index, err)
+		}
+		if err := decodeHexInto(s.KzgCommitment, sidecar.KZGCommitment[:]); err != nil {
+			return nil, fmt.Errorf("invalid kzg commitment of blob sidecar %v: %w", sidecar.Index, err)
+		}
+		sidecars = append(sidecars, sidecar)
+	}
+	return sidecars, nil
+}
+
+// verifyBlobs cross-checks the blob gas used of the deneb blocks in the slot interval [firstSlot,endSlot) against
+// their kzg commitments and blob sidecars, blocks of other forks and missed slots are skipped.
+func verifyBlobs(ctx context.Context, client BeaconBlockProvider, firstSlot, endSlot uint64, concurrency int) ([]BlobDiscrepancy, error) {
+	discrepancies := []BlobDiscrepancy{}
+	discrepanciesMu := sync.Mutex{}
+	g := new(errgroup.Group)
+	g.SetLimit(concurrency)
+	for i := firstSlot; i < endSlot; i++ {
+		i := i
+		g.Go(func() error {
+			reason, err := verifyBlockBlobs(ctx, client, i)
+			if err != nil {
+				return fmt.Errorf("error verifying blobs of block %v: %w", i, err)
+			}
+			if reason != "" {
+				discrepanciesMu.Lock()
+				discrepancies = append(discrepancies, BlobDiscrepancy{Slot: i, Reason: reason})
+				discrepanciesMu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	sort.Slice(discrepancies, func(i, j int) bool { return discrepancies[i].Slot < discrepancies[j].Slot })
+	return discrepancies, nil
+}
+
+// verifyBlockBlobs returns the reason why the blobs of the block at the given slot are inconsistent or an empty string if they are not.
+func verifyBlockBlobs(ctx context.Context, client BeaconBlockProvider, slot uint64) (string, error) {
+	block, err := client.BeaconBlock(ctx, slot)
+	if err != nil {
+		return "", err
+	}
+	if block == nil || block.Deneb == nil {
+		return "", nil
+	}
+	commitments := block.Deneb.BlobKZGCommitments
+	if expected := uint64(len(commitments)) * gasPerBlob; block.Deneb.BlobGasUsed != expected {
+		return fmt.Sprintf("blob gas used %v does not match %v kzg commitments (%v)", block.Deneb.BlobGasUsed, len(commitments), expected), nil
+	}
+	if len(commitments) == 0 {
+		return "", nil
+	}
+
+	sidecars, err := client.BlobSidecars(ctx, slot)
+	if err != nil {
+		return "", err
+	}
+	if sidecars == nil {
+		return "blob sidecars unavailable", nil
+	}
+	if len(sidecars) != len(commitments) {
+		return fmt.Sprintf("%v blob sidecars for %v kzg commitments", len(sidecars), len(commitments)), nil
+	}
+	for _, s := range sidecars {
+		if s.Slot != slot {
+			return fmt.Sprintf("blob sidecar %v belongs to slot %v", s.Index, s.Slot), nil
+		}
+		if s.Index >= uint64(len(commitments)) || commitments[s.Index] != s.KZGCommitment {
+			return fmt.Sprintf("kzg commitment of blob sidecar %v does not match block", s.Index), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,105 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// blobsTestClient serves the blocks of a map and the blob sidecars of a mocked node.
+type blobsTestClient struct {
+	blocks map[uint64]*SignedBeaconBlock
+	url    string
+}
+
+func (c *blobsTestClient) BeaconBlock(ctx context.Context, slot uint64) (*SignedBeaconBlock, error) {
+	if slot == 108 {
+		return nil, errors.New("invalid blob_gas_used")
+	}
+	return c.blocks[slot], nil
+}
+
+func (c *blobsTestClient) BlindedBeaconBlock(ctx context.Context, slot uint64) (*SignedBlindedBeaconBlock, error) {
+	return nil, nil
+}
+
+func (c *blobsTestClient) BlobSidecars(ctx context.Context, slot uint64) ([]BlobSidecar, error) {
+	return getBlobSidecars(ctx, c.url, slot)
+}
+
+func TestVerifyBlobs(t *testing.T) {
+	commitmentA := "0x" + strings.Repeat("aa", 48)
+	commitmentB := "0x" + strings.Repeat("bb", 48)
+	denebBlock := func(blobGasUsed uint64, commitments ...string) *SignedBeaconBlock {
+		block := &SignedBeaconBlock{Fork: "deneb", Deneb: &DenebBlockFields{BlobGasUsed: blobGasUsed}}
+		for _, c := range commitments {
+			var commitment KZGCommitment
+			if err := decodeHexInto(c, commitment[:]); err != nil {
+				t.Fatal(err)
+			}
+			block.Deneb.BlobKZGCommitments = append(block.Deneb.BlobKZGCommitments, commitment)
+		}
+		return block
+	}
+	sidecar := func(slot, index int, commitment string) string {
+		return fmt.Sprintf(`{"index":"%d","kzg_commitment":"%s","signed_block_header":{"message":{"slot":"%d"}}}`, index, commitment, slot)
+	}
+
+	blocks := map[uint64]*SignedBeaconBlock{
+		// consistent block with 2 blobs
+		100: denebBlock(2*gasPerBlob, commitmentA, commitmentB),
+		// blob gas used does not match the number of commitments
+		101: denebBlock(gasPerBlob, commitmentA, commitmentB),
+		// missing sidecar
+		102: denebBlock(2*gasPerBlob, commitmentA, commitmentB),
+		// sidecar with a different commitment
+		103: denebBlock(gasPerBlob, commitmentA),
+		// sidecars have been pruned
+		104: denebBlock(gasPerBlob, commitmentA),
+		// block without blobs
+		105: denebBlock(0),
+		// pre-deneb block
+		106: {Fork: "bellatrix"},
+		// 107 is a missed slot
+	}
+	mocks := map[string]string{
+		"/eth/v1/beacon/blob_sidecars/100": fmt.Sprintf(`{"data":[%s,%s]}`, sidecar(100, 0, commitmentA), sidecar(100, 1, commitmentB)),
+		"/eth/v1/beacon/blob_sidecars/102": fmt.Sprintf(`{"data":[%s]}`, sidecar(102, 0, commitmentA)),
+		"/eth/v1/beacon/blob_sidecars/103": fmt.Sprintf(`{"data":[%s]}`, sidecar(103, 0, commitmentB)),
+		"/eth/v1/beacon/blob_sidecars/109": `{"data":[{"index":"0","kzg_commitment":"0x01","signed_block_header":{"message":{"slot":"109"}}}]}`,
+	}
+	bnServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mock, exists := mocks[r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(mock))
+	}))
+	defer bnServer.Close()
+	client := &blobsTestClient{blocks: blocks, url: bnServer.URL}
+
+	discrepancies, err := verifyBlobs(context.Background(), client, 100, 108, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slots := []uint64{}
+	for _, d := range discrepancies {
+		slots = append(slots, d.Slot)
+	}
+	if fmt.Sprintf("%v", slots) != "[101 102 103 104]" {
+		t.Errorf("wrong discrepancies: %+v", discrepancies)
+	}
+
+	if _, err := verifyBlobs(context.Background(), client, 108, 109, 1); err == nil {
+		t.Errorf("expected error of the block")
+	}
+	blocks[109] = denebBlock(gasPerBlob, commitmentA)
+	if _, err := verifyBlobs(context.Background(), client, 109, 110, 1); err == nil {
+		t.Errorf("expected error for invalid kzg commitment of sidecar")
+	}
+}
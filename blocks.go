@@ -0,0 +1,174 @@
+package ethstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SignedBeaconBlock is a signed beacon block of any fork. The block types of the go-eth2-client version this module
+// is built with end at bellatrix, so capella and deneb blocks are decoded as the bellatrix block they extend and the
+// fields that were added by capella and deneb are decoded into Capella and Deneb.
+type SignedBeaconBlock struct {
+	spec.VersionedSignedBeaconBlock
+	// Fork is the fork of the block as the beacon node reported it, e.g. "deneb".
+	Fork string
+	// Capella holds the fields added by capella, it is nil for blocks of earlier forks.
+	Capella *CapellaBlockFields
+	// Deneb holds the fields added by deneb, it is nil for blocks of earlier forks.
+	Deneb *DenebBlockFields
+}
+
+// CapellaBlockFields holds the fields of capella and later blocks that bellatrix blocks do not have.
+type CapellaBlockFields struct {
+	// Withdrawals are the withdrawals of the execution payload.
+	Withdrawals           []Withdrawal
+	BLSToExecutionChanges []BLSToExecutionChange
+}
+
+// BLSToExecutionChange is a signed change of the withdrawal credentials of a validator from bls (0x00) to an
+// execution address (0x01).
+type BLSToExecutionChange struct {
+	ValidatorIndex     phase0.ValidatorIndex
+	FromBLSPubkey      phase0.BLSPubKey
+	ToExecutionAddress bellatrix.ExecutionAddress
+	Signature          phase0.BLSSignature
+}
+
+// DenebBlockFields holds the fields of deneb and later blocks that capella blocks do not have.
+type DenebBlockFields struct {
+	BlobKZGCommitments []KZGCommitment
+	// BlobGasUsed and ExcessBlobGas are fields of the execution payload.
+	BlobGasUsed   uint64
+	ExcessBlobGas uint64
+}
+
+// KZGCommitment is the commitment to a blob of a deneb block.
+type KZGCommitment [48]byte
+
+// SignedBlindedBeaconBlock is a signed blinded beacon block of bellatrix or a later fork. Blinded blocks of later forks
+// are decoded as the bellatrix block they extend, their withdrawals are only part of the execution block.
+type SignedBlindedBeaconBlock struct {
+	*v1.SignedBlindedBeaconBlock
+	// Fork is the fork of the block as the beacon node reported it, e.g. "capella".
+	Fork string
+}
+
+// newSignedBeaconBlock wraps a block decoded by go-eth2-client, it returns nil if the block is nil.
+func newSignedBeaconBlock(block *spec.VersionedSignedBeaconBlock) *SignedBeaconBlock {
+	if block == nil {
+		return nil
+	}
+	return &SignedBeaconBlock{VersionedSignedBeaconBlock: *block, Fork: strings.ToLower(block.Version.String())}
+}
+
+// hasWithdrawals reports if blocks of the fork have withdrawals.
+func hasWithdrawals(fork string) bool {
+	switch fork {
+	case "phase0", "altair", "bellatrix":
+		return false
+	}
+	return true
+}
+
+type forkFieldsJSON struct {
+	Message struct {
+		Body struct {
+			ExecutionPayload struct {
+				Withdrawals   []withdrawalJSON `json:"withdrawals"`
+				BlobGasUsed   string           `json:"blob_gas_used"`
+				ExcessBlobGas string           `json:"excess_blob_gas"`
+			} `json:"execution_payload"`
+			BLSToExecutionChanges []struct {
+				Message struct {
+					ValidatorIndex     string `json:"validator_index"`
+					FromBLSPubkey      string `json:"from_bls_pubkey"`
+					ToExecutionAddress string `json:"to_execution_address"`
+				} `json:"message"`
+				Signature string `json:"signature"`
+			} `json:"bls_to_execution_changes"`
+			BlobKZGCommitments []string `json:"blob_kzg_commitments"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+// decodeForkFields decodes the fields that capella and deneb added to the json block of the given fork.
+func decodeForkFields(block *SignedBeaconBlock, data []byte) error {
+	if !hasWithdrawals(block.Fork) {
+		return nil
+	}
+	var res forkFieldsJSON
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	body := res.Message.Body
+	block.Capella = &CapellaBlockFields{
+		Withdrawals:           make([]Withdrawal, 0, len(body.ExecutionPayload.Withdrawals)),
+		BLSToExecutionChanges: make([]BLSToExecutionChange, 0, len(body.BLSToExecutionChanges)),
+	}
+	for _, w := range body.ExecutionPayload.Withdrawals {
+		withdrawal, err := w.decode()
+		if err != nil {
+			return err
+		}
+		block.Capella.Withdrawals = append(block.Capella.Withdrawals, withdrawal)
+	}
+	for _, c := range body.BLSToExecutionChanges {
+		var change BLSToExecutionChange
+		index, err := strconv.ParseUint(c.Message.ValidatorIndex, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid validator_index of bls to execution change: %w", err)
+		}
+		change.ValidatorIndex = phase0.ValidatorIndex(index)
+		if err := decodeHexInto(c.Message.FromBLSPubkey, change.FromBLSPubkey[:]); err != nil {
+			return fmt.Errorf("invalid from_bls_pubkey of bls to execution change: %w", err)
+		}
+		if err := decodeHexInto(c.Message.ToExecutionAddress, change.ToExecutionAddress[:]); err != nil {
+			return fmt.Errorf("invalid to_execution_address of bls to execution change: %w", err)
+		}
+		if err := decodeHexInto(c.Signature, change.Signature[:]); err != nil {
+			return fmt.Errorf("invalid signature of bls to execution change: %w", err)
+		}
+		block.Capella.BLSToExecutionChanges = append(block.Capella.BLSToExecutionChanges, change)
+	}
+	if block.Fork == "capella" {
+		return nil
+	}
+
+	var err error
+	block.Deneb = &DenebBlockFields{BlobKZGCommitments: make([]KZGCommitment, len(body.BlobKZGCommitments))}
+	for i, c := range body.BlobKZGCommitments {
+		if err := decodeHexInto(c, block.Deneb.BlobKZGCommitments[i][:]); err != nil {
+			return fmt.Errorf("invalid blob kzg commitment: %w", err)
+		}
+	}
+	block.Deneb.BlobGasUsed, err = strconv.ParseUint(body.ExecutionPayload.BlobGasUsed, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid blob_gas_used: %w", err)
+	}
+	block.Deneb.ExcessBlobGas, err = strconv.ParseUint(body.ExecutionPayload.ExcessBlobGas, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid excess_blob_gas: %w", err)
+	}
+	return nil
+}
+
+// decodeHexInto decodes the 0x-prefixed hex string into dst, which it has to fill exactly.
+func decodeHexInto(s string, dst []byte) error {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != len(dst) {
+		return fmt.Errorf("%v bytes instead of %v", len(b), len(dst))
+	}
+	copy(dst, b)
+	return nil
+}
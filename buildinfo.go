@@ -0,0 +1,63 @@
+package ethstore
+
+import (
+	"runtime/debug"
+
+	"github.com/gobitfly/eth.store/version"
+)
+
+// MethodologyVersion must be increased whenever a change to Calculate changes the results of already calculated days.
+const MethodologyVersion = 1
+
+const modulePath = "github.com/gobitfly/eth.store"
+
+// supportedForks are the forks whose blocks Calculate is able to decode.
+var supportedForks = []string{"phase0", "altair", "bellatrix", "capella", "deneb"}
+
+// Provenance identifies the code that produced a result.
+type Provenance struct {
+	ModuleVersion      string   `json:"moduleVersion"`
+	GitCommit          string   `json:"gitCommit"`
+	GitDate            string   `json:"gitDate"`
+	MethodologyVersion int      `json:"methodologyVersion"`
+	SupportedForks     []string `json:"supportedForks"`
+	DayBoundary        string   `json:"dayBoundary,omitempty"`
+	Eligibility        string   `json:"eligibility,omitempty"`
+	SpecHash           string   `json:"specHash,omitempty"`
+}
+
+// BuildInfo returns the provenance of this build. The module version and git commit are taken from the
+// version package if they have been set at build-time and from the go build info otherwise, e.g. if
+// eth.store is used as a library.
+func BuildInfo() *Provenance {
+	p := &Provenance{
+		ModuleVersion:      version.Version,
+		GitCommit:          version.GitCommit,
+		GitDate:            version.GitDate,
+		MethodologyVersion: MethodologyVersion,
+		SupportedForks:     append([]string{}, supportedForks...),
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return p
+	}
+	if p.ModuleVersion == "undefined" {
+		if info.Main.Path == modulePath {
+			p.ModuleVersion = info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				p.ModuleVersion = dep.Version
+			}
+		}
+	}
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision" && p.GitCommit == "undefined":
+			p.GitCommit = s.Value
+		case s.Key == "vcs.time" && p.GitDate == "undefined":
+			p.GitDate = s.Value
+		}
+	}
+	return p
+}
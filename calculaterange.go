@@ -0,0 +1,91 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+)
+
+// calculateSession holds the state that the calculations of consecutive days share: the clients, which cache the spec
+// and the genesis of the network, and the validators of the state at the end of the last calculated day, which is the
+// state at the start of the next day.
+type calculateSession struct {
+	client        BeaconClient
+	gethRpcClient *gethRPC.Client
+	endSlot       uint64
+	endValidators map[phase0.ValidatorIndex]*v1.Validator
+}
+
+// clients returns the clients of the session and connects them on first use, a nil session connects new clients. The
+// consensus client of a session that has been created with a client is not replaced.
+func (s *calculateSession) clients(ctx context.Context, bnAddress, elAddress string) (BeaconClient, *gethRPC.Client, error) {
+	if s != nil && s.client != nil && s.gethRpcClient != nil {
+		return s.client, s.gethRpcClient, nil
+	}
+	gethRpcClient, err := newExecClient(ctx, elAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	var client BeaconClient
+	if s != nil && s.client != nil {
+		client = s.client
+	} else {
+		client, err = newConsClient(ctx, bnAddress)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if s != nil {
+		s.client, s.gethRpcClient = client, gethRpcClient
+	}
+	return client, gethRpcClient, nil
+}
+
+// validators returns the validators of the state at the given slot, they are taken from the session if the previous
+// day ended at the slot.
+func (s *calculateSession) validators(ctx context.Context, client BeaconClient, slot uint64) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+	if s != nil && s.endValidators != nil && s.endSlot == slot {
+		return s.endValidators, nil
+	}
+	return GetValidators(ctx, client, fmt.Sprintf("%d", slot))
+}
+
+// setEndValidators keeps the validators of the state at the end of a day for the start of the next day.
+func (s *calculateSession) setEndValidators(slot uint64, validators map[phase0.ValidatorIndex]*v1.Validator) {
+	if s != nil {
+		s.endSlot, s.endValidators = slot, validators
+	}
+}
+
+// CalculateRange calculates the days [firstDay, lastDay] one after another like Calculate and returns them in ascending
+// order with their per-validator results keyed by day. The days share the clients and with them the spec and genesis
+// lookups, and the validators at the end of a day are reused as the validators at the start of the next day, so every
+// validator snapshot is only requested once.
+func CalculateRange(ctx context.Context, bnAddress, elAddress string, firstDay, lastDay uint64, concurrency int) ([]*Day, map[uint64]map[uint64]*Day, error) {
+	if lastDay < firstDay {
+		return nil, nil, fmt.Errorf("last day %v is before first day %v", lastDay, firstDay)
+	}
+	session := &calculateSession{}
+	days := make([]*Day, 0, lastDay-firstDay+1)
+	validatorDaysByDay := make(map[uint64]map[uint64]*Day, lastDay-firstDay+1)
+	for day := firstDay; day <= lastDay; day++ {
+		d, validatorDays, err := calculate(ctx, bnAddress, elAddress, fmt.Sprintf("%d", day), concurrency, nil, MethodologyVersion, session, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error calculating day %v: %w", day, err)
+		}
+		if t := GetMethodologyTransition(); t.overlaps(day) {
+			// the previous methodology starts and ends at the same states
+			previous, _, err := calculate(ctx, bnAddress, elAddress, d.Day.String(), concurrency, nil, t.PreviousVersion, session, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error calculating day %v with previous methodology version %v: %w", day, t.PreviousVersion, err)
+			}
+			d.PreviousMethodology = previous
+		}
+		days = append(days, d)
+		validatorDaysByDay[day] = validatorDays
+	}
+	return days, validatorDaysByDay, nil
+}
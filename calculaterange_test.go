@@ -0,0 +1,75 @@
+package ethstore
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestCalculateRange(t *testing.T) {
+	requests := map[string]int{}
+	requestsMu := sync.Mutex{}
+	bnServer, elServer := newEthstoreMockServers(t, func(r *http.Request) {
+		requestsMu.Lock()
+		defer requestsMu.Unlock()
+		requests[r.URL.Path]++
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	days, validatorDaysByDay, err := CalculateRange(context.Background(), bnServer.URL, elServer.URL, 10, 10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, expectedValidatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 1 || !days[0].Apr.Equal(expected.Apr) || len(validatorDaysByDay[10]) != len(expectedValidatorDays) {
+		t.Errorf("wrong days: %+v", days)
+	}
+	if _, _, err := CalculateRange(context.Background(), bnServer.URL, elServer.URL, 11, 10, 10); err == nil {
+		t.Errorf("expected error for empty range")
+	}
+
+	// the validators at the end of the previous day are the validators at the start of the day
+	session := &calculateSession{}
+	client, _, err := session.clients(context.Background(), bnServer.URL, elServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startValidators, err := GetValidators(context.Background(), client, "72000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.setEndValidators(72000, startValidators)
+	validatorsCacheMu.Lock()
+	validatorsCache.Purge()
+	validatorsCacheMu.Unlock()
+	requestsMu.Lock()
+	requests = map[string]int{}
+	requestsMu.Unlock()
+
+	d, _, err := calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10, nil, MethodologyVersion, session, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Apr.Equal(expected.Apr) {
+		t.Errorf("wrong apr: %v != %v", d.Apr, expected.Apr)
+	}
+	requestsMu.Lock()
+	defer requestsMu.Unlock()
+	if n := requests["/eth/v1/beacon/states/72000/validators"]; n != 0 {
+		t.Errorf("validators at the start of the day requested %v times", n)
+	}
+	if n := requests["/eth/v1/beacon/states/79200/validators"]; n == 0 {
+		t.Errorf("validators at the end of the day not requested")
+	}
+	if n := requests["/eth/v1/config/spec"] + requests["/eth/v1/beacon/genesis"]; n != 0 {
+		t.Errorf("spec and genesis requested %v times", n)
+	}
+	if session.endSlot != 79200 {
+		t.Errorf("wrong end slot of session: %v", session.endSlot)
+	}
+}
@@ -0,0 +1,65 @@
+package ethstore
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+var censoringBuilders map[common.Address]bool
+var censoringBuildersMu = sync.Mutex{}
+
+// CensorshipStats holds the share of the execution blocks of the eth.store validators that have been built by builders
+// that are considered censoring. Builders are identified by the fee recipient of the execution payload, which is the
+// address of the builder for blocks built via mev-boost.
+type CensorshipStats struct {
+	Blocks                decimal.Decimal `json:"blocks"`
+	CensoringBlocks       decimal.Decimal `json:"censoringBlocks"`
+	CensoringBlocksShare  decimal.Decimal `json:"censoringBlocksShare"`
+	CensoringTxFeesSumWei decimal.Decimal `json:"censoringTxFeesSumWei"`
+	CensoringTxFeesShare  decimal.Decimal `json:"censoringTxFeesShare"`
+}
+
+// SetCensoringBuilders sets the addresses of the builders that are considered censoring, no addresses disable the
+// censorship analytics.
+func SetCensoringBuilders(builders []common.Address) {
+	censoringBuildersMu.Lock()
+	defer censoringBuildersMu.Unlock()
+	if len(builders) == 0 {
+		censoringBuilders = nil
+		return
+	}
+	censoringBuilders = make(map[common.Address]bool, len(builders))
+	for _, b := range builders {
+		censoringBuilders[b] = true
+	}
+}
+
+func GetCensoringBuilders() map[common.Address]bool {
+	censoringBuildersMu.Lock()
+	defer censoringBuildersMu.Unlock()
+	return censoringBuilders
+}
+
+// add counts an execution block of an eth.store validator.
+func (s *CensorshipStats) add(censoring bool, txFeesWei *big.Int) {
+	s.Blocks = s.Blocks.Add(decimal.NewFromInt(1))
+	if censoring {
+		s.CensoringBlocks = s.CensoringBlocks.Add(decimal.NewFromInt(1))
+		s.CensoringTxFeesSumWei = s.CensoringTxFeesSumWei.Add(decimal.NewFromBigInt(txFeesWei, 0))
+	}
+}
+
+// finalize sets the shares of the censoring blocks given the tx fees of all blocks of the eth.store validators.
+func (s *CensorshipStats) finalize(txFeesSumWei decimal.Decimal) {
+	s.CensoringBlocksShare = decimal.Zero
+	if s.Blocks.IsPositive() {
+		s.CensoringBlocksShare = s.CensoringBlocks.Div(s.Blocks)
+	}
+	s.CensoringTxFeesShare = decimal.Zero
+	if txFeesSumWei.IsPositive() {
+		s.CensoringTxFeesShare = s.CensoringTxFeesSumWei.Div(txFeesSumWei)
+	}
+}
@@ -0,0 +1,64 @@
+package ethstore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+func TestCensorshipStats(t *testing.T) {
+	s := &CensorshipStats{}
+	s.add(true, big.NewInt(300))
+	s.add(false, big.NewInt(500))
+	s.add(false, big.NewInt(200))
+	s.add(true, big.NewInt(0))
+	s.finalize(decimal.NewFromInt(1000))
+
+	if !s.Blocks.Equal(decimal.NewFromInt(4)) || !s.CensoringBlocks.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("wrong number of blocks: %v, %v", s.Blocks, s.CensoringBlocks)
+	}
+	if !s.CensoringBlocksShare.Equal(decimal.NewFromFloat(0.5)) {
+		t.Errorf("wrong share of censoring blocks: %v != %v", s.CensoringBlocksShare, 0.5)
+	}
+	if !s.CensoringTxFeesSumWei.Equal(decimal.NewFromInt(300)) || !s.CensoringTxFeesShare.Equal(decimal.NewFromFloat(0.3)) {
+		t.Errorf("wrong censoring tx fees: %v (share: %v)", s.CensoringTxFeesSumWei, s.CensoringTxFeesShare)
+	}
+
+	empty := &CensorshipStats{}
+	empty.finalize(decimal.Zero)
+	if !empty.CensoringBlocksShare.IsZero() || !empty.CensoringTxFeesShare.IsZero() {
+		t.Errorf("wrong shares without blocks: %+v", empty)
+	}
+}
+
+func TestCensorshipOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.Censorship != nil {
+		t.Errorf("censorship stats without censoring builders: %+v", day.Censorship)
+	}
+
+	SetCensoringBuilders([]common.Address{common.HexToAddress("0x8b0c2c4c8eb078bc6c01f48523764c8942c0c6c4")})
+	defer SetCensoringBuilders(nil)
+	day, _, err = Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all mocked blocks are built by the same builder
+	c := day.Censorship
+	if c == nil || !c.Blocks.IsPositive() || !c.CensoringBlocks.Equal(c.Blocks) || !c.CensoringBlocksShare.Equal(decimal.NewFromInt(1)) {
+		t.Fatalf("wrong censorship stats: %+v", c)
+	}
+	if !c.CensoringTxFeesSumWei.Equal(day.TxFeesSumWei) {
+		t.Errorf("wrong censoring tx fees: %v != %v", c.CensoringTxFeesSumWei, day.TxFeesSumWei)
+	}
+}
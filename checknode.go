@@ -0,0 +1,239 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// missedSlotScan is the number of slots before the finalized slot that are searched for a missed slot.
+const missedSlotScan = 128
+
+// NodeCheck is the result of one check of the compatibility of a beacon node. Optional checks do not affect the
+// compatibility, e.g. blocks are requested as json if the node does not support ssz.
+type NodeCheck struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Optional bool          `json:"optional,omitempty"`
+	Detail   string        `json:"detail"`
+	Duration time.Duration `json:"duration"`
+}
+
+// NodeReport holds the results of the checks of a beacon node.
+type NodeReport struct {
+	Address string      `json:"address"`
+	Version string      `json:"version"`
+	Checks  []NodeCheck `json:"checks"`
+}
+
+// Compatible reports whether all required checks passed or were skipped.
+func (r *NodeReport) Compatible() bool {
+	for _, c := range r.Checks {
+		if !c.Passed && !c.Skipped && !c.Optional {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckNode runs the requests Calculate makes against the beacon node at the given address and reports for each of
+// them whether the node responds as expected: the spec and fork schedule, the root and finality of the historical
+// state at the start of the finalized day, the validators of that state, a missed slot and blocks as ssz. A failing
+// check is part of the report, an error is only returned if the node can not be reached.
+func CheckNode(ctx context.Context, address string) (*NodeReport, error) {
+	client, err := newConsClient(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	r := &NodeReport{Address: client.Address()}
+	r.Version, err = client.NodeVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting node version: %w", err)
+	}
+	check := func(name string, optional bool, run func() (string, error)) bool {
+		start := time.Now()
+		detail, err := run()
+		c := NodeCheck{Name: name, Passed: err == nil, Optional: optional, Detail: detail, Duration: time.Since(start)}
+		if err != nil {
+			c.Detail = err.Error()
+		}
+		r.Checks = append(r.Checks, c)
+		return c.Passed
+	}
+	skip := func(name, reason string) {
+		r.Checks = append(r.Checks, NodeCheck{Name: name, Skipped: true, Detail: reason})
+	}
+
+	var apiSpec map[string]interface{}
+	var timing *ChainTiming
+	specOk := check("spec", false, func() (string, error) {
+		apiSpec, err = client.Spec(ctx)
+		if err != nil {
+			return "", err
+		}
+		timing, err = getChainTiming(ctx, client)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("slotsPerEpoch: %v, secondsPerSlot: %v", timing.SlotsPerEpoch, timing.SecondsPerSlot), nil
+	})
+	if specOk {
+		check("fork schedule", false, func() (string, error) {
+			return checkForkSchedule(ctx, address, apiSpec)
+		})
+	} else {
+		skip("fork schedule", "spec not available")
+	}
+
+	var finalizedSlot uint64
+	finalizedOk := check("finalized header", false, func() (string, error) {
+		h, err := client.BeaconBlockHeader(ctx, "finalized")
+		if err != nil {
+			return "", err
+		}
+		finalizedSlot = uint64(h.Header.Message.Slot)
+		return fmt.Sprintf("slot: %v", finalizedSlot), nil
+	})
+
+	if specOk && finalizedOk {
+		// the state at the start of the finalized day is the oldest state a calculation of the finalized day requests
+		boundary := GetDayBoundary()
+		slot := boundary.FirstSlot(boundary.Day(finalizedSlot, timing)-1, timing)
+		check("historical state", false, func() (string, error) {
+			root, err := getStateRoot(ctx, client, slot)
+			if err != nil {
+				return "", err
+			}
+			finality, err := client.Finality(ctx, fmt.Sprintf("%d", slot))
+			if err != nil {
+				return "", fmt.Errorf("error getting finality checkpoints at slot %v: %w", slot, err)
+			}
+			if finality.Finalized == nil {
+				return "", fmt.Errorf("no finalized checkpoint at slot %v", slot)
+			}
+			return fmt.Sprintf("slot: %v, root: %#x, finalizedEpoch: %v", slot, root, finality.Finalized.Epoch), nil
+		})
+		check("validators", false, func() (string, error) {
+			start := time.Now()
+			validators, err := requestValidators(ctx, client, fmt.Sprintf("%d", slot))
+			if err != nil {
+				return "", err
+			}
+			if len(validators) == 0 {
+				return "", fmt.Errorf("no validators at slot %v", slot)
+			}
+			return fmt.Sprintf("slot: %v, validators: %v, duration: %v", slot, len(validators), time.Since(start).Round(time.Millisecond)), nil
+		})
+	} else {
+		skip("historical state", "finalized slot not available")
+		skip("validators", "finalized slot not available")
+	}
+
+	if finalizedOk {
+		checkMissedSlot(ctx, address, getBeaconClientName(ctx, client), finalizedSlot, check, skip)
+		check("ssz", true, func() (string, error) {
+			supported, err := supportsSSZ(ctx, address, finalizedSlot)
+			if err != nil {
+				return "", err
+			}
+			if !supported {
+				return "", fmt.Errorf("blocks are not served as ssz, they are requested as json")
+			}
+			return fmt.Sprintf("slot: %v", finalizedSlot), nil
+		})
+	} else {
+		skip("missed slot", "finalized slot not available")
+		skip("ssz", "finalized slot not available")
+	}
+	return r, nil
+}
+
+// checkForkSchedule compares the fork schedule of the beacon node with the fork epochs of its spec, scheduled forks
+// that are not supported are reported in the detail since the days before their activation can still be calculated.
+func checkForkSchedule(ctx context.Context, address string, apiSpec map[string]interface{}) (string, error) {
+	var schedule struct {
+		Data []struct {
+			PreviousVersion string `json:"previous_version"`
+			CurrentVersion  string `json:"current_version"`
+			Epoch           string `json:"epoch"`
+		} `json:"data"`
+	}
+	found, err := getBeaconJson(ctx, address, "/eth/v1/config/fork_schedule", &schedule)
+	if err != nil {
+		return "", err
+	}
+	if !found || len(schedule.Data) == 0 {
+		return "", fmt.Errorf("no fork schedule")
+	}
+	scheduled := map[uint64]string{}
+	for _, f := range schedule.Data {
+		epoch, err := strconv.ParseUint(f.Epoch, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("error parsing epoch of fork %v: %w", f.CurrentVersion, err)
+		}
+		// the genesis fork has no epoch in the spec
+		if f.PreviousVersion != f.CurrentVersion && epoch != uint64(farFutureEpoch) {
+			scheduled[epoch] = f.CurrentVersion
+		}
+	}
+	unsupported := []string{}
+	for _, name := range forkNames[1:] {
+		epoch, err := getSpecUint64(apiSpec, strings.ToUpper(name)+"_FORK_EPOCH")
+		if err != nil || epoch == uint64(farFutureEpoch) {
+			continue
+		}
+		if _, exists := scheduled[epoch]; !exists {
+			return "", fmt.Errorf("fork %v at epoch %v of the spec is missing in the fork schedule", name, epoch)
+		}
+		delete(scheduled, epoch)
+		supported := false
+		for _, s := range supportedForks {
+			supported = supported || s == name
+		}
+		if !supported {
+			unsupported = append(unsupported, name)
+		}
+	}
+	// the remaining forks are newer than the forks eth.store knows
+	epochs := make([]uint64, 0, len(scheduled))
+	for epoch := range scheduled {
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] < epochs[j] })
+	for _, epoch := range epochs {
+		unsupported = append(unsupported, fmt.Sprintf("%v@%v", scheduled[epoch], epoch))
+	}
+	if len(unsupported) > 0 {
+		return fmt.Sprintf("forks: %v, unsupported: %v (days after their activation can not be calculated)", len(schedule.Data), strings.Join(unsupported, ",")), nil
+	}
+	return fmt.Sprintf("forks: %v", len(schedule.Data)), nil
+}
+
+// checkMissedSlot searches the slots before the finalized slot for a missed slot and checks that the block request of
+// the missed slot is recognized as a missed slot, the check is skipped if no slot was missed.
+func checkMissedSlot(ctx context.Context, address, clientName string, finalizedSlot uint64, check func(string, bool, func() (string, error)) bool, skip func(string, string)) {
+	for slot := finalizedSlot; slot > 0 && slot+missedSlotScan > finalizedSlot; slot-- {
+		var header struct{}
+		found, err := getBeaconJson(ctx, address, fmt.Sprintf("/eth/v1/beacon/headers/%d", slot), &header)
+		if err != nil || found {
+			continue
+		}
+		check("missed slot", false, func() (string, error) {
+			block, err := getSignedBeaconBlock(ctx, address, slot)
+			block, err = normalizeBlockResponse(clientName, block, err)
+			if err != nil {
+				return "", err
+			}
+			if block != nil {
+				return "", fmt.Errorf("block returned for missed slot %v", slot)
+			}
+			return fmt.Sprintf("slot: %v", slot), nil
+		})
+		return
+	}
+	skip("missed slot", fmt.Sprintf("no missed slot within the last %v slots", missedSlotScan))
+}
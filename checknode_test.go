@@ -0,0 +1,86 @@
+package ethstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckNode(t *testing.T) {
+	for _, pruned := range []bool{false, true} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/eth/v1/node/version":
+				w.Write([]byte(`{"data":{"version":"Lighthouse/v2.3.1-564d7da/x86_64-linux"}}`))
+				return
+			case "/eth/v1/beacon/genesis":
+				w.Write([]byte(`{"data":{"genesis_time":"1606824023","genesis_validators_root":"0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95","genesis_fork_version":"0x00000000"}}`))
+				return
+			case "/eth/v1/config/spec":
+				w.Write([]byte(`{"data":{"SECONDS_PER_SLOT":"12","SLOTS_PER_EPOCH":"32","ALTAIR_FORK_EPOCH":"10","BELLATRIX_FORK_EPOCH":"18446744073709551615"}}`))
+				return
+			case "/eth/v1/config/deposit_contract":
+				w.Write([]byte(`{"data":{"chain_id":"1","address":"0x00000000219ab540356cbb839cbe05303d7705fa"}}`))
+				return
+			case "/eth/v1/config/fork_schedule":
+				w.Write([]byte(`{"data":[{"previous_version":"0x00000000","current_version":"0x00000000","epoch":"0"},{"previous_version":"0x00000000","current_version":"0x01000000","epoch":"10"},{"previous_version":"0x01000000","current_version":"0x02000000","epoch":"18446744073709551615"}]}`))
+				return
+			case "/eth/v1/beacon/headers/finalized", "/eth/v1/beacon/headers/14500", "/eth/v1/beacon/headers/14499":
+				w.Write([]byte(`{"data":{"root":"0x3aee29bcfa7a9fdf01394a3dce74ae063c89023df71867ad1555f1e494d138ee","canonical":true,"header":{"message":{"slot":"14500","proposer_index":"1","parent_root":"0x4a451b6a4962bcbd619ee1f0b6a7d85dded49f049877de325122e21350e5d6f2","state_root":"0xf12219d8bcdb7ed125da01e4f7aa30754bff2c9fc0bf57dd728c0b02bb847a92","body_root":"0x31f4433e6e260a0fac6e80ad3f9df1998fbbab269408601a6da7a5d32ccbb258"},"signature":"0x8ccb90ff41ec1f82975fb12384f3d44194b27403f1454e878e9c07c9951df33968556e2ce0dfb8ce42e2e0bbac8c80e211d35d01617712292805bc8d9ac2e3429f821953cfc1dbb9d9ea359cd37b39850f4e29c81fc3d67e150985c609d4e826"}}}`))
+				return
+			}
+			// the state at the start of the finalized day 1 is pruned on nodes without archive
+			if strings.HasPrefix(r.URL.Path, "/eth/v1/beacon/states/7200/") && !pruned {
+				switch strings.TrimPrefix(r.URL.Path, "/eth/v1/beacon/states/7200/") {
+				case "root":
+					w.Write([]byte(`{"data":{"root":"0x65ff6f9be55e066f1ed9f5f899752e174c31793034260389316c0ae897483512"}}`))
+					return
+				case "finality_checkpoints":
+					w.Write([]byte(`{"data":{"previous_justified":{"epoch":"223","root":"0x0000000000000000000000000000000000000000000000000000000000000000"},"current_justified":{"epoch":"224","root":"0x0000000000000000000000000000000000000000000000000000000000000000"},"finalized":{"epoch":"223","root":"0x0000000000000000000000000000000000000000000000000000000000000000"}}}`))
+					return
+				case "validators":
+					w.Write([]byte(`{"data":[{"index":"0","balance":"32000000000","status":"active_ongoing","validator":{"pubkey":"0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","withdrawal_credentials":"0x0000000000000000000000000000000000000000000000000000000000000000","effective_balance":"32000000000","slashed":false,"activation_eligibility_epoch":"0","activation_epoch":"0","exit_epoch":"18446744073709551615","withdrawable_epoch":"18446744073709551615"}}]}`))
+					return
+				}
+			}
+			// slot 14498 is missed
+			if r.URL.Path == "/eth/v2/beacon/blocks/14498" || r.URL.Path == "/eth/v1/beacon/headers/14498" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"code":404,"message":"NOT_FOUND: beacon block"}`))
+				return
+			}
+			if r.URL.Path == "/eth/v2/beacon/blocks/14500" {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+		}))
+
+		r, err := CheckNode(context.Background(), server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Version != "Lighthouse/v2.3.1-564d7da/x86_64-linux" {
+			t.Errorf("wrong version: %v", r.Version)
+		}
+		checks := map[string]NodeCheck{}
+		for _, c := range r.Checks {
+			checks[c.Name] = c
+		}
+		for _, name := range []string{"spec", "fork schedule", "finalized header", "validators", "missed slot", "ssz"} {
+			if c := checks[name]; !c.Passed && (!pruned || name != "validators") {
+				t.Errorf("check %v failed (pruned: %v): %v", name, pruned, c.Detail)
+			}
+		}
+		if !strings.Contains(checks["missed slot"].Detail, "14498") {
+			t.Errorf("wrong missed slot: %v", checks["missed slot"].Detail)
+		}
+		if checks["historical state"].Passed == pruned || checks["validators"].Passed == pruned || r.Compatible() == pruned {
+			t.Errorf("wrong compatibility (pruned: %v): historical state: %v, validators: %v, compatible: %v", pruned, checks["historical state"].Detail, checks["validators"].Detail, r.Compatible())
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+// Checkpoint holds the progress of a cancelled calculation of a day, it can be serialized and passed to Resume
+// to continue the calculation without processing the blocks of the already processed slots again.
+type Checkpoint struct {
+	Day                    uint64                          `json:"day"`
+	MethodologyVersion     int                             `json:"methodologyVersion,omitempty"`
+	ProcessedSlots         []uint64                        `json:"processedSlots"`
+	MissedSlots            uint64                          `json:"missedSlots"`
+	SyncBitsSet            uint64                          `json:"syncBitsSet,omitempty"`
+	SyncBits               uint64                          `json:"syncBits,omitempty"`
+	ProposalTxFeesWei      []decimal.Decimal               `json:"proposalTxFeesWei"`
+	FeeRecipientMismatches []FeeRecipientMismatch          `json:"feeRecipientMismatches,omitempty"`
+	Censorship             *CensorshipStats                `json:"censorship,omitempty"`
+	TxDecodeErrors         *TxDecodeErrors                 `json:"txDecodeErrors,omitempty"`
+	TxFeeBreakdown         *TxFeeBreakdown                 `json:"txFeeBreakdown,omitempty"`
+	Alerts                 []Alert                         `json:"alerts,omitempty"`
+	MissedSlotList         []uint64                        `json:"missedSlotList,omitempty"`
+	EpochDepositsGwei      map[uint64]phase0.Gwei          `json:"epochDepositsGwei,omitempty"`
+	EpochWithdrawalsGwei   map[uint64]phase0.Gwei          `json:"epochWithdrawalsGwei,omitempty"`
+	BlockDeposits          []BlockDeposit                  `json:"blockDeposits,omitempty"`
+	FirstExecutionBlock    uint64                          `json:"firstExecutionBlock,omitempty"`
+	LastExecutionBlock     uint64                          `json:"lastExecutionBlock,omitempty"`
+	BurnedFeesWei          decimal.Decimal                 `json:"burnedFeesWei"`
+	NetworkDepositsGwei    phase0.Gwei                     `json:"networkDepositsGwei,omitempty"`
+	NetworkWithdrawalsGwei phase0.Gwei                     `json:"networkWithdrawalsGwei,omitempty"`
+	GasStats               *GasStats                       `json:"gasStats,omitempty"`
+	Orphans                *OrphanStats                    `json:"orphans,omitempty"`
+	Validators             map[uint64]*ValidatorCheckpoint `json:"validators"`
+}
+
+// ValidatorCheckpoint holds the partial sums of a validator of the processed slots of a Checkpoint.
+type ValidatorCheckpoint struct {
+	DepositsSumGwei    phase0.Gwei     `json:"depositsSumGwei"`
+	WithdrawalsSumGwei phase0.Gwei     `json:"withdrawalsSumGwei,omitempty"`
+	TxFeesSumWei       decimal.Decimal `json:"txFeesSumWei"`
+	MevRewardsWei      decimal.Decimal `json:"mevRewardsWei"`
+	Proposals          uint64          `json:"proposals"`
+}
+
+// PartialResultError is returned by Calculate and Resume if the context is cancelled after blocks have been processed.
+type PartialResultError struct {
+	Checkpoint *Checkpoint
+	Err        error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("calculation of day %v cancelled after %v slots: %v", e.Checkpoint.Day, len(e.Checkpoint.ProcessedSlots), e.Err)
+}
+
+func (e *PartialResultError) Unwrap() error {
+	return e.Err
+}
+
+// Resume continues the calculation of the day of the given checkpoint with the methodology version of the checkpoint.
+func Resume(ctx context.Context, bnAddress, elAddress string, checkpoint *Checkpoint, concurrency int) (*Day, map[uint64]*Day, error) {
+	if checkpoint == nil {
+		return nil, nil, fmt.Errorf("no checkpoint to resume from")
+	}
+	// checkpoints without methodology version have been created by the first methodology version
+	methodology := checkpoint.MethodologyVersion
+	if methodology == 0 {
+		methodology = 1
+	}
+	return calculate(ctx, bnAddress, elAddress, fmt.Sprintf("%d", checkpoint.Day), concurrency, checkpoint, methodology, nil, nil)
+}
+
+// newCheckpoint completes the given checkpoint with the processed slots and the partial sums of the validators, the
+// other fields must not share memory with the running calculation.
+func newCheckpoint(cp *Checkpoint, processedSlots map[uint64]bool, validatorsByIndex map[phase0.ValidatorIndex]*Validator) *Checkpoint {
+	cp.ProcessedSlots = make([]uint64, 0, len(processedSlots))
+	cp.Validators = make(map[uint64]*ValidatorCheckpoint, len(validatorsByIndex))
+	for slot := range processedSlots {
+		cp.ProcessedSlots = append(cp.ProcessedSlots, slot)
+	}
+	sort.Slice(cp.ProcessedSlots, func(i, j int) bool { return cp.ProcessedSlots[i] < cp.ProcessedSlots[j] })
+	for index, v := range validatorsByIndex {
+		cp.Validators[uint64(index)] = &ValidatorCheckpoint{
+			DepositsSumGwei:    v.DepositsSumGwei,
+			WithdrawalsSumGwei: v.WithdrawalsSumGwei,
+			TxFeesSumWei:       decimal.NewFromBigInt(v.TxFeesSumWei, 0),
+			MevRewardsWei:      decimal.NewFromBigInt(v.MevRewardsWei, 0),
+			Proposals:          v.Proposals,
+		}
+	}
+	return cp
+}
+
+// apply restores the partial sums of the checkpoint, the checkpoint must belong to the same validator-set.
+func (cp *Checkpoint) apply(validatorsByIndex map[phase0.ValidatorIndex]*Validator) (map[uint64]bool, error) {
+	if len(cp.Validators) != len(validatorsByIndex) {
+		return nil, fmt.Errorf("checkpoint of day %v has %v validators instead of %v", cp.Day, len(cp.Validators), len(validatorsByIndex))
+	}
+	for index, vc := range cp.Validators {
+		v, exists := validatorsByIndex[phase0.ValidatorIndex(index)]
+		if !exists {
+			return nil, fmt.Errorf("validator %v of checkpoint of day %v is not part of the validator-set", index, cp.Day)
+		}
+		v.DepositsSumGwei = vc.DepositsSumGwei
+		v.WithdrawalsSumGwei = vc.WithdrawalsSumGwei
+		v.TxFeesSumWei = new(big.Int).Set(vc.TxFeesSumWei.BigInt())
+		v.MevRewardsWei = new(big.Int).Set(vc.MevRewardsWei.BigInt())
+		v.Proposals = vc.Proposals
+	}
+	processedSlots := make(map[uint64]bool, len(cp.ProcessedSlots))
+	for _, slot := range cp.ProcessedSlots {
+		processedSlots[slot] = true
+	}
+	return processedSlots, nil
+}
@@ -0,0 +1,101 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var blockRequests uint64
+	var cancelAfterRequests uint32
+	bnServer, elServer := newEthstoreMockServers(t, func(r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/eth/v2/beacon/blocks/") {
+			return
+		}
+		if slot, _ := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/eth/v2/beacon/blocks/"), 10, 64); slot < 10*225*32 {
+			return
+		}
+		if atomic.AddUint64(&blockRequests, 1) == 2000 && atomic.LoadUint32(&cancelAfterRequests) == 1 {
+			cancel()
+		}
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	expected, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreUint64(&blockRequests, 0)
+	atomic.StoreUint32(&cancelAfterRequests, 1)
+
+	_, _, err = Calculate(ctx, bnServer.URL, elServer.URL, "10", 10)
+	var partial *PartialResultError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected PartialResultError, got: %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", partial.Err)
+	}
+	processed := len(partial.Checkpoint.ProcessedSlots)
+	if processed == 0 || processed >= 7200 {
+		t.Fatalf("wrong number of processed slots: %v", processed)
+	}
+
+	// the checkpoint is persisted by the caller
+	checkpointJson, err := json.Marshal(partial.Checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint := &Checkpoint{}
+	err = json.Unmarshal(checkpointJson, checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Resume(context.Background(), bnServer.URL, elServer.URL, &Checkpoint{Day: 10, Validators: map[uint64]*ValidatorCheckpoint{999: {}}}, 10); err == nil {
+		t.Errorf("expected error for checkpoint of wrong validator-set")
+	}
+
+	atomic.StoreUint32(&cancelAfterRequests, 0)
+	atomic.StoreUint64(&blockRequests, 0)
+	day, _, err := Resume(context.Background(), bnServer.URL, elServer.URL, checkpoint, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests := atomic.LoadUint64(&blockRequests); requests != uint64(7200-processed) {
+		t.Errorf("wrong number of block requests after resume: %v != %v", requests, 7200-processed)
+	}
+	if !day.Apr.Equal(expected.Apr) {
+		t.Errorf("wrong Apr: %v != %v", day.Apr, expected.Apr)
+	}
+	if !day.TxFeesSumWei.Equal(expected.TxFeesSumWei) {
+		t.Errorf("wrong TxFeesSumWei: %v != %v", day.TxFeesSumWei, expected.TxFeesSumWei)
+	}
+	if !day.BurnedFeesWei.Equal(expected.BurnedFeesWei) {
+		t.Errorf("wrong BurnedFeesWei: %v != %v", day.BurnedFeesWei, expected.BurnedFeesWei)
+	}
+	if day.GasStats == nil || !day.GasStats.GasUsed.Equal(expected.GasStats.GasUsed) || !day.GasStats.AvgBaseFeePerGasWei.Equal(expected.GasStats.AvgBaseFeePerGasWei) {
+		t.Errorf("wrong GasStats: %+v != %+v", day.GasStats, expected.GasStats)
+	}
+	if !day.DepositsSumGwei.Equal(expected.DepositsSumGwei) {
+		t.Errorf("wrong DepositsSumGwei: %v != %v", day.DepositsSumGwei, expected.DepositsSumGwei)
+	}
+	if !day.ProposalsActual.Equal(expected.ProposalsActual) {
+		t.Errorf("wrong ProposalsActual: %v != %v", day.ProposalsActual, expected.ProposalsActual)
+	}
+	if !day.SyncParticipation.Equal(expected.SyncParticipation) {
+		t.Errorf("wrong SyncParticipation: %v != %v", day.SyncParticipation, expected.SyncParticipation)
+	}
+	if !day.AprUpperBound.Equal(expected.AprUpperBound) {
+		t.Errorf("wrong AprUpperBound: %v != %v", day.AprUpperBound, expected.AprUpperBound)
+	}
+}
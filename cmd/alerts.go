@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	ethstore "github.com/gobitfly/eth.store"
+	"github.com/shopspring/decimal"
+)
+
+var notifiers []ethstore.Notifier
+
+// setupAlerts configures the alert rules and the notifiers the alerts are delivered to.
+func setupAlerts() {
+	if opts.AlertAprDeviation == 0 && opts.AlertDeposit == 0 && opts.AlertMissedSlots == 0 && opts.AlertSlashings == "" && opts.AprBounds == "" {
+		return
+	}
+	ethstore.SetAlertRules(&ethstore.AlertRules{
+		AprDeviation:      decimal.NewFromFloat(opts.AlertAprDeviation),
+		DepositGwei:       phase0.Gwei(decimal.NewFromFloat(opts.AlertDeposit).Mul(decimal.NewFromInt(1e9)).IntPart()),
+		MissedSlotStreak:  opts.AlertMissedSlots,
+		SlashingWatchList: parseWatchList(opts.AlertSlashings),
+	})
+	notifiers = append(notifiers, ethstore.LogNotifier{})
+	if opts.AlertWebhook != "" {
+		notifiers = append(notifiers, ethstore.WebhookNotifier{URL: opts.AlertWebhook})
+	}
+	ethstore.SetAlertNotifiers(notifiers...)
+}
+
+// parseWatchList parses comma-separated validator indices into a watch list.
+func parseWatchList(indicesStr string) map[phase0.ValidatorIndex]bool {
+	watchList := map[phase0.ValidatorIndex]bool{}
+	for _, index := range parseValidatorIndices(indicesStr) {
+		watchList[index] = true
+	}
+	return watchList
+}
+
+// parseValidatorIndices parses comma-separated validator indices.
+func parseValidatorIndices(indicesStr string) []phase0.ValidatorIndex {
+	indices := []phase0.ValidatorIndex{}
+	for _, s := range strings.Split(indicesStr, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		index, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			log.Fatalf("error parsing validator indices: %v", err)
+		}
+		indices = append(indices, phase0.ValidatorIndex(index))
+	}
+	return indices
+}
+
+// notifyAlerts delivers the alerts of the day to all notifiers, previous is the day before d if it is known.
+func notifyAlerts(d, previous *ethstore.Day) {
+	rules := ethstore.GetAlertRules()
+	if rules == nil {
+		return
+	}
+	alerts := append([]ethstore.Alert{}, d.Alerts...)
+	if previous != nil && previous.Day.Equal(d.Day.Sub(decimal.NewFromInt(1))) {
+		if a := rules.AprAlert(d, previous); a != nil {
+			alerts = append(alerts, *a)
+		}
+	}
+	for _, a := range alerts {
+		if a.Delivered {
+			continue
+		}
+		for _, n := range notifiers {
+			if err := n.Notify(context.Background(), a); err != nil {
+				log.Printf("error notifying alert of day %v: %v", a.Day, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// backfill calculates the days from a start day up to the finalized day into db.dsn or json.file. The progress is
+// recorded after every day and an interrupted backfill resumes from it, a day that was interrupted halfway is resumed
+// from its checkpoint.
+func backfill(args []string) {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	from := fs.Uint64("from", 0, "first day to calculate (default genesis)")
+	to := fs.Uint64("to", 0, "last day to calculate (default the finalized day)")
+	progressFile := fs.String("progress.file", "backfill.progress.json", "path to the json-file the progress of the backfill is recorded in and resumed from")
+	fs.Parse(args)
+
+	var store ethstore.ResultStore
+	switch {
+	case dbStore != nil:
+		store = dbStore
+	case opts.JsonFile != "":
+		fileStore, err := ethstore.NewFileStore(opts.JsonFile)
+		if err != nil {
+			log.Fatalf("error opening json.file: %v", err)
+		}
+		store = fileStore
+	default:
+		log.Fatalf("backfill requires db.dsn or json.file to store the days in")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	last := *to
+	if last == 0 {
+		finalizedDay, err := ethstore.GetFinalizedDay(ctx, opts.ConsAddress)
+		if err != nil {
+			log.Fatalf("error getting finalized day: %v", err)
+		}
+		last = finalizedDay
+	}
+	var previous *ethstore.Day
+	err := ethstore.Backfill(ctx, opts.ConsAddress, opts.ExecAddress, store, *from, last, *progressFile, opts.Concurrency, func(d *ethstore.Day, validatorDays map[uint64]*ethstore.Day) {
+		err := storeValidators(context.Background(), d.Day.BigInt().Uint64(), validatorDays)
+		if err != nil {
+			log.Fatalf("error storing validators in db.dsn: %v", err)
+		}
+		notifyAlerts(d, previous)
+		previous = d
+		pushDay(d, validatorDays)
+		log.Printf("backfilled day %v, apr: %v", d.Day, d.Apr.StringFixed(9))
+	})
+	if err != nil {
+		log.Fatalf("error backfilling days: %v (progress recorded in %v)", err, *progressFile)
+	}
+	log.Printf("backfilled all days up to day %v", last)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// bench measures the throughput of a consensus node for the requests eth.store makes and predicts how long the
+// calculation of a day takes against it.
+func bench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	endpoint := fs.String("endpoint", firstConsAddress(), "address of the consensus node to benchmark")
+	blocks := fs.Int("blocks", 100, "number of blocks to fetch sequentially and concurrently")
+	concurrency := fs.Int("concurrency", 10, "number of concurrent block requests")
+	fs.Parse(args)
+
+	res, err := ethstore.Bench(context.Background(), *endpoint, *blocks, *concurrency)
+	if err != nil {
+		log.Fatalf("error benchmarking %v: %v", *endpoint, err)
+	}
+	fmt.Printf("validators: %v, state download: %v\n", res.Validators, res.StateDuration)
+	fmt.Printf("blocks: %v, sequential: %.2f blocks/s, concurrent (%v): %.2f blocks/s\n", res.Blocks, res.SequentialBlocksPerSecond, res.Concurrency, res.ConcurrentBlocksPerSecond)
+	fmt.Printf("ssz supported: %v\n", res.SSZSupported)
+	fmt.Printf("predicted day calculation (%v slots): %v\n", res.SlotsPerDay, res.PredictedDayDuration)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// checkNode runs the requests eth.store makes against a consensus node and prints a compatibility report of the node,
+// it exits with status 1 if the node is not compatible.
+func checkNode(args []string) {
+	fs := flag.NewFlagSet("check-node", flag.ExitOnError)
+	endpoint := fs.String("endpoint", firstConsAddress(), "address of the consensus node to check")
+	fs.Parse(args)
+
+	r, err := ethstore.CheckNode(context.Background(), *endpoint)
+	if err != nil {
+		log.Fatalf("error checking %v: %v", *endpoint, err)
+	}
+	if opts.Json {
+		reportJson, err := json.MarshalIndent(r, "", "\t")
+		if err != nil {
+			log.Fatalf("error marshaling report: %v", err)
+		}
+		fmt.Printf("%s\n", reportJson)
+	} else {
+		fmt.Printf("node: %v, version: %v\n", r.Address, r.Version)
+		for _, c := range r.Checks {
+			result := "ok"
+			switch {
+			case c.Skipped:
+				result = "skipped"
+			case !c.Passed && c.Optional:
+				result = "warning"
+			case !c.Passed:
+				result = "failed"
+			}
+			fmt.Printf("%v: %v (%v): %v\n", c.Name, result, c.Duration.Round(time.Millisecond), c.Detail)
+		}
+		fmt.Printf("compatible: %v\n", r.Compatible())
+	}
+	if !r.Compatible() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// compact deletes the per-validator results of the days before the latest db.validators.retention days stored in the
+// db.dsn database, the days themselves are kept forever.
+func compact(args []string) {
+	if dbStore == nil {
+		log.Fatalf("compact requires db.dsn")
+	}
+	if opts.DbRetention == 0 {
+		log.Fatalf("compact requires db.validators.retention")
+	}
+	deleted, err := compactValidators(context.Background())
+	if err != nil {
+		log.Fatalf("error compacting db.dsn: %v", err)
+	}
+	fmt.Printf("deleted per-validator results: %v\n", deleted)
+}
+
+// compactValidators deletes the per-validator results of the days before the latest db.validators.retention days
+// stored in the db.dsn database and returns the number of deleted rows.
+func compactValidators(ctx context.Context) (int64, error) {
+	latest, err := dbStore.Latest(ctx)
+	if errors.Is(err, ethstore.ErrDayNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	last := latest.Day.BigInt().Uint64()
+	if last+1 <= opts.DbRetention {
+		return 0, nil
+	}
+	return dbStore.CompactValidators(ctx, last+1-opts.DbRetention)
+}
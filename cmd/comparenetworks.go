@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ethstore "github.com/gobitfly/eth.store"
+	"github.com/shopspring/decimal"
+)
+
+// compareNetworkNames are the networks compare-networks has a flag for, in the order of the comparison table.
+var compareNetworkNames = []string{"mainnet", "gnosis", "holesky", "hoodi", "sepolia"}
+
+// compareNetworks calculates the days of the given networks that match a date and prints their apr and the composition
+// of their rewards side by side.
+func compareNetworks(args []string) {
+	fs := flag.NewFlagSet("compare-networks", flag.ExitOnError)
+	dayDate := fs.String("day-date", "", "UTC date to compare the networks at, e.g. 2024-06-01, the day of every network that includes noon of the date is calculated")
+	addresses := map[string]*string{}
+	for _, name := range compareNetworkNames {
+		addresses[name] = fs.String(name, "", fmt.Sprintf("consensus and execution node of %v as \"cons-address,exec-address\"", name))
+	}
+	fs.Parse(args)
+
+	date, err := time.Parse("2006-01-02", *dayDate)
+	if err != nil {
+		log.Fatalf("invalid day-date: %v", *dayDate)
+	}
+	networks := []ethstore.Network{}
+	for _, name := range compareNetworkNames {
+		if *addresses[name] == "" {
+			continue
+		}
+		parts := strings.Split(*addresses[name], ",")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			log.Fatalf("invalid addresses of %v, expected \"cons-address,exec-address\": %v", name, *addresses[name])
+		}
+		networks = append(networks, ethstore.Network{Name: name, ConsAddress: parts[0], ExecAddress: parts[1]})
+	}
+	if len(networks) < 2 {
+		log.Fatalf("compare-networks requires the addresses of at least two networks")
+	}
+
+	comparisons, err := ethstore.CompareNetworks(context.Background(), networks, date, opts.Concurrency, calcOptions...)
+	if err != nil {
+		log.Fatalf("error comparing networks: %v", err)
+	}
+	if opts.Json {
+		comparisonsJson, err := json.MarshalIndent(comparisons, "", "\t")
+		if err != nil {
+			log.Fatalf("error marshaling comparison: %v", err)
+		}
+		fmt.Printf("%s\n", comparisonsJson)
+		return
+	}
+	hundred := decimal.NewFromInt(100)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "network\tday\twindow\tvalidators\tapr\tconsensus\texecution\ttotalRewardsWei\n")
+	for _, c := range comparisons {
+		d := c.Day
+		window := ""
+		if d.Window != nil {
+			window = fmt.Sprintf("%v - %v", d.Window.StartTime.UTC().Format(time.RFC3339), d.Window.EndTime.UTC().Format(time.RFC3339))
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v%%\t%v%%\t%v%%\t%v\n", c.Network, d.Day, window, d.Validators, d.Apr.Mul(hundred).StringFixed(3), c.ConsensusRewardShare.Mul(hundred).StringFixed(2), c.ExecutionRewardShare.Mul(hundred).StringFixed(2), d.TotalRewardsWei)
+	}
+	w.Flush()
+}
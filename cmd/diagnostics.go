@@ -0,0 +1,32 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	_ "net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// startDiagnostics serves the pprof endpoints on the given address and periodically logs memory and goroutine stats,
+// both are disabled if the address is empty or the interval is 0.
+func startDiagnostics(pprofAddress string, interval time.Duration) {
+	if pprofAddress != "" {
+		go func() {
+			log.Printf("serving pprof on http://%v/debug/pprof/", pprofAddress)
+			err := http.ListenAndServe(pprofAddress, nil)
+			if err != nil {
+				log.Printf("error serving pprof: %v", err)
+			}
+		}()
+	}
+	if interval > 0 {
+		go func() {
+			var m runtime.MemStats
+			for range time.Tick(interval) {
+				runtime.ReadMemStats(&m)
+				log.Printf("diagnostics: heapAlloc: %vMiB, heapInuse: %vMiB, sys: %vMiB, numGC: %v, goroutines: %v", m.HeapAlloc>>20, m.HeapInuse>>20, m.Sys>>20, m.NumGC, runtime.NumGoroutine())
+			}
+		}()
+	}
+}
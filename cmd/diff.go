@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	ethstore "github.com/gobitfly/eth.store"
+	"github.com/shopspring/decimal"
+)
+
+// diff prints the fields of the days of two json-files that differ by more than the tolerances, it exits with status 1
+// if there are differences.
+func diff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	tolerance := fs.Float64("tolerance", 0, "relative tolerance of numeric fields, e.g. 0.0001 for 0.01%")
+	fieldTolerances := fs.String("tolerance.fields", "", "comma-separated relative tolerances of single fields, e.g. \"apr=0.001,epochSeries=0.01\"")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		log.Fatalf("usage: diff [flags] fileA.json fileB.json")
+	}
+	for _, path := range fs.Args() {
+		if _, err := os.Stat(path); err != nil {
+			log.Fatalf("error reading %v: %v", path, err)
+		}
+	}
+	tolerances := &ethstore.DiffTolerances{
+		Default: decimal.NewFromFloat(*tolerance),
+		Fields:  map[string]decimal.Decimal{},
+	}
+	for _, t := range strings.Split(*fieldTolerances, ",") {
+		if t == "" {
+			continue
+		}
+		parts := strings.Split(t, "=")
+		if len(parts) != 2 {
+			log.Fatalf("invalid field tolerance: %v", t)
+		}
+		tol, err := decimal.NewFromString(parts[1])
+		if err != nil {
+			log.Fatalf("invalid field tolerance %v: %v", t, err)
+		}
+		tolerances.Fields[parts[0]] = tol
+	}
+
+	diffs, err := ethstore.DiffDays(ethstore.LatestDays(readJsonFile(fs.Arg(0))), ethstore.LatestDays(readJsonFile(fs.Arg(1))), tolerances)
+	if err != nil {
+		log.Fatalf("error comparing days: %v", err)
+	}
+	if opts.Json {
+		diffsJson, err := json.MarshalIndent(diffs, "", "\t")
+		if err != nil {
+			log.Fatalf("error marshaling diffs: %v", err)
+		}
+		fmt.Printf("%s\n", diffsJson)
+	} else {
+		for _, d := range diffs {
+			fmt.Printf("day: %v, field: %v, a: %v, b: %v\n", d.Day, d.Field, d.A, d.B)
+		}
+	}
+	if len(diffs) > 0 {
+		os.Exit(1)
+	}
+}
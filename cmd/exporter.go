@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ethstore "github.com/gobitfly/eth.store"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// exporter serves the apr and reward split of the latest finalized day of the network and of a subset of validators
+// as prometheus metrics and calculates every new finalized day.
+func exporter(args []string) {
+	fs := flag.NewFlagSet("exporter", flag.ExitOnError)
+	address := fs.String("address", "localhost:9888", "address to serve the metrics on /metrics")
+	validatorsStr := fs.String("validators", "", "comma-separated indices of the validators to export the rewards of, e.g. \"1,2,3\"")
+	interval := fs.Duration("interval", 10*time.Minute, "interval to check for a new finalized day in")
+	fs.Parse(args)
+
+	validators := []uint64{}
+	for _, s := range strings.Split(*validatorsStr, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		index, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			log.Fatalf("error parsing validators: %v", err)
+		}
+		validators = append(validators, index)
+	}
+
+	e := ethstore.NewExporter(validators)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+	go func() {
+		var lastDay uint64
+		calculated := false
+		for ; true; <-time.After(*interval) {
+			day, err := ethstore.GetFinalizedDay(context.Background(), opts.ConsAddress)
+			if err != nil {
+				log.Printf("error getting finalized day: %v", err)
+				continue
+			}
+			if calculated && day <= lastDay {
+				continue
+			}
+			d, validatorDays, err := ethstore.CalculateWithOptions(context.Background(), opts.ConsAddress, opts.ExecAddress, fmt.Sprintf("%d", day), calculateOptions()...)
+			if err != nil {
+				log.Printf("error calculating day %v: %v", day, err)
+				continue
+			}
+			lastDay, calculated = day, true
+			if d.Anomalous {
+				log.Printf("not exporting day %v: apr %v is outside of apr.bounds", day, d.Apr.StringFixed(9))
+				continue
+			}
+			e.Update(d, validatorDays)
+			pushMetrics(registry, d)
+			log.Printf("exporting day %v, apr: %v", day, d.Apr.StringFixed(9))
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	log.Printf("serving metrics on http://%v/metrics", *address)
+	err := http.ListenAndServe(*address, mux)
+	if err != nil {
+		log.Fatalf("error serving metrics: %v", err)
+	}
+}
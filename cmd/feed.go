@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// feed serves the last days stored in the json-file as a json-feed and reloads the file when it changes. With
+// db.validators the daily income of the validators stored in the db.dsn database, otherwise of those stored in the
+// validators-file, is served on /v1/validators/{index}/income and summed by withdrawal address on
+// /v1/addresses/{address}/income.
+func feed(args []string) {
+	fs := flag.NewFlagSet("feed", flag.ExitOnError)
+	address := fs.String("address", "localhost:8080", "address to serve /feed/latest.json and /feed/history.json on")
+	days := fs.Int("days", 30, "number of last days in /feed/history.json (all if 0)")
+	maxAge := fs.Duration("max-age", 5*time.Minute, "duration caches may serve the feed for (Cache-Control max-age)")
+	reload := fs.Duration("reload", time.Minute, "interval to check the json-file and the validators-file for changes in")
+	fs.Parse(args)
+
+	if opts.JsonFile == "" {
+		log.Fatalf("feed requires -json.file")
+	}
+	f := ethstore.NewFeed(*days, *maxAge)
+	err := f.Update(ethstore.LatestDays(readJsonFile(opts.JsonFile)))
+	if err != nil {
+		log.Fatalf("error updating feed: %v", err)
+	}
+	go reloadFile(opts.JsonFile, *reload, func(data []byte) error {
+		fileDays := []*ethstore.Day{}
+		err := json.Unmarshal(data, &fileDays)
+		if err != nil {
+			return fmt.Errorf("error parsing json-file: %w", err)
+		}
+		return f.Update(ethstore.LatestDays(fileDays))
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/feed/", f)
+	switch {
+	case dbStore != nil && opts.DbValidators:
+		mountIncome(mux, dbStore, *address)
+	case opts.ValidatorsFile != "":
+		validatorStore := ethstore.NewMemoryValidatorStore()
+		go reloadFile(opts.ValidatorsFile, *reload, func(data []byte) error {
+			fileValidatorDays := map[uint64]map[uint64]*ethstore.Day{}
+			err := json.Unmarshal(data, &fileValidatorDays)
+			if err != nil {
+				return fmt.Errorf("error parsing validators file: %w", err)
+			}
+			validatorStore.Update(fileValidatorDays)
+			return nil
+		})
+		mountIncome(mux, validatorStore, *address)
+	}
+	log.Printf("serving feed on http://%v/feed/latest.json", *address)
+	err = http.ListenAndServe(*address, mux)
+	if err != nil {
+		log.Fatalf("error serving feed: %v", err)
+	}
+}
+
+// mountIncome serves the daily income of the per-validator results of the store on /v1/validators/{index}/income and
+// /v1/addresses/{address}/income.
+func mountIncome(mux *http.ServeMux, store ethstore.ValidatorResultStore, address string) {
+	mux.Handle("/v1/", ethstore.NewIncomeAPI(store))
+	log.Printf("serving income on http://%v/v1/validators/{index}/income and http://%[1]v/v1/addresses/{address}/income", address)
+}
+
+// reloadFile passes the content of the file to load whenever its modification time changes, starting with the current
+// content. The file may be rewritten while it is read, a file that can not be loaded is read again after the interval.
+func reloadFile(path string, interval time.Duration, load func(data []byte) error) {
+	var modified time.Time
+	for ; true; <-time.After(interval) {
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Printf("error checking %v: %v", path, err)
+			continue
+		}
+		if !info.ModTime().After(modified) {
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Printf("error reading %v: %v", path, err)
+			continue
+		}
+		err = load(data)
+		if err != nil {
+			log.Printf("error loading %v: %v", path, err)
+			continue
+		}
+		modified = info.ModTime()
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"github.com/gobitfly/eth.store/fixture"
+)
+
+// serveFixture generates the fixture of a scenario file, prints the day eth.store is expected to calculate from it and
+// serves it as consensus and execution node until it is stopped.
+func serveFixture(args []string) {
+	fs := flag.NewFlagSet("fixture", flag.ExitOnError)
+	scenarioFile := fs.String("scenario", "", "json-file of the scenario to generate, e.g. {\"day\":10,\"validators\":64,\"consensusRewardGwei\":3000000,\"txFeeGwei\":10000}")
+	consAddress := fs.String("cons.address", "localhost:5052", "address to serve the beacon api of the fixture on")
+	execAddress := fs.String("exec.address", "localhost:8545", "address to serve the json-rpc of the fixture on")
+	fs.Parse(args)
+
+	data, err := ioutil.ReadFile(*scenarioFile)
+	if err != nil {
+		log.Fatalf("error reading scenario: %v", err)
+	}
+	var scenario fixture.Scenario
+	err = json.Unmarshal(data, &scenario)
+	if err != nil {
+		log.Fatalf("error parsing scenario: %v", err)
+	}
+	f, err := fixture.Generate(scenario)
+	if err != nil {
+		log.Fatalf("error generating fixture: %v", err)
+	}
+	expectedJson, err := json.MarshalIndent(f.Expected, "", "\t")
+	if err != nil {
+		log.Fatalf("error marshaling expected day: %v", err)
+	}
+	fmt.Printf("%s\n", expectedJson)
+
+	go func() {
+		log.Printf("serving the execution node of the fixture on http://%v", *execAddress)
+		err := http.ListenAndServe(*execAddress, f.ExecutionHandler())
+		if err != nil {
+			log.Fatalf("error serving execution node: %v", err)
+		}
+	}()
+	log.Printf("serving the consensus node of the fixture on http://%v", *consAddress)
+	err = http.ListenAndServe(*consAddress, f.BeaconHandler())
+	if err != nil {
+		log.Fatalf("error serving consensus node: %v", err)
+	}
+}
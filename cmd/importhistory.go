@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// importHistory imports the days of an eth.store history exported by another source, e.g. beaconcha.in, into db.dsn or
+// json.file, so that the trend and the trailing averages are available before the days have been calculated. The
+// imported days are flagged as unverified until they are recalculated, days that are already stored are kept.
+func importHistory(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "format of the history, \"csv\" or \"json\" (default the extension of the file)")
+	overwrite := fs.Bool("overwrite", false, "also replace stored days that have been calculated instead of keeping them")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("usage: eth.store -json.file=ethstore.json import [-format=csv] history.csv")
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	var store ethstore.ResultStore
+	switch {
+	case dbStore != nil:
+		store = dbStore
+	case opts.JsonFile != "":
+		fileStore, err := ethstore.NewFileStore(opts.JsonFile)
+		if err != nil {
+			log.Fatalf("error opening json.file: %v", err)
+		}
+		store = fileStore
+	default:
+		log.Fatalf("import requires db.dsn or json.file to store the days in")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("error opening history: %v", err)
+	}
+	defer f.Close()
+	days, err := ethstore.ReadHistory(f, *format)
+	if err != nil {
+		log.Fatalf("error reading history: %v", err)
+	}
+	imported := 0
+	for _, d := range days {
+		stored, err := store.Get(context.Background(), d.Day.BigInt().Uint64())
+		if err != nil && !errors.Is(err, ethstore.ErrDayNotFound) {
+			log.Fatalf("error getting stored day %v: %v", d.Day, err)
+		}
+		if err == nil && !stored.Unverified && !*overwrite {
+			continue
+		}
+		err = store.Put(context.Background(), d)
+		if err != nil {
+			log.Fatalf("error storing day %v: %v", d.Day, err)
+		}
+		imported++
+	}
+	log.Printf("imported %v of %v days as unverified, recalculate them to verify them", imported, len(days))
+}
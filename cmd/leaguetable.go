@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+
+	ethstore "github.com/gobitfly/eth.store"
+	"github.com/shopspring/decimal"
+)
+
+// leagueTable prints the entities of the per-validator results stored in the validators-file ranked by their apr.
+func leagueTable(args []string) {
+	fs := flag.NewFlagSet("league-table", flag.ExitOnError)
+	from := fs.Uint64("from", 0, "first day of the league table")
+	to := fs.Uint64("to", 0, "last day of the league table (last stored day if 0)")
+	fs.Parse(args)
+
+	if opts.ValidatorsFile == "" {
+		log.Fatalf("league-table requires -validators.file")
+	}
+	validatorsBytes, err := ioutil.ReadFile(opts.ValidatorsFile)
+	if err != nil {
+		log.Fatalf("error reading validators file: %v", err)
+	}
+	fileValidatorDays := map[uint64]map[uint64]*ethstore.Day{}
+	err = json.Unmarshal(validatorsBytes, &fileValidatorDays)
+	if err != nil {
+		log.Fatalf("error parsing validators file: %v", err)
+	}
+	days := []uint64{}
+	for day := range fileValidatorDays {
+		if day >= *from && (*to == 0 || day <= *to) {
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		log.Fatalf("no days of the validators file in the given range")
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+
+	validatorDaysByDay := []map[uint64]*ethstore.Day{}
+	for _, day := range days {
+		if entityLabels != nil {
+			ethstore.LabelEntities(fileValidatorDays[day], entityLabels)
+		}
+		validatorDaysByDay = append(validatorDaysByDay, fileValidatorDays[day])
+	}
+	entries := ethstore.LeagueTable(validatorDaysByDay)
+	if opts.Json {
+		entriesJson, err := json.MarshalIndent(entries, "", "\t")
+		if err != nil {
+			log.Fatalf("error marshaling league table: %v", err)
+		}
+		fmt.Printf("%s\n", entriesJson)
+		return
+	}
+	fmt.Printf("days: %v-%v, entities: %v\n", days[0], days[len(days)-1], len(entries))
+	for _, e := range entries {
+		fmt.Printf("rank: %v, entity: %v, validators: %v, apr: %v, executionRewardShare: %v%%, totalRewardsWei: %v\n", e.Rank, e.Entity, e.Validators.StringFixed(2), e.Apr.StringFixed(9), e.ExecutionRewardShare.Mul(decimal.NewFromInt(100)).StringFixed(2), e.TotalRewardsWei)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	ethstore "github.com/gobitfly/eth.store"
+	"github.com/gobitfly/eth.store/fixture"
+)
+
+func TestSetupCalculateOptions(t *testing.T) {
+	defer func() {
+		opts.LowMemory, opts.LeakPerEpoch, opts.TwoStates, opts.DbValidators, opts.Attestations = false, false, false, false, false
+		calcOptions = nil
+	}()
+
+	// conflicting flags are rejected for every command
+	opts.LowMemory, opts.DbValidators = true, true
+	if err := setupCalculateOptions(); err == nil {
+		t.Errorf("expected an error for low-memory with db.validators")
+	}
+	opts.LowMemory, opts.DbValidators = false, false
+	opts.TwoStates, opts.LeakPerEpoch = true, true
+	if err := setupCalculateOptions(); err == nil {
+		t.Errorf("expected an error for two-states with leak.per-epoch")
+	}
+
+	// the calculations of the commands use the options of the flags
+	f, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000, BaseFeeGwei: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	requestedStates := map[string]bool{}
+	bnHandler := f.BeaconHandler()
+	bnServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/eth/v1/beacon/states/") {
+			mu.Lock()
+			requestedStates[strings.Split(strings.TrimPrefix(r.URL.Path, "/eth/v1/beacon/states/"), "/")[0]] = true
+			mu.Unlock()
+		}
+		bnHandler.ServeHTTP(w, r)
+	}))
+	defer bnServer.Close()
+	elServer := httptest.NewServer(f.ExecutionHandler())
+	defer elServer.Close()
+
+	calcOptions = nil
+	opts.TwoStates, opts.LeakPerEpoch, opts.LowMemory = false, true, true
+	if err := setupCalculateOptions(); err != nil {
+		t.Fatal(err)
+	}
+	d, validatorDays, err := ethstore.CalculateWithOptions(context.Background(), bnServer.URL, elServer.URL, "10", calculateOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Apr.Equal(f.Expected.Apr) {
+		t.Errorf("wrong apr: %v != %v", d.Apr, f.Expected.Apr)
+	}
+	if len(validatorDays) != 0 {
+		t.Errorf("expected no per-validator results with low-memory: %v", len(validatorDays))
+	}
+	// the per-epoch leak detection requests the state of every epoch of the day
+	if !requestedStates["72032"] {
+		t.Errorf("expected the state of the second epoch with leak.per-epoch: %v", requestedStates)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+)
+
+// migrate migrates the schema of the db.dsn database: up applies the pending migrations, down reverts the latest
+// applied migrations and version prints the version of the schema.
+func migrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	steps := fs.Int("steps", 1, "number of the latest applied migrations reverted by down")
+	fs.Parse(args)
+
+	if dbStore == nil {
+		log.Fatalf("migrate requires db.dsn")
+	}
+	ctx := context.Background()
+	var err error
+	switch fs.Arg(0) {
+	case "up":
+		err = dbStore.Migrate(ctx)
+	case "down":
+		err = dbStore.MigrateDown(ctx, *steps)
+	case "version":
+	default:
+		log.Fatalf("unknown migrate direction %q, expected up, down or version", fs.Arg(0))
+	}
+	if err != nil {
+		log.Fatalf("error migrating db.dsn: %v", err)
+	}
+	version, err := dbStore.Version(ctx)
+	if err != nil {
+		log.Fatalf("error getting schema version of db.dsn: %v", err)
+	}
+	fmt.Printf("schema version: %v\n", version)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// exit codes of -once for the orchestration by a cron job, errors that are not worth retrying exit with 1 like any
+// fatal error
+const (
+	onceExitNothingToDo = 0
+	onceExitSuccess     = 10
+	onceExitRetryable   = 75
+)
+
+// onceDay returns the newest finalized day that is missing or unverified in json.file, it exits with
+// onceExitNothingToDo if all finalized days are stored.
+func onceDay(path, consAddress string) uint64 {
+	finalizedDay, err := ethstore.GetFinalizedDay(context.Background(), consAddress)
+	if err != nil {
+		fatalRetryable(err, "error getting finalized day: %v", err)
+	}
+	stored := map[uint64]bool{}
+	for _, d := range ethstore.LatestDays(readJsonFile(path)) {
+		// imported days are missing until they are recalculated
+		stored[d.Day.BigInt().Uint64()] = !d.Unverified
+	}
+	for dd := int64(finalizedDay); dd >= 0; dd-- {
+		if !stored[uint64(dd)] {
+			return uint64(dd)
+		}
+	}
+	log.Printf("nothing to do: all days up to the finalized day %v are stored in %v", finalizedDay, path)
+	os.Exit(onceExitNothingToDo)
+	return 0
+}
+
+// retryable reports whether a calculation that failed with err may succeed when it is retried, which is the case
+// unless the configuration or the recorded spec is at fault or the day is anomalous.
+func retryable(err error) bool {
+	return !errors.Is(err, ethstore.ErrSpecMismatch) && !errors.Is(err, ethstore.ErrStatesRequired) && !errors.Is(err, ethstore.ErrAnomalousDay)
+}
+
+// fatalRetryable logs the error and exits, with onceExitRetryable in -once mode if the error is retryable.
+func fatalRetryable(err error, format string, v ...interface{}) {
+	if opts.Once && retryable(err) {
+		log.Printf(format, v...)
+		os.Exit(onceExitRetryable)
+	}
+	log.Fatalf(format, v...)
+}
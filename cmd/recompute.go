@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// recompute recalculates the days stored in the json-file with another methodology version and writes the results
+// into a separate file, so the results of both versions can be compared before switching to the new version.
+func recompute(args []string) {
+	fs := flag.NewFlagSet("recompute", flag.ExitOnError)
+	methodologyStr := fs.String("methodology", fmt.Sprintf("v%d", ethstore.MethodologyVersion), "methodology version to recompute the days with, e.g. \"v2\"")
+	from := fs.Uint64("from", 0, "first stored day to recompute")
+	to := fs.Uint64("to", 0, "last stored day to recompute (last stored day if 0)")
+	parallel := fs.Int("parallel", 2, "number of days to recompute at the same time")
+	out := fs.String("out", "", "path to the file to write the recomputed days into (json.file with the version appended if empty, e.g. ethstore.v2.json)")
+	fs.Parse(args)
+
+	if opts.JsonFile == "" {
+		log.Fatalf("recompute requires -json.file")
+	}
+	methodology, err := strconv.Atoi(strings.TrimPrefix(*methodologyStr, "v"))
+	if err != nil {
+		log.Fatalf("error parsing methodology: %v", err)
+	}
+	if *out == "" {
+		ext := filepath.Ext(opts.JsonFile)
+		*out = fmt.Sprintf("%s.v%d%s", strings.TrimSuffix(opts.JsonFile, ext), methodology, ext)
+	}
+
+	stored := []*ethstore.Day{}
+	for _, d := range ethstore.LatestDays(readJsonFile(opts.JsonFile)) {
+		day := uint64(d.Day.IntPart())
+		if day >= *from && (*to == 0 || day <= *to) {
+			stored = append(stored, d)
+		}
+	}
+	if len(stored) == 0 {
+		log.Fatalf("no days of the json-file in the given range")
+	}
+	recomputed, err := ethstore.Recompute(context.Background(), opts.ConsAddress, opts.ExecAddress, stored, methodology, *parallel, opts.Concurrency, calcOptions...)
+	if err != nil {
+		log.Fatalf("error recomputing days: %v", err)
+	}
+	writeJsonFile(*out, recomputed)
+
+	storedByDay := map[uint64]*ethstore.Day{}
+	for _, d := range stored {
+		storedByDay[uint64(d.Day.IntPart())] = d
+	}
+	for _, d := range recomputed {
+		s := storedByDay[uint64(d.Day.IntPart())]
+		storedVersion := 0
+		if s.Provenance != nil {
+			storedVersion = s.Provenance.MethodologyVersion
+		}
+		fmt.Printf("day: %v, apr (v%v): %v, apr (v%v): %v, difference: %v\n", d.Day, storedVersion, s.Apr.StringFixed(9), methodology, d.Apr.StringFixed(9), d.Apr.Sub(s.Apr).StringFixed(9))
+	}
+	fmt.Printf("recomputed %v days with methodology version %v into %v\n", len(recomputed), methodology, *out)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	ethstore "github.com/gobitfly/eth.store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var remoteWriter *ethstore.RemoteWriter
+
+// setupRemoteWrite configures the remote-write endpoint the metrics of the calculated days are pushed to.
+func setupRemoteWrite() {
+	if opts.RemoteWriteURL == "" {
+		return
+	}
+	labels := map[string]string{}
+	for _, s := range strings.Split(opts.RemoteWriteLabels, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		name, value, found := strings.Cut(s, "=")
+		if !found || name == "" {
+			log.Fatalf("error parsing remote-write.labels: invalid label %v", s)
+		}
+		labels[name] = value
+	}
+	remoteWriter = &ethstore.RemoteWriter{
+		URL:      opts.RemoteWriteURL,
+		Username: opts.RemoteWriteUser,
+		Password: opts.RemoteWritePass,
+		Tenant:   opts.RemoteWriteTenant,
+		Labels:   labels,
+	}
+}
+
+// pushDay pushes the metrics of the day to the remote-write endpoint, the metrics are the ones of the exporter
+// command without a subset of validators.
+func pushDay(d *ethstore.Day, validatorDays map[uint64]*ethstore.Day) {
+	if remoteWriter == nil {
+		return
+	}
+	e := ethstore.NewExporter(nil)
+	e.Update(d, validatorDays)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+	pushMetrics(registry, d)
+}
+
+// pushMetrics pushes the metrics of the gatherer to the remote-write endpoint after the day has been calculated.
+func pushMetrics(g prometheus.Gatherer, d *ethstore.Day) {
+	if remoteWriter == nil {
+		return
+	}
+	err := remoteWriter.Push(context.Background(), g, time.Now())
+	if err != nil {
+		log.Printf("error pushing metrics of day %v: %v", d.Day, err)
+	}
+}
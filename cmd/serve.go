@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// serve keeps calculating every new finalized day and serves the calculated days on /days, /days/latest and
+// /days/{day} and the last days as json-feed on /feed/latest.json and /feed/history.json. With db.dsn or json.file the
+// stored days are served as well and the calculated days are added to the database or the file, the days after the
+// last stored day are calculated first. With db.validators the daily income of the validators stored in the database
+// is served on /v1/validators/{index}/income and /v1/addresses/{address}/income.
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	address := fs.String("address", "localhost:8080", "address to serve /days, /feed and with db.validators /v1 on")
+	interval := fs.Duration("interval", 10*time.Minute, "interval to check for a new finalized day in")
+	feedDays := fs.Int("feed.days", 30, "number of last days in /feed/history.json (all if 0)")
+	feedMaxAge := fs.Duration("feed.max-age", 5*time.Minute, "duration caches may serve the feed for (Cache-Control max-age)")
+	fs.Parse(args)
+
+	var store ethstore.ResultStore = ethstore.NewMemoryStore()
+	if dbStore != nil {
+		store = dbStore
+	} else if opts.JsonFile != "" {
+		fileStore, err := ethstore.NewFileStore(opts.JsonFile)
+		if err != nil {
+			log.Fatalf("error opening json.file: %v", err)
+		}
+		store = fileStore
+	}
+	f := ethstore.NewFeed(*feedDays, *feedMaxAge)
+	updateFeed(f, store)
+	go func() {
+		for ; true; <-time.After(*interval) {
+			finalizedDay, err := ethstore.GetFinalizedDay(context.Background(), opts.ConsAddress)
+			if err != nil {
+				log.Printf("error getting finalized day: %v", err)
+				continue
+			}
+			// without stored days only the finalized day is calculated
+			next := finalizedDay
+			previous, err := store.Latest(context.Background())
+			if err == nil {
+				next = uint64(previous.Day.IntPart()) + 1
+			} else if !errors.Is(err, ethstore.ErrDayNotFound) {
+				log.Printf("error getting latest day: %v", err)
+				continue
+			}
+			for day := next; day <= finalizedDay; day++ {
+				d, validatorDays, err := ethstore.CalculateWithOptions(context.Background(), opts.ConsAddress, opts.ExecAddress, fmt.Sprintf("%d", day), calculateOptions()...)
+				if err != nil {
+					log.Printf("error calculating day %v: %v", day, err)
+					break
+				}
+				notifyAlerts(d, previous)
+				if d.Anomalous {
+					log.Printf("not storing day %v: apr %v is outside of apr.bounds", day, d.Apr.StringFixed(9))
+					break
+				}
+				previous = d
+				err = store.Put(context.Background(), d)
+				if err == nil {
+					err = storeValidators(context.Background(), day, validatorDays)
+				}
+				if err != nil {
+					log.Printf("error storing day %v: %v", day, err)
+					break
+				}
+				pushDay(d, validatorDays)
+				updateFeed(f, store)
+				log.Printf("calculated day %v, apr: %v", day, d.Apr.StringFixed(9))
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	api := ethstore.NewDaysAPI(store)
+	mux.Handle("/days", api)
+	mux.Handle("/days/", api)
+	mux.Handle("/feed/", f)
+	if dbStore != nil && opts.DbValidators {
+		mountIncome(mux, dbStore, *address)
+	}
+	log.Printf("serving days on http://%v/days", *address)
+	err := http.ListenAndServe(*address, mux)
+	if err != nil {
+		log.Fatalf("error serving days: %v", err)
+	}
+}
+
+// updateFeed replaces the days of the feed with the days of the store, a store without days leaves the feed empty.
+func updateFeed(f *ethstore.Feed, store ethstore.ResultStore) {
+	days, err := store.Range(context.Background(), 0, math.MaxUint64)
+	if err != nil {
+		log.Printf("error getting days of the feed: %v", err)
+		return
+	}
+	if len(days) == 0 {
+		return
+	}
+	err = f.Update(days)
+	if err != nil {
+		log.Printf("error updating feed: %v", err)
+	}
+}
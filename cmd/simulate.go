@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// simulate takes the reward distribution of the days given by the global days-flag and simulates the apr
+// an operator with the given number of validators would have achieved in a year.
+func simulate(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	validators := fs.Int("validators", 1, "number of validators run by the simulated operator")
+	runs := fs.Int("runs", 10000, "number of simulated years")
+	seed := fs.Int64("seed", 1, "seed of the random number generator")
+	fs.Parse(args)
+
+	ethstoreDays := []*ethstore.Day{}
+	for _, dd := range parseDays(opts.Days, opts.ConsAddress) {
+		d, _, err := ethstore.CalculateWithOptions(context.Background(), opts.ConsAddress, opts.ExecAddress, fmt.Sprintf("%d", dd), calculateOptions()...)
+		if err != nil {
+			log.Fatalf("error calculating ethstore: %v", err)
+		}
+		ethstoreDays = append(ethstoreDays, d)
+	}
+
+	sim, err := ethstore.Simulate(ethstoreDays, *validators, *runs, *seed)
+	if err != nil {
+		log.Fatalf("error simulating: %v", err)
+	}
+
+	if opts.Json {
+		simJson, err := json.MarshalIndent(sim, "", "\t")
+		if err != nil {
+			log.Fatalf("error marshaling simulation: %v", err)
+		}
+		fmt.Printf("%s\n", simJson)
+		return
+	}
+	for _, p := range sim.Percentiles {
+		fmt.Printf("validators: %v, runs: %v, percentile: %v, apr: %v\n", sim.Validators, sim.Runs, p.Percentile, p.Apr.StringFixed(9))
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"math"
+	"os"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// timeline writes the lifecycle events (activations, exits, slashings and withdrawal credential changes) of all
+// validators during the days stored in db.dsn or json.file to stdout, derived from the validator snapshots at the
+// boundaries of the days.
+func timeline(args []string) {
+	fs := flag.NewFlagSet("timeline", flag.ExitOnError)
+	from := fs.Uint64("from", 0, "first day of the timeline (default the first stored day)")
+	to := fs.Uint64("to", 0, "last day of the timeline (default the last stored day)")
+	format := fs.String("format", "csv", "format of the timeline, \"csv\" or \"json\"")
+	fs.Parse(args)
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("unknown timeline format: %v", *format)
+	}
+
+	var store ethstore.ResultStore
+	switch {
+	case dbStore != nil:
+		store = dbStore
+	case opts.JsonFile != "":
+		fileStore, err := ethstore.NewFileStore(opts.JsonFile)
+		if err != nil {
+			log.Fatalf("error opening json.file: %v", err)
+		}
+		store = fileStore
+	default:
+		log.Fatalf("timeline requires db.dsn or json.file to determine the stored days")
+	}
+
+	last := uint64(math.MaxUint64)
+	if *to != 0 {
+		last = *to
+	}
+	days, err := store.Range(context.Background(), *from, last)
+	if err != nil {
+		log.Fatalf("error getting stored days: %v", err)
+	}
+	if len(days) == 0 {
+		log.Fatalf("no stored days within the range")
+	}
+	firstDay, lastDay := days[0].Day.BigInt().Uint64(), days[len(days)-1].Day.BigInt().Uint64()
+	events, err := ethstore.GetLifecycleTimeline(context.Background(), opts.ConsAddress, firstDay, lastDay)
+	if err != nil {
+		log.Fatalf("error getting lifecycle timeline: %v", err)
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(events)
+	} else {
+		err = ethstore.WriteLifecycleCSV(os.Stdout, events)
+	}
+	if err != nil {
+		log.Fatalf("error writing timeline: %v", err)
+	}
+	log.Printf("wrote %v lifecycle events of days %v-%v", len(events), firstDay, lastDay)
+}
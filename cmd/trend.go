@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// trend prints the trend statistics of the apr of the days stored in the json-file as json.
+func trend(args []string) {
+	fs := flag.NewFlagSet("trend", flag.ExitOnError)
+	from := fs.Uint64("from", 0, "first day of the trend")
+	to := fs.Uint64("to", 0, "last day of the trend (last stored day if 0)")
+	window := fs.Int("window", 7, "number of days to smooth the apr over for the drawdown")
+	fs.Parse(args)
+
+	if opts.JsonFile == "" {
+		log.Fatalf("trend requires -json.file")
+	}
+	days := []*ethstore.Day{}
+	for _, d := range ethstore.LatestDays(readJsonFile(opts.JsonFile)) {
+		day := d.Day.BigInt().Uint64()
+		if day >= *from && (*to == 0 || day <= *to) {
+			days = append(days, d)
+		}
+	}
+	summary, err := ethstore.Trend(days, *window)
+	if err != nil {
+		log.Fatalf("error computing trend: %v", err)
+	}
+	summaryJson, err := json.MarshalIndent(summary, "", "\t")
+	if err != nil {
+		log.Fatalf("error marshaling trend: %v", err)
+	}
+	fmt.Printf("%s\n", summaryJson)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// verifyStore re-derives the input hashes of the days stored in the json-file and reports every day that does
+// not match, the recorded state roots are compared to the ones of the consensus node unless offline is set.
+func verifyStore(args []string) {
+	fs := flag.NewFlagSet("verify-store", flag.ExitOnError)
+	offline := fs.Bool("offline", false, "only verify the recorded state roots without refetching them from the consensus node")
+	fs.Parse(args)
+
+	if opts.JsonFile == "" {
+		log.Fatalf("verify-store requires -json.file")
+	}
+	bnAddress := opts.ConsAddress
+	if *offline {
+		bnAddress = ""
+	}
+
+	failed := 0
+	fileDays := ethstore.LatestDays(readJsonFile(opts.JsonFile))
+	for _, d := range fileDays {
+		mismatches, err := ethstore.VerifyDay(context.Background(), bnAddress, d)
+		if err != nil {
+			log.Fatalf("error verifying day %v: %v", d.Day, err)
+		}
+		if len(mismatches) > 0 {
+			failed++
+		}
+		for _, m := range mismatches {
+			fmt.Printf("day: %v, mismatch: %v\n", d.Day, m)
+		}
+	}
+	if failed > 0 {
+		log.Fatalf("%v of %v stored days do not match", failed, len(fileDays))
+	}
+	fmt.Printf("verified %v stored days\n", len(fileDays))
+}
@@ -0,0 +1,86 @@
+package ethstore
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// CommissionRates holds the commission an operator charges on the rewards of its validators. Validators of a pool are
+// charged the rate of the label of the pool, all other validators are charged the default rate.
+type CommissionRates struct {
+	Default decimal.Decimal            `json:"default"`
+	Labels  map[string]decimal.Decimal `json:"labels"`
+	Pools   map[string][]uint64        `json:"pools"`
+}
+
+// Validate checks that all rates are between 0 and 1 and that every pool has a rate and every validator is in at most
+// one pool.
+func (r *CommissionRates) Validate() error {
+	one := decimal.NewFromInt(1)
+	if r.Default.IsNegative() || r.Default.GreaterThan(one) {
+		return fmt.Errorf("invalid default commission rate: %v", r.Default)
+	}
+	for label, rate := range r.Labels {
+		if rate.IsNegative() || rate.GreaterThan(one) {
+			return fmt.Errorf("invalid commission rate of label %v: %v", label, rate)
+		}
+	}
+	pools := map[uint64]string{}
+	for label, validators := range r.Pools {
+		if _, exists := r.Labels[label]; !exists {
+			return fmt.Errorf("no commission rate for pool %v", label)
+		}
+		for _, index := range validators {
+			if other, exists := pools[index]; exists {
+				return fmt.Errorf("validator %v is in pool %v and pool %v", index, other, label)
+			}
+			pools[index] = label
+		}
+	}
+	return nil
+}
+
+// rates returns the commission rate of every validator that is in a pool.
+func (r *CommissionRates) rates() map[uint64]decimal.Decimal {
+	rates := map[uint64]decimal.Decimal{}
+	for label, validators := range r.Pools {
+		for _, index := range validators {
+			rates[index] = r.Labels[label]
+		}
+	}
+	return rates
+}
+
+// ApplyCommission deducts the commission from the rewards of the per-validator days returned by Calculate and sets the
+// gross apr, the net apr and the commission of the validators and of the day. No commission is charged on negative
+// rewards.
+func ApplyCommission(d *Day, validatorDays map[uint64]*Day, r *CommissionRates) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	rates := r.rates()
+	commissionWei := decimal.Zero
+	for index, vd := range validatorDays {
+		rate, exists := rates[index]
+		if !exists {
+			rate = r.Default
+		}
+		vCommissionWei := decimal.Zero
+		if vd.TotalRewardsWei.IsPositive() {
+			vCommissionWei = vd.TotalRewardsWei.Mul(rate).Floor()
+		}
+		setCommission(vd, vCommissionWei)
+		commissionWei = commissionWei.Add(vCommissionWei)
+	}
+	setCommission(d, commissionWei)
+	return nil
+}
+
+func setCommission(d *Day, commissionWei decimal.Decimal) {
+	aprGross := d.Apr
+	aprNet := groupApr(d.TotalRewardsWei.Sub(commissionWei), d.EffectiveBalanceGwei)
+	d.CommissionWei = &commissionWei
+	d.AprGross = &aprGross
+	d.AprNet = &aprNet
+}
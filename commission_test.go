@@ -0,0 +1,102 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCommissionRatesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rates CommissionRates
+		valid bool
+	}{
+		{name: "default only", rates: CommissionRates{Default: decimal.NewFromFloat(0.1)}, valid: true},
+		{name: "negative default", rates: CommissionRates{Default: decimal.NewFromFloat(-0.1)}},
+		{name: "default above 1", rates: CommissionRates{Default: decimal.NewFromFloat(1.1)}},
+		{
+			name: "pools",
+			rates: CommissionRates{
+				Labels: map[string]decimal.Decimal{"a": decimal.NewFromFloat(0.05), "b": decimal.NewFromFloat(0.15)},
+				Pools:  map[string][]uint64{"a": {1, 2}, "b": {3}},
+			},
+			valid: true,
+		},
+		{name: "pool without rate", rates: CommissionRates{Pools: map[string][]uint64{"a": {1}}}},
+		{
+			name: "validator in two pools",
+			rates: CommissionRates{
+				Labels: map[string]decimal.Decimal{"a": decimal.Zero, "b": decimal.Zero},
+				Pools:  map[string][]uint64{"a": {1}, "b": {1}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		err := tt.rates.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%v: expected error", tt.name)
+		}
+	}
+}
+
+func TestApplyCommission(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, validatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates := &CommissionRates{
+		Default: decimal.NewFromFloat(0.1),
+		Labels:  map[string]decimal.Decimal{"pool": decimal.NewFromFloat(0.5)},
+		Pools:   map[string][]uint64{"pool": {0}},
+	}
+	err = ApplyCommission(day, validatorDays, rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if day.AprGross == nil || day.AprNet == nil || day.CommissionWei == nil {
+		t.Fatalf("no commission fields set: %+v", day)
+	}
+	if !day.AprGross.Equal(day.Apr) {
+		t.Errorf("wrong gross apr: %v != %v", day.AprGross, day.Apr)
+	}
+	if !day.AprNet.LessThan(*day.AprGross) {
+		t.Errorf("net apr not below gross apr: %v >= %v", day.AprNet, day.AprGross)
+	}
+
+	commissionWei := decimal.Zero
+	for index, vd := range validatorDays {
+		rate := rates.Default
+		if index == 0 {
+			rate = rates.Labels["pool"]
+		}
+		expected := decimal.Zero
+		if vd.TotalRewardsWei.IsPositive() {
+			expected = vd.TotalRewardsWei.Mul(rate).Floor()
+		}
+		if !vd.CommissionWei.Equal(expected) {
+			t.Errorf("wrong commission of validator %v: %v != %v", index, vd.CommissionWei, expected)
+		}
+		commissionWei = commissionWei.Add(*vd.CommissionWei)
+	}
+	if !day.CommissionWei.Equal(commissionWei) {
+		t.Errorf("wrong commission of day: %v != %v", day.CommissionWei, commissionWei)
+	}
+	expectedAprNet := groupApr(day.TotalRewardsWei.Sub(commissionWei), day.EffectiveBalanceGwei)
+	if !day.AprNet.Equal(expectedAprNet) {
+		t.Errorf("wrong net apr: %v != %v", day.AprNet, expectedAprNet)
+	}
+
+	if err := ApplyCommission(day, validatorDays, &CommissionRates{Default: decimal.NewFromInt(2)}); err == nil {
+		t.Errorf("expected error for invalid commission rate")
+	}
+}
@@ -0,0 +1,73 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Network is a network of a comparison with the addresses of its consensus and execution node.
+type Network struct {
+	Name        string
+	ConsAddress string
+	ExecAddress string
+}
+
+// NetworkComparison is the day of a network that matches the date of a comparison with the shares of the consensus
+// and the execution rewards in its total rewards.
+type NetworkComparison struct {
+	Network              string          `json:"network"`
+	Day                  *Day            `json:"day"`
+	ConsensusRewardShare decimal.Decimal `json:"consensusRewardShare"`
+	ExecutionRewardShare decimal.Decimal `json:"executionRewardShare"`
+}
+
+// MatchingDay returns the day of the boundary that includes noon UTC of the calendar date of date, which is the day
+// with the largest overlap with the date if the days are 24 hours long. Networks with different genesis times and
+// slot times have different days, the matching days of a date cover (mostly) the same time on every network.
+func MatchingDay(boundary DayBoundary, t *ChainTiming, date time.Time) (uint64, error) {
+	noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, time.UTC)
+	if noon.Before(t.Genesis) {
+		return 0, fmt.Errorf("date %v is before genesis %v", noon.Format("2006-01-02"), t.Genesis.UTC())
+	}
+	slot := uint64(noon.Unix()-t.Genesis.Unix()) / t.SecondsPerSlot
+	return boundary.Day(slot, t), nil
+}
+
+// CompareNetworks calculates the matching day (see MatchingDay) of the date on every network like Calculate, the
+// comparisons are returned in the order of the networks. The networks are calculated one after another with the same
+// options, see CalculateWithOptions.
+func CompareNetworks(ctx context.Context, networks []Network, date time.Time, concurrency int, options ...Option) ([]*NetworkComparison, error) {
+	o := &calculateOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	comparisons := make([]*NetworkComparison, 0, len(networks))
+	for _, n := range networks {
+		client, err := newConsClient(ctx, n.ConsAddress)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to the consensus node of %v: %w", n.Name, err)
+		}
+		timing, err := getChainTiming(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("error getting the chain timing of %v: %w", n.Name, err)
+		}
+		day, err := MatchingDay(o.getDayBoundary(), timing, date)
+		if err != nil {
+			return nil, fmt.Errorf("error matching the date on %v: %w", n.Name, err)
+		}
+		d, _, err := CalculateWithOptions(ctx, n.ConsAddress, n.ExecAddress, fmt.Sprintf("%d", day), append([]Option{WithConcurrency(concurrency)}, options...)...)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating day %v of %v: %w", day, n.Name, err)
+		}
+		c := &NetworkComparison{Network: n.Name, Day: d}
+		if !d.TotalRewardsWei.IsZero() {
+			c.ConsensusRewardShare = d.ConsensusRewardsWei.Div(d.TotalRewardsWei)
+			c.ExecutionRewardShare = d.TxFeesSumWei.Div(d.TotalRewardsWei)
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons, nil
+}
@@ -0,0 +1,91 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth.store/fixture"
+	"github.com/shopspring/decimal"
+)
+
+func TestMatchingDay(t *testing.T) {
+	mainnet := &ChainTiming{Genesis: time.Unix(1606824023, 0), SecondsPerSlot: 12, SlotsPerEpoch: 32}
+	gnosis := &ChainTiming{Genesis: time.Unix(1638993340, 0), SecondsPerSlot: 5, SlotsPerEpoch: 16}
+	tests := []struct {
+		name     string
+		boundary DayBoundary
+		timing   *ChainTiming
+		date     string
+		day      uint64
+		err      bool
+	}{
+		// day 10 starts on 2020-12-11 at 12:00:23 and includes noon of 2020-12-12
+		{name: "mainnet genesis", boundary: GenesisDayBoundary{}, timing: mainnet, date: "2020-12-12", day: 10},
+		{name: "mainnet utc", boundary: UTCDayBoundary{}, timing: mainnet, date: "2020-12-12", day: 11},
+		// noon of the date of genesis is before genesis
+		{name: "before genesis", boundary: GenesisDayBoundary{}, timing: mainnet, date: "2020-12-01", err: true},
+		// gnosis genesis is at 2021-12-08 20:35:40
+		{name: "gnosis genesis", boundary: GenesisDayBoundary{}, timing: gnosis, date: "2024-06-01", day: 905},
+		{name: "gnosis utc", boundary: UTCDayBoundary{}, timing: gnosis, date: "2024-06-01", day: 906},
+	}
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		day, err := MatchingDay(tt.boundary, tt.timing, date)
+		if (err != nil) != tt.err {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !tt.err && day != tt.day {
+			t.Errorf("%v: wrong day: %v != %v", tt.name, day, tt.day)
+		}
+	}
+}
+
+func TestCompareNetworks(t *testing.T) {
+	a, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 32, ConsensusRewardGwei: 2800000, TxFeeGwei: 30000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bnServer, elServer := a.Servers()
+	defer bnServer.Close()
+	defer elServer.Close()
+	otherBnServer, otherElServer := b.Servers()
+	defer otherBnServer.Close()
+	defer otherElServer.Close()
+
+	networks := []Network{
+		{Name: "a", ConsAddress: bnServer.URL, ExecAddress: elServer.URL},
+		{Name: "b", ConsAddress: otherBnServer.URL, ExecAddress: otherElServer.URL},
+	}
+	date, _ := time.Parse("2006-01-02", "2020-12-12")
+	comparisons, err := CompareNetworks(context.Background(), networks, date, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comparisons) != 2 || comparisons[0].Network != "a" || comparisons[1].Network != "b" {
+		t.Fatalf("wrong comparisons: %v", comparisons)
+	}
+	for i, f := range []*fixture.Fixture{a, b} {
+		c := comparisons[i]
+		if c.Day.Day.IntPart() != 10 {
+			t.Errorf("wrong day of %v: %v", c.Network, c.Day.Day)
+		}
+		if !c.Day.Apr.Equal(f.Expected.Apr) {
+			t.Errorf("wrong apr of %v: %v != %v", c.Network, c.Day.Apr, f.Expected.Apr)
+		}
+		executionShare := f.Expected.TxFeesSumWei.Div(f.Expected.TotalRewardsWei)
+		if !c.ExecutionRewardShare.Equal(executionShare) || !c.ConsensusRewardShare.Add(c.ExecutionRewardShare).Equal(decimal.NewFromInt(1)) {
+			t.Errorf("wrong reward shares of %v: %v %v", c.Network, c.ConsensusRewardShare, c.ExecutionRewardShare)
+		}
+	}
+
+	date, _ = time.Parse("2006-01-02", "2020-11-30")
+	if _, err := CompareNetworks(context.Background(), networks, date, 10); err == nil {
+		t.Errorf("expected error for date before genesis")
+	}
+}
@@ -0,0 +1,58 @@
+package ethstore
+
+import (
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+// farFutureEpoch is FAR_FUTURE_EPOCH of the consensus specs, the exit epoch of validators that are not exiting.
+const farFutureEpoch = phase0.Epoch(0xffffffffffffffff)
+
+// ValidatorComposition holds the number of active validators of the network by their state and by the type of their
+// withdrawal credentials.
+type ValidatorComposition struct {
+	Active                 decimal.Decimal `json:"active"`
+	Slashed                decimal.Decimal `json:"slashed"`
+	Exiting                decimal.Decimal `json:"exiting"`
+	BlsCredentials         decimal.Decimal `json:"blsCredentials"`
+	ExecutionCredentials   decimal.Decimal `json:"executionCredentials"`
+	CompoundingCredentials decimal.Decimal `json:"compoundingCredentials"`
+}
+
+// getComposition counts the active validators of the given validators, exiting validators have an exit epoch
+// (including slashed validators) and the credentials are counted by their prefix (0x00, 0x01 and 0x02).
+func getComposition(validators map[phase0.ValidatorIndex]*v1.Validator) *ValidatorComposition {
+	var active, slashed, exiting, bls, execution, compounding int64
+	for _, val := range validators {
+		if !val.Status.IsActive() {
+			continue
+		}
+		active++
+		if val.Validator.Slashed {
+			slashed++
+		}
+		if val.Validator.ExitEpoch != farFutureEpoch {
+			exiting++
+		}
+		if len(val.Validator.WithdrawalCredentials) == 0 {
+			continue
+		}
+		switch val.Validator.WithdrawalCredentials[0] {
+		case 0x00:
+			bls++
+		case 0x01:
+			execution++
+		case compoundingWithdrawalPrefix:
+			compounding++
+		}
+	}
+	return &ValidatorComposition{
+		Active:                 decimal.NewFromInt(active),
+		Slashed:                decimal.NewFromInt(slashed),
+		Exiting:                decimal.NewFromInt(exiting),
+		BlsCredentials:         decimal.NewFromInt(bls),
+		ExecutionCredentials:   decimal.NewFromInt(execution),
+		CompoundingCredentials: decimal.NewFromInt(compounding),
+	}
+}
@@ -0,0 +1,68 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+func TestGetComposition(t *testing.T) {
+	validator := func(status v1.ValidatorState, prefix byte, slashed bool, exitEpoch phase0.Epoch) *v1.Validator {
+		return &v1.Validator{
+			Status: status,
+			Validator: &phase0.Validator{
+				WithdrawalCredentials: append([]byte{prefix}, make([]byte, 31)...),
+				Slashed:               slashed,
+				ExitEpoch:             exitEpoch,
+			},
+		}
+	}
+	c := getComposition(map[phase0.ValidatorIndex]*v1.Validator{
+		0: validator(v1.ValidatorStateActiveOngoing, 0x00, false, farFutureEpoch),
+		1: validator(v1.ValidatorStateActiveOngoing, 0x01, false, farFutureEpoch),
+		2: validator(v1.ValidatorStateActiveExiting, 0x01, false, 100),
+		3: validator(v1.ValidatorStateActiveSlashed, 0x02, true, 100),
+		4: validator(v1.ValidatorStateExitedSlashed, 0x01, true, 50),
+		5: validator(v1.ValidatorStatePendingQueued, 0x02, false, farFutureEpoch),
+	})
+	expected := []struct {
+		name  string
+		value decimal.Decimal
+		count int64
+	}{
+		{"active", c.Active, 4},
+		{"slashed", c.Slashed, 1},
+		{"exiting", c.Exiting, 2},
+		{"blsCredentials", c.BlsCredentials, 1},
+		{"executionCredentials", c.ExecutionCredentials, 2},
+		{"compoundingCredentials", c.CompoundingCredentials, 1},
+	}
+	for _, e := range expected {
+		if !e.value.Equal(decimal.NewFromInt(e.count)) {
+			t.Errorf("wrong number of %v validators: %v != %v", e.name, e.value, e.count)
+		}
+	}
+}
+
+func TestCompositionOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*ValidatorComposition{day.CompositionStart, day.CompositionEnd} {
+		if c == nil || c.Active.LessThan(day.Validators) {
+			t.Fatalf("wrong composition: %+v", c)
+		}
+		credentials := c.BlsCredentials.Add(c.ExecutionCredentials).Add(c.CompoundingCredentials)
+		if credentials.GreaterThan(c.Active) {
+			t.Errorf("more credentials than active validators: %+v", c)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeSSZ  = "application/octet-stream"
+	// acceptSSZOrJSON is requested until a beacon node has responded, ssz is preferred since it is smaller and faster
+	// to decode
+	acceptSSZOrJSON = "application/octet-stream;q=1.0,application/json;q=0.9"
+)
+
+// errContentType is returned if a beacon node does not support the requested content type.
+var errContentType = errors.New("unsupported content type")
+
+// contentTypes holds the content type each endpoint of each beacon node has responded with, keyed by address and
+// endpoint.
+var contentTypes = map[string]string{}
+var contentTypesMu = &sync.Mutex{}
+
+func getContentType(address, endpoint string) string {
+	contentTypesMu.Lock()
+	defer contentTypesMu.Unlock()
+	return contentTypes[address+endpoint]
+}
+
+func setContentType(address, endpoint, contentType string) {
+	contentTypesMu.Lock()
+	defer contentTypesMu.Unlock()
+	contentTypes[address+endpoint] = contentType
+}
+
+// getSignedBeaconBlock requests the block at the given slot as ssz or json, whichever the beacon node supports, it
+// returns nil without an error if there is no block at the slot. The first request to a node accepts both and the
+// content type of the response is used for the following requests. If the node rejects the content type or its ssz
+// can not be decoded, the block is requested in the other content type.
+func getSignedBeaconBlock(ctx context.Context, address string, slot uint64) (*SignedBeaconBlock, error) {
+	const endpoint = "/eth/v2/beacon/blocks"
+	path := fmt.Sprintf("%s/%d", endpoint, slot)
+	accept := getContentType(address, endpoint)
+	if accept == "" {
+		accept = acceptSSZOrJSON
+	}
+	block, contentType, err := requestSignedBeaconBlock(ctx, address, path, accept)
+	if errors.Is(err, errContentType) {
+		fallback := contentTypeJSON
+		if accept == contentTypeJSON {
+			fallback = contentTypeSSZ
+		}
+		if GetDebugLevel() > 0 {
+			log.Printf("DEBUG eth.store: requesting block at slot %v as %v: %v", slot, fallback, err)
+		}
+		block, contentType, err = requestSignedBeaconBlock(ctx, address, path, fallback)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error requesting block at slot %v: %w", slot, err)
+	}
+	if contentType != "" && contentType != accept {
+		setContentType(address, endpoint, contentType)
+	}
+	return block, nil
+}
+
+// requestSignedBeaconBlock returns the block at the given path and the content type of the response, the content type
+// is empty if there is no block.
+func requestSignedBeaconBlock(ctx context.Context, address, path, accept string) (*SignedBeaconBlock, string, error) {
+	status, header, body, err := getBeacon(ctx, address, path, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return nil, "", nil
+	case status == http.StatusNotAcceptable || status == http.StatusUnsupportedMediaType:
+		return nil, "", fmt.Errorf("%w %v: status %v: %s", errContentType, accept, status, body)
+	case status != http.StatusOK:
+		return nil, "", fmt.Errorf("status %v: %s", status, body)
+	case len(body) == 0:
+		// some beacon nodes respond to missed slots with an empty body
+		return nil, "", nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	var block *SignedBeaconBlock
+	if mediaType == contentTypeSSZ {
+		err := decodeResponse(ctx, path, func(check func() error) (err error) {
+			// an ssz block is decoded at once, its size is bounded by the maximum response size
+			if err := check(); err != nil {
+				return err
+			}
+			block, err = decodeSSZBlock(header.Get("Eth-Consensus-Version"), body)
+			return err
+		})
+		if errors.Is(err, ErrDecodeTimeout) || ctx.Err() != nil {
+			return nil, "", err
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("%w %v: %v", errContentType, mediaType, err)
+		}
+		return block, contentTypeSSZ, nil
+	}
+	err = decodeResponse(ctx, path, func(check func() error) (err error) {
+		block, err = decodeJSONBlock(body, check)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return block, contentTypeJSON, nil
+}
+
+// decodeSSZBlock decodes an ssz block of the given version, the ssz of capella and later blocks is not supported and
+// these blocks are requested as json instead.
+func decodeSSZBlock(version string, body []byte) (*SignedBeaconBlock, error) {
+	var err error
+	block := &spec.VersionedSignedBeaconBlock{}
+	switch strings.ToLower(version) {
+	case "phase0":
+		block.Version, block.Phase0 = spec.DataVersionPhase0, &phase0.SignedBeaconBlock{}
+		err = block.Phase0.UnmarshalSSZ(body)
+	case "altair":
+		block.Version, block.Altair = spec.DataVersionAltair, &altair.SignedBeaconBlock{}
+		err = block.Altair.UnmarshalSSZ(body)
+	case "bellatrix":
+		block.Version, block.Bellatrix = spec.DataVersionBellatrix, &bellatrix.SignedBeaconBlock{}
+		err = block.Bellatrix.UnmarshalSSZ(body)
+	default:
+		return nil, fmt.Errorf("unsupported version of ssz block: %q", version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding ssz block: %w", err)
+	}
+	return newSignedBeaconBlock(block), nil
+}
+
+func decodeJSONBlock(body []byte, check func() error) (*SignedBeaconBlock, error) {
+	var res struct {
+		Version string          `json:"version"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := unmarshalJSON(body, &res, check); err != nil {
+		return nil, fmt.Errorf("error parsing block: %w", err)
+	}
+	if err := check(); err != nil {
+		return nil, err
+	}
+	if len(res.Data) == 0 || string(res.Data) == "null" {
+		return nil, nil
+	}
+	var err error
+	block := &SignedBeaconBlock{Fork: res.Version}
+	switch res.Version {
+	case "phase0":
+		block.Version, block.Phase0 = spec.DataVersionPhase0, &phase0.SignedBeaconBlock{}
+		err = json.Unmarshal(res.Data, block.Phase0)
+	case "altair":
+		block.Version, block.Altair = spec.DataVersionAltair, &altair.SignedBeaconBlock{}
+		err = json.Unmarshal(padSyncCommitteeBits(res.Data), block.Altair)
+	case "bellatrix", "capella", "deneb":
+		// the fields of capella and deneb blocks are a superset of the fields of bellatrix blocks
+		block.Version, block.Bellatrix = spec.DataVersionBellatrix, &bellatrix.SignedBeaconBlock{}
+		err = json.Unmarshal(padSyncCommitteeBits(res.Data), block.Bellatrix)
+		if err == nil {
+			err = decodeForkFields(block, res.Data)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported version of block: %q", res.Version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing block: %w", err)
+	}
+	return block, nil
+}
+
+// syncCommitteeBits matches the sync committee bits of a json block.
+var syncCommitteeBits = regexp.MustCompile(`"sync_committee_bits"\s*:\s*"0x([0-9a-fA-F]*)"`)
+
+// padSyncCommitteeBits pads the sync committee bits of blocks of presets with smaller sync committees than the 512
+// members of the mainnet preset the block types are limited to, e.g. the 32 members of the minimal preset of devnets.
+// The sync participation is counted with the SYNC_COMMITTEE_SIZE of the spec, so the padded bits are not counted.
+func padSyncCommitteeBits(data []byte) []byte {
+	return syncCommitteeBits.ReplaceAllFunc(data, func(m []byte) []byte {
+		bits := syncCommitteeBits.FindSubmatch(m)[1]
+		if len(bits) >= 128 {
+			return m
+		}
+		return []byte(fmt.Sprintf(`"sync_committee_bits":"0x%s%s"`, bits, strings.Repeat("0", 128-len(bits))))
+	})
+}
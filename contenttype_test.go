@@ -0,0 +1,105 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestGetSignedBeaconBlock(t *testing.T) {
+	block := func(slot uint64) *phase0.SignedBeaconBlock {
+		return &phase0.SignedBeaconBlock{
+			Message: &phase0.BeaconBlock{
+				Slot: phase0.Slot(slot),
+				Body: &phase0.BeaconBlockBody{
+					ETH1Data:          &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+					Graffiti:          make([]byte, 32),
+					ProposerSlashings: []*phase0.ProposerSlashing{},
+					AttesterSlashings: []*phase0.AttesterSlashing{},
+					Attestations:      []*phase0.Attestation{},
+					Deposits:          []*phase0.Deposit{},
+					VoluntaryExits:    []*phase0.SignedVoluntaryExit{},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		// ssz and json are the content types the node supports, version is whether it sets Eth-Consensus-Version
+		ssz, json, version bool
+		// accepts are the Accept headers of the requests for the first two blocks, including fallbacks
+		accepts []string
+	}{
+		{name: "ssz and json", ssz: true, json: true, version: true, accepts: []string{acceptSSZOrJSON, contentTypeSSZ}},
+		{name: "ssz only", ssz: true, version: true, accepts: []string{acceptSSZOrJSON, contentTypeSSZ}},
+		{name: "json only", json: true, accepts: []string{acceptSSZOrJSON, contentTypeJSON}},
+		{name: "ssz without version", ssz: true, json: true, accepts: []string{acceptSSZOrJSON, contentTypeJSON, contentTypeJSON}},
+	}
+	for _, tt := range tests {
+		var accepts []string
+		mu := sync.Mutex{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept := r.Header.Get("Accept")
+			mu.Lock()
+			accepts = append(accepts, accept)
+			mu.Unlock()
+			var slot uint64
+			if _, err := fmt.Sscanf(r.URL.Path, "/eth/v2/beacon/blocks/%d", &slot); err != nil {
+				t.Errorf("%v: unexpected request: %v", tt.name, r.URL.Path)
+				return
+			}
+			if slot == 3 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			b := block(slot)
+			switch {
+			case tt.ssz && strings.Contains(accept, contentTypeSSZ):
+				data, err := b.MarshalSSZ()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tt.version {
+					w.Header().Set("Eth-Consensus-Version", "phase0")
+				}
+				w.Header().Set("Content-Type", contentTypeSSZ)
+				w.Write(data)
+			case tt.json:
+				data, err := json.Marshal(b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Header().Set("Content-Type", contentTypeJSON)
+				w.Write([]byte(fmt.Sprintf(`{"version":"phase0","data":%s}`, data)))
+			default:
+				w.WriteHeader(http.StatusNotAcceptable)
+			}
+		}))
+
+		for _, slot := range []uint64{1, 2} {
+			b, err := getSignedBeaconBlock(context.Background(), server.URL, slot)
+			if err != nil {
+				t.Errorf("%v: unexpected error: %v", tt.name, err)
+				continue
+			}
+			if b == nil || b.Phase0 == nil || uint64(b.Phase0.Message.Slot) != slot {
+				t.Errorf("%v: wrong block at slot %v: %+v", tt.name, slot, b)
+			}
+		}
+		if b, err := getSignedBeaconBlock(context.Background(), server.URL, 3); b != nil || err != nil {
+			t.Errorf("%v: expected missed slot, got %v, %v", tt.name, b, err)
+		}
+		server.Close()
+
+		if len(accepts) < len(tt.accepts) || strings.Join(accepts[:len(tt.accepts)], " ") != strings.Join(tt.accepts, " ") {
+			t.Errorf("%v: wrong accept headers: %v, expected to start with %v", tt.name, accepts, tt.accepts)
+		}
+	}
+}
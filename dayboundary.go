@@ -0,0 +1,200 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChainTiming holds the timing parameters of the chain that day boundaries are derived from.
+type ChainTiming struct {
+	Genesis        time.Time
+	SecondsPerSlot uint64
+	SlotsPerEpoch  uint64
+}
+
+// SecondsPerEpoch returns the duration of an epoch in seconds.
+func (t *ChainTiming) SecondsPerEpoch() uint64 {
+	return t.SecondsPerSlot * t.SlotsPerEpoch
+}
+
+// secondsPerDay is the length of a day of GenesisDayBoundary.
+const secondsPerDay = 24 * 3600
+
+// DayBoundary defines the slots of a day. A day starts at FirstSlot(day) and ends before FirstSlot(day+1), Day
+// returns the day that includes the given slot.
+type DayBoundary interface {
+	FirstSlot(day uint64, t *ChainTiming) uint64
+	Day(slot uint64, t *ChainTiming) uint64
+	// String returns the boundary in the format of ParseDayBoundary.
+	String() string
+}
+
+// GenesisDayBoundary starts the days at genesis and every 24 hours after it (225 epochs on mainnet), it is the
+// boundary of the eth.store methodology. A day starts with the epoch that includes its start, so the days of chains
+// whose epochs do not divide 24 hours consist of whole epochs and do not drift from the 24 hours.
+type GenesisDayBoundary struct{}
+
+func (GenesisDayBoundary) FirstSlot(day uint64, t *ChainTiming) uint64 {
+	return day * secondsPerDay / t.SecondsPerEpoch() * t.SlotsPerEpoch
+}
+
+func (GenesisDayBoundary) Day(slot uint64, t *ChainTiming) uint64 {
+	// the last day that starts at or before the epoch of the slot
+	return ((slot/t.SlotsPerEpoch+1)*t.SecondsPerEpoch() - 1) / secondsPerDay
+}
+
+func (GenesisDayBoundary) String() string {
+	return "genesis"
+}
+
+// UTCDayBoundary starts the days at midnight UTC, day 0 is the (partial) calendar day of genesis.
+type UTCDayBoundary struct{}
+
+func (UTCDayBoundary) FirstSlot(day uint64, t *ChainTiming) uint64 {
+	genesis := t.Genesis.UTC()
+	midnight := time.Date(genesis.Year(), genesis.Month(), genesis.Day(), 0, 0, 0, 0, time.UTC)
+	start := midnight.Add(time.Duration(day) * 24 * time.Hour)
+	if !start.After(genesis) {
+		return 0
+	}
+	// the first slot that starts at or after midnight
+	secondsPerSlot := int64(t.SecondsPerSlot)
+	return uint64((start.Unix() - genesis.Unix() + secondsPerSlot - 1) / secondsPerSlot)
+}
+
+func (UTCDayBoundary) Day(slot uint64, t *ChainTiming) uint64 {
+	genesis := t.Genesis.UTC()
+	midnight := time.Date(genesis.Year(), genesis.Month(), genesis.Day(), 0, 0, 0, 0, time.UTC)
+	slotTime := genesis.Add(time.Duration(slot*t.SecondsPerSlot) * time.Second)
+	return uint64(slotTime.Sub(midnight) / (24 * time.Hour))
+}
+
+func (UTCDayBoundary) String() string {
+	return "utc"
+}
+
+// EpochDayBoundary starts the days every Epochs epochs after the epoch Offset.
+type EpochDayBoundary struct {
+	Epochs uint64
+	Offset uint64
+}
+
+func (b EpochDayBoundary) FirstSlot(day uint64, t *ChainTiming) uint64 {
+	return (b.Offset + day*b.Epochs) * t.SlotsPerEpoch
+}
+
+func (b EpochDayBoundary) Day(slot uint64, t *ChainTiming) uint64 {
+	epoch := slot / t.SlotsPerEpoch
+	if epoch < b.Offset {
+		return 0
+	}
+	return (epoch - b.Offset) / b.Epochs
+}
+
+func (b EpochDayBoundary) String() string {
+	return fmt.Sprintf("epochs:%d:%d", b.Epochs, b.Offset)
+}
+
+// ParseDayBoundary parses a day boundary of the format "genesis", "utc" or "epochs:length[:offset]".
+func ParseDayBoundary(s string) (DayBoundary, error) {
+	switch s {
+	case "", "genesis":
+		return GenesisDayBoundary{}, nil
+	case "utc":
+		return UTCDayBoundary{}, nil
+	}
+	parts := strings.Split(s, ":")
+	if parts[0] != "epochs" || len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid day boundary: %v", s)
+	}
+	epochs, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || epochs == 0 {
+		return nil, fmt.Errorf("invalid number of epochs of day boundary: %v", s)
+	}
+	b := EpochDayBoundary{Epochs: epochs}
+	if len(parts) == 3 {
+		b.Offset, err = strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset of day boundary: %v", s)
+		}
+	}
+	return b, nil
+}
+
+// DaysOfDate returns the days of the given boundary that overlap the UTC calendar date of date, the date is taken from
+// its year, month and day regardless of its location. A date overlaps the days of its first and its last slot, dates
+// before genesis overlap none. Unix time and with it the slots of the chain skip leap seconds, every date has exactly
+// 86400 seconds.
+func DaysOfDate(boundary DayBoundary, t *ChainTiming, date time.Time) []uint64 {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	if !end.After(t.Genesis) {
+		return nil
+	}
+	slotDuration := time.Duration(t.SecondsPerSlot) * time.Second
+	firstSlot := uint64(0)
+	if start.After(t.Genesis) {
+		firstSlot = uint64(start.Sub(t.Genesis) / slotDuration)
+	}
+	// the slot of the last instant of the date
+	lastSlot := uint64((end.Sub(t.Genesis) - 1) / slotDuration)
+	first, last := boundary.Day(firstSlot, t), boundary.Day(lastSlot, t)
+	days := make([]uint64, 0, last-first+1)
+	for day := first; day <= last; day++ {
+		days = append(days, day)
+	}
+	return days
+}
+
+var dayBoundary DayBoundary = GenesisDayBoundary{}
+var dayBoundaryMu = &sync.Mutex{}
+
+// SetDayBoundary sets the boundary of the days calculated by Calculate, the default is GenesisDayBoundary.
+func SetDayBoundary(b DayBoundary) {
+	dayBoundaryMu.Lock()
+	defer dayBoundaryMu.Unlock()
+	if b == nil {
+		b = GenesisDayBoundary{}
+	}
+	dayBoundary = b
+}
+
+func GetDayBoundary() DayBoundary {
+	dayBoundaryMu.Lock()
+	defer dayBoundaryMu.Unlock()
+	return dayBoundary
+}
+
+// GetChainTiming returns the timing parameters of the chain of the consensus node at address.
+func GetChainTiming(ctx context.Context, address string) (*ChainTiming, error) {
+	client, err := newConsClient(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return getChainTiming(ctx, client)
+}
+
+// getChainTiming returns the timing parameters of the chain of the given client.
+func getChainTiming(ctx context.Context, client BeaconClient) (*ChainTiming, error) {
+	apiSpec, err := client.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secondsPerSlot, err := getSpecDuration(apiSpec, "SECONDS_PER_SLOT")
+	if err != nil {
+		return nil, err
+	}
+	slotsPerEpoch, err := getSpecUint64(apiSpec, "SLOTS_PER_EPOCH")
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := client.GenesisTime(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting genesisTime: %w", err)
+	}
+	return &ChainTiming{Genesis: genesis, SecondsPerSlot: uint64(secondsPerSlot.Seconds()), SlotsPerEpoch: slotsPerEpoch}, nil
+}
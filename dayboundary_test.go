@@ -0,0 +1,179 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDayBoundaries(t *testing.T) {
+	// mainnet
+	timing := &ChainTiming{Genesis: time.Unix(1606824023, 0), SecondsPerSlot: 12, SlotsPerEpoch: 32}
+	tests := []struct {
+		boundary  DayBoundary
+		day       uint64
+		firstSlot uint64
+	}{
+		{GenesisDayBoundary{}, 0, 0},
+		{GenesisDayBoundary{}, 497, 3578400},
+		{UTCDayBoundary{}, 0, 0},
+		// genesis was at 2020-12-01 12:00:23 UTC, the first slot of 2020-12-02 starts at 00:00:11
+		{UTCDayBoundary{}, 1, 3599},
+		{UTCDayBoundary{}, 2, 3599 + 7200},
+		{EpochDayBoundary{Epochs: 100, Offset: 10}, 0, 320},
+		{EpochDayBoundary{Epochs: 100, Offset: 10}, 2, 6720},
+	}
+	for _, tt := range tests {
+		if s := tt.boundary.FirstSlot(tt.day, timing); s != tt.firstSlot {
+			t.Errorf("wrong first slot of day %v with boundary %v: %v != %v", tt.day, tt.boundary, s, tt.firstSlot)
+		}
+		if d := tt.boundary.Day(tt.firstSlot, timing); d != tt.day {
+			t.Errorf("wrong day of slot %v with boundary %v: %v != %v", tt.firstSlot, tt.boundary, d, tt.day)
+		}
+		if tt.day > 0 {
+			if d := tt.boundary.Day(tt.firstSlot-1, timing); d != tt.day-1 {
+				t.Errorf("wrong day of slot %v with boundary %v: %v != %v", tt.firstSlot-1, tt.boundary, d, tt.day-1)
+			}
+		}
+	}
+
+	// the days of the minimal preset with 6 second slots have 1800 epochs of 8 slots, 24 hours are not a multiple of
+	// the 224 seconds of epochs of 7 second slots, so their days start with the epoch that includes the 24 hours
+	for _, tt := range []struct {
+		timing    *ChainTiming
+		day       uint64
+		firstSlot uint64
+	}{
+		{&ChainTiming{SecondsPerSlot: 6, SlotsPerEpoch: 8}, 1, 14400},
+		{&ChainTiming{SecondsPerSlot: 6, SlotsPerEpoch: 8}, 497, 497 * 14400},
+		{&ChainTiming{SecondsPerSlot: 7, SlotsPerEpoch: 32}, 1, 385 * 32},
+		{&ChainTiming{SecondsPerSlot: 7, SlotsPerEpoch: 32}, 2, 771 * 32},
+		{&ChainTiming{SecondsPerSlot: 7, SlotsPerEpoch: 32}, 1000, 385714 * 32},
+	} {
+		if s := (GenesisDayBoundary{}).FirstSlot(tt.day, tt.timing); s != tt.firstSlot {
+			t.Errorf("wrong first slot of day %v with %+v: %v != %v", tt.day, tt.timing, s, tt.firstSlot)
+		}
+		if d := (GenesisDayBoundary{}).Day(tt.firstSlot, tt.timing); d != tt.day {
+			t.Errorf("wrong day of slot %v with %+v: %v != %v", tt.firstSlot, tt.timing, d, tt.day)
+		}
+		if d := (GenesisDayBoundary{}).Day(tt.firstSlot-1, tt.timing); d != tt.day-1 {
+			t.Errorf("wrong day of slot %v with %+v: %v != %v", tt.firstSlot-1, tt.timing, d, tt.day-1)
+		}
+	}
+
+	for _, s := range []string{"genesis", "utc", "epochs:100:10"} {
+		b, err := ParseDayBoundary(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != s {
+			t.Errorf("wrong day boundary: %v != %v", b, s)
+		}
+	}
+	if b, err := ParseDayBoundary("epochs:225"); err != nil || b != (EpochDayBoundary{Epochs: 225}) {
+		t.Errorf("wrong day boundary without offset: %v, %v", b, err)
+	}
+	for _, s := range []string{"epochs", "epochs:0", "epochs:x", "epochs:1:2:3", "calendar"} {
+		if _, err := ParseDayBoundary(s); err == nil {
+			t.Errorf("expected error for day boundary %v", s)
+		}
+	}
+}
+
+func TestDaysOfDate(t *testing.T) {
+	mainnet := time.Unix(1606824023, 0)
+	midnight := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		genesis time.Time
+		date    time.Time
+		days    string
+	}{
+		{"before genesis", mainnet, time.Date(2020, 11, 30, 0, 0, 0, 0, time.UTC), "[]"},
+		// genesis was at 2020-12-01 12:00:23 UTC
+		{"date of genesis", mainnet, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), "[0]"},
+		{"date after genesis", mainnet, time.Date(2020, 12, 2, 0, 0, 0, 0, time.UTC), "[0 1]"},
+		{"time of the date is ignored", mainnet, time.Date(2020, 12, 2, 23, 59, 59, 0, time.UTC), "[0 1]"},
+		{"location of the date is ignored", mainnet, time.Date(2020, 12, 2, 23, 0, 0, 0, time.FixedZone("UTC-5", -5*3600)), "[0 1]"},
+		{"later date", mainnet, time.Date(2022, 4, 13, 0, 0, 0, 0, time.UTC), "[497 498]"},
+		// a leap second was inserted at 2016-12-31 23:59:60, time normalizes it to the next date
+		{"leap second", time.Date(2016, 12, 1, 12, 0, 0, 0, time.UTC), time.Date(2016, 12, 31, 23, 59, 60, 0, time.UTC), "[30 31]"},
+		{"genesis at midnight", midnight, midnight, "[0]"},
+		{"day after genesis at midnight", midnight, midnight.Add(24 * time.Hour), "[1]"},
+		{"date ending at genesis", midnight, midnight.Add(-time.Hour), "[]"},
+	}
+	for _, tt := range tests {
+		timing := &ChainTiming{Genesis: tt.genesis, SecondsPerSlot: 12, SlotsPerEpoch: 32}
+		if days := fmt.Sprintf("%v", DaysOfDate(GenesisDayBoundary{}, timing, tt.date)); days != tt.days {
+			t.Errorf("%v: wrong days: %v != %v", tt.name, days, tt.days)
+		}
+	}
+
+	// the days of 7 second slots start with the epoch that includes the 24 hours, day 1 starts 160 seconds before the
+	// second date and day 2 starts 96 seconds before the third date
+	timing := &ChainTiming{Genesis: midnight, SecondsPerSlot: 7, SlotsPerEpoch: 32}
+	if days := fmt.Sprintf("%v", DaysOfDate(GenesisDayBoundary{}, timing, midnight.Add(24*time.Hour))); days != "[1 2]" {
+		t.Errorf("wrong days of epoch-aligned boundary: %v != %v", days, "[1 2]")
+	}
+	// the date after the genesis of mainnet starts in epoch 112 and ends in epoch 337, the days with an offset of 100 epochs
+	// change at epoch 325
+	if days := fmt.Sprintf("%v", DaysOfDate(EpochDayBoundary{Epochs: 225, Offset: 100}, &ChainTiming{Genesis: mainnet, SecondsPerSlot: 12, SlotsPerEpoch: 32}, time.Date(2020, 12, 2, 0, 0, 0, 0, time.UTC))); days != "[0 1]" {
+		t.Errorf("wrong days of epoch boundary: %v != %v", days, "[0 1]")
+	}
+
+	// every second of every date of ten years is part of exactly the days of the date
+	for _, genesis := range []time.Time{mainnet, midnight, time.Unix(1606824023, 999999999), time.Unix(1616508000, 0)} {
+		timing := &ChainTiming{Genesis: genesis, SecondsPerSlot: 12, SlotsPerEpoch: 32}
+		var previous []uint64
+		date := time.Date(genesis.UTC().Year(), genesis.UTC().Month(), genesis.UTC().Day(), 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 3653; i++ {
+			days := DaysOfDate(GenesisDayBoundary{}, timing, date)
+			if len(days) == 0 || len(days) > 2 {
+				t.Fatalf("wrong days of %v with genesis %v: %v", date, genesis, days)
+			}
+			if previous != nil && days[0] != previous[len(previous)-1] && days[0] != previous[len(previous)-1]+1 {
+				t.Fatalf("days of %v with genesis %v do not follow %v: %v", date, genesis, previous, days)
+			}
+			for _, instant := range []time.Time{date, date.Add(12 * time.Hour), date.Add(24*time.Hour - time.Nanosecond)} {
+				if instant.Before(genesis) {
+					continue
+				}
+				day := GenesisDayBoundary{}.Day(uint64(instant.Sub(genesis)/(12*time.Second)), timing)
+				if day < days[0] || day > days[len(days)-1] {
+					t.Fatalf("day %v of %v is not part of the days of its date with genesis %v: %v", day, instant, genesis, days)
+				}
+			}
+			previous = days
+			date = date.AddDate(0, 0, 1)
+		}
+	}
+}
+
+func TestCalculateWithDayBoundary(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.Provenance.DayBoundary != "" {
+		t.Errorf("day boundary recorded for the default boundary: %v", day.Provenance.DayBoundary)
+	}
+
+	// 225 epochs starting at genesis are the slots of the default boundary
+	SetDayBoundary(EpochDayBoundary{Epochs: 225})
+	defer SetDayBoundary(nil)
+	epochDay, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if epochDay.Provenance.DayBoundary != "epochs:225:0" {
+		t.Errorf("wrong day boundary: %v", epochDay.Provenance.DayBoundary)
+	}
+	if !epochDay.StartEpoch.Equal(day.StartEpoch) || !epochDay.Apr.Equal(day.Apr) || epochDay.InputHash != day.InputHash {
+		t.Errorf("wrong day with epoch boundary: startEpoch: %v, apr: %v", epochDay.StartEpoch, epochDay.Apr)
+	}
+}
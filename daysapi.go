@@ -0,0 +1,76 @@
+package ethstore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DaysAPI serves the days of a ResultStore: /days lists all days in ascending order, optionally limited by the query
+// parameters from and to, /days/latest holds the latest day and /days/{day} a single day.
+type DaysAPI struct {
+	store ResultStore
+}
+
+// NewDaysAPI returns a days api that serves the days of the given store.
+func NewDaysAPI(store ResultStore) *DaysAPI {
+	return &DaysAPI{store: store}
+}
+
+func (a *DaysAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// the routes are /days, /days/latest and /days/{day}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "days" || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	if len(parts) == 1 {
+		from, to, err := parseDayRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		days, err := a.store.Range(r.Context(), from, to)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJson(w, days)
+		return
+	}
+	if parts[1] == "latest" {
+		latest, err := a.store.Latest(r.Context())
+		if errors.Is(err, ErrDayNotFound) {
+			http.Error(w, "no days calculated yet", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJson(w, latest)
+		return
+	}
+	day, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid day: %v", parts[1]), http.StatusBadRequest)
+		return
+	}
+	d, err := a.store.Get(r.Context(), day)
+	if errors.Is(err, ErrDayNotFound) {
+		http.Error(w, fmt.Sprintf("day %v has not been calculated", day), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJson(w, d)
+}
@@ -0,0 +1,105 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDaysAPI(t *testing.T) {
+	store := NewMemoryStore()
+	server := httptest.NewServer(NewDaysAPI(store))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/days/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("wrong status of latest day without days: %v", res.StatusCode)
+	}
+
+	for _, d := range []*Day{{Day: decimal.NewFromInt(12), Apr: decimal.NewFromFloat(0.1)}, {Day: decimal.NewFromInt(10)}, {Day: decimal.NewFromInt(11)}, {Day: decimal.NewFromInt(12), Apr: decimal.NewFromFloat(0.2)}} {
+		if err := store.Put(context.Background(), d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		days   []int64
+	}{
+		{"/days", http.StatusOK, []int64{10, 11, 12}},
+		{"/days?from=11", http.StatusOK, []int64{11, 12}},
+		{"/days?from=10&to=11", http.StatusOK, []int64{10, 11}},
+		{"/days?from=13", http.StatusOK, []int64{}},
+		{"/days?from=12&to=10", http.StatusBadRequest, nil},
+		{"/days/latest", http.StatusOK, []int64{12}},
+		{"/days/11", http.StatusOK, []int64{11}},
+		{"/days/9", http.StatusNotFound, nil},
+		{"/days/x", http.StatusBadRequest, nil},
+		{"/days/11/validators", http.StatusNotFound, nil},
+		{"/other", http.StatusNotFound, nil},
+	} {
+		res, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var days []*Day
+		if res.StatusCode == http.StatusOK {
+			var raw json.RawMessage
+			err = json.NewDecoder(res.Body).Decode(&raw)
+			if err == nil && len(raw) > 0 && raw[0] == '{' {
+				days = make([]*Day, 1)
+				err = json.Unmarshal(raw, &days[0])
+			} else if err == nil {
+				err = json.Unmarshal(raw, &days)
+			}
+		}
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != tc.status {
+			t.Errorf("%v: wrong status: %v != %v", tc.path, res.StatusCode, tc.status)
+			continue
+		}
+		if tc.days == nil {
+			continue
+		}
+		if len(days) != len(tc.days) {
+			t.Errorf("%v: wrong number of days: %v != %v", tc.path, len(days), len(tc.days))
+			continue
+		}
+		for i, d := range days {
+			if d.Day.IntPart() != tc.days[i] {
+				t.Errorf("%v: wrong day: %v != %v", tc.path, d.Day, tc.days[i])
+			}
+		}
+	}
+
+	res, err = http.Get(server.URL + "/days/12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var latest Day
+	err = json.NewDecoder(res.Body).Decode(&latest)
+	res.Body.Close()
+	if err != nil || !latest.Apr.Equal(decimal.NewFromFloat(0.2)) {
+		t.Errorf("expected the recalculated day: %+v, %v", latest, err)
+	}
+	res, err = http.Post(server.URL+"/days", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("wrong status of post: %v", res.StatusCode)
+	}
+}
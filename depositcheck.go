@@ -0,0 +1,164 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+)
+
+const depositEventAbi = `[{"anonymous":false,"inputs":[{"indexed":false,"name":"pubkey","type":"bytes"},{"indexed":false,"name":"withdrawal_credentials","type":"bytes"},{"indexed":false,"name":"amount","type":"bytes"},{"indexed":false,"name":"signature","type":"bytes"},{"indexed":false,"name":"index","type":"bytes"}],"name":"DepositEvent","type":"event"}]`
+
+// depositLogsChunkSize is the number of execution blocks requested per eth_getLogs call.
+const depositLogsChunkSize = 1000
+
+var depositCheck bool
+var depositCheckMu = sync.Mutex{}
+
+// SetDepositCheck enables reconciling the deposits included in the beacon blocks of a day against the DepositEvent logs
+// of the deposit contract.
+func SetDepositCheck(enabled bool) {
+	depositCheckMu.Lock()
+	defer depositCheckMu.Unlock()
+	depositCheck = enabled
+}
+
+func GetDepositCheck() bool {
+	depositCheckMu.Lock()
+	defer depositCheckMu.Unlock()
+	return depositCheck
+}
+
+// BlockDeposit is a deposit included in the beacon block at Slot.
+type BlockDeposit struct {
+	Slot uint64              `json:"slot"`
+	Data *phase0.DepositData `json:"data"`
+}
+
+// DepositMismatch describes a deposit included in a beacon block of the day without a matching DepositEvent log.
+type DepositMismatch struct {
+	Slot       uint64 `json:"slot"`
+	Pubkey     string `json:"pubkey"`
+	AmountGwei uint64 `json:"amountGwei"`
+	Reason     string `json:"reason"`
+}
+
+func depositKey(pubkey, withdrawalCredentials []byte, amountGwei uint64, signature []byte) string {
+	return fmt.Sprintf("%x:%x:%d:%x", pubkey, withdrawalCredentials, amountGwei, signature)
+}
+
+// getDepositEvents returns the number of DepositEvent logs of the deposit contract in the block range
+// [fromBlock,toBlock] by deposit.
+func getDepositEvents(ctx context.Context, elClient *gethRPC.Client, depositContract common.Address, fromBlock, toBlock uint64) (map[string]int, error) {
+	depositAbi, err := abi.JSON(strings.NewReader(depositEventAbi))
+	if err != nil {
+		return nil, err
+	}
+	event := depositAbi.Events["DepositEvent"]
+	events := map[string]int{}
+	for from := fromBlock; from <= toBlock; from += depositLogsChunkSize {
+		to := from + depositLogsChunkSize - 1
+		if to > toBlock {
+			to = toBlock
+		}
+		var logs []gethTypes.Log
+		err := elClient.CallContext(ctx, &logs, "eth_getLogs", map[string]interface{}{
+			"address":   depositContract,
+			"topics":    []common.Hash{event.ID},
+			"fromBlock": hexutil.EncodeUint64(from),
+			"toBlock":   hexutil.EncodeUint64(to),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting deposit logs of blocks %v-%v: %w", from, to, err)
+		}
+		for _, l := range logs {
+			values, err := event.Inputs.Unpack(l.Data)
+			if err != nil || len(values) != 5 {
+				return nil, fmt.Errorf("error decoding deposit log of tx %v: %v", l.TxHash, err)
+			}
+			pubkey, _ := values[0].([]byte)
+			withdrawalCredentials, _ := values[1].([]byte)
+			amount, _ := values[2].([]byte)
+			signature, _ := values[3].([]byte)
+			if len(amount) != 8 {
+				return nil, fmt.Errorf("invalid amount of deposit log of tx %v", l.TxHash)
+			}
+			// the amount is encoded little-endian
+			amountGwei := uint64(0)
+			for i := 7; i >= 0; i-- {
+				amountGwei = amountGwei<<8 | uint64(amount[i])
+			}
+			events[depositKey(pubkey, withdrawalCredentials, amountGwei, signature)]++
+		}
+	}
+	return events, nil
+}
+
+// checkDeposits reconciles the deposits of the beacon blocks against the DepositEvent logs of the deposit contract in
+// the execution blocks [fromBlock,toBlock], every deposit without a matching log is returned as mismatch.
+func checkDeposits(ctx context.Context, elClient *gethRPC.Client, depositContract common.Address, fromBlock, toBlock uint64, deposits []BlockDeposit) ([]DepositMismatch, error) {
+	if len(deposits) == 0 {
+		return nil, nil
+	}
+	events, err := getDepositEvents(ctx, elClient, depositContract, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	var mismatches []DepositMismatch
+	for _, d := range deposits {
+		key := depositKey(d.Data.PublicKey[:], d.Data.WithdrawalCredentials, uint64(d.Data.Amount), d.Data.Signature[:])
+		if events[key] > 0 {
+			events[key]--
+			continue
+		}
+		mismatches = append(mismatches, DepositMismatch{
+			Slot:       d.Slot,
+			Pubkey:     fmt.Sprintf("%#x", d.Data.PublicKey),
+			AmountGwei: uint64(d.Data.Amount),
+			Reason:     fmt.Sprintf("no matching DepositEvent in execution blocks %v-%v", fromBlock, toBlock),
+		})
+	}
+	return mismatches, nil
+}
+
+// getDepositMismatches checks the deposits of the beacon blocks against the deposit logs of the execution blocks of the
+// day and of the preceding blocks a deposit can have been made in before it is included: deposits are included after
+// ETH1_FOLLOW_DISTANCE blocks and the eth1 voting period, deposits that waited longer because of a backlog are reported
+// as mismatches.
+func getDepositMismatches(ctx context.Context, client BeaconClient, elClient *gethRPC.Client, apiSpec map[string]interface{}, firstExecutionBlock, lastExecutionBlock uint64, deposits []BlockDeposit) ([]DepositMismatch, error) {
+	followDistance, err := getSpecUint64(apiSpec, "ETH1_FOLLOW_DISTANCE")
+	if err != nil {
+		return nil, err
+	}
+	votingPeriodEpochs, err := getSpecUint64(apiSpec, "EPOCHS_PER_ETH1_VOTING_PERIOD")
+	if err != nil {
+		return nil, err
+	}
+	slotsPerEpoch, err := getSpecUint64(apiSpec, "SLOTS_PER_EPOCH")
+	if err != nil {
+		return nil, err
+	}
+	depositContract, err := client.DepositContract(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting deposit contract: %w", err)
+	}
+	lookback := followDistance + votingPeriodEpochs*slotsPerEpoch
+	fromBlock := uint64(0)
+	if firstExecutionBlock > lookback {
+		fromBlock = firstExecutionBlock - lookback
+	}
+	mismatches, err := checkDeposits(ctx, elClient, common.BytesToAddress(depositContract.Address), fromBlock, lastExecutionBlock, deposits)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(mismatches, func(i, j int) bool { return mismatches[i].Slot < mismatches[j].Slot })
+	return mismatches, nil
+}
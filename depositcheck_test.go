@@ -0,0 +1,122 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestCheckDeposits(t *testing.T) {
+	depositAbi, err := abi.JSON(strings.NewReader(depositEventAbi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := depositAbi.Events["DepositEvent"]
+	depositContract := common.HexToAddress("0x00000000219ab540356cbb839cbe05303d7705fa")
+
+	newDeposit := func(i byte, amountGwei phase0.Gwei) *phase0.DepositData {
+		d := &phase0.DepositData{WithdrawalCredentials: make([]byte, 32), Amount: amountGwei}
+		d.PublicKey[0] = i
+		d.WithdrawalCredentials[0] = 1
+		d.Signature[0] = i
+		return d
+	}
+	newLog := func(blockNumber uint64, d *phase0.DepositData) gethTypes.Log {
+		amount := make([]byte, 8)
+		for i := 0; i < 8; i++ {
+			amount[i] = byte(uint64(d.Amount) >> (8 * i))
+		}
+		data, err := event.Inputs.Pack(d.PublicKey[:], d.WithdrawalCredentials, amount, d.Signature[:], make([]byte, 8))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gethTypes.Log{Address: depositContract, Topics: []common.Hash{event.ID}, Data: data, BlockNumber: blockNumber}
+	}
+
+	included := newDeposit(1, 32e9)
+	twice := newDeposit(2, 1e9)
+	wrongAmount := newDeposit(3, 32e9)
+	logs := []gethTypes.Log{
+		newLog(100, included),
+		newLog(1500, twice),
+		newLog(1600, twice),
+		newLog(2100, newDeposit(3, 31e9)),
+		// outside of the checked range
+		newLog(3000, newDeposit(4, 32e9)),
+	}
+
+	requests := 0
+	elServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []struct {
+				Address   common.Address `json:"address"`
+				FromBlock hexutil.Uint64 `json:"fromBlock"`
+				ToBlock   hexutil.Uint64 `json:"toBlock"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Method != "eth_getLogs" || len(req.Params) != 1 {
+			t.Errorf("unexpected request: %s", body)
+			return
+		}
+		requests++
+		p := req.Params[0]
+		if p.Address != depositContract || uint64(p.ToBlock)-uint64(p.FromBlock) >= depositLogsChunkSize {
+			t.Errorf("wrong logs request: %s", body)
+		}
+		res := []gethTypes.Log{}
+		for _, l := range logs {
+			if l.BlockNumber >= uint64(p.FromBlock) && l.BlockNumber <= uint64(p.ToBlock) {
+				res = append(res, l)
+			}
+		}
+		resJson, _ := json.Marshal(res)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + string(resJson) + `}`))
+	}))
+	defer elServer.Close()
+	elClient, err := gethRPC.Dial(elServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mismatches, err := checkDeposits(context.Background(), elClient, depositContract, 0, 2500, []BlockDeposit{
+		{Slot: 10, Data: included},
+		{Slot: 11, Data: twice},
+		{Slot: 12, Data: twice},
+		{Slot: 13, Data: wrongAmount},
+		{Slot: 14, Data: newDeposit(4, 32e9)},
+		// the second deposit of the same data needs a second log
+		{Slot: 15, Data: included},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("wrong number of requests: %v != %v", requests, 3)
+	}
+	if len(mismatches) != 3 || mismatches[0].Slot != 13 || mismatches[1].Slot != 14 || mismatches[2].Slot != 15 {
+		t.Fatalf("wrong mismatches: %+v", mismatches)
+	}
+	if mismatches[0].AmountGwei != 32e9 || !strings.Contains(mismatches[0].Reason, "0-2500") {
+		t.Errorf("wrong mismatch: %+v", mismatches[0])
+	}
+
+	mismatches, err = checkDeposits(context.Background(), elClient, depositContract, 0, 2500, nil)
+	if err != nil || len(mismatches) != 0 {
+		t.Errorf("unexpected mismatches without deposits: %v (err: %v)", mismatches, err)
+	}
+}
@@ -0,0 +1,157 @@
+package ethstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// DiffTolerances are relative tolerances for the numeric fields of days: two values a and b are equal if
+// |a-b| <= tolerance * max(|a|,|b|). Fields are identified by their json path, e.g. "apr" or "censorship.blocks", a
+// tolerance for a field also applies to its nested fields.
+type DiffTolerances struct {
+	Default decimal.Decimal
+	Fields  map[string]decimal.Decimal
+}
+
+// FieldDiff is a field of a day that differs between two results, the value of a field that is missing in a result
+// is empty.
+type FieldDiff struct {
+	Day   decimal.Decimal `json:"day"`
+	Field string          `json:"field"`
+	A     string          `json:"a"`
+	B     string          `json:"b"`
+}
+
+func (t *DiffTolerances) tolerance(field string) decimal.Decimal {
+	if t == nil {
+		return decimal.Zero
+	}
+	for f := field; f != ""; {
+		if tol, exists := t.Fields[f]; exists {
+			return tol
+		}
+		i := strings.LastIndexAny(f, ".[")
+		if i < 0 {
+			break
+		}
+		f = f[:i]
+	}
+	return t.Default
+}
+
+// flattenDay returns the json values of the fields of the day by their json path.
+func flattenDay(d *Day) (map[string]string, error) {
+	dayJson, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(dayJson))
+	dec.UseNumber()
+	var v interface{}
+	err = dec.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	flatten("", v, fields)
+	return fields, nil
+}
+
+func flatten(path string, v interface{}, fields map[string]string) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, e := range vv {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			flatten(p, e, fields)
+		}
+	case []interface{}:
+		for i, e := range vv {
+			flatten(fmt.Sprintf("%s[%d]", path, i), e, fields)
+		}
+	case nil:
+	default:
+		fields[path] = fmt.Sprintf("%v", vv)
+	}
+}
+
+func valuesEqual(a, b string, tolerance decimal.Decimal) bool {
+	if a == b {
+		return true
+	}
+	da, errA := decimal.NewFromString(a)
+	db, errB := decimal.NewFromString(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	max := da.Abs()
+	if db.Abs().GreaterThan(max) {
+		max = db.Abs()
+	}
+	return da.Sub(db).Abs().LessThanOrEqual(tolerance.Mul(max))
+}
+
+// DiffDays compares the days of two results field by field and returns the fields that differ by more than the
+// tolerances sorted by day and field. Days that are only part of one result are reported with the field "day".
+func DiffDays(a, b []*Day, tolerances *DiffTolerances) ([]FieldDiff, error) {
+	daysA := map[string]*Day{}
+	daysB := map[string]*Day{}
+	keys := map[string]decimal.Decimal{}
+	for _, d := range a {
+		daysA[d.Day.String()] = d
+		keys[d.Day.String()] = d.Day
+	}
+	for _, d := range b {
+		daysB[d.Day.String()] = d
+		keys[d.Day.String()] = d.Day
+	}
+
+	diffs := []FieldDiff{}
+	for key, day := range keys {
+		dA, existsA := daysA[key]
+		dB, existsB := daysB[key]
+		if !existsA || !existsB {
+			diff := FieldDiff{Day: day, Field: "day"}
+			if existsA {
+				diff.A = key
+			} else {
+				diff.B = key
+			}
+			diffs = append(diffs, diff)
+			continue
+		}
+		fieldsA, err := flattenDay(dA)
+		if err != nil {
+			return nil, fmt.Errorf("error flattening day %v: %w", key, err)
+		}
+		fieldsB, err := flattenDay(dB)
+		if err != nil {
+			return nil, fmt.Errorf("error flattening day %v: %w", key, err)
+		}
+		for field, valueA := range fieldsA {
+			valueB, exists := fieldsB[field]
+			if !exists || !valuesEqual(valueA, valueB, tolerances.tolerance(field)) {
+				diffs = append(diffs, FieldDiff{Day: day, Field: field, A: valueA, B: valueB})
+			}
+		}
+		for field, valueB := range fieldsB {
+			if _, exists := fieldsA[field]; !exists {
+				diffs = append(diffs, FieldDiff{Day: day, Field: field, B: valueB})
+			}
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if !diffs[i].Day.Equal(diffs[j].Day) {
+			return diffs[i].Day.LessThan(diffs[j].Day)
+		}
+		return diffs[i].Field < diffs[j].Field
+	})
+	return diffs, nil
+}
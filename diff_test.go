@@ -0,0 +1,53 @@
+package ethstore
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDiffDays(t *testing.T) {
+	day := func(d int64, apr string, txFees int64) *Day {
+		return &Day{
+			Day:          decimal.NewFromInt(d),
+			Apr:          decimal.RequireFromString(apr),
+			TxFeesSumWei: decimal.NewFromInt(txFees),
+			Censorship:   &CensorshipStats{Blocks: decimal.NewFromInt(txFees)},
+		}
+	}
+	a := []*Day{day(1, "0.05", 100), day(2, "0.05", 100), day(3, "0.05", 100)}
+	b := []*Day{day(2, "0.0500001", 100), day(3, "0.051", 200), day(4, "0.05", 100)}
+	b[0].Censorship.Blocks = decimal.NewFromInt(150)
+
+	diffs, err := DiffDays(a, b, &DiffTolerances{
+		Default: decimal.RequireFromString("0.00001"),
+		Fields:  map[string]decimal.Decimal{"censorship": decimal.NewFromInt(1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []FieldDiff{
+		{Day: decimal.NewFromInt(1), Field: "day", A: "1"},
+		{Day: decimal.NewFromInt(3), Field: "apr", A: "0.05", B: "0.051"},
+		{Day: decimal.NewFromInt(3), Field: "txFeesSumWei", A: "100", B: "200"},
+		{Day: decimal.NewFromInt(4), Field: "day", B: "4"},
+	}
+	// day 2 only differs within the tolerances
+	if len(diffs) != len(expected) {
+		t.Fatalf("wrong diffs: %+v", diffs)
+	}
+	for i, d := range diffs {
+		e := expected[i]
+		if !d.Day.Equal(e.Day) || d.Field != e.Field || d.A != e.A || d.B != e.B {
+			t.Errorf("wrong diff %v: %+v != %+v", i, d, e)
+		}
+	}
+
+	diffs, err = DiffDays(a[1:2], b[:1], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 || diffs[0].Field != "apr" || diffs[1].Field != "censorship.blocks" {
+		t.Errorf("wrong diffs without tolerances: %+v", diffs)
+	}
+}
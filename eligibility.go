@@ -0,0 +1,133 @@
+package ethstore
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// EligibilityRule decides whether a validator is part of the validator set of a day, start is the validator at the
+// first slot of the day and end at the first slot of the next day, endEpoch is the first epoch of the next day.
+type EligibilityRule interface {
+	Eligible(start, end *v1.Validator, endEpoch phase0.Epoch) bool
+	// String returns the rule in the format of ParseEligibilityRules.
+	String() string
+}
+
+// ActiveAtStart requires the validator to be active at the start of the day.
+type ActiveAtStart struct{}
+
+func (ActiveAtStart) Eligible(start, end *v1.Validator, endEpoch phase0.Epoch) bool {
+	return start.Status.IsActive()
+}
+
+func (ActiveAtStart) String() string {
+	return "active-at-start"
+}
+
+// ActiveAtEnd requires the validator to be active until the end of the day.
+type ActiveAtEnd struct{}
+
+func (ActiveAtEnd) Eligible(start, end *v1.Validator, endEpoch phase0.Epoch) bool {
+	return end.Validator.ActivationEpoch < endEpoch && end.Validator.ExitEpoch >= endEpoch
+}
+
+func (ActiveAtEnd) String() string {
+	return "active-at-end"
+}
+
+// ActiveDuringDay requires the validator to be active in at least one epoch of the day, it includes validators that
+// are activated or exit during the day.
+type ActiveDuringDay struct{}
+
+func (ActiveDuringDay) Eligible(start, end *v1.Validator, endEpoch phase0.Epoch) bool {
+	return start.Status.IsActive() || end.Validator.ActivationEpoch < endEpoch && end.Validator.ExitEpoch >= endEpoch
+}
+
+func (ActiveDuringDay) String() string {
+	return "active-during-day"
+}
+
+// NotSlashed requires the validator not to be slashed until the end of the day.
+type NotSlashed struct{}
+
+func (NotSlashed) Eligible(start, end *v1.Validator, endEpoch phase0.Epoch) bool {
+	return !end.Validator.Slashed
+}
+
+func (NotSlashed) String() string {
+	return "not-slashed"
+}
+
+// EligibilityRules is the conjunction of its rules, a validator is eligible if it satisfies all of them.
+type EligibilityRules []EligibilityRule
+
+// DefaultEligibilityRules are the rules of the eth.store methodology, slashed validators are included as long as
+// they are active for the whole day.
+var DefaultEligibilityRules = EligibilityRules{ActiveAtStart{}, ActiveAtEnd{}}
+
+func (r EligibilityRules) Eligible(start, end *v1.Validator, endEpoch phase0.Epoch) bool {
+	for _, rule := range r {
+		if !rule.Eligible(start, end, endEpoch) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r EligibilityRules) String() string {
+	names := make([]string, len(r))
+	for i, rule := range r {
+		names[i] = rule.String()
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseEligibilityRules parses a comma-separated list of the rules "active-at-start", "active-at-end",
+// "active-during-day" and "not-slashed", an empty list returns DefaultEligibilityRules.
+func ParseEligibilityRules(s string) (EligibilityRules, error) {
+	if s == "" {
+		return DefaultEligibilityRules, nil
+	}
+	rules := EligibilityRules{}
+	for _, name := range strings.Split(s, ",") {
+		switch strings.TrimSpace(name) {
+		case "active-at-start":
+			rules = append(rules, ActiveAtStart{})
+		case "active-at-end":
+			rules = append(rules, ActiveAtEnd{})
+		case "active-during-day":
+			rules = append(rules, ActiveDuringDay{})
+		case "not-slashed":
+			rules = append(rules, NotSlashed{})
+		default:
+			return nil, fmt.Errorf("invalid eligibility rule: %v", name)
+		}
+	}
+	return rules, nil
+}
+
+var eligibilityRules = DefaultEligibilityRules
+var eligibilityRulesMu = &sync.Mutex{}
+
+// SetEligibilityRules sets the rules of the validator set of the days calculated by Calculate, the default is
+// DefaultEligibilityRules.
+//
+// Deprecated: use WithEligibilityRules.
+func SetEligibilityRules(r EligibilityRules) {
+	eligibilityRulesMu.Lock()
+	defer eligibilityRulesMu.Unlock()
+	if len(r) == 0 {
+		r = DefaultEligibilityRules
+	}
+	eligibilityRules = r
+}
+
+func GetEligibilityRules() EligibilityRules {
+	eligibilityRulesMu.Lock()
+	defer eligibilityRulesMu.Unlock()
+	return eligibilityRules
+}
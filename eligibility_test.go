@@ -0,0 +1,82 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestEligibilityRules(t *testing.T) {
+	validator := func(status v1.ValidatorState, activationEpoch, exitEpoch phase0.Epoch, slashed bool) *v1.Validator {
+		return &v1.Validator{
+			Status:    status,
+			Validator: &phase0.Validator{ActivationEpoch: activationEpoch, ExitEpoch: exitEpoch, Slashed: slashed},
+		}
+	}
+	endEpoch := phase0.Epoch(2475)
+	validators := map[string][2]*v1.Validator{
+		"ongoing":   {validator(v1.ValidatorStateActiveOngoing, 0, farFutureEpoch, false), validator(v1.ValidatorStateActiveOngoing, 0, farFutureEpoch, false)},
+		"exiting":   {validator(v1.ValidatorStateActiveExiting, 0, 2400, false), validator(v1.ValidatorStateExitedUnslashed, 0, 2400, false)},
+		"activated": {validator(v1.ValidatorStatePendingQueued, 2300, farFutureEpoch, false), validator(v1.ValidatorStateActiveOngoing, 2300, farFutureEpoch, false)},
+		"pending":   {validator(v1.ValidatorStatePendingQueued, 2500, farFutureEpoch, false), validator(v1.ValidatorStatePendingQueued, 2500, farFutureEpoch, false)},
+		"slashed":   {validator(v1.ValidatorStateActiveOngoing, 0, farFutureEpoch, false), validator(v1.ValidatorStateActiveSlashed, 0, 3000, true)},
+	}
+	tests := []struct {
+		rules    string
+		eligible []string
+	}{
+		{"", []string{"ongoing", "slashed"}},
+		{"active-at-start,active-at-end,not-slashed", []string{"ongoing"}},
+		{"active-during-day", []string{"ongoing", "exiting", "activated", "slashed"}},
+		{"active-at-end", []string{"ongoing", "activated", "slashed"}},
+	}
+	for _, tt := range tests {
+		rules, err := ParseEligibilityRules(tt.rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eligible := map[string]bool{}
+		for _, name := range tt.eligible {
+			eligible[name] = true
+		}
+		for name, v := range validators {
+			if rules.Eligible(v[0], v[1], endEpoch) != eligible[name] {
+				t.Errorf("%q: wrong eligibility of %v validator: %v", tt.rules, name, !eligible[name])
+			}
+		}
+	}
+
+	if rules, _ := ParseEligibilityRules(" not-slashed , active-at-start"); rules.String() != "not-slashed,active-at-start" {
+		t.Errorf("wrong rules: %v", rules)
+	}
+	if _, err := ParseEligibilityRules("active-at-start,unknown"); err == nil {
+		t.Errorf("expected error for unknown rule")
+	}
+}
+
+func TestEligibilityOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.Validators.IntPart() != 29 || day.Provenance.Eligibility != "" {
+		t.Fatalf("wrong day with default rules: validators: %v, eligibility: %q", day.Validators, day.Provenance.Eligibility)
+	}
+
+	// validators that exit or are activated during the day are included
+	SetEligibilityRules(EligibilityRules{ActiveDuringDay{}})
+	defer SetEligibilityRules(nil)
+	day, _, err = Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.Validators.IntPart() != 32 || day.Provenance.Eligibility != "active-during-day" {
+		t.Errorf("wrong day with active-during-day: validators: %v, eligibility: %q", day.Validators, day.Provenance.Eligibility)
+	}
+}
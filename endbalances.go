@@ -0,0 +1,117 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+var endBalances bool
+var endBalancesMu = &sync.Mutex{}
+
+// SetEndBalances requests the state at the end of a day from the validator_balances endpoint with the indices of the
+// validators selected at the start of the day instead of requesting all validators again, which halves the bandwidth
+// and the memory of the validator snapshots. The validators are selected by their record at the start of the day, so
+// validators whose exit is initiated and takes effect during the day are included, and the composition at the end of
+// the day is not reported. CalculateRange always requests all validators, as they are the validators at the start of
+// the next day.
+//
+// Deprecated: use WithEndBalances.
+func SetEndBalances(enabled bool) {
+	endBalancesMu.Lock()
+	defer endBalancesMu.Unlock()
+	endBalances = enabled
+}
+
+func GetEndBalances() bool {
+	endBalancesMu.Lock()
+	defer endBalancesMu.Unlock()
+	return endBalances
+}
+
+// getEndValidatorsFromBalances returns the validators of the start of the day that are eligible by their record at the
+// start with their balance at the given state, the balances are requested in batches of maxValidatorsChunkSize indices.
+// Validators without a balance in the response are left out.
+func getEndValidatorsFromBalances(ctx context.Context, address, stateID string, start map[phase0.ValidatorIndex]*v1.Validator, eligibility EligibilityRules, endEpoch phase0.Epoch) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+	indices := []phase0.ValidatorIndex{}
+	for index, val := range start {
+		if eligibility.Eligible(val, val, endEpoch) {
+			indices = append(indices, index)
+		}
+	}
+	end := make(map[phase0.ValidatorIndex]*v1.Validator, len(indices))
+	for i := 0; i < len(indices); i += maxValidatorsChunkSize {
+		batch := indices[i:]
+		if len(batch) > maxValidatorsChunkSize {
+			batch = batch[:maxValidatorsChunkSize]
+		}
+		balances, err := requestValidatorBalances(ctx, address, stateID, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range batch {
+			balance, exists := balances[index]
+			if !exists {
+				continue
+			}
+			val := *start[index]
+			val.Balance = balance
+			end[index] = &val
+		}
+	}
+	return end, nil
+}
+
+// requestValidatorBalances requests the balances of the validators with the given indices at the state, balances of
+// other validators are ignored in case the node ignores the filter.
+func requestValidatorBalances(ctx context.Context, address, stateID string, indices []phase0.ValidatorIndex) (map[phase0.ValidatorIndex]phase0.Gwei, error) {
+	requested := make(map[phase0.ValidatorIndex]bool, len(indices))
+	ids := make([]string, len(indices))
+	for i, index := range indices {
+		requested[index] = true
+		ids[i] = fmt.Sprintf("%d", index)
+	}
+	var res struct {
+		Data []*v1.ValidatorBalance `json:"data"`
+	}
+	_, err := getBeaconJson(ctx, address, fmt.Sprintf("/eth/v1/beacon/states/%s/validator_balances?id=%s", stateID, strings.Join(ids, ",")), &res)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validator balances for slot %v: %w", stateID, err)
+	}
+	balances := make(map[phase0.ValidatorIndex]phase0.Gwei, len(indices))
+	for _, b := range res.Data {
+		if b != nil && requested[b.Index] {
+			balances[b.Index] = b.Balance
+		}
+	}
+	return balances, nil
+}
+
+// requestValidatorsByIndex requests the validators with the given indices at the state, e.g. the validators of the exit
+// watch list when the state at the end of the day is only requested as balances.
+func requestValidatorsByIndex(ctx context.Context, address, stateID string, indices []phase0.ValidatorIndex) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+	requested := make(map[phase0.ValidatorIndex]bool, len(indices))
+	ids := make([]string, len(indices))
+	for i, index := range indices {
+		requested[index] = true
+		ids[i] = fmt.Sprintf("%d", index)
+	}
+	var res struct {
+		Data []*v1.Validator `json:"data"`
+	}
+	_, err := getBeaconJson(ctx, address, fmt.Sprintf("/eth/v1/beacon/states/%s/validators?id=%s", stateID, strings.Join(ids, ",")), &res)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validators for slot %v: %w", stateID, err)
+	}
+	vals := make(map[phase0.ValidatorIndex]*v1.Validator, len(indices))
+	for _, v := range res.Data {
+		if v != nil && requested[v.Index] {
+			vals[v.Index] = v
+		}
+	}
+	return vals, nil
+}
@@ -0,0 +1,63 @@
+package ethstore
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestEndBalances(t *testing.T) {
+	var endValidatorsRequests, endBalancesRequests int32
+	bnServer, elServer := newEthstoreMockServers(t, func(r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/beacon/states/79200/validators":
+			atomic.AddInt32(&endValidatorsRequests, 1)
+		case "/eth/v1/beacon/states/79200/validator_balances":
+			if !strings.Contains(r.URL.RawQuery, "id=") {
+				t.Errorf("validator balances requested without ids")
+			}
+			atomic.AddInt32(&endBalancesRequests, 1)
+		}
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, validatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetEndBalances(true)
+	defer SetEndBalances(false)
+	// the validators of the previous calculation are cached
+	validatorsCacheMu.Lock()
+	validatorsCache = nil
+	validatorsCacheMu.Unlock()
+	atomic.StoreInt32(&endValidatorsRequests, 0)
+	balancesDay, balancesValidatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&endValidatorsRequests) != 0 || atomic.LoadInt32(&endBalancesRequests) == 0 {
+		t.Errorf("wrong requests of the end state: %v validators, %v balances", endValidatorsRequests, endBalancesRequests)
+	}
+	if balancesDay.CompositionEnd != nil {
+		t.Errorf("composition at the end of the day reported without the validators at the end")
+	}
+	// validator 1 exits at the end of the day, which is only known from its record at the end
+	if _, exists := balancesValidatorDays[1]; !exists || len(balancesValidatorDays) != len(validatorDays)+1 {
+		t.Errorf("wrong validators: %v (%v with all validators)", len(balancesValidatorDays), len(validatorDays))
+	}
+	for index, d := range validatorDays {
+		if !balancesValidatorDays[index].EndBalanceGwei.Equal(d.EndBalanceGwei) {
+			t.Errorf("wrong end balance of validator %v: %v != %v", index, balancesValidatorDays[index].EndBalanceGwei, d.EndBalanceGwei)
+		}
+	}
+	if !balancesDay.Validators.Equal(day.Validators.Add(decimal.NewFromInt(1))) {
+		t.Errorf("wrong number of validators: %v != %v+1", balancesDay.Validators, day.Validators)
+	}
+}
@@ -0,0 +1,181 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/http"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog"
+)
+
+var endpointDiscovery bool
+var endpointDiscoveryMu = sync.Mutex{}
+
+// SetEndpointDiscovery enables resolving the host names of the node addresses to all of their A and AAAA records, the
+// resolved endpoints are treated as failover pool and the first one accepting a connection is used. Since the endpoints
+// are addressed by ip, tls certificates of https endpoints have to be valid for the ips.
+func SetEndpointDiscovery(enabled bool) {
+	endpointDiscoveryMu.Lock()
+	defer endpointDiscoveryMu.Unlock()
+	endpointDiscovery = enabled
+}
+
+func GetEndpointDiscovery() bool {
+	endpointDiscoveryMu.Lock()
+	defer endpointDiscoveryMu.Unlock()
+	return endpointDiscovery
+}
+
+// NormalizeAddress returns the address as url with the http scheme if it has none. IPv6 literals without brackets are
+// bracketed, a port can only be given for bracketed literals (e.g. "[::1]:5052") since "::1:5052" is a valid address
+// itself. File paths (e.g. of an ipc endpoint) are returned unchanged.
+func NormalizeAddress(address string) (string, error) {
+	if strings.HasPrefix(address, "/") || strings.HasPrefix(address, ".") {
+		return address, nil
+	}
+	if !strings.Contains(address, "://") {
+		host := address
+		rest := ""
+		if i := strings.Index(address, "/"); i >= 0 {
+			host, rest = address[:i], address[i:]
+		}
+		if ip := net.ParseIP(host); ip != nil && strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		address = "http://" + host + rest
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %v: %w", address, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid address %v: missing host", address)
+	}
+	return address, nil
+}
+
+// ResolveEndpoints returns the normalized address with its host name replaced by every address the host name resolves
+// to, in the order returned by the resolver. Addresses with an ip literal as host are returned as is.
+func ResolveEndpoints(ctx context.Context, address string) ([]string, error) {
+	address, err := NormalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return []string{address}, nil
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil {
+		return []string{address}, nil
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving %v: %w", host, err)
+	}
+	endpoints := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		e := *u
+		if port := u.Port(); port != "" {
+			e.Host = net.JoinHostPort(ip.IP.String(), port)
+		} else if ip.IP.To4() == nil {
+			e.Host = "[" + ip.IP.String() + "]"
+		} else {
+			e.Host = ip.IP.String()
+		}
+		endpoints = append(endpoints, e.String())
+	}
+	return endpoints, nil
+}
+
+// endpoints returns the endpoints to try for the given address, which may be several addresses separated by commas. With
+// endpoint discovery every address is resolved to its endpoints, otherwise the endpoints are the normalized addresses.
+func endpoints(ctx context.Context, address string) ([]string, error) {
+	all := []string{}
+	for _, a := range strings.Split(address, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		if GetEndpointDiscovery() {
+			resolved, err := ResolveEndpoints(ctx, a)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, resolved...)
+			continue
+		}
+		normalized, err := NormalizeAddress(a)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, normalized)
+	}
+	if len(all) == 0 {
+		return nil, fmt.Errorf("no address given")
+	}
+	return all, nil
+}
+
+// newConsClient connects to the consensus node at the given address. If the address consists of several endpoints,
+// the healthy endpoints are preferred in their order, the first one that accepts the connection is used and the others
+// are its failover pool for the requests of blocks and states.
+func newConsClient(ctx context.Context, address string) (*HTTPBeaconClient, error) {
+	candidates, err := endpoints(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) > 1 {
+		candidates = orderByHealth(ctx, candidates)
+	}
+	for i, endpoint := range candidates {
+		service, err := http.New(ctx, http.WithAddress(endpoint), http.WithTimeout(calculateOptionsOf(ctx).getConsTimeout()), http.WithLogLevel(zerolog.WarnLevel))
+		if err == nil {
+			client := service.(*http.Service)
+			// the endpoints that refused the connection are tried last
+			pool := append([]string{client.Address()}, candidates[i+1:]...)
+			setFailoverPool(client.Address(), append(pool, candidates[:i]...))
+			return &HTTPBeaconClient{Service: client}, nil
+		}
+		if i == len(candidates)-1 {
+			return nil, err
+		}
+		log.Printf("error connecting to consensus node %v, trying next endpoint: %v", endpoint, err)
+	}
+	return nil, fmt.Errorf("no endpoints for %v", address)
+}
+
+// newExecClient connects to the execution node at the given address, with endpoint discovery the first resolved
+// endpoint that responds to eth_chainId is used.
+func newExecClient(ctx context.Context, address string) (*gethRPC.Client, error) {
+	candidates, err := endpoints(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 1 {
+		return gethRPC.DialContext(ctx, candidates[0])
+	}
+	for i, endpoint := range candidates {
+		client, err := gethRPC.DialContext(ctx, endpoint)
+		if err == nil {
+			var chainId json.RawMessage
+			err = client.CallContext(ctx, &chainId, "eth_chainId")
+			if err == nil {
+				return client, nil
+			}
+			client.Close()
+		}
+		if i == len(candidates)-1 {
+			return nil, err
+		}
+		log.Printf("error connecting to execution node %v, trying next endpoint: %v", endpoint, err)
+	}
+	return nil, fmt.Errorf("no endpoints for %v", address)
+}
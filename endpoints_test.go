@@ -0,0 +1,67 @@
+package ethstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		address    string
+		normalized string
+	}{
+		{"http://localhost:4000", "http://localhost:4000"},
+		{"localhost:4000", "http://localhost:4000"},
+		{"https://node.example.com/api/", "https://node.example.com/api/"},
+		{"10.0.0.1:5052", "http://10.0.0.1:5052"},
+		{"[::1]:5052", "http://[::1]:5052"},
+		{"::1", "http://[::1]"},
+		{"fe80::1/eth", "http://[fe80::1]/eth"},
+		{"http://[2001:db8::1]:5052", "http://[2001:db8::1]:5052"},
+		{"/tmp/geth.ipc", "/tmp/geth.ipc"},
+	}
+	for _, tt := range tests {
+		n, err := NormalizeAddress(tt.address)
+		if err != nil {
+			t.Errorf("error normalizing %v: %v", tt.address, err)
+			continue
+		}
+		if n != tt.normalized {
+			t.Errorf("wrong normalized address of %v: %v != %v", tt.address, n, tt.normalized)
+		}
+	}
+	for _, address := range []string{"", "http://", "http://[::1"} {
+		if _, err := NormalizeAddress(address); err == nil {
+			t.Errorf("expected error for address %q", address)
+		}
+	}
+}
+
+func TestResolveEndpoints(t *testing.T) {
+	endpoints, err := ResolveEndpoints(context.Background(), "[::1]:5052")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 1 || endpoints[0] != "http://[::1]:5052" {
+		t.Errorf("wrong endpoints of ip literal: %v", endpoints)
+	}
+
+	endpoints, err = ResolveEndpoints(context.Background(), "http://localhost:5052/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) == 0 {
+		t.Fatalf("no endpoints for localhost")
+	}
+	for _, e := range endpoints {
+		if e != "http://127.0.0.1:5052/api" && e != "http://[::1]:5052/api" {
+			t.Errorf("wrong endpoint of localhost: %v", e)
+		}
+	}
+
+	_, err = ResolveEndpoints(context.Background(), "http://does-not-exist.invalid:5052")
+	if err == nil || !strings.Contains(err.Error(), "error resolving") {
+		t.Errorf("expected resolve error, got %v", err)
+	}
+}
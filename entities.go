@@ -0,0 +1,54 @@
+package ethstore
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EntityLabels maps withdrawal addresses and validator pubkeys (lowercase, 0x-prefixed) to the label of the entity
+// that operates the validators, e.g. from public staking-entity datasets.
+type EntityLabels map[string]string
+
+// ReadEntityLabels reads entity labels from csv with the columns address and label, the address is a withdrawal
+// address or a validator pubkey. A header line is skipped.
+func ReadEntityLabels(r io.Reader) (EntityLabels, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	labels := EntityLabels{}
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("invalid entity label in line %v: expected address and label", line)
+		}
+		address := strings.ToLower(strings.TrimSpace(record[0]))
+		if !strings.HasPrefix(address, "0x") {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("invalid address in line %v: %v", line, record[0])
+		}
+		labels[address] = strings.TrimSpace(record[1])
+	}
+	return labels, nil
+}
+
+// LabelEntities sets the entity of the per-validator days returned by Calculate, a label of the pubkey of a validator
+// takes precedence over a label of its withdrawal address.
+func LabelEntities(validatorDays map[uint64]*Day, labels EntityLabels) {
+	for _, d := range validatorDays {
+		if label, exists := labels[strings.ToLower(d.Pubkey)]; exists {
+			d.Entity = label
+		} else if label, exists := labels[WithdrawalAddress(d.WithdrawalCredentials)]; exists {
+			d.Entity = label
+		}
+	}
+}
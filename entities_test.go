@@ -0,0 +1,56 @@
+package ethstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEntityLabels(t *testing.T) {
+	labels, err := ReadEntityLabels(strings.NewReader(`address,label
+0xA0b86991c6218b36c1d19d4a2e9eb0ce3606eb48, Pool A
+0x8000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000ff,Solo
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 || labels["0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"] != "Pool A" {
+		t.Fatalf("wrong labels: %v", labels)
+	}
+
+	validatorDays := map[uint64]*Day{
+		0: {WithdrawalCredentials: "0x010000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		// the label of the pubkey takes precedence
+		1: {WithdrawalCredentials: "0x010000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Pubkey: "0x8000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000ff"},
+		2: {WithdrawalCredentials: "0x0100000000000000000000000000000000000000000000000000000000000001"},
+	}
+	LabelEntities(validatorDays, labels)
+	for index, entity := range map[uint64]string{0: "Pool A", 1: "Solo", 2: ""} {
+		if validatorDays[index].Entity != entity {
+			t.Errorf("wrong entity of validator %v: %q != %q", index, validatorDays[index].Entity, entity)
+		}
+	}
+
+	if _, err := ReadEntityLabels(strings.NewReader("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48,A\nnot-an-address,B\n")); err == nil {
+		t.Errorf("expected error for invalid address")
+	}
+	if _, err := ReadEntityLabels(strings.NewReader("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48\n")); err == nil {
+		t.Errorf("expected error for missing label")
+	}
+}
+
+func TestPubkeyOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	_, validatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for index, d := range validatorDays {
+		if len(d.Pubkey) != 98 {
+			t.Errorf("wrong pubkey of validator %v: %q", index, d.Pubkey)
+		}
+	}
+}
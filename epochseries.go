@@ -0,0 +1,95 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+	"golang.org/x/sync/errgroup"
+)
+
+var epochSeries bool
+var epochSeriesMu = sync.Mutex{}
+
+// SetEpochSeries enables the per-epoch series of the consensus rewards of the eth.store validators, which requires the
+// balances of all validators at every epoch of the day.
+func SetEpochSeries(enabled bool) {
+	epochSeriesMu.Lock()
+	defer epochSeriesMu.Unlock()
+	epochSeries = enabled
+}
+
+func GetEpochSeries() bool {
+	epochSeriesMu.Lock()
+	defer epochSeriesMu.Unlock()
+	return epochSeries
+}
+
+// EpochRewards holds the consensus rewards of the eth.store validators during an epoch of the day.
+type EpochRewards struct {
+	Epoch                decimal.Decimal `json:"epoch"`
+	ConsensusRewardsGwei decimal.Decimal `json:"consensusRewardsGwei"`
+	ConsensusRewardsWei  decimal.Decimal `json:"consensusRewardsWei"`
+	Apr                  decimal.Decimal `json:"apr"`
+}
+
+// getEpochSeries samples the balances of the eth.store validators at the first slot of every epoch of the day, the
+// consensus rewards of an epoch are the difference of the balances at the start of the epoch and of the next epoch
+// minus the deposits and plus the withdrawals included during the epoch.
+func getEpochSeries(ctx context.Context, client BeaconClient, validatorsByIndex map[phase0.ValidatorIndex]*Validator, epochDepositsGwei, epochWithdrawalsGwei map[uint64]phase0.Gwei, firstSlot, endSlot, slotsPerEpoch uint64, concurrency int) ([]EpochRewards, error) {
+	epochs := (endSlot - firstSlot) / slotsPerEpoch
+	balancesGwei := make([]int64, epochs+1)
+	var effectiveBalanceGwei int64
+	for _, v := range validatorsByIndex {
+		balancesGwei[0] += int64(v.StartBalanceGwei)
+		balancesGwei[epochs] += int64(v.EndBalanceGwei)
+		effectiveBalanceGwei += int64(v.EffectiveBalanceGwei)
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for k := uint64(1); k < epochs; k++ {
+		k := k
+		g.Go(func() error {
+			slot := firstSlot + k*slotsPerEpoch
+			release, err := acquireRequest(gCtx)
+			if err != nil {
+				return err
+			}
+			balances, err := client.ValidatorBalances(gCtx, fmt.Sprintf("%d", slot), nil)
+			release()
+			if err != nil {
+				return fmt.Errorf("error getting validator balances at slot %v: %w", slot, err)
+			}
+			sum := int64(0)
+			for index := range validatorsByIndex {
+				sum += int64(balances[index])
+			}
+			// every goroutine writes a different element
+			balancesGwei[k] = sum
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	series := make([]EpochRewards, epochs)
+	firstEpoch := firstSlot / slotsPerEpoch
+	for k := uint64(0); k < epochs; k++ {
+		rewardsGwei := decimal.NewFromInt(balancesGwei[k+1] - balancesGwei[k] - int64(epochDepositsGwei[firstEpoch+k]) + int64(epochWithdrawalsGwei[firstEpoch+k]))
+		apr := decimal.Zero
+		if effectiveBalanceGwei > 0 {
+			apr = decimal.NewFromInt(365 * int64(epochs)).Mul(rewardsGwei).Div(decimal.NewFromInt(effectiveBalanceGwei))
+		}
+		series[k] = EpochRewards{
+			Epoch:                decimal.NewFromInt(int64(firstEpoch + k)),
+			ConsensusRewardsGwei: rewardsGwei,
+			ConsensusRewardsWei:  gweiToWei(rewardsGwei),
+			Apr:                  apr,
+		}
+	}
+	return series, nil
+}
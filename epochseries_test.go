@@ -0,0 +1,43 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestEpochSeries(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	SetEpochSeries(true)
+	defer SetEpochSeries(false)
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(day.EpochSeries) != 225 {
+		t.Fatalf("wrong number of epochs: %v != %v", len(day.EpochSeries), 225)
+	}
+
+	sum := decimal.Zero
+	for i, e := range day.EpochSeries {
+		if !e.Epoch.Equal(decimal.NewFromInt(int64(2250 + i))) {
+			t.Errorf("wrong epoch: %v != %v", e.Epoch, 2250+i)
+		}
+		// the deposit of validator 4 must not be counted as reward
+		if e.ConsensusRewardsGwei.IsNegative() || e.ConsensusRewardsGwei.GreaterThan(decimal.NewFromInt(1e6)) {
+			t.Errorf("wrong rewards of epoch %v: %v", e.Epoch, e.ConsensusRewardsGwei)
+		}
+		sum = sum.Add(e.ConsensusRewardsGwei)
+	}
+	if !sum.Equal(day.ConsensusRewardsGwei) {
+		t.Errorf("sum of epoch rewards does not match rewards of the day: %v != %v", sum, day.ConsensusRewardsGwei)
+	}
+	expectedApr := decimal.NewFromInt(365 * 225).Mul(day.EpochSeries[1].ConsensusRewardsGwei).Div(day.EffectiveBalanceGwei)
+	if !day.EpochSeries[1].Apr.Equal(expectedApr) {
+		t.Errorf("wrong apr of epoch: %v != %v", day.EpochSeries[1].Apr, expectedApr)
+	}
+}
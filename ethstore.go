@@ -4,28 +4,29 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"math/big"
+	"runtime/debug"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
-	"github.com/attestantio/go-eth2-client/http"
 	"github.com/attestantio/go-eth2-client/spec"
-	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/altair"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	gethTypes "github.com/ethereum/go-ethereum/core/types"
 	gethRPC "github.com/ethereum/go-ethereum/rpc"
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/prysmaticlabs/prysm/v3/beacon-chain/core/signing"
 	"github.com/prysmaticlabs/prysm/v3/contracts/deposit"
 	ethpb "github.com/prysmaticlabs/prysm/v3/proto/prysm/v1alpha1"
-	"github.com/rs/zerolog"
 	"github.com/shopspring/decimal"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
 var debugLevel = uint64(0)
@@ -35,30 +36,98 @@ var consTimeout = time.Second * 120
 var consTimeoutMu = sync.Mutex{}
 var validatorsCache *lru.Cache
 var validatorsCacheMu = sync.Mutex{}
+var calculateGroup singleflight.Group
+
+// compoundingWithdrawalPrefix is the first byte of the withdrawal credentials of compounding validators (EIP-7251),
+// their rewards are not skimmed above 32 eth but increase their effective balance up to 2048 eth.
+const compoundingWithdrawalPrefix = 0x02
 
 type Day struct {
-	Day                  decimal.Decimal `json:"day"`
-	DayTime              time.Time       `json:"dayTime"`
-	Apr                  decimal.Decimal `json:"apr"`
-	Validators           decimal.Decimal `json:"validators"`
-	StartEpoch           decimal.Decimal `json:"startEpoch"`
-	EffectiveBalanceGwei decimal.Decimal `json:"effectiveBalanceGwei"`
-	StartBalanceGwei     decimal.Decimal `json:"startBalanceGwei"`
-	EndBalanceGwei       decimal.Decimal `json:"endBalanceGwei"`
-	DepositsSumGwei      decimal.Decimal `json:"depositsSumGwei"`
-	ConsensusRewardsGwei decimal.Decimal `json:"consensusRewardsGwei"`
-	TxFeesSumWei         decimal.Decimal `json:"txFeesSumWei"`
-	TotalRewardsWei      decimal.Decimal `json:"totalRewardsWei"`
+	Day                      decimal.Decimal        `json:"day"`
+	DayTime                  time.Time              `json:"dayTime"`
+	Apr                      decimal.Decimal        `json:"apr"`
+	AprBps                   decimal.Decimal        `json:"aprBps"`
+	AprPercent               decimal.Decimal        `json:"aprPercent"`
+	Validators               decimal.Decimal        `json:"validators"`
+	StartEpoch               decimal.Decimal        `json:"startEpoch"`
+	Window                   *DayWindow             `json:"window,omitempty"`
+	EffectiveBalanceGwei     decimal.Decimal        `json:"effectiveBalanceGwei"`
+	StartBalanceGwei         decimal.Decimal        `json:"startBalanceGwei"`
+	EndBalanceGwei           decimal.Decimal        `json:"endBalanceGwei"`
+	DepositsSumGwei          decimal.Decimal        `json:"depositsSumGwei"`
+	WithdrawalsSumGwei       decimal.Decimal        `json:"withdrawalsSumGwei"`
+	ConsensusRewardsGwei     decimal.Decimal        `json:"consensusRewardsGwei"`
+	ConsensusRewardsWei      decimal.Decimal        `json:"consensusRewardsWei"`
+	TxFeesSumWei             decimal.Decimal        `json:"txFeesSumWei"`
+	BurnedFeesWei            decimal.Decimal        `json:"burnedFeesWei"`
+	NetIssuanceWei           *decimal.Decimal       `json:"netIssuanceWei,omitempty"`
+	GasStats                 *GasStats              `json:"gasStats,omitempty"`
+	Orphans                  *OrphanStats           `json:"orphans,omitempty"`
+	TotalRewardsWei          decimal.Decimal        `json:"totalRewardsWei"`
+	ProposalsExpected        decimal.Decimal        `json:"proposalsExpected"`
+	ProposalsActual          decimal.Decimal        `json:"proposalsActual"`
+	ProposerLuck             decimal.Decimal        `json:"proposerLuck"`
+	ConsensusBaselineGwei    decimal.Decimal        `json:"consensusBaselineGwei"`
+	ConsensusBaselineRatio   decimal.Decimal        `json:"consensusBaselineRatio"`
+	AprLowerBound            decimal.Decimal        `json:"aprLowerBound"`
+	AprUpperBound            decimal.Decimal        `json:"aprUpperBound"`
+	ValidatorsCompounding    decimal.Decimal        `json:"validatorsCompounding"`
+	AprCompounding           *decimal.Decimal       `json:"aprCompounding,omitempty"`
+	AprNonCompounding        decimal.Decimal        `json:"aprNonCompounding"`
+	AprGross                 *decimal.Decimal       `json:"aprGross,omitempty"`
+	AprNet                   *decimal.Decimal       `json:"aprNet,omitempty"`
+	AprContribution          *decimal.Decimal       `json:"aprContribution,omitempty"`
+	CommissionWei            *decimal.Decimal       `json:"commissionWei,omitempty"`
+	MevRewardsWei            *decimal.Decimal       `json:"mevRewardsWei,omitempty"`
+	ProposalTxFeesWei        []decimal.Decimal      `json:"-"`
+	MissedSlots              decimal.Decimal        `json:"missedSlots"`
+	SyncParticipation        decimal.Decimal        `json:"syncParticipation"`
+	CompositionStart         *ValidatorComposition  `json:"compositionStart,omitempty"`
+	CompositionEnd           *ValidatorComposition  `json:"compositionEnd,omitempty"`
+	ValidatorExits           []ValidatorExit        `json:"validatorExits,omitempty"`
+	AttestationEffectiveness *decimal.Decimal       `json:"attestationEffectiveness,omitempty"`
+	InactivityLeak           bool                   `json:"inactivityLeak"`
+	InactivityLeakEpochs     decimal.Decimal        `json:"inactivityLeakEpochs"`
+	InactivityPenaltiesGwei  *decimal.Decimal       `json:"inactivityPenaltiesGwei,omitempty"`
+	BlobDiscrepancies        []BlobDiscrepancy      `json:"blobDiscrepancies,omitempty"`
+	FeeRecipientMismatches   []FeeRecipientMismatch `json:"feeRecipientMismatches,omitempty"`
+	Censorship               *CensorshipStats       `json:"censorship,omitempty"`
+	TxDecodeErrors           *TxDecodeErrors        `json:"txDecodeErrors,omitempty"`
+	TxFeeBreakdown           *TxFeeBreakdown        `json:"txFeeBreakdown,omitempty"`
+	Alerts                   []Alert                `json:"alerts,omitempty"`
+	EpochSeries              []EpochRewards         `json:"epochSeries,omitempty"`
+	DepositMismatches        []DepositMismatch      `json:"depositMismatches,omitempty"`
+	ActivationQueue          *ActivationQueue       `json:"activationQueue,omitempty"`
+	Provenance               *Provenance            `json:"provenance,omitempty"`
+	PreviousMethodology      *Day                   `json:"previousMethodology,omitempty"`
+	StartStateRoot           string                 `json:"startStateRoot,omitempty"`
+	EndStateRoot             string                 `json:"endStateRoot,omitempty"`
+	InputHash                string                 `json:"inputHash,omitempty"`
+	Revision                 int                    `json:"revision,omitempty"`
+	Supersedes               *int                   `json:"supersedes,omitempty"`
+	Superseded               bool                   `json:"superseded,omitempty"`
+	Correction               bool                   `json:"correction,omitempty"`
+	Unverified               bool                   `json:"unverified,omitempty"`
+	Anomalous                bool                   `json:"anomalous,omitempty"`
+	WithdrawalCredentials    string                 `json:"withdrawalCredentials,omitempty"`
+	WithdrawalGroups         []WithdrawalGroup      `json:"withdrawalGroups,omitempty"`
+	Pubkey                   string                 `json:"pubkey,omitempty"`
+	Entity                   string                 `json:"entity,omitempty"`
 }
 
 type Validator struct {
-	Index                phase0.ValidatorIndex
-	Pubkey               phase0.BLSPubKey
-	EffectiveBalanceGwei phase0.Gwei
-	StartBalanceGwei     phase0.Gwei
-	EndBalanceGwei       phase0.Gwei
-	DepositsSumGwei      phase0.Gwei
-	TxFeesSumWei         *big.Int
+	Index                 phase0.ValidatorIndex
+	Pubkey                phase0.BLSPubKey
+	EffectiveBalanceGwei  phase0.Gwei
+	StartBalanceGwei      phase0.Gwei
+	EndBalanceGwei        phase0.Gwei
+	DepositsSumGwei       phase0.Gwei
+	WithdrawalsSumGwei    phase0.Gwei
+	TxFeesSumWei          *big.Int
+	MevRewardsWei         *big.Int
+	Proposals             uint64
+	Compounding           bool
+	WithdrawalCredentials []byte
 }
 
 func SetDebugLevel(lvl uint64) {
@@ -93,106 +162,276 @@ func GetExecTimeout() time.Duration {
 	return execTimeout
 }
 
+// GetFinalizedDay returns the most recent day whose end epoch is finalized, i.e. the latest day whose results can not
+// change anymore.
 func GetFinalizedDay(ctx context.Context, address string) (uint64, error) {
-	service, err := http.New(ctx, http.WithAddress(address), http.WithTimeout(GetConsTimeout()), http.WithLogLevel(zerolog.WarnLevel))
+	client, err := newConsClient(ctx, address)
 	if err != nil {
 		return 0, err
 	}
-	client := service.(*http.Service)
-	apiSpec, err := client.Spec(ctx)
+	timing, err := getChainTiming(ctx, client)
 	if err != nil {
 		return 0, err
 	}
-	secondsPerSlotIf, exists := apiSpec["SECONDS_PER_SLOT"]
-	if !exists {
-		return 0, fmt.Errorf("undefined SECONDS_PER_SLOT in spec")
-	}
-	secondsPerSlotDur, ok := secondsPerSlotIf.(time.Duration)
-	if !ok {
-		return 0, fmt.Errorf("invalid format of SECONDS_PER_SLOT in spec")
-	}
-	secondsPerSlot := uint64(secondsPerSlotDur.Seconds())
-	slotsPerDay := 3600 * 24 / secondsPerSlot
 
-	h, err := client.BeaconBlockHeader(ctx, "finalized")
-	if err != nil {
-		return 0, err
-	}
-
-	day := uint64(h.Header.Message.Slot)/slotsPerDay - 1
-	return day, nil
+	day, _, err := latestFinalizedDay(ctx, client, timing, GetDayBoundary())
+	return day, err
 }
 
 func GetHeadDay(ctx context.Context, address string) (uint64, error) {
-	service, err := http.New(ctx, http.WithAddress(address), http.WithTimeout(GetConsTimeout()), http.WithLogLevel(zerolog.WarnLevel))
+	client, err := newConsClient(ctx, address)
 	if err != nil {
 		return 0, err
 	}
-	client := service.(*http.Service)
-	apiSpec, err := client.Spec(ctx)
+	timing, err := getChainTiming(ctx, client)
 	if err != nil {
 		return 0, err
 	}
-	secondsPerSlotIf, exists := apiSpec["SECONDS_PER_SLOT"]
-	if !exists {
-		return 0, fmt.Errorf("undefined SECONDS_PER_SLOT in spec")
-	}
-	secondsPerSlotDur, ok := secondsPerSlotIf.(time.Duration)
-	if !ok {
-		return 0, fmt.Errorf("invalid format of SECONDS_PER_SLOT in spec")
-	}
-	secondsPerSlot := uint64(secondsPerSlotDur.Seconds())
-	slotsPerDay := 3600 * 24 / secondsPerSlot
 
 	h, err := client.BeaconBlockHeader(ctx, "finalized")
 	if err != nil {
 		return 0, err
 	}
 
-	day := uint64(h.Header.Message.Slot) / slotsPerDay
+	day := GetDayBoundary().Day(uint64(h.Header.Message.Slot), timing)
 	return day, nil
 }
 
-func GetValidators(ctx context.Context, client *http.Service, stateID string) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+func GetValidators(ctx context.Context, client BeaconClient, stateID string) (map[phase0.ValidatorIndex]*v1.Validator, error) {
 	validatorsCacheMu.Lock()
 	defer validatorsCacheMu.Unlock()
+	if validatorsCache == nil {
+		c, err := lru.New(2)
+		if err != nil {
+			return nil, err
+		}
+		validatorsCache = c
+	}
 	key := fmt.Sprintf("%s:%s", client.Address(), stateID)
 	val, found := validatorsCache.Get(key)
 	if found {
 		return val.(map[phase0.ValidatorIndex]*v1.Validator), nil
 	}
-	vals, err := client.Validators(ctx, stateID, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error getting validators for slot %v: %w", stateID, err)
+	cache := getResponseCache(client.Address())
+	vals, cached := cache.validators(stateID)
+	if !cached {
+		var err error
+		vals, err = requestValidators(ctx, client, stateID)
+		if err != nil {
+			return nil, err
+		}
+		cache.setValidators(stateID, vals)
+	}
+	if !calculateOptionsOf(ctx).getLowMemory() {
+		validatorsCache.Add(key, vals)
 	}
-	validatorsCache.Add(key, vals)
 	return vals, nil
 }
 
+func getSpecUint64(apiSpec map[string]interface{}, key string) (uint64, error) {
+	valIf, exists := apiSpec[key]
+	if !exists {
+		return 0, fmt.Errorf("undefined %s in spec", key)
+	}
+	val, ok := valIf.(uint64)
+	if !ok {
+		return 0, fmt.Errorf("invalid format of %s in spec", key)
+	}
+	return val, nil
+}
+
+func getSpecDuration(apiSpec map[string]interface{}, key string) (time.Duration, error) {
+	valIf, exists := apiSpec[key]
+	if !exists {
+		return 0, fmt.Errorf("undefined %s in spec", key)
+	}
+	val, ok := valIf.(time.Duration)
+	if !ok {
+		return 0, fmt.Errorf("invalid format of %s in spec", key)
+	}
+	return val, nil
+}
+
+type calculateResult struct {
+	day          *Day
+	perValidator map[uint64]*Day
+}
+
+// Calculate calculates the eth.store of the given day and the results of every eth.store validator keyed by its
+// index with the package settings, see CalculateWithOptions to configure a single calculation. Concurrent calls for
+// the same day and nodes share one calculation and its results.
 func Calculate(ctx context.Context, bnAddress, elAddress, dayStr string, concurrency int) (*Day, map[uint64]*Day, error) {
-	if validatorsCache == nil {
-		c, err := lru.New(2)
+	return CalculateWithOptions(ctx, bnAddress, elAddress, dayStr, WithConcurrency(concurrency))
+}
+
+// calculateCoalesced calculates the day with the options, concurrent calls for the same day and nodes are coalesced
+// into one calculation if their options lead to the same result. The shared calculation runs until its result is
+// returned or every caller stopped waiting for it, a caller whose context is done returns without affecting the
+// others. The last caller to stop waiting cancels the calculation and gets its partial result. Every caller of a
+// shared calculation gets a deep copy of the results. Calculations with exclusive options are not coalesced.
+//
+// Days within the methodology transition are calculated with the previous methodology version as well, unless the
+// options set the methodology version.
+func calculateCoalesced(ctx context.Context, bnAddress, elAddress, dayStr string, options *calculateOptions) (*Day, map[uint64]*Day, error) {
+	calculateDay := func(ctx context.Context) (*Day, map[uint64]*Day, error) {
+		var session *calculateSession
+		if options.client != nil {
+			session = &calculateSession{client: options.client}
+			bnAddress = ""
+		}
+		methodology := MethodologyVersion
+		if options.methodology != 0 {
+			methodology = options.methodology
+		}
+		day, perValidator, err := calculate(ctx, bnAddress, elAddress, dayStr, options.concurrency, nil, methodology, session, options)
 		if err != nil {
 			return nil, nil, err
 		}
-		validatorsCache = c
+		if t := GetMethodologyTransition(); options.methodology == 0 && t.overlaps(uint64(day.Day.IntPart())) {
+			previous, _, err := calculate(ctx, bnAddress, elAddress, day.Day.String(), options.concurrency, nil, t.PreviousVersion, session, options)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error calculating day %v with previous methodology version %v: %w", day.Day, t.PreviousVersion, err)
+			}
+			day.PreviousMethodology = previous
+		}
+		return day, perValidator, nil
+	}
+	if options.exclusive {
+		return calculateDay(ctx)
+	}
+
+	c := joinCoalesced(ctx, fmt.Sprintf("%s|%s|%s|%s", bnAddress, elAddress, dayStr, options.coalesceKey()))
+	ch := calculateGroup.DoChan(c.key, func() (interface{}, error) {
+		day, perValidator, err := calculateDay(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &calculateResult{day: day, perValidator: perValidator}, nil
+	})
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+		c.leave()
+	case <-ctx.Done():
+		if !c.leave() {
+			return nil, nil, ctx.Err()
+		}
+		res = <-ch
 	}
+	if res.Err != nil {
+		return nil, nil, res.Err
+	}
+	result := res.Val.(*calculateResult)
+	if !res.Shared {
+		return result.day, result.perValidator, nil
+	}
+	perValidator := make(map[uint64]*Day, len(result.perValidator))
+	for index, d := range result.perValidator {
+		perValidator[index] = copyDay(d)
+	}
+	return copyDay(result.day), perValidator, nil
+}
+
+var coalescedMu = sync.Mutex{}
+var coalesced = map[string]*coalescedCalculation{}
+var coalescedGeneration uint64
+
+// coalescedCalculation is the context of a shared calculation, it keeps the values of the context of the caller that
+// started it but is only cancelled once all callers left.
+type coalescedCalculation struct {
+	id      string
+	key     string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	callers int
+}
+
+// joinCoalesced joins the shared calculation of the id or starts a new one. The key of the calculation in
+// calculateGroup is unique per calculation, so a caller never joins a calculation that is cancelled already.
+func joinCoalesced(ctx context.Context, id string) *coalescedCalculation {
+	coalescedMu.Lock()
+	defer coalescedMu.Unlock()
+	c, exists := coalesced[id]
+	if !exists {
+		coalescedGeneration++
+		c = &coalescedCalculation{id: id, key: fmt.Sprintf("%s|%d", id, coalescedGeneration)}
+		c.ctx, c.cancel = context.WithCancel(detachedContext{ctx})
+		coalesced[id] = c
+	}
+	c.callers++
+	return c
+}
+
+// leave cancels the shared calculation after the last caller left and reports whether the caller was the last.
+func (c *coalescedCalculation) leave() bool {
+	coalescedMu.Lock()
+	defer coalescedMu.Unlock()
+	c.callers--
+	if c.callers > 0 {
+		return false
+	}
+	c.cancel()
+	delete(coalesced, c.id)
+	return true
+}
+
+// detachedContext keeps the values of its parent but is neither cancelled with it nor has its deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
 
-	gethRpcClient, err := gethRPC.Dial(elAddress)
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// calculate calculates the day, options override the package settings and are nil for Calculate.
+func calculate(ctx context.Context, bnAddress, elAddress, dayStr string, concurrency int, checkpoint *Checkpoint, methodology int, session *calculateSession, options *calculateOptions) (*Day, map[uint64]*Day, error) {
+	ctx, span := StartSpan(ctx, "ethstore.Calculate", map[string]string{"day": dayStr})
+	defer span.End()
+
+	if !supportedMethodology(methodology) {
+		return nil, nil, fmt.Errorf("unsupported methodology version %v", methodology)
+	}
+	if err := checkTwoStates(options); err != nil {
+		return nil, nil, err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// the requests of the calculation take the timeouts and settings of the options from the context
+	ctx = withCalculateOptions(ctx, options)
+	client, gethRpcClient, err := session.clients(ctx, bnAddress, elAddress)
 	if err != nil {
 		return nil, nil, err
 	}
+	// the raw beacon api requests use the endpoint of the client or the client if it implements BeaconAPIGetter
+	bnAddress = client.Address()
+	ctx = withBeaconAPIGetter(ctx, client)
 
-	service, err := http.New(ctx, http.WithAddress(bnAddress), http.WithTimeout(GetConsTimeout()), http.WithLogLevel(zerolog.WarnLevel))
+	err = waitForSync(ctx, bnAddress)
 	if err != nil {
 		return nil, nil, err
 	}
-	client := service.(*http.Service)
 
 	apiSpec, err := client.Spec(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
+	network := options.getNetworkOverrides()
+	apiSpec = network.spec(apiSpec)
 
 	genesisForkVersionIf, exists := apiSpec["GENESIS_FORK_VERSION"]
 	if !exists {
@@ -227,6 +466,27 @@ func Calculate(ctx context.Context, bnAddress, elAddress, dayStr string, concurr
 		return nil, nil, fmt.Errorf("invalid format of SLOTS_PER_EPOCH in spec")
 	}
 
+	minEpochsToInactivityPenalty, err := getSpecUint64(apiSpec, "MIN_EPOCHS_TO_INACTIVITY_PENALTY")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the sync committee of the minimal preset has 32 members, phase0 nodes do not report it
+	syncCommitteeSize, err := getSpecUint64(apiSpec, "SYNC_COMMITTEE_SIZE")
+	if err != nil {
+		syncCommitteeSize = 512
+	}
+
+	baseRewardFactor, err := getSpecUint64(apiSpec, "BASE_REWARD_FACTOR")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	effectiveBalanceIncrement, err := getSpecUint64(apiSpec, "EFFECTIVE_BALANCE_INCREMENT")
+	if err != nil {
+		return nil, nil, err
+	}
+
 	secondsPerSlotIf, exists := apiSpec["SECONDS_PER_SLOT"]
 	if !exists {
 		return nil, nil, fmt.Errorf("undefined SECONDS_PER_SLOT in spec")
@@ -237,20 +497,26 @@ func Calculate(ctx context.Context, bnAddress, elAddress, dayStr string, concurr
 	}
 	secondsPerSlot := uint64(secondsPerSlotDur.Seconds())
 
-	slotsPerDay := 3600 * 24 / secondsPerSlot
+	genesis, err := client.GenesisTime(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting genesisTime: %w", err)
+	}
+	genesis = network.genesis(genesis)
+	timing := &ChainTiming{Genesis: genesis, SecondsPerSlot: secondsPerSlot, SlotsPerEpoch: slotsPerEpoch}
+	boundary := options.getDayBoundary()
+	specSnapshot := newSpecSnapshot(apiSpec, genesis)
+	specSnapshot.Network = network.name(specSnapshot.Network)
 
-	finalizedHeader, err := client.BeaconBlockHeader(ctx, "finalized")
+	finalizedDay, finalizedSlot, err := latestFinalizedDay(ctx, client, timing, boundary)
 	if err != nil {
 		return nil, nil, err
 	}
-	finalizedSlot := uint64(finalizedHeader.Header.Message.Slot)
-	finalizedDay := finalizedSlot/slotsPerDay - 1
 
 	var day uint64
 	if dayStr == "finalized" {
 		day = finalizedDay
 	} else if dayStr == "head" {
-		day = finalizedSlot / slotsPerDay
+		day = boundary.Day(finalizedSlot, timing)
 	} else {
 		day, err = strconv.ParseUint(dayStr, 10, 64)
 		if err != nil {
@@ -258,194 +524,578 @@ func Calculate(ctx context.Context, bnAddress, elAddress, dayStr string, concurr
 		}
 	}
 
+	// the states at the end of a day that is not finalized can still change, unless allowed such a day is refused
+	// instead of returning an apr that silently differs from the final one
+	availableSlot := finalizedSlot
 	if day > finalizedDay {
-		return nil, nil, fmt.Errorf("requested to calculate eth.store for a future day (last finalized day: %v, requested day: %v)", finalizedDay, day)
+		if !options.getAllowUnfinalized() {
+			return nil, nil, fmt.Errorf("%w: requested to calculate eth.store for a future day (last finalized day: %v, requested day: %v)", ErrNotFinalized, finalizedDay, day)
+		}
+		headHeader, err := client.BeaconBlockHeader(ctx, "head")
+		if err != nil {
+			return nil, nil, err
+		}
+		availableSlot = uint64(headHeader.Header.Message.Slot)
+		if boundary.FirstSlot(day+1, timing) > availableSlot {
+			return nil, nil, fmt.Errorf("requested to calculate eth.store for a day that has not ended at the head slot %v (requested day: %v)", availableSlot, day)
+		}
+		log.Printf("WARNING eth.store: day %v is not finalized yet (last finalized day: %v), its results can still change", day, finalizedDay)
 	}
 
-	firstSlot := day * slotsPerDay
-	endSlot := (day + 1) * slotsPerDay // first slot not included in this eth.store-day
+	err = openResponseCache(options.getCacheDir(), bnAddress, specSnapshot, finalizedSlot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkSpecHistory(day, specSnapshot); err != nil {
+		return nil, nil, err
+	}
+
+	firstSlot := boundary.FirstSlot(day, timing)
+	endSlot := boundary.FirstSlot(day+1, timing) // first slot not included in this eth.store-day
+	dayEndSlot := endSlot
 
-	if endSlot > finalizedSlot {
-		endSlot = finalizedSlot
+	if endSlot > availableSlot {
+		endSlot = availableSlot
 	}
 	lastSlot := endSlot - 1
+	window := newDayWindow(boundary, firstSlot, endSlot, dayEndSlot, timing)
+	log.Printf("eth.store: calculating day %v: %v", day, window)
 
 	firstEpoch := firstSlot / slotsPerEpoch
 	lastEpoch := lastSlot / slotsPerEpoch
 	endEpoch := lastEpoch + 1
+	// electra credits deposits from the pending deposits of the state instead of the blocks, nodes of earlier forks do
+	// not report its fork epoch
+	electraEpoch, err := getSpecUint64(apiSpec, "ELECTRA_FORK_EPOCH")
+	electra := err == nil && electraEpoch <= lastEpoch
 
-	genesis, err := client.GenesisTime(ctx)
+	startStateRoot, err := getStateRoot(ctx, client, firstSlot)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error getting genesisTime: %w", err)
+		return nil, nil, err
+	}
+	endStateRoot, err := getStateRoot(ctx, client, endSlot)
+	if err != nil {
+		return nil, nil, err
 	}
 	startTime := time.Unix(genesis.Unix()+int64(firstSlot)*int64(secondsPerSlot), 0)
 	endTime := time.Unix(genesis.Unix()+int64(lastSlot)*int64(secondsPerSlot), 0)
 
-	if GetDebugLevel() > 0 {
+	if options.getDebugLevel() > 0 {
 		log.Printf("DEBUG eth.store: calculating day %v (%v - %v, epochs: %v-%v, slots: %v-%v, genesis: %v, finalizedSlot: %v)\n", day, startTime, endTime, firstEpoch, lastEpoch, firstSlot, lastSlot, genesis, finalizedSlot)
 	}
 
 	validatorsByIndex := map[phase0.ValidatorIndex]*Validator{}
 	validatorsByPubkey := map[phase0.BLSPubKey]*Validator{}
+	var totalActiveEffectiveBalanceGwei phase0.Gwei
 
-	startValidators, err := GetValidators(ctx, client, fmt.Sprintf("%d", firstSlot))
+	validatorsCtx, validatorsSpan := StartSpan(ctx, "ethstore.validators", map[string]string{"firstSlot": fmt.Sprintf("%d", firstSlot), "endSlot": fmt.Sprintf("%d", endSlot)})
+	startValidators, err := session.validators(validatorsCtx, client, firstSlot)
 	if err != nil {
+		validatorsSpan.RecordError(err)
+		validatorsSpan.End()
 		return nil, nil, fmt.Errorf("error getting startValidators for firstSlot %d: %w", firstSlot, err)
 	}
 
+	// the balances of all validators are part of the net issuance of the network
+	var networkStartBalanceGwei, networkEndBalanceGwei phase0.Gwei
 	for _, val := range startValidators {
-		if !val.Status.IsActive() {
-			continue
-		}
-		vv := &Validator{
-			Index:                val.Index,
-			Pubkey:               val.Validator.PublicKey,
-			EffectiveBalanceGwei: val.Validator.EffectiveBalance,
-			StartBalanceGwei:     val.Balance,
-			TxFeesSumWei:         new(big.Int),
+		if val.Status.IsActive() {
+			totalActiveEffectiveBalanceGwei += val.Validator.EffectiveBalance
 		}
-		validatorsByIndex[val.Index] = vv
-		validatorsByPubkey[val.Validator.PublicKey] = vv
+		networkStartBalanceGwei += val.Balance
 	}
 
-	endValidators, err := GetValidators(ctx, client, fmt.Sprintf("%d", endSlot))
+	eligibility := options.getEligibilityRules()
+	// a session reuses the validators at the end of the day as the validators at the start of the next day
+	balancesOnly := session == nil && options.getEndBalances()
+	var endValidators map[phase0.ValidatorIndex]*v1.Validator
+	if balancesOnly {
+		endValidators, err = getEndValidatorsFromBalances(validatorsCtx, client.Address(), fmt.Sprintf("%d", endSlot), startValidators, eligibility, phase0.Epoch(endEpoch))
+	} else {
+		endValidators, err = session.validators(validatorsCtx, client, endSlot)
+	}
 	if err != nil {
+		validatorsSpan.RecordError(err)
+		validatorsSpan.End()
 		return nil, nil, fmt.Errorf("error getting endValidators for endSlot %d: %w", endSlot, err)
 	}
+	session.setEndValidators(endSlot, endValidators)
+	if !balancesOnly {
+		for _, val := range endValidators {
+			networkEndBalanceGwei += val.Balance
+		}
+	}
+	compositionStart := getComposition(startValidators)
+	var compositionEnd *ValidatorComposition
+	exitValidators := endValidators
+	if !balancesOnly {
+		compositionEnd = getComposition(endValidators)
+	} else if watchList := GetExitWatchList(); len(watchList) > 0 {
+		exitValidators, err = requestValidatorsByIndex(validatorsCtx, client.Address(), fmt.Sprintf("%d", endSlot), watchList)
+		if err != nil {
+			validatorsSpan.RecordError(err)
+			validatorsSpan.End()
+			return nil, nil, err
+		}
+	}
+	validatorsSpan.End()
+	validatorExits := getValidatorExits(exitValidators, GetExitWatchList(), endEpoch, timing)
 
+	// the endBalance of a validator is the balance of the first epoch of the next day
 	for _, val := range endValidators {
-		v, exists := validatorsByIndex[val.Index]
-		if !exists {
+		start, exists := startValidators[val.Index]
+		if !exists || !eligibility.Eligible(start, val, phase0.Epoch(endEpoch)) {
 			continue
 		}
-		if uint64(val.Validator.ExitEpoch) < endEpoch {
-			// do not account validators that have not been active until the end of the day
-			delete(validatorsByIndex, val.Index)
-			delete(validatorsByPubkey, val.Validator.PublicKey)
-			continue
+		vv := &Validator{
+			Index:                 val.Index,
+			Pubkey:                val.Validator.PublicKey,
+			EffectiveBalanceGwei:  start.Validator.EffectiveBalance,
+			StartBalanceGwei:      start.Balance,
+			EndBalanceGwei:        val.Balance,
+			TxFeesSumWei:          new(big.Int),
+			MevRewardsWei:         new(big.Int),
+			Compounding:           len(start.Validator.WithdrawalCredentials) > 0 && start.Validator.WithdrawalCredentials[0] == compoundingWithdrawalPrefix,
+			WithdrawalCredentials: start.Validator.WithdrawalCredentials,
 		}
-		// set endBalance of validator to the balance of the first epoch of the next day
-		v.EndBalanceGwei = val.Balance
+		validatorsByIndex[val.Index] = vv
+		validatorsByPubkey[val.Validator.PublicKey] = vv
 	}
-	if GetDebugLevel() > 0 {
+	if options.getDebugLevel() > 0 {
 		log.Printf("DEBUG eth.store: startValidators: %v, endValidators: %v, ethstoreValidators: %v", len(startValidators), len(endValidators), len(validatorsByIndex))
 	}
+	lowMemory := options.getLowMemory()
+	if lowMemory {
+		// the validator-sets are the largest allocations of the calculation and not used anymore
+		debug.FreeOSMemory()
+		if concurrency > lowMemoryConcurrency {
+			concurrency = lowMemoryConcurrency
+		}
+	}
 
 	g := new(errgroup.Group)
 	g.SetLimit(concurrency)
 	validatorsMu := sync.Mutex{}
+	proposalTxFeesWei := []decimal.Decimal{}
+	missedSlots := uint64(0)
+	// the sync committee participation of the day is the share of set bits of the sync aggregates of all blocks
+	var syncBitsSet, syncBits uint64
+	var feeRecipientMismatches []FeeRecipientMismatch
+	expectedFeeRecipients := GetExpectedFeeRecipients()
+	builders := GetCensoringBuilders()
+	rules := GetAlertRules()
+	var alerts []Alert
+	// the missed slots are only recorded if streaks of missed slots are alerted
+	var missedSlotList []uint64
+	// deposits and withdrawals of the eth.store validators per epoch for the per-epoch series
+	epochDepositsGwei := map[uint64]phase0.Gwei{}
+	epochWithdrawalsGwei := map[uint64]phase0.Gwei{}
+	// the deposits of all blocks and the range of the execution blocks of the day for the deposit check
+	depositCheck := GetDepositCheck()
+	// the builder payments of the proposals of the eth.store validators are reported separately
+	mev := options.getIncludeMEV()
+	var blockDeposits []BlockDeposit
+	var firstExecutionBlock, lastExecutionBlock uint64
+	// the base fees of all blocks of the day are burned, not only those of the blocks of the eth.store validators
+	burnedFeesWei := new(big.Int)
+	// the deposits and withdrawals of all validators for the net issuance of the network
+	var networkDepositsGwei, networkWithdrawalsGwei phase0.Gwei
+	gasStats := &GasStats{}
+	var orphans *OrphanStats
+	if GetOrphanedBlocks() {
+		orphans = &OrphanStats{}
+	}
+	var censorship *CensorshipStats
+	if builders != nil {
+		censorship = &CensorshipStats{}
+	}
+	var feeBreakdown *TxFeeBreakdown
+	if options.getTxFeeBreakdown() {
+		feeBreakdown = &TxFeeBreakdown{ByType: map[string]TxFees{}, ByKind: map[string]TxFees{}}
+	}
+	txDecodeErrors := &TxDecodeErrors{}
+	clientName := getBeaconClientName(ctx, client)
+
+	// slots that have been processed before a checkpoint are skipped, processedSlots is only accessed while holding validatorsMu
+	checkpointSlots := map[uint64]bool{}
+	if checkpoint != nil {
+		if checkpoint.Day != day {
+			return nil, nil, fmt.Errorf("checkpoint of day %v can not be used to calculate day %v", checkpoint.Day, day)
+		}
+		checkpointSlots, err = checkpoint.apply(validatorsByIndex)
+		if err != nil {
+			return nil, nil, err
+		}
+		proposalTxFeesWei = append(proposalTxFeesWei, checkpoint.ProposalTxFeesWei...)
+		missedSlots = checkpoint.MissedSlots
+		syncBitsSet, syncBits = checkpoint.SyncBitsSet, checkpoint.SyncBits
+		feeRecipientMismatches = append(feeRecipientMismatches, checkpoint.FeeRecipientMismatches...)
+		alerts = append(alerts, checkpoint.Alerts...)
+		missedSlotList = append(missedSlotList, checkpoint.MissedSlotList...)
+		for epoch, amount := range checkpoint.EpochDepositsGwei {
+			epochDepositsGwei[epoch] = amount
+		}
+		for epoch, amount := range checkpoint.EpochWithdrawalsGwei {
+			epochWithdrawalsGwei[epoch] = amount
+		}
+		blockDeposits = append(blockDeposits, checkpoint.BlockDeposits...)
+		firstExecutionBlock, lastExecutionBlock = checkpoint.FirstExecutionBlock, checkpoint.LastExecutionBlock
+		burnedFeesWei.Set(checkpoint.BurnedFeesWei.BigInt())
+		networkDepositsGwei, networkWithdrawalsGwei = checkpoint.NetworkDepositsGwei, checkpoint.NetworkWithdrawalsGwei
+		if checkpoint.GasStats != nil {
+			g := *checkpoint.GasStats
+			gasStats = &g
+		}
+		if orphans != nil && checkpoint.Orphans != nil {
+			orphans = checkpoint.Orphans.clone()
+		}
+		if censorship != nil && checkpoint.Censorship != nil {
+			c := *checkpoint.Censorship
+			censorship = &c
+		}
+		if feeBreakdown != nil && checkpoint.TxFeeBreakdown != nil {
+			feeBreakdown = checkpoint.TxFeeBreakdown.clone()
+		}
+		if checkpoint.TxDecodeErrors != nil {
+			txDecodeErrors = checkpoint.TxDecodeErrors.clone()
+		}
+	}
+	progress := newProgressReporter(ctx, options.getProgressSinks(), day, firstEpoch, endEpoch)
+	if checkpoint != nil {
+		progress.addCheckpoint(checkpoint)
+	}
+	processedSlots := make(map[uint64]bool, endSlot-firstSlot)
+	for slot := range checkpointSlots {
+		processedSlots[slot] = true
+	}
+	// partialResult turns errors caused by a cancelled context into a PartialResultError, it must not be called
+	// while blocks are processed
+	partialResult := func(err error) error {
+		if ctx.Err() == nil {
+			return err
+		}
+		cp := &Checkpoint{
+			Day:                    day,
+			MethodologyVersion:     methodology,
+			MissedSlots:            missedSlots,
+			SyncBitsSet:            syncBitsSet,
+			SyncBits:               syncBits,
+			ProposalTxFeesWei:      append([]decimal.Decimal{}, proposalTxFeesWei...),
+			FeeRecipientMismatches: append([]FeeRecipientMismatch{}, feeRecipientMismatches...),
+			Alerts:                 append([]Alert{}, alerts...),
+			MissedSlotList:         append([]uint64{}, missedSlotList...),
+			EpochDepositsGwei:      make(map[uint64]phase0.Gwei, len(epochDepositsGwei)),
+			EpochWithdrawalsGwei:   make(map[uint64]phase0.Gwei, len(epochWithdrawalsGwei)),
+			BlockDeposits:          append([]BlockDeposit{}, blockDeposits...),
+			FirstExecutionBlock:    firstExecutionBlock,
+			LastExecutionBlock:     lastExecutionBlock,
+			BurnedFeesWei:          decimal.NewFromBigInt(burnedFeesWei, 0),
+			NetworkDepositsGwei:    networkDepositsGwei,
+			NetworkWithdrawalsGwei: networkWithdrawalsGwei,
+		}
+		g := *gasStats
+		cp.GasStats = &g
+		if orphans != nil {
+			cp.Orphans = orphans.clone()
+		}
+		for epoch, amount := range epochDepositsGwei {
+			cp.EpochDepositsGwei[epoch] = amount
+		}
+		for epoch, amount := range epochWithdrawalsGwei {
+			cp.EpochWithdrawalsGwei[epoch] = amount
+		}
+		if censorship != nil {
+			c := *censorship
+			cp.Censorship = &c
+		}
+		if feeBreakdown != nil {
+			cp.TxFeeBreakdown = feeBreakdown.clone()
+		}
+		if txDecodeErrors.Slots.IsPositive() {
+			cp.TxDecodeErrors = txDecodeErrors.clone()
+		}
+		return &PartialResultError{
+			Checkpoint: newCheckpoint(cp, processedSlots, validatorsByIndex),
+			Err:        err,
+		}
+	}
+
+	// blocks are traced in batches of one epoch, the span of an epoch ends when all of its blocks have been processed,
+	// the progress of the day is sent at the same time
+	var epochSpan Span
+	var epochWg *sync.WaitGroup
+	var spanEpoch uint64
+	endEpochSpan := func() {
+		if epochSpan == nil {
+			return
+		}
+		span, wg := epochSpan, epochWg
+		go func() {
+			wg.Wait()
+			span.End()
+		}()
+		progress.endEpoch(spanEpoch, wg)
+		epochSpan = nil
+	}
+
+	// no more blocks are requested while the node is not ready, blocks that are already requested are still processed
+	var syncErr error
 
 	// get all deposits and txs of all active validators in the slot interval [startSlot,endSlot)
 	for i := firstSlot; i < endSlot; i++ {
 		i := i
-		if GetDebugLevel() > 0 && (endSlot-i)%1000 == 0 {
+		if ctx.Err() != nil {
+			break
+		}
+		if i%slotsPerEpoch == 0 && i != firstSlot {
+			syncErr = waitForSync(ctx, bnAddress)
+			if syncErr != nil {
+				break
+			}
+		}
+		if epochSpan == nil || i%slotsPerEpoch == 0 {
+			endEpochSpan()
+			_, epochSpan = StartSpan(ctx, "ethstore.blocks", map[string]string{"epoch": fmt.Sprintf("%d", i/slotsPerEpoch)})
+			epochWg = &sync.WaitGroup{}
+			spanEpoch = i / slotsPerEpoch
+		}
+		if checkpointSlots[i] {
+			continue
+		}
+		if options.getDebugLevel() > 0 && (endSlot-i)%1000 == 0 {
 			log.Printf("DEBUG eth.store: checking blocks for deposits and txs: %.0f%% (%v of %v-%v)\n", 100*float64(i-firstSlot)/float64(endSlot-firstSlot), i, firstSlot, endSlot)
 		}
-		g.Go(func() error {
-			var block *spec.VersionedSignedBeaconBlock
-			var err error
-			for j := 0; j < 10; j++ { // retry up to 10 times on failure
-				block, err = client.SignedBeaconBlock(ctx, fmt.Sprintf("%d", i))
-
-				if err == nil {
-					break
-				} else {
-					log.Printf("error retrieving beacon block at slot %v: %v", i, err)
-					time.Sleep(time.Duration(j) * time.Second)
+		span, wg := epochSpan, epochWg
+		wg.Add(1)
+		g.Go(func() (err error) {
+			defer func() {
+				if err != nil {
+					span.RecordError(err)
 				}
+				wg.Done()
+			}()
+			// transient errors of the request are retried according to the retry policy
+			block, err := client.BeaconBlock(ctx, i)
+			block, err = normalizeBlockResponse(clientName, block, err)
+			var blinded *SignedBlindedBeaconBlock
+			if err != nil && ctx.Err() != nil {
+				return err
 			}
 			if err != nil {
-				return fmt.Errorf("error getting block %v: %w", i, err)
-			}
-			if block == nil {
+				// fall back to the blinded block if the full block is unavailable, the tx hashes are taken from the execution node
+				var blindedErr error
+				blinded, blindedErr = client.BlindedBeaconBlock(ctx, i)
+				if blindedErr != nil || blinded == nil {
+					return fmt.Errorf("error getting block %v: %w", i, err)
+				}
+				if options.getDebugLevel() > 0 {
+					log.Printf("DEBUG eth.store: using blinded block at slot %v: %v", i, err)
+				}
+			} else if block == nil {
+				var orphaned []OrphanedBlock
+				if orphans != nil {
+					orphaned, err = getOrphanedBlocks(ctx, bnAddress, i)
+					if err != nil {
+						return err
+					}
+				}
+				validatorsMu.Lock()
+				defer validatorsMu.Unlock()
+				missedSlots++
+				if orphans != nil {
+					orphans.add(orphaned, validatorsByIndex)
+				}
+				processedSlots[i] = true
+				progress.addMissedSlot(i / slotsPerEpoch)
+				if rules != nil && rules.MissedSlotStreak > 0 {
+					missedSlotList = append(missedSlotList, i)
+				}
 				return nil
 			}
 			var deposits []*phase0.Deposit
-			var exec *bellatrix.ExecutionPayload
+			var exec *executionBlock
 			var proposerIndex phase0.ValidatorIndex
-			switch block.Version {
-			case spec.DataVersionPhase0:
+			var syncAggregate *altair.SyncAggregate
+			var proposerSlashings []*phase0.ProposerSlashing
+			var attesterSlashings []*phase0.AttesterSlashing
+			switch {
+			case blinded != nil:
+				deposits = blinded.Message.Body.Deposits
+				proposerSlashings = blinded.Message.Body.ProposerSlashings
+				attesterSlashings = blinded.Message.Body.AttesterSlashings
+				proposerIndex = blinded.Message.ProposerIndex
+				syncAggregate = blinded.Message.Body.SyncAggregate
+				for j := 0; j < 10; j++ { // retry up to 10 times
+					execCtx, cancel := context.WithTimeout(ctx, options.getExecTimeout())
+					header := blinded.Message.Body.ExecutionPayloadHeader
+					exec, err = cachedExecutionBlock(common.Hash(header.BlockHash), func() (*executionBlock, error) {
+						return executionBlockFromHeader(execCtx, gethRpcClient, header)
+					})
+					cancel()
+					if err == nil || ctx.Err() != nil {
+						break
+					}
+					log.Printf("error getting execution block of blinded block at slot %v: %v", i, err)
+					time.Sleep(time.Duration(j) * time.Second)
+				}
+				if err != nil {
+					return fmt.Errorf("error getting execution block of blinded block at slot %v: %w", i, err)
+				}
+				if hasWithdrawals(blinded.Fork) && exec.Withdrawals == nil {
+					return fmt.Errorf("execution block of %v blinded block at slot %v has no withdrawals", blinded.Fork, i)
+				}
+			case block.Version == spec.DataVersionPhase0:
 				deposits = block.Phase0.Message.Body.Deposits
+				proposerSlashings = block.Phase0.Message.Body.ProposerSlashings
+				attesterSlashings = block.Phase0.Message.Body.AttesterSlashings
 				proposerIndex = block.Phase0.Message.ProposerIndex
-			case spec.DataVersionAltair:
+			case block.Version == spec.DataVersionAltair:
 				deposits = block.Altair.Message.Body.Deposits
+				proposerSlashings = block.Altair.Message.Body.ProposerSlashings
+				attesterSlashings = block.Altair.Message.Body.AttesterSlashings
 				proposerIndex = block.Altair.Message.ProposerIndex
-			case spec.DataVersionBellatrix:
+				syncAggregate = block.Altair.Message.Body.SyncAggregate
+			case block.Version == spec.DataVersionBellatrix:
 				deposits = block.Bellatrix.Message.Body.Deposits
+				proposerSlashings = block.Bellatrix.Message.Body.ProposerSlashings
+				attesterSlashings = block.Bellatrix.Message.Body.AttesterSlashings
 				proposerIndex = block.Bellatrix.Message.ProposerIndex
-				exec = block.Bellatrix.Message.Body.ExecutionPayload
+				syncAggregate = block.Bellatrix.Message.Body.SyncAggregate
+				payload := block.Bellatrix.Message.Body.ExecutionPayload
+				var withdrawals []Withdrawal
+				if block.Capella != nil {
+					withdrawals = block.Capella.Withdrawals
+				}
+				exec, err = cachedExecutionBlock(common.Hash(payload.BlockHash), func() (*executionBlock, error) {
+					return executionBlockFromPayload(payload, withdrawals), nil
+				})
+				if err != nil {
+					return fmt.Errorf("error decoding execution payload of block at slot %v: %w", i, err)
+				}
 			default:
 				return fmt.Errorf("unknown block version for block %v: %v", i, block.Version)
 			}
 
+			blockTxFeesWei := new(big.Int)
+			var txReceipts []*TxReceipt
+			var unexpectedTypes map[string]int64
 			if exec != nil {
 				// only add tx fees of blocks that have been proposed from validators that have been active the whole day
-				v, exists := validatorsByIndex[proposerIndex]
-				if exists && len(exec.Transactions) > 0 {
-					txHashes := []common.Hash{}
-					for _, tx := range exec.Transactions {
-						var decTx gethTypes.Transaction
-						err := decTx.UnmarshalBinary([]byte(tx))
-						if err != nil {
-							return err
-						}
-						txHashes = append(txHashes, decTx.Hash())
-					}
-
-					var txReceipts []*TxReceipt
+				_, exists := validatorsByIndex[proposerIndex]
+				// the receipts of a block whose tx fees are cached are only requested again for the breakdown of the
+				// tx fees and the builder payment
+				cachedFeesWei, cachedTypes, cached := cachedTxFees(exec)
+				if exists && len(exec.TxHashes) > 0 && cached && feeBreakdown == nil && !mev {
+					blockTxFeesWei, unexpectedTypes = cachedFeesWei, cachedTypes
+				} else if exists && len(exec.TxHashes) > 0 {
 					for j := 0; j < 10; j++ { // retry up to 10 times
-						ctx, cancel := context.WithTimeout(context.Background(), GetExecTimeout())
-						txReceipts, err = batchRequestReceipts(ctx, gethRpcClient, txHashes)
+						ctx, cancel := context.WithTimeout(context.Background(), options.getExecTimeout())
+						txReceipts, err = requestReceipts(ctx, gethRpcClient, options.getReceiptsMode(), exec)
 						if err == nil {
 							cancel()
 							break
 						} else {
-							log.Printf("error doing batchRequestReceipts for slot %v: %v", i, err)
+							log.Printf("error requesting receipts for slot %v: %v", i, err)
 							time.Sleep(time.Duration(j) * time.Second)
 						}
 						cancel()
 					}
 					if err != nil {
-						return fmt.Errorf("error doing batchRequestReceipts for slot %v: %w", i, err)
+						return fmt.Errorf("error requesting receipts for slot %v: %w", i, err)
 					}
 
 					totalTxFee := big.NewInt(0)
 					for _, r := range txReceipts {
 						if r.EffectiveGasPrice == nil {
-							return fmt.Errorf("no EffectiveGasPrice for slot %v: %v", i, txHashes)
+							return fmt.Errorf("no EffectiveGasPrice for slot %v: %v", i, exec.TxHashes)
 						}
 						txFee := new(big.Int).Mul(r.EffectiveGasPrice.ToInt(), new(big.Int).SetUint64(uint64(r.GasUsed)))
 						totalTxFee.Add(totalTxFee, txFee)
 					}
 
-					// base fee per gas is stored little-endian but we need it
-					// big-endian for big.Int.
-					var baseFeePerGasBEBytes [32]byte
-					for i := 0; i < 32; i++ {
-						baseFeePerGasBEBytes[i] = exec.BaseFeePerGas[32-1-i]
-					}
-					baseFeePerGas := new(big.Int).SetBytes(baseFeePerGasBEBytes[:])
+					baseFeePerGas := exec.BaseFeePerGas
 					burntFee := new(big.Int).Mul(baseFeePerGas, new(big.Int).SetUint64(exec.GasUsed))
 
 					totalTxFee.Sub(totalTxFee, burntFee)
+					blockTxFeesWei = totalTxFee
+					unexpectedTypes = unexpectedTxTypes(txReceipts)
+					cacheTxFees(exec, blockTxFeesWei, unexpectedTypes)
 
-					validatorsMu.Lock()
-					v.TxFeesSumWei.Add(v.TxFeesSumWei, totalTxFee)
-					validatorsMu.Unlock()
-
-					if GetDebugLevel() > 1 {
+					if options.getDebugLevel() > 1 {
 						log.Printf("DEBUG eth.store: slot: %v, block: %v, baseFee: %v, txFees: %v, burnt: %v\n", i, exec.BlockNumber, baseFeePerGas, totalTxFee, burntFee)
 					}
 				}
 			}
+			var builderPaymentWei *big.Int
+			if exec != nil && mev {
+				for j := 0; j < 10; j++ { // retry up to 10 times
+					execCtx, cancel := context.WithTimeout(ctx, options.getExecTimeout())
+					builderPaymentWei, err = getBuilderPayment(execCtx, gethRpcClient, exec, txReceipts)
+					cancel()
+					if err == nil || ctx.Err() != nil {
+						break
+					}
+					log.Printf("error getting builder payment of block at slot %v: %v", i, err)
+					time.Sleep(time.Duration(j) * time.Second)
+				}
+				if err != nil {
+					return fmt.Errorf("error getting builder payment of block at slot %v: %w", i, err)
+				}
+			}
+
+			// slashings of watched validators are delivered right away instead of at the end of the day
+			slashingAlerts := rules.slashingAlerts(day, i, proposerSlashings, attesterSlashings)
+			deliverAlerts(ctx, slashingAlerts)
 
 			validatorsMu.Lock()
 			defer validatorsMu.Unlock()
+			processedSlots[i] = true
+			alerts = append(alerts, slashingAlerts...)
+			if exec != nil {
+				txDecodeErrors.add(i, exec.TxDecodeErrors, unexpectedTypes)
+				burnedFeesWei.Add(burnedFeesWei, new(big.Int).Mul(exec.BaseFeePerGas, new(big.Int).SetUint64(exec.GasUsed)))
+				gasStats.add(exec)
+			}
+			if syncAggregate != nil {
+				syncBitsSet += syncAggregate.SyncCommitteeBits.Count()
+				syncBits += syncCommitteeSize
+			}
+			if v, exists := validatorsByIndex[proposerIndex]; exists {
+				v.TxFeesSumWei.Add(v.TxFeesSumWei, blockTxFeesWei)
+				if builderPaymentWei != nil {
+					v.MevRewardsWei.Add(v.MevRewardsWei, builderPaymentWei)
+				}
+				v.Proposals++
+				proposalTxFeesWei = append(proposalTxFeesWei, decimal.NewFromBigInt(blockTxFeesWei, 0))
+				progress.addProposal(i/slotsPerEpoch, blockTxFeesWei)
+				if exec != nil && censorship != nil {
+					censorship.add(builders[exec.FeeRecipient], blockTxFeesWei)
+				}
+				if exec != nil && feeBreakdown != nil {
+					feeBreakdown.add(txReceipts, exec.BaseFeePerGas)
+				}
+				if exec != nil {
+					if m := expectedFeeRecipients.check(i, uint64(proposerIndex), exec.FeeRecipient); m != nil {
+						log.Printf("fee recipient mismatch: block at slot %v of validator %v pays to %v instead of %v", i, proposerIndex, m.FeeRecipient.Hex(), m.ExpectedFeeRecipient.Hex())
+						feeRecipientMismatches = append(feeRecipientMismatches, *m)
+					}
+				}
+			}
+			if depositCheck {
+				if exec != nil && (firstExecutionBlock == 0 || exec.BlockNumber < firstExecutionBlock) {
+					firstExecutionBlock = exec.BlockNumber
+				}
+				if exec != nil && exec.BlockNumber > lastExecutionBlock {
+					lastExecutionBlock = exec.BlockNumber
+				}
+				for _, d := range deposits {
+					blockDeposits = append(blockDeposits, BlockDeposit{Slot: i, Data: d.Data})
+				}
+			}
 			for _, d := range deposits {
-				v, exists := validatorsByPubkey[d.Data.PublicKey]
-				if !exists {
-					// only add deposits of validators that have been active the whole day
-					continue
+				if a := rules.depositAlert(day, i, d); a != nil {
+					alerts = append(alerts, *a)
 				}
 				msg := &ethpb.Deposit_Data{
 					PublicKey:             d.Data.PublicKey[:],
@@ -455,84 +1105,416 @@ func Calculate(ctx context.Context, bnAddress, elAddress, dayStr string, concurr
 				}
 				err := deposit.VerifyDepositSignature(msg, depositDomainComputed)
 				if err != nil {
-					if GetDebugLevel() > 0 {
+					if options.getDebugLevel() > 0 {
 						log.Printf("DEBUG eth.store: invalid deposit signature in block %d: %v", i, err)
 					}
 					continue
 				}
-				if GetDebugLevel() > 0 {
+				networkDepositsGwei += d.Data.Amount
+				v, exists := validatorsByPubkey[d.Data.PublicKey]
+				if !exists {
+					// only add deposits of validators that have been active the whole day
+					continue
+				}
+				if options.getDebugLevel() > 0 {
 					log.Printf("DEBUG eth.store: extra deposit at block %d from %v: %#x: %v\n", i, v.Index, d.Data.PublicKey, d.Data.Amount)
 				}
 				v.DepositsSumGwei += d.Data.Amount
+				epochDepositsGwei[i/slotsPerEpoch] += d.Data.Amount
+				progress.addDeposit(i/slotsPerEpoch, d.Data.Amount)
+			}
+			// the withdrawals of the blocks since capella are deducted from the balances and added back to the rewards
+			var withdrawals []Withdrawal
+			if exec != nil {
+				withdrawals = exec.Withdrawals
+			}
+			for _, w := range withdrawals {
+				networkWithdrawalsGwei += w.AmountGwei
+				if v, exists := validatorsByIndex[w.ValidatorIndex]; exists {
+					v.WithdrawalsSumGwei += w.AmountGwei
+					epochWithdrawalsGwei[i/slotsPerEpoch] += w.AmountGwei
+				}
 			}
 
 			return nil
 		})
 	}
+	endEpochSpan()
 	if err := g.Wait(); err != nil {
-		return nil, nil, err
+		return nil, nil, partialResult(err)
+	}
+	if syncErr != nil {
+		return nil, nil, partialResult(syncErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, partialResult(err)
+	}
+
+	ctx, computeSpan := StartSpan(ctx, "ethstore.compute", nil)
+	defer computeSpan.End()
+
+	effectiveBalances := make(map[phase0.ValidatorIndex]phase0.Gwei, len(validatorsByIndex))
+	for index, v := range validatorsByIndex {
+		effectiveBalances[index] = v.EffectiveBalanceGwei
+	}
+
+	var attestationEffectiveness map[phase0.ValidatorIndex]float64
+	if p := GetAttestationProvider(); p != nil {
+		attestationEffectiveness, err = getAttestationEffectiveness(ctx, p, firstEpoch, lastEpoch, effectiveBalances, concurrency)
+		if err != nil {
+			return nil, nil, partialResult(fmt.Errorf("error getting attestation effectiveness: %w", err))
+		}
+	}
+
+	var depositMismatches []DepositMismatch
+	if depositCheck && firstExecutionBlock > 0 {
+		depositMismatches, err = getDepositMismatches(ctx, client, gethRpcClient, apiSpec, firstExecutionBlock, lastExecutionBlock, blockDeposits)
+		if err != nil {
+			return nil, nil, partialResult(err)
+		}
+	}
+
+	var epochRewards []EpochRewards
+	if GetEpochSeries() {
+		epochRewards, err = getEpochSeries(ctx, client, validatorsByIndex, epochDepositsGwei, epochWithdrawalsGwei, firstSlot, endSlot, slotsPerEpoch, concurrency)
+		if err != nil {
+			return nil, nil, partialResult(err)
+		}
+	}
+
+	// flag days that overlap an inactivity leak, their rewards are not representative for normal operation
+	var leakEpochs []uint64
+	if options.getPerEpochLeakDetection() {
+		leakEpochs, err = getInactivityLeakEpochs(ctx, client, firstEpoch, lastEpoch, slotsPerEpoch, minEpochsToInactivityPenalty, concurrency)
+	} else {
+		leakEpochs, err = getInactivityLeakEpochsOfTwoStates(ctx, client, firstSlot, endSlot, slotsPerEpoch, minEpochsToInactivityPenalty)
+	}
+	if err != nil {
+		return nil, nil, partialResult(err)
+	}
+	var inactivityPenaltiesGwei *decimal.Decimal
+	if p, ok := GetAttestationProvider().(InactivityPenaltyProvider); ok && len(leakEpochs) > 0 {
+		penalties, err := getInactivityPenalties(ctx, p, leakEpochs, effectiveBalances, concurrency)
+		if err != nil {
+			return nil, nil, partialResult(err)
+		}
+		d := decimal.NewFromInt(int64(penalties))
+		inactivityPenaltiesGwei = &d
+	}
+	if options.getDebugLevel() > 0 && len(leakEpochs) > 0 {
+		log.Printf("DEBUG eth.store: inactivity leak during %v epochs: %v", len(leakEpochs), leakEpochs)
+	}
+
+	var blobDiscrepancies []BlobDiscrepancy
+	if GetBlobVerification() {
+		blobDiscrepancies, err = verifyBlobs(ctx, client, firstSlot, endSlot, concurrency)
+		if err != nil {
+			return nil, nil, partialResult(err)
+		}
 	}
 
 	var totalEffectiveBalanceGwei phase0.Gwei
 	var totalStartBalanceGwei phase0.Gwei
 	var totalEndBalanceGwei phase0.Gwei
 	var totalDepositsSumGwei phase0.Gwei
+	var totalWithdrawalsSumGwei phase0.Gwei
 	totalTxFeesSumWei := new(big.Int)
+	totalMevRewardsWei := new(big.Int)
+	var totalProposals uint64
+	for _, v := range validatorsByIndex {
+		totalTxFeesSumWei.Add(totalTxFeesSumWei, v.TxFeesSumWei)
+		totalMevRewardsWei.Add(totalMevRewardsWei, v.MevRewardsWei)
+		totalProposals += v.Proposals
+	}
+
+	// blocks are processed concurrently, sort the samples to keep the result deterministic
+	sort.Slice(proposalTxFeesWei, func(i, j int) bool {
+		return proposalTxFeesWei[i].LessThan(proposalTxFeesWei[j])
+	})
+	sort.Slice(feeRecipientMismatches, func(i, j int) bool {
+		return feeRecipientMismatches[i].Slot < feeRecipientMismatches[j].Slot
+	})
+	if orphans != nil {
+		sort.Slice(orphans.Blocks, func(i, j int) bool {
+			if orphans.Blocks[i].Slot != orphans.Blocks[j].Slot {
+				return orphans.Blocks[i].Slot < orphans.Blocks[j].Slot
+			}
+			return orphans.Blocks[i].Root < orphans.Blocks[j].Root
+		})
+	}
+	alerts = append(alerts, rules.missedSlotStreakAlerts(day, missedSlotList)...)
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Slot < alerts[j].Slot
+	})
+	// days before the merge have no execution blocks
+	if gasStats.Blocks.IsPositive() {
+		gasStats.finalize()
+	} else {
+		gasStats = nil
+	}
+	if censorship != nil {
+		censorship.finalize(decimal.NewFromBigInt(totalTxFeesSumWei, 0))
+	}
+
+	// every slot of the day is assigned to a proposer with a probability proportional to its effective balance,
+	// the number of proposals of the eth.store validators is therefore poisson-like distributed and so are their tx fees
+	proposalSlots := decimal.NewFromInt(int64(endSlot - firstSlot))
+	avgTxFeesPerProposalWei := decimal.Zero
+	if totalProposals > 0 {
+		avgTxFeesPerProposalWei = decimal.NewFromBigInt(totalTxFeesSumWei, 0).Div(decimal.NewFromInt(int64(totalProposals)))
+	}
 
 	ethstorePerValidator := make(map[uint64]*Day, len(validatorsByIndex))
+	baseline := newConsensusBaseline(totalActiveEffectiveBalanceGwei, baseRewardFactor, effectiveBalanceIncrement, endEpoch-firstEpoch, GetExpectedParticipation())
+	provenance := BuildInfo()
+	provenance.MethodologyVersion = methodology
+	provenance.SpecHash = specSnapshot.Hash()
+	if _, isDefault := boundary.(GenesisDayBoundary); !isDefault {
+		provenance.DayBoundary = boundary.String()
+	}
+	if eligibility.String() != DefaultEligibilityRules.String() {
+		provenance.Eligibility = eligibility.String()
+	}
+
+	// the apr of compounding (0x02) and non-compounding (0x00 and 0x01) validators is reported separately. Since
+	// electra deposits are credited from the pending deposits of the state and consolidations move balance to
+	// compounding validators, neither is part of the blocks, so the apr of compounding validators is not reported for
+	// electra days.
+	var compoundingValidators int64
+	compoundingRewardsWei, nonCompoundingRewardsWei := decimal.Zero, decimal.Zero
+	compoundingEffectiveBalanceGwei, nonCompoundingEffectiveBalanceGwei := decimal.Zero, decimal.Zero
 
 	for index, v := range validatorsByIndex {
 		totalEffectiveBalanceGwei += v.EffectiveBalanceGwei
 		totalStartBalanceGwei += v.StartBalanceGwei
 		totalEndBalanceGwei += v.EndBalanceGwei
 		totalDepositsSumGwei += v.DepositsSumGwei
-		totalTxFeesSumWei.Add(totalTxFeesSumWei, v.TxFeesSumWei)
-
-		validatorConsensusRewardsGwei := decimal.NewFromInt(int64(v.EndBalanceGwei) - int64(v.StartBalanceGwei) - int64(v.DepositsSumGwei))
-		validatorRewardsWei := decimal.NewFromBigInt(v.TxFeesSumWei, 0).Add(validatorConsensusRewardsGwei.Mul(decimal.NewFromInt(1e9)))
+		totalWithdrawalsSumGwei += v.WithdrawalsSumGwei
+
+		validatorConsensusRewardsGwei := decimal.NewFromInt(int64(v.EndBalanceGwei) - int64(v.StartBalanceGwei) - int64(v.DepositsSumGwei) + int64(v.WithdrawalsSumGwei))
+		validatorConsensusRewardsWei := gweiToWei(validatorConsensusRewardsGwei)
+		validatorRewardsWei := decimal.NewFromBigInt(v.TxFeesSumWei, 0).Add(validatorConsensusRewardsWei)
+		validatorProposalsExpected := proposalSlots.Mul(decimal.NewFromInt(int64(v.EffectiveBalanceGwei))).Div(decimal.NewFromInt(int64(totalActiveEffectiveBalanceGwei)))
+		validatorApr := decimal.NewFromInt(365).Mul(validatorRewardsWei).Div(decimal.NewFromInt(int64(v.EffectiveBalanceGwei)).Mul(decimal.NewFromInt(1e9)))
+		validatorAprBand := proposalAprBand(validatorProposalsExpected, avgTxFeesPerProposalWei, decimal.NewFromInt(int64(v.EffectiveBalanceGwei)))
+		validatorBaselineGwei := baseline.expectedRewardsGwei(v.EffectiveBalanceGwei)
+
+		if v.Compounding {
+			compoundingValidators++
+			compoundingRewardsWei = compoundingRewardsWei.Add(validatorRewardsWei)
+			compoundingEffectiveBalanceGwei = compoundingEffectiveBalanceGwei.Add(decimal.NewFromInt(int64(v.EffectiveBalanceGwei)))
+		} else {
+			nonCompoundingRewardsWei = nonCompoundingRewardsWei.Add(validatorRewardsWei)
+			nonCompoundingEffectiveBalanceGwei = nonCompoundingEffectiveBalanceGwei.Add(decimal.NewFromInt(int64(v.EffectiveBalanceGwei)))
+		}
 
+		if lowMemory {
+			continue
+		}
 		ethstorePerValidator[uint64(index)] = &Day{
-			Day:                  decimal.NewFromInt(int64(day)),
-			DayTime:              startTime,
-			StartEpoch:           decimal.NewFromInt(int64(firstEpoch)),
-			Apr:                  decimal.NewFromInt(365).Mul(validatorRewardsWei).Div(decimal.NewFromInt(int64(v.EffectiveBalanceGwei)).Mul(decimal.NewFromInt(1e9))),
-			Validators:           decimal.NewFromInt(int64(len(validatorsByIndex))),
-			EffectiveBalanceGwei: decimal.NewFromInt(int64(v.EffectiveBalanceGwei)),
-			StartBalanceGwei:     decimal.NewFromInt(int64(v.StartBalanceGwei)),
-			EndBalanceGwei:       decimal.NewFromInt(int64(v.EndBalanceGwei)),
-			DepositsSumGwei:      decimal.NewFromInt(int64(v.DepositsSumGwei)),
-			TxFeesSumWei:         decimal.NewFromBigInt(v.TxFeesSumWei, 0),
-			ConsensusRewardsGwei: validatorConsensusRewardsGwei,
-			TotalRewardsWei:      validatorRewardsWei,
+			Day:                   decimal.NewFromInt(int64(day)),
+			DayTime:               startTime,
+			StartEpoch:            decimal.NewFromInt(int64(firstEpoch)),
+			Apr:                   validatorApr,
+			Validators:            decimal.NewFromInt(int64(len(validatorsByIndex))),
+			EffectiveBalanceGwei:  decimal.NewFromInt(int64(v.EffectiveBalanceGwei)),
+			StartBalanceGwei:      decimal.NewFromInt(int64(v.StartBalanceGwei)),
+			EndBalanceGwei:        decimal.NewFromInt(int64(v.EndBalanceGwei)),
+			DepositsSumGwei:       decimal.NewFromInt(int64(v.DepositsSumGwei)),
+			WithdrawalsSumGwei:    decimal.NewFromInt(int64(v.WithdrawalsSumGwei)),
+			TxFeesSumWei:          decimal.NewFromBigInt(v.TxFeesSumWei, 0),
+			ConsensusRewardsGwei:  validatorConsensusRewardsGwei,
+			ConsensusRewardsWei:   validatorConsensusRewardsWei,
+			TotalRewardsWei:       validatorRewardsWei,
+			ProposalsExpected:     validatorProposalsExpected,
+			ProposalsActual:       decimal.NewFromInt(int64(v.Proposals)),
+			ProposerLuck:          proposerLuck(v.Proposals, validatorProposalsExpected),
+			AprLowerBound:         validatorApr.Sub(validatorAprBand),
+			AprUpperBound:         validatorApr.Add(validatorAprBand),
+			Provenance:            provenance,
+			WithdrawalCredentials: fmt.Sprintf("%#x", v.WithdrawalCredentials),
+		}
+		ethstorePerValidator[uint64(index)].Pubkey = fmt.Sprintf("%#x", v.Pubkey)
+		ethstorePerValidator[uint64(index)].ConsensusBaselineGwei = validatorBaselineGwei
+		ethstorePerValidator[uint64(index)].ConsensusBaselineRatio = baselineRatio(validatorConsensusRewardsGwei, validatorBaselineGwei)
+		if attestationEffectiveness != nil {
+			e := decimal.NewFromFloat(attestationEffectiveness[index])
+			ethstorePerValidator[uint64(index)].AttestationEffectiveness = &e
+		}
+		if mev {
+			m := decimal.NewFromBigInt(v.MevRewardsWei, 0)
+			ethstorePerValidator[uint64(index)].MevRewardsWei = &m
 		}
 
 	}
 
-	totalConsensusRewardsGwei := decimal.NewFromInt(int64(totalEndBalanceGwei) - int64(totalStartBalanceGwei) - int64(totalDepositsSumGwei))
-	totalRewardsWei := decimal.NewFromBigInt(totalTxFeesSumWei, 0).Add(totalConsensusRewardsGwei.Mul(decimal.NewFromInt(1e9)))
+	totalConsensusRewardsGwei := decimal.NewFromInt(int64(totalEndBalanceGwei) - int64(totalStartBalanceGwei) - int64(totalDepositsSumGwei) + int64(totalWithdrawalsSumGwei))
+	totalConsensusRewardsWei := gweiToWei(totalConsensusRewardsGwei)
+	totalRewardsWei := decimal.NewFromBigInt(totalTxFeesSumWei, 0).Add(totalConsensusRewardsWei)
+	totalProposalsExpected := proposalSlots.Mul(decimal.NewFromInt(int64(totalEffectiveBalanceGwei))).Div(decimal.NewFromInt(int64(totalActiveEffectiveBalanceGwei)))
+	apr := decimal.NewFromInt(365).Mul(totalRewardsWei).Div(decimal.NewFromInt(int64(totalEffectiveBalanceGwei)).Mul(decimal.NewFromInt(1e9)))
+	aprBand := proposalAprBand(totalProposalsExpected, avgTxFeesPerProposalWei, decimal.NewFromInt(int64(totalEffectiveBalanceGwei)))
+	baselineGwei := baseline.expectedRewardsGwei(totalEffectiveBalanceGwei)
+	syncParticipation := decimal.Zero
+	if syncBits > 0 {
+		syncParticipation = decimal.NewFromInt(int64(syncBitsSet)).Div(decimal.NewFromInt(int64(syncBits)))
+	}
 
 	ethstoreDay := &Day{
-		Day:                  decimal.NewFromInt(int64(day)),
-		DayTime:              startTime,
-		StartEpoch:           decimal.NewFromInt(int64(firstEpoch)),
-		Apr:                  decimal.NewFromInt(365).Mul(totalRewardsWei).Div(decimal.NewFromInt(int64(totalEffectiveBalanceGwei)).Mul(decimal.NewFromInt(1e9))),
-		Validators:           decimal.NewFromInt(int64(len(validatorsByIndex))),
-		EffectiveBalanceGwei: decimal.NewFromInt(int64(totalEffectiveBalanceGwei)),
-		StartBalanceGwei:     decimal.NewFromInt(int64(totalStartBalanceGwei)),
-		EndBalanceGwei:       decimal.NewFromInt(int64(totalEndBalanceGwei)),
-		DepositsSumGwei:      decimal.NewFromInt(int64(totalDepositsSumGwei)),
-		TxFeesSumWei:         decimal.NewFromBigInt(totalTxFeesSumWei, 0),
-		ConsensusRewardsGwei: totalConsensusRewardsGwei,
-		TotalRewardsWei:      totalRewardsWei,
-	}
-
-	if GetDebugLevel() > 0 {
+		Day:                     decimal.NewFromInt(int64(day)),
+		DayTime:                 startTime,
+		StartEpoch:              decimal.NewFromInt(int64(firstEpoch)),
+		Window:                  window,
+		Apr:                     apr,
+		AprBps:                  aprBps(apr),
+		AprPercent:              aprPercent(apr),
+		Validators:              decimal.NewFromInt(int64(len(validatorsByIndex))),
+		EffectiveBalanceGwei:    decimal.NewFromInt(int64(totalEffectiveBalanceGwei)),
+		StartBalanceGwei:        decimal.NewFromInt(int64(totalStartBalanceGwei)),
+		EndBalanceGwei:          decimal.NewFromInt(int64(totalEndBalanceGwei)),
+		DepositsSumGwei:         decimal.NewFromInt(int64(totalDepositsSumGwei)),
+		WithdrawalsSumGwei:      decimal.NewFromInt(int64(totalWithdrawalsSumGwei)),
+		TxFeesSumWei:            decimal.NewFromBigInt(totalTxFeesSumWei, 0),
+		BurnedFeesWei:           decimal.NewFromBigInt(burnedFeesWei, 0),
+		ConsensusRewardsGwei:    totalConsensusRewardsGwei,
+		ConsensusRewardsWei:     totalConsensusRewardsWei,
+		TotalRewardsWei:         totalRewardsWei,
+		ProposalsExpected:       totalProposalsExpected,
+		ProposalsActual:         decimal.NewFromInt(int64(totalProposals)),
+		ProposerLuck:            proposerLuck(totalProposals, totalProposalsExpected),
+		ConsensusBaselineGwei:   baselineGwei,
+		ConsensusBaselineRatio:  baselineRatio(totalConsensusRewardsGwei, baselineGwei),
+		AprLowerBound:           apr.Sub(aprBand),
+		AprUpperBound:           apr.Add(aprBand),
+		ProposalTxFeesWei:       proposalTxFeesWei,
+		MissedSlots:             decimal.NewFromInt(int64(missedSlots)),
+		SyncParticipation:       syncParticipation,
+		CompositionStart:        compositionStart,
+		CompositionEnd:          compositionEnd,
+		ValidatorExits:          validatorExits,
+		InactivityLeak:          len(leakEpochs) > 0,
+		InactivityLeakEpochs:    decimal.NewFromInt(int64(len(leakEpochs))),
+		InactivityPenaltiesGwei: inactivityPenaltiesGwei,
+		BlobDiscrepancies:       blobDiscrepancies,
+		FeeRecipientMismatches:  feeRecipientMismatches,
+		GasStats:                gasStats,
+		Orphans:                 orphans,
+		Censorship:              censorship,
+		TxFeeBreakdown:          feeBreakdown,
+		Alerts:                  alerts,
+		EpochSeries:             epochRewards,
+		DepositMismatches:       depositMismatches,
+		ValidatorsCompounding:   decimal.NewFromInt(compoundingValidators),
+		AprNonCompounding:       groupApr(nonCompoundingRewardsWei, nonCompoundingEffectiveBalanceGwei),
+		Provenance:              provenance,
+		StartStateRoot:          fmt.Sprintf("%#x", startStateRoot),
+		EndStateRoot:            fmt.Sprintf("%#x", endStateRoot),
+		InputHash:               InputHash(day, methodology, startStateRoot, endStateRoot),
+	}
+
+	// the apr contributions of the validators add up to the apr of the day, validators whose contribution is below their
+	// share of the effective balance pulled the apr down
+	for _, d := range ethstorePerValidator {
+		c := decimal.NewFromInt(365).Mul(d.TotalRewardsWei).Div(decimal.NewFromInt(int64(totalEffectiveBalanceGwei)).Mul(decimal.NewFromInt(1e9)))
+		d.AprContribution = &c
+	}
+
+	if !electra {
+		aprCompounding := groupApr(compoundingRewardsWei, compoundingEffectiveBalanceGwei)
+		ethstoreDay.AprCompounding = &aprCompounding
+	}
+
+	if mev {
+		m := decimal.NewFromBigInt(totalMevRewardsWei, 0)
+		ethstoreDay.MevRewardsWei = &m
+	}
+
+	// the net issuance is the issuance of the consensus rewards of all validators of the network minus the burned base
+	// fees. It is unknown if only the balances of the eth.store validators at the end of the day are requested, and
+	// since electra, whose deposits are credited from the pending deposits of the state.
+	if !balancesOnly && !electra {
+		networkConsensusRewardsGwei := decimal.NewFromInt(int64(networkEndBalanceGwei) - int64(networkStartBalanceGwei) - int64(networkDepositsGwei) + int64(networkWithdrawalsGwei))
+		netIssuanceWei := gweiToWei(networkConsensusRewardsGwei).Sub(ethstoreDay.BurnedFeesWei)
+		ethstoreDay.NetIssuanceWei = &netIssuanceWei
+	}
+
+	if txDecodeErrors.Slots.IsPositive() {
+		log.Printf("day %v has transactions that could not be decoded: %v", day, txDecodeErrors)
+		ethstoreDay.TxDecodeErrors = txDecodeErrors
+	}
+
+	if attestationEffectiveness != nil && len(attestationEffectiveness) > 0 {
+		sum := 0.0
+		for _, e := range attestationEffectiveness {
+			sum += e
+		}
+		e := decimal.NewFromFloat(sum / float64(len(attestationEffectiveness)))
+		ethstoreDay.AttestationEffectiveness = &e
+	}
+
+	if err := checkInvariants(ethstoreDay, ethstorePerValidator); err != nil {
+		return nil, nil, err
+	}
+	if err := recordSpecHistory(day, specSnapshot); err != nil {
+		return nil, nil, err
+	}
+	// an apr outside of the sanity bounds is alerted right away, the day is only marked so that it is not published
+	if a := GetAprBounds().aprAlert(ethstoreDay); a != nil {
+		log.Printf("WARNING eth.store: day %v is anomalous: %v", day, a.Message)
+		anomalyAlerts := []Alert{*a}
+		deliverAlerts(ctx, anomalyAlerts)
+		ethstoreDay.Anomalous = true
+		ethstoreDay.Alerts = append(anomalyAlerts, ethstoreDay.Alerts...)
+	}
+
+	if options.getDebugLevel() > 0 {
 		log.Printf("DEBUG eth.store: %+v\n", ethstoreDay)
 	}
+	progress.finish(ethstoreDay)
 
 	return ethstoreDay, ethstorePerValidator, nil
 }
 
+// aprBps returns the apr in basis points (apr * 10000) rounded half away from zero to 2 decimal places.
+func aprBps(apr decimal.Decimal) decimal.Decimal {
+	return apr.Shift(4).Round(2)
+}
+
+// aprPercent returns the apr in percent (apr * 100) rounded half away from zero to 4 decimal places.
+func aprPercent(apr decimal.Decimal) decimal.Decimal {
+	return apr.Shift(2).Round(4)
+}
+
+// gweiToWei converts an amount of Gwei to Wei, fields in Wei that are derived from fields in Gwei must use it.
+func gweiToWei(gwei decimal.Decimal) decimal.Decimal {
+	return gwei.Mul(decimal.NewFromInt(1e9))
+}
+
+// groupApr returns the apr of a group of validators or 0 if the group is empty.
+func groupApr(rewardsWei, effectiveBalanceGwei decimal.Decimal) decimal.Decimal {
+	if effectiveBalanceGwei.IsZero() {
+		return decimal.Zero
+	}
+	return decimal.NewFromInt(365).Mul(rewardsWei).Div(effectiveBalanceGwei.Mul(decimal.NewFromInt(1e9)))
+}
+
+func proposerLuck(proposals uint64, proposalsExpected decimal.Decimal) decimal.Decimal {
+	if proposalsExpected.IsZero() {
+		return decimal.Zero
+	}
+	return decimal.NewFromInt(int64(proposals)).Div(proposalsExpected)
+}
+
+// proposalAprBand returns the half-width of the 95% confidence interval of the apr that is caused by the
+// variance of the number of proposals (standard deviation sqrt(proposalsExpected)).
+func proposalAprBand(proposalsExpected, avgTxFeesPerProposalWei, effectiveBalanceGwei decimal.Decimal) decimal.Decimal {
+	if effectiveBalanceGwei.IsZero() {
+		return decimal.Zero
+	}
+	stdDev := decimal.NewFromFloat(math.Sqrt(proposalsExpected.InexactFloat64()))
+	return decimal.NewFromFloat(1.96).Mul(stdDev).Mul(avgTxFeesPerProposalWei).Mul(decimal.NewFromInt(365)).Div(effectiveBalanceGwei.Mul(decimal.NewFromInt(1e9)))
+}
+
 func batchRequestReceipts(ctx context.Context, elClient *gethRPC.Client, txHashes []common.Hash) ([]*TxReceipt, error) {
 	elems := make([]gethRPC.BatchElem, 0, len(txHashes))
 	errors := make([]error, 0, len(txHashes))
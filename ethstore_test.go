@@ -3,12 +3,20 @@ package ethstore
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -39,14 +47,278 @@ func TestEthstore(t *testing.T) {
 	// - 32e18*29 = sumOfEffectiveBalances = 29 validators have each an effective balance of 32 eth at the start of the eth.store-day
 	// - 0.0621640625 = eth.store-apr = according to the eth.store-calculation validators will earn 6.22% interest in a year
 
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	// SetDebugLevel(1)
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 1)
+	if err != nil {
+		t.Error(err)
+	}
+
+	t.Logf("%+v", *day)
+
+	extraDepositsWei := decimal.NewFromInt(32e9).Mul(decimal.NewFromInt(1e9))
+	endWei := decimal.NewFromInt(29 * 320032e5).Mul(decimal.NewFromInt(1e9)).Add(extraDepositsWei)
+	startWei := decimal.NewFromInt(29 * 32e9).Mul(decimal.NewFromInt(1e9))
+	consWei := endWei.Sub(startWei).Sub(extraDepositsWei)
+	execWei := decimal.NewFromInt(29 * 10000 * 225).Mul(decimal.NewFromInt(1e9))
+	eff := decimal.NewFromInt(29 * 32e9).Mul(decimal.NewFromInt(1e9))
+	apr := decimal.NewFromInt(365).Mul(consWei.Add(execWei)).Div(eff)
+
+	if day.Day.String() != "10" {
+		t.Errorf("wrong Day: %v != %v", day.Day.String(), 10)
+	}
+	if !day.Apr.Equal(apr) {
+		t.Errorf("wrong Apr: %v != %v", day.Apr, apr)
+	}
+	if day.Validators.IntPart() != 29 {
+		t.Errorf("wrong Validators: %v != %v", day.Validators, 29)
+	}
+	if day.StartEpoch.IntPart() != 2250 {
+		t.Errorf("wrong StartEpoch: %v != %v", day.StartEpoch, 2250)
+	}
+	if !day.StartBalanceGwei.Equal(startWei.Div(decimal.NewFromInt(1e9))) {
+		t.Errorf("wrong StartBalanceGwei: %v != %v", day.StartBalanceGwei, startWei.Div(decimal.NewFromInt(1e9)))
+	}
+	if !day.EndBalanceGwei.Equal(endWei.Div(decimal.NewFromInt(1e9))) {
+		t.Errorf("wrong EndBalanceGwei: %v != %v", day.EndBalanceGwei, endWei.Div(decimal.NewFromInt(1e9)))
+	}
+	if !day.DepositsSumGwei.Equal(extraDepositsWei.Div(decimal.NewFromInt(1e9))) {
+		t.Errorf("wrong DepositsSumGwei: %v != %v", day.DepositsSumGwei, extraDepositsWei.Div(decimal.NewFromInt(1e9)))
+	}
+	if !day.ConsensusRewardsGwei.Equal(consWei.Div(decimal.NewFromInt(1e9))) {
+		t.Errorf("wrong ConsensusRewardsGwei: %v != %v", day.ConsensusRewardsGwei, 92800000)
+	}
+	if !day.ConsensusRewardsWei.Equal(consWei) {
+		t.Errorf("wrong ConsensusRewardsWei: %v != %v", day.ConsensusRewardsWei, consWei)
+	}
+	if !day.TxFeesSumWei.Equal(execWei) {
+		t.Errorf("wrong TxFeesSumWei: %v != %v", day.TxFeesSumWei, execWei)
+	}
+	if !day.TotalRewardsWei.Equal(consWei.Add(execWei)) {
+		t.Errorf("wrong TotalRewardsWei: %v != %v", day.TotalRewardsWei, consWei.Add(execWei))
+	}
+	// day 10 starts 10 days after the genesis at 2020-12-01 12:00:23 UTC
+	expectedWindow := &DayWindow{DayBoundary: "genesis", FirstSlot: 72000, LastSlot: 79199, FirstEpoch: 2250, LastEpoch: 2474, StartTime: time.Date(2020, 12, 11, 12, 0, 23, 0, time.UTC), EndTime: time.Date(2020, 12, 12, 12, 0, 23, 0, time.UTC), ExpectedBlocks: 7200}
+	if !reflect.DeepEqual(day.Window, expectedWindow) {
+		t.Errorf("wrong Window: %+v != %+v", day.Window, expectedWindow)
+	}
+	// 30 validators are active at the start of the day (indices 1 and 4 to 32), 29 of them are in the eth.store
+	// validator-set, so they are expected to propose 29/30 of the 7200 slots but only proposed 29*225 blocks
+	if !day.ProposalsExpected.Equal(decimal.NewFromInt(6960)) {
+		t.Errorf("wrong ProposalsExpected: %v != %v", day.ProposalsExpected, 6960)
+	}
+	if day.ProposalsActual.IntPart() != 29*225 {
+		t.Errorf("wrong ProposalsActual: %v != %v", day.ProposalsActual, 29*225)
+	}
+	if !day.ProposerLuck.Equal(decimal.NewFromFloat(0.9375)) {
+		t.Errorf("wrong ProposerLuck: %v != %v", day.ProposerLuck, 0.9375)
+	}
+	if day.Provenance == nil || day.Provenance.MethodologyVersion != MethodologyVersion || len(day.Provenance.SupportedForks) == 0 {
+		t.Errorf("wrong Provenance: %+v", day.Provenance)
+	}
+	if !day.MissedSlots.IsZero() {
+		t.Errorf("wrong MissedSlots: %v != %v", day.MissedSlots, 0)
+	}
+	if !day.AprLowerBound.LessThan(day.Apr) || !day.AprUpperBound.GreaterThan(day.Apr) {
+		t.Errorf("wrong apr confidence interval: %v - %v (apr: %v)", day.AprLowerBound, day.AprUpperBound, day.Apr)
+	}
+}
+
+func TestEthstoreCompounding(t *testing.T) {
+	// the scenario of TestEthstore with validators 5 and 6 as compounding validators that earned twice the consensus
+	// rewards during day 10
+	compoundingMocks := func(mocks map[string]string) {
+		for path, balance := range map[string]string{"/eth/v1/beacon/states/72000/validators": "", "/eth/v1/beacon/states/79200/validators": "32006400000"} {
+			var res struct {
+				Data []map[string]interface{} `json:"data"`
+			}
+			if err := json.Unmarshal([]byte(mocks[path]), &res); err != nil {
+				t.Fatal(err)
+			}
+			for i := 5; i <= 6; i++ {
+				res.Data[i]["validator"].(map[string]interface{})["withdrawal_credentials"] = fmt.Sprintf("0x02%062x", i)
+				if balance != "" {
+					res.Data[i]["balance"] = balance
+				}
+			}
+			data, err := json.Marshal(&res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mocks[path] = string(data)
+		}
+	}
+	bnServer, elServer := newEthstoreMockServersWithMocks(t, nil, compoundingMocks)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validatorExecWei := decimal.NewFromInt(10000 * 225).Mul(decimal.NewFromInt(1e9))
+	compoundingApr := decimal.NewFromInt(365).Mul(decimal.NewFromInt(64e5 * 1e9).Add(validatorExecWei)).Div(decimal.NewFromInt(32e9).Mul(decimal.NewFromInt(1e9)))
+	nonCompoundingApr := decimal.NewFromInt(365).Mul(decimal.NewFromInt(32e5 * 1e9).Add(validatorExecWei)).Div(decimal.NewFromInt(32e9).Mul(decimal.NewFromInt(1e9)))
+	if day.ValidatorsCompounding.IntPart() != 2 {
+		t.Errorf("wrong ValidatorsCompounding: %v != %v", day.ValidatorsCompounding, 2)
+	}
+	if day.AprCompounding == nil || !day.AprCompounding.Equal(compoundingApr) {
+		t.Errorf("wrong AprCompounding: %v != %v", day.AprCompounding, compoundingApr)
+	}
+	if !day.AprNonCompounding.Equal(nonCompoundingApr) {
+		t.Errorf("wrong AprNonCompounding: %v != %v", day.AprNonCompounding, nonCompoundingApr)
+	}
+	if day.Validators.IntPart() != 29 {
+		t.Errorf("wrong Validators: %v != %v", day.Validators, 29)
+	}
+
+	// electra deposits and consolidations are not part of the blocks, so the apr of compounding validators is unknown
+	electraServer, electraElServer := newEthstoreMockServersWithMocks(t, nil, func(mocks map[string]string) {
+		compoundingMocks(mocks)
+		mocks["/eth/v1/config/spec"] = strings.Replace(mocks["/eth/v1/config/spec"], `"SECONDS_PER_SLOT"`, `"ELECTRA_FORK_EPOCH":"0","SECONDS_PER_SLOT"`, 1)
+	})
+	defer electraServer.Close()
+	defer electraElServer.Close()
+	day, _, err = Calculate(context.Background(), electraServer.URL, electraElServer.URL, "10", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.AprCompounding != nil {
+		t.Errorf("unexpected AprCompounding of electra day: %v", day.AprCompounding)
+	}
+	if day.ValidatorsCompounding.IntPart() != 2 || !day.AprNonCompounding.Equal(nonCompoundingApr) {
+		t.Errorf("wrong compounding validators of electra day: %v, %v", day.ValidatorsCompounding, day.AprNonCompounding)
+	}
+}
+
+func TestCalculateCoalescesConcurrentRequests(t *testing.T) {
+	release := make(chan struct{})
+	var blockRequests uint64
+	bnServer, elServer := newEthstoreMockServers(t, func(r *http.Request) {
+		if r.URL.Path == "/eth/v1/beacon/genesis" {
+			<-release
+		}
+		if r.URL.Path == "/eth/v2/beacon/blocks/72000" {
+			atomic.AddUint64(&blockRequests, 1)
+		}
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	// a caller that stops waiting, e.g. the leader, does not cancel the calculation of the others
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, _, err := Calculate(ctx, bnServer.URL, elServer.URL, "10", 10)
+		cancelled <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	days := make([]*Day, 2)
+	wg := sync.WaitGroup{}
+	for i := range days {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+			if err != nil {
+				t.Error(err)
+			}
+			days[i] = day
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled for the cancelled caller: %v", err)
+	}
+	close(release)
+	wg.Wait()
+
+	if requests := atomic.LoadUint64(&blockRequests); requests != 1 {
+		t.Errorf("wrong number of requests for block 72000: %v != %v", requests, 1)
+	}
+	if days[0] == nil || days[1] == nil || days[0] == days[1] {
+		t.Fatalf("expected a copy of the result for every caller: %p, %p", days[0], days[1])
+	}
+	if !days[0].Apr.Equal(days[1].Apr) {
+		t.Errorf("different results: %v != %v", days[0].Apr, days[1].Apr)
+	}
+	if days[0].Window == days[1].Window || days[0].CompositionStart == days[1].CompositionStart {
+		t.Errorf("expected deep copies of the result: %p, %p", days[0].CompositionStart, days[1].CompositionStart)
+	}
+}
+
+func TestCalculateMinimalPreset(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+	mainnetDay, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the minimal preset has epochs of 8 slots and a sync committee of 32 members, with slots of 12 seconds (as kurtosis
+	// devnets run it) day 10 has the same slots as on mainnet but consists of the 900 epochs 9000-9899
+	syncBits := regexp.MustCompile(`"sync_committee_bits":"0x([0-9a-f]{8})[0-9a-f]*"`)
+	bnServer, elServer = newEthstoreMockServersWithMocks(t, nil, func(mocks map[string]string) {
+		spec := mocks["/eth/v1/config/spec"]
+		for _, r := range [][2]string{{`"CONFIG_NAME":"mainnet"`, `"CONFIG_NAME":"minimal"`}, {`"PRESET_BASE":"mainnet"`, `"PRESET_BASE":"minimal"`}, {`"SLOTS_PER_EPOCH":"32"`, `"SLOTS_PER_EPOCH":"8"`}, {`"SYNC_COMMITTEE_SIZE":"512"`, `"SYNC_COMMITTEE_SIZE":"32"`}} {
+			spec = strings.Replace(spec, r[0], r[1], 1)
+		}
+		mocks["/eth/v1/config/spec"] = spec
+		for path, mock := range mocks {
+			if strings.HasPrefix(path, "/eth/v2/beacon/blocks/") {
+				mocks[path] = syncBits.ReplaceAllString(mock, `"sync_committee_bits":"0x$1"`)
+			}
+		}
+		for e := 9000; e <= 9900; e++ {
+			mocks[fmt.Sprintf("/eth/v1/beacon/states/%d/finality_checkpoints", e*8)] = fmt.Sprintf(`{"data":{"previous_justified":{"epoch":"%[1]d","root":"0x0000000000000000000000000000000000000000000000000000000000000000"},"current_justified":{"epoch":"%[1]d","root":"0x0000000000000000000000000000000000000000000000000000000000000000"},"finalized":{"epoch":"%[1]d","root":"0x0000000000000000000000000000000000000000000000000000000000000000"}}}`, e-2)
+		}
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.StartEpoch.IntPart() != 9000 {
+		t.Errorf("wrong StartEpoch: %v != %v", day.StartEpoch, 9000)
+	}
+	if !day.Apr.Equal(mainnetDay.Apr) || !day.Validators.Equal(mainnetDay.Validators) {
+		t.Errorf("wrong results: apr: %v != %v, validators: %v != %v", day.Apr, mainnetDay.Apr, day.Validators, mainnetDay.Validators)
+	}
+	// 22 of the 32 bits of 0xf74edf53 are set
+	if !day.SyncParticipation.Equal(decimal.NewFromFloat(0.6875)) {
+		t.Errorf("wrong SyncParticipation: %v != %v", day.SyncParticipation, 0.6875)
+	}
+	if day.InactivityLeak {
+		t.Errorf("wrong InactivityLeak: %v != %v", day.InactivityLeak, false)
+	}
+}
+
+// newEthstoreMockServers returns a beacon node and an execution node that serve the scenario described in TestEthstore,
+// onRequest is called for every request to the beacon node.
+func newEthstoreMockServers(t *testing.T, onRequest func(r *http.Request)) (bnServer, elServer *httptest.Server) {
+	return newEthstoreMockServersWithMocks(t, onRequest, nil)
+}
+
+// newEthstoreMockServersWithMocks is newEthstoreMockServers with a function that modifies the responses of the beacon
+// node keyed by path before the servers start.
+func newEthstoreMockServersWithMocks(t *testing.T, onRequest func(r *http.Request), modify func(mocks map[string]string)) (bnServer, elServer *httptest.Server) {
 	mocks := map[string]string{
-		"/eth/v1/beacon/genesis":           `{"data":{"genesis_time":"1606824023","genesis_validators_root":"0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95","genesis_fork_version":"0x00000000"}}`,
-		"/eth/v1/beacon/headers/finalized": `{"data":{"root":"0x3aee29bcfa7a9fdf01394a3dce74ae063c89023df71867ad1555f1e494d138ee","canonical":true,"header":{"message":{"slot":"4485760","proposer_index":"44643","parent_root":"0x4a451b6a4962bcbd619ee1f0b6a7d85dded49f049877de325122e21350e5d6f2","state_root":"0xf12219d8bcdb7ed125da01e4f7aa30754bff2c9fc0bf57dd728c0b02bb847a92","body_root":"0x31f4433e6e260a0fac6e80ad3f9df1998fbbab269408601a6da7a5d32ccbb258"},"signature":"0x8ccb90ff41ec1f82975fb12384f3d44194b27403f1454e878e9c07c9951df33968556e2ce0dfb8ce42e2e0bbac8c80e211d35d01617712292805bc8d9ac2e3429f821953cfc1dbb9d9ea359cd37b39850f4e29c81fc3d67e150985c609d4e826"}}}`,
-		"/eth/v1/config/spec":              `{"data":{"CONFIG_NAME":"mainnet","PRESET_BASE":"mainnet","TERMINAL_TOTAL_DIFFICULTY":"115792089237316195423570985008687907853269984665640564039457584007913129638912","TERMINAL_BLOCK_HASH":"0x0000000000000000000000000000000000000000000000000000000000000000","TERMINAL_BLOCK_HASH_ACTIVATION_EPOCH":"18446744073709551615","SAFE_SLOTS_TO_IMPORT_OPTIMISTICALLY":"128","MIN_GENESIS_ACTIVE_VALIDATOR_COUNT":"16384","MIN_GENESIS_TIME":"1606824000","GENESIS_FORK_VERSION":"0x00000000","GENESIS_DELAY":"604800","ALTAIR_FORK_VERSION":"0x01000000","ALTAIR_FORK_EPOCH":"74240","BELLATRIX_FORK_VERSION":"0x02000000","BELLATRIX_FORK_EPOCH":"18446744073709551615","SECONDS_PER_SLOT":"12","SECONDS_PER_ETH1_BLOCK":"14","MIN_VALIDATOR_WITHDRAWABILITY_DELAY":"256","SHARD_COMMITTEE_PERIOD":"256","ETH1_FOLLOW_DISTANCE":"2048","INACTIVITY_SCORE_BIAS":"4","INACTIVITY_SCORE_RECOVERY_RATE":"16","EJECTION_BALANCE":"16000000000","MIN_PER_EPOCH_CHURN_LIMIT":"4","CHURN_LIMIT_QUOTIENT":"65536","PROPOSER_SCORE_BOOST":"40","DEPOSIT_CHAIN_ID":"1","DEPOSIT_NETWORK_ID":"1","DEPOSIT_CONTRACT_ADDRESS":"0x00000000219ab540356cbb839cbe05303d7705fa","MAX_COMMITTEES_PER_SLOT":"64","TARGET_COMMITTEE_SIZE":"128","MAX_VALIDATORS_PER_COMMITTEE":"2048","SHUFFLE_ROUND_COUNT":"90","HYSTERESIS_QUOTIENT":"4","HYSTERESIS_DOWNWARD_MULTIPLIER":"1","HYSTERESIS_UPWARD_MULTIPLIER":"5","SAFE_SLOTS_TO_UPDATE_JUSTIFIED":"8","MIN_DEPOSIT_AMOUNT":"1000000000","MAX_EFFECTIVE_BALANCE":"32000000000","EFFECTIVE_BALANCE_INCREMENT":"1000000000","MIN_ATTESTATION_INCLUSION_DELAY":"1","SLOTS_PER_EPOCH":"32","MIN_SEED_LOOKAHEAD":"1","MAX_SEED_LOOKAHEAD":"4","EPOCHS_PER_ETH1_VOTING_PERIOD":"64","SLOTS_PER_HISTORICAL_ROOT":"8192","MIN_EPOCHS_TO_INACTIVITY_PENALTY":"4","EPOCHS_PER_HISTORICAL_VECTOR":"65536","EPOCHS_PER_SLASHINGS_VECTOR":"8192","HISTORICAL_ROOTS_LIMIT":"16777216","VALIDATOR_REGISTRY_LIMIT":"1099511627776","BASE_REWARD_FACTOR":"64","WHISTLEBLOWER_REWARD_QUOTIENT":"512","PROPOSER_REWARD_QUOTIENT":"8","INACTIVITY_PENALTY_QUOTIENT":"67108864","MIN_SLASHING_PENALTY_QUOTIENT":"128","PROPORTIONAL_SLASHING_MULTIPLIER":"1","MAX_PROPOSER_SLASHINGS":"16","MAX_ATTESTER_SLASHINGS":"2","MAX_ATTESTATIONS":"128","MAX_DEPOSITS":"16","MAX_VOLUNTARY_EXITS":"16","INACTIVITY_PENALTY_QUOTIENT_ALTAIR":"50331648","MIN_SLASHING_PENALTY_QUOTIENT_ALTAIR":"64","PROPORTIONAL_SLASHING_MULTIPLIER_ALTAIR":"2","SYNC_COMMITTEE_SIZE":"512","EPOCHS_PER_SYNC_COMMITTEE_PERIOD":"256","MIN_SYNC_COMMITTEE_PARTICIPANTS":"1","RANDOM_SUBNETS_PER_VALIDATOR":"1","EPOCHS_PER_RANDOM_SUBNET_SUBSCRIPTION":"256","DOMAIN_DEPOSIT":"0x03000000","DOMAIN_SELECTION_PROOF":"0x05000000","DOMAIN_BEACON_ATTESTER":"0x01000000","BLS_WITHDRAWAL_PREFIX":"0x00","TARGET_AGGREGATORS_PER_COMMITTEE":"16","DOMAIN_BEACON_PROPOSER":"0x00000000","DOMAIN_VOLUNTARY_EXIT":"0x04000000","DOMAIN_RANDAO":"0x02000000","DOMAIN_AGGREGATE_AND_PROOF":"0x06000000"}}`,
-		"/eth/v1/config/deposit_contract":  `{"data":{"chain_id":"1","address":"0x00000000219ab540356cbb839cbe05303d7705fa"}}`,
-		"/eth/v1/config/fork_schedule":     `{"data":[{"previous_version":"0x00000000","current_version":"0x00000000","epoch":"0"},{"previous_version":"0x00000000","current_version":"0x01000000","epoch":"74240"}]}`,
-		"/eth/v1/node/version":             `{"data":{"version":"Lighthouse/v2.3.1-564d7da/x86_64-linux"}}`,
-		"/eth/v2/beacon/blocks/0":          `{"version":"phase0","data":{"message":{"slot":"0","proposer_index":"0","parent_root":"0x0000000000000000000000000000000000000000000000000000000000000000","state_root":"0x7e76880eb67bbdc86250aa578958e9d0675e64e714337855204fb5abaaf82c2b","body":{"randao_reveal":"0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","eth1_data":{"deposit_root":"0x0000000000000000000000000000000000000000000000000000000000000000","deposit_count":"0","block_hash":"0x0000000000000000000000000000000000000000000000000000000000000000"},"graffiti":"0x0000000000000000000000000000000000000000000000000000000000000000","proposer_slashings":[],"attester_slashings":[],"attestations":[],"deposits":[],"voluntary_exits":[]}},"signature":"0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"}}`,
+		"/eth/v1/beacon/genesis":                          `{"data":{"genesis_time":"1606824023","genesis_validators_root":"0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95","genesis_fork_version":"0x00000000"}}`,
+		"/eth/v1/beacon/headers/finalized":                `{"data":{"root":"0x3aee29bcfa7a9fdf01394a3dce74ae063c89023df71867ad1555f1e494d138ee","canonical":true,"header":{"message":{"slot":"4485760","proposer_index":"44643","parent_root":"0x4a451b6a4962bcbd619ee1f0b6a7d85dded49f049877de325122e21350e5d6f2","state_root":"0xf12219d8bcdb7ed125da01e4f7aa30754bff2c9fc0bf57dd728c0b02bb847a92","body_root":"0x31f4433e6e260a0fac6e80ad3f9df1998fbbab269408601a6da7a5d32ccbb258"},"signature":"0x8ccb90ff41ec1f82975fb12384f3d44194b27403f1454e878e9c07c9951df33968556e2ce0dfb8ce42e2e0bbac8c80e211d35d01617712292805bc8d9ac2e3429f821953cfc1dbb9d9ea359cd37b39850f4e29c81fc3d67e150985c609d4e826"}}}`,
+		"/eth/v1/beacon/states/head/finality_checkpoints": `{"data":{"previous_justified":{"epoch":"140181","root":"0x0000000000000000000000000000000000000000000000000000000000000000"},"current_justified":{"epoch":"140182","root":"0x0000000000000000000000000000000000000000000000000000000000000000"},"finalized":{"epoch":"140180","root":"0x3aee29bcfa7a9fdf01394a3dce74ae063c89023df71867ad1555f1e494d138ee"}}}`,
+		"/eth/v1/config/spec":                             `{"data":{"CONFIG_NAME":"mainnet","PRESET_BASE":"mainnet","TERMINAL_TOTAL_DIFFICULTY":"115792089237316195423570985008687907853269984665640564039457584007913129638912","TERMINAL_BLOCK_HASH":"0x0000000000000000000000000000000000000000000000000000000000000000","TERMINAL_BLOCK_HASH_ACTIVATION_EPOCH":"18446744073709551615","SAFE_SLOTS_TO_IMPORT_OPTIMISTICALLY":"128","MIN_GENESIS_ACTIVE_VALIDATOR_COUNT":"16384","MIN_GENESIS_TIME":"1606824000","GENESIS_FORK_VERSION":"0x00000000","GENESIS_DELAY":"604800","ALTAIR_FORK_VERSION":"0x01000000","ALTAIR_FORK_EPOCH":"74240","BELLATRIX_FORK_VERSION":"0x02000000","BELLATRIX_FORK_EPOCH":"18446744073709551615","SECONDS_PER_SLOT":"12","SECONDS_PER_ETH1_BLOCK":"14","MIN_VALIDATOR_WITHDRAWABILITY_DELAY":"256","SHARD_COMMITTEE_PERIOD":"256","ETH1_FOLLOW_DISTANCE":"2048","INACTIVITY_SCORE_BIAS":"4","INACTIVITY_SCORE_RECOVERY_RATE":"16","EJECTION_BALANCE":"16000000000","MIN_PER_EPOCH_CHURN_LIMIT":"4","CHURN_LIMIT_QUOTIENT":"65536","PROPOSER_SCORE_BOOST":"40","DEPOSIT_CHAIN_ID":"1","DEPOSIT_NETWORK_ID":"1","DEPOSIT_CONTRACT_ADDRESS":"0x00000000219ab540356cbb839cbe05303d7705fa","MAX_COMMITTEES_PER_SLOT":"64","TARGET_COMMITTEE_SIZE":"128","MAX_VALIDATORS_PER_COMMITTEE":"2048","SHUFFLE_ROUND_COUNT":"90","HYSTERESIS_QUOTIENT":"4","HYSTERESIS_DOWNWARD_MULTIPLIER":"1","HYSTERESIS_UPWARD_MULTIPLIER":"5","SAFE_SLOTS_TO_UPDATE_JUSTIFIED":"8","MIN_DEPOSIT_AMOUNT":"1000000000","MAX_EFFECTIVE_BALANCE":"32000000000","EFFECTIVE_BALANCE_INCREMENT":"1000000000","MIN_ATTESTATION_INCLUSION_DELAY":"1","SLOTS_PER_EPOCH":"32","MIN_SEED_LOOKAHEAD":"1","MAX_SEED_LOOKAHEAD":"4","EPOCHS_PER_ETH1_VOTING_PERIOD":"64","SLOTS_PER_HISTORICAL_ROOT":"8192","MIN_EPOCHS_TO_INACTIVITY_PENALTY":"4","EPOCHS_PER_HISTORICAL_VECTOR":"65536","EPOCHS_PER_SLASHINGS_VECTOR":"8192","HISTORICAL_ROOTS_LIMIT":"16777216","VALIDATOR_REGISTRY_LIMIT":"1099511627776","BASE_REWARD_FACTOR":"64","WHISTLEBLOWER_REWARD_QUOTIENT":"512","PROPOSER_REWARD_QUOTIENT":"8","INACTIVITY_PENALTY_QUOTIENT":"67108864","MIN_SLASHING_PENALTY_QUOTIENT":"128","PROPORTIONAL_SLASHING_MULTIPLIER":"1","MAX_PROPOSER_SLASHINGS":"16","MAX_ATTESTER_SLASHINGS":"2","MAX_ATTESTATIONS":"128","MAX_DEPOSITS":"16","MAX_VOLUNTARY_EXITS":"16","INACTIVITY_PENALTY_QUOTIENT_ALTAIR":"50331648","MIN_SLASHING_PENALTY_QUOTIENT_ALTAIR":"64","PROPORTIONAL_SLASHING_MULTIPLIER_ALTAIR":"2","SYNC_COMMITTEE_SIZE":"512","EPOCHS_PER_SYNC_COMMITTEE_PERIOD":"256","MIN_SYNC_COMMITTEE_PARTICIPANTS":"1","RANDOM_SUBNETS_PER_VALIDATOR":"1","EPOCHS_PER_RANDOM_SUBNET_SUBSCRIPTION":"256","DOMAIN_DEPOSIT":"0x03000000","DOMAIN_SELECTION_PROOF":"0x05000000","DOMAIN_BEACON_ATTESTER":"0x01000000","BLS_WITHDRAWAL_PREFIX":"0x00","TARGET_AGGREGATORS_PER_COMMITTEE":"16","DOMAIN_BEACON_PROPOSER":"0x00000000","DOMAIN_VOLUNTARY_EXIT":"0x04000000","DOMAIN_RANDAO":"0x02000000","DOMAIN_AGGREGATE_AND_PROOF":"0x06000000"}}`,
+		"/eth/v1/config/deposit_contract":                 `{"data":{"chain_id":"1","address":"0x00000000219ab540356cbb839cbe05303d7705fa"}}`,
+		"/eth/v1/config/fork_schedule":                    `{"data":[{"previous_version":"0x00000000","current_version":"0x00000000","epoch":"0"},{"previous_version":"0x00000000","current_version":"0x01000000","epoch":"74240"}]}`,
+		"/eth/v1/node/syncing":                            `{"data":{"head_slot":"80000","sync_distance":"0","is_syncing":false,"is_optimistic":false,"el_offline":false}}`,
+		"/eth/v1/node/version":                            `{"data":{"version":"Lighthouse/v2.3.1-564d7da/x86_64-linux"}}`,
+		"/eth/v2/beacon/blocks/0":                         `{"version":"phase0","data":{"message":{"slot":"0","proposer_index":"0","parent_root":"0x0000000000000000000000000000000000000000000000000000000000000000","state_root":"0x7e76880eb67bbdc86250aa578958e9d0675e64e714337855204fb5abaaf82c2b","body":{"randao_reveal":"0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","eth1_data":{"deposit_root":"0x0000000000000000000000000000000000000000000000000000000000000000","deposit_count":"0","block_hash":"0x0000000000000000000000000000000000000000000000000000000000000000"},"graffiti":"0x0000000000000000000000000000000000000000000000000000000000000000","proposer_slashings":[],"attester_slashings":[],"attestations":[],"deposits":[],"voluntary_exits":[]}},"signature":"0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"}}`,
 	}
 
 	type MockValidator struct {
@@ -144,6 +416,39 @@ func TestEthstore(t *testing.T) {
 
 	mocks["/eth/v1/beacon/states/72000/validators"] = string(mockStartValidatorsJson)
 	mocks["/eth/v1/beacon/states/79200/validators"] = string(mockEndValidatorsJson)
+	endBalances := make([]string, numValis)
+	for i := 0; i < numValis; i++ {
+		endBalances[i] = fmt.Sprintf(`{"index":"%d","balance":"%s"}`, i, mockEndValidators.Data[i].Balance)
+	}
+	mocks["/eth/v1/beacon/states/79200/validator_balances"] = fmt.Sprintf(`{"data":[%s]}`, strings.Join(endBalances, ","))
+	mocks["/eth/v1/beacon/states/72000/root"] = `{"data":{"root":"0x3c900df8e277bade69a1c29a93f9442940fc5e43a96c60dfc33d0f0a54a73af6"}}`
+	mocks["/eth/v1/beacon/states/79200/root"] = `{"data":{"root":"0x65ff6f9be55e066f1ed9f5f899752e174c31793034260389316c0ae897483512"}}`
+
+	// the balances of the validators at the epochs of day 10 grow linearly from their start to their end balance,
+	// the deposit of validator 4 is included in the first epoch
+	for k := 1; k < 225; k++ {
+		balances := make([]string, numValis)
+		for i := 0; i < numValis; i++ {
+			start, _ := strconv.ParseInt(mockStartValidators.Data[i].Balance, 10, 64)
+			end, _ := strconv.ParseInt(mockEndValidators.Data[i].Balance, 10, 64)
+			deposit := int64(0)
+			if i == 4 {
+				deposit = 32e9
+			}
+			balances[i] = fmt.Sprintf(`{"index":"%d","balance":"%d"}`, i, start+deposit+(end-start-deposit)*int64(k)/225)
+		}
+		mocks[fmt.Sprintf("/eth/v1/beacon/states/%d/validator_balances", 72000+k*32)] = fmt.Sprintf(`{"data":[%s]}`, strings.Join(balances, ","))
+	}
+
+	// finality stalled between epoch 2297 and 2309 which results in an inactivity leak during epochs 2301 to 2309, the
+	// finality of the first epoch of day 11 is the finality at the end of day 10
+	for e := 10 * 225; e <= 11*225; e++ {
+		finalizedEpoch := e - 2
+		if e >= 2297 && e <= 2309 {
+			finalizedEpoch = 2295
+		}
+		mocks[fmt.Sprintf("/eth/v1/beacon/states/%d/finality_checkpoints", e*32)] = fmt.Sprintf(`{"data":{"previous_justified":{"epoch":"%[1]d","root":"0x0000000000000000000000000000000000000000000000000000000000000000"},"current_justified":{"epoch":"%[1]d","root":"0x0000000000000000000000000000000000000000000000000000000000000000"},"finalized":{"epoch":"%[1]d","root":"0x0000000000000000000000000000000000000000000000000000000000000000"}}}`, finalizedEpoch)
+	}
 
 	validator4DidExtraDeposit := false
 	for i := 10 * 225 * 32; i < 11*225*32; i++ {
@@ -197,8 +502,15 @@ func TestEthstore(t *testing.T) {
 		mocks[fmt.Sprintf("/eth/v2/beacon/blocks/%d", i)] = fmt.Sprintf(`{"version":"bellatrix","data":{"message":{"slot":"%d","proposer_index":"%d","parent_root":"0xae77f6e0db57769b5ec6c16c4ef7489ddd47728d98297833b5a1692afc5072cb","state_root":"0x3c900df8e277bade69a1c29a93f9442940fc5e43a96c60dfc33d0f0a54a73af6","body":{"randao_reveal":"0x886b31ed2d6caead1e6632dcaec7edb113789f81dbc101160f903ad72c01429203c15ae75e00bd6987ca5ec79750f9c6040a7805284b24f5b3fa8131579c743e592033de069345ccb4b9a99fd73712d8b2276791847282dbfb7634fcb050ae80","eth1_data":{"deposit_root":"0x9df92d765b5aa041fd4bbe8d5878eb89290efa78e444c1a603eecfae2ea05fa4","deposit_count":"403","block_hash":"0x4d0d1732d9a72d2127ab2ad120e66da738cab3369239ec9debd7aea3b89f9812"},"graffiti":"0x0000000000000000000000000000000000000000000000000000000000000000","proposer_slashings":[],"attester_slashings":[],"attestations":[{"aggregation_bits":"0xf7fa6fffbcbbbf6f","data":{"slot":"357843","index":"0","beacon_block_root":"0xae77f6e0db57769b5ec6c16c4ef7489ddd47728d98297833b5a1692afc5072cb","source":{"epoch":"11181","root":"0xa0d0f93cc58e7e0a6b08c600d2a8054dc41fbadd8aba116e6e8cb1a1870321d0"},"target":{"epoch":"11182","root":"0x82cf146d63ea46194fb6ea4e2c99b244aea76cf8c6546ae09a749a0406d78823"}},"signature":"0xad7d675b775c89fb5c1605f1c91bb595e4feb0a2a0440b23aacfbc6d95daa02e761e8ad48a6cf0dd041d65250a97bf1200e879212f389173cdb2c5792d977411aa44f62eb79e71447f00f2eb02c3aacb4fdc4e939a5d7d01a2198ccdb758b641"}],"deposits":%s,"voluntary_exits":[],"sync_aggregate":{"sync_committee_bits":"0xf74edf53ffdb7f7f7db76efef7fcfb6eff7ffeffbff7f7fddf3f57f7d7fff1b7b7fb3e7bffffff5afe7fffff7fcb437fdffee3efd6dff76df766ffffd7fffff1","sync_committee_signature":"0x98fef94f6488bcb1d1c47517e28683d280c36cfd3caa37403e40a72b0500de7ce84f234760edc17a2bd1031db194570d17af1eb253d4d117f88b39e30ee0ab7c00db268db8369188600a9665708ddd34701840ca1bc1b3c646641b60eda2019d"},"execution_payload":{"parent_hash":"0xca7e7e7fcf3ef35a569c1647d56b11873664e3972d17c5dc339af901230166d5","fee_recipient":"0x8b0c2c4c8eb078bc6c01f48523764c8942c0c6c4","state_root":"0x65ff6f9be55e066f1ed9f5f899752e174c31793034260389316c0ae897483512","receipts_root":"0x1544df33845496bdab8cb97867ec0c6e060ed6690e54c85ae4cb9cc58ddc00dd","logs_bloom":"0x08000000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000200000000000000000000000000004000000000000001002000000000000001000000000000000000000000000000020000000100000000000800000000000000000000000000000000080000000000000000000000000000000000000480000000008000000000000000000000001040000000000000000000000000000000000000000000000000000000000000000000000400000000000000004000000001000000000000000020000000000000000000000000000000000000000000000000000000000000000010","prev_randao":"0x3c3397f7c670538c30a11f6c5733e66af09f9a34ab0ef31b0ffa63314b79099f","block_number":"1663387","gas_limit":"30000000","gas_used":"230800","timestamp":"1660027728","extra_data":"0x","base_fee_per_gas":"10","block_hash":"0x8145108c4ba0bd6507019ee9ef1eaa225daa0fd220bfea44f5e1d3b58c313875","transactions":["%#x"]}}},"signature":"0x8b0c109f0148cd7979bc8101f35e909c8b24e08fbfb0a36491270f2d3889c08b71ab83f59f005eff75272627e569f2d91769524dd5790f918955315534e245ad65423fe45f6fb749d9d4cc593c6f56388eef6c5b123b0f7cb526cbdf7fa053c8"}}`, i, proposer, deposits, createTx(txFeeGweiPerBlock))
 	}
 
-	bnServer := httptest.NewServer(
+	if modify != nil {
+		modify(mocks)
+	}
+
+	bnServer = httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if onRequest != nil {
+				onRequest(r)
+			}
 			mock, exists := mocks[r.URL.Path]
 			if !exists {
 				t.Errorf("mock does not exist for request: %v", r.URL.Path)
@@ -206,9 +518,8 @@ func TestEthstore(t *testing.T) {
 			w.Write([]byte(mock))
 		}),
 	)
-	defer bnServer.Close()
 
-	elServer := httptest.NewServer(
+	elServer = httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			effectiveGasPrice := hexutil.EncodeUint64(100)
 			gasUsed := hexutil.EncodeUint64(1e11 + 23080)
@@ -216,54 +527,7 @@ func TestEthstore(t *testing.T) {
 			w.Write(d)
 		}),
 	)
-	defer elServer.Close()
-
-	// SetDebugLevel(1)
-	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 1)
-	if err != nil {
-		t.Error(err)
-	}
-
-	t.Logf("%+v", *day)
-
-	extraDepositsWei := decimal.NewFromInt(32e9).Mul(decimal.NewFromInt(1e9))
-	endWei := decimal.NewFromInt(29 * 320032e5).Mul(decimal.NewFromInt(1e9)).Add(extraDepositsWei)
-	startWei := decimal.NewFromInt(29 * 32e9).Mul(decimal.NewFromInt(1e9))
-	consWei := endWei.Sub(startWei).Sub(extraDepositsWei)
-	execWei := decimal.NewFromInt(29 * 10000 * 225).Mul(decimal.NewFromInt(1e9))
-	eff := decimal.NewFromInt(29 * 32e9).Mul(decimal.NewFromInt(1e9))
-	apr := decimal.NewFromInt(365).Mul(consWei.Add(execWei)).Div(eff)
-
-	if day.Day.String() != "10" {
-		t.Errorf("wrong Day: %v != %v", day.Day.String(), 10)
-	}
-	if !day.Apr.Equal(apr) {
-		t.Errorf("wrong Apr: %v != %v", day.Apr, apr)
-	}
-	if day.Validators.IntPart() != 29 {
-		t.Errorf("wrong Validators: %v != %v", day.Validators, 29)
-	}
-	if day.StartEpoch.IntPart() != 2250 {
-		t.Errorf("wrong StartEpoch: %v != %v", day.StartEpoch, 2250)
-	}
-	if !day.StartBalanceGwei.Equal(startWei.Div(decimal.NewFromInt(1e9))) {
-		t.Errorf("wrong StartBalanceGwei: %v != %v", day.StartBalanceGwei, startWei.Div(decimal.NewFromInt(1e9)))
-	}
-	if !day.EndBalanceGwei.Equal(endWei.Div(decimal.NewFromInt(1e9))) {
-		t.Errorf("wrong EndBalanceGwei: %v != %v", day.EndBalanceGwei, endWei.Div(decimal.NewFromInt(1e9)))
-	}
-	if !day.DepositsSumGwei.Equal(extraDepositsWei.Div(decimal.NewFromInt(1e9))) {
-		t.Errorf("wrong DepositsSumGwei: %v != %v", day.DepositsSumGwei, extraDepositsWei.Div(decimal.NewFromInt(1e9)))
-	}
-	if !day.ConsensusRewardsGwei.Equal(consWei.Div(decimal.NewFromInt(1e9))) {
-		t.Errorf("wrong ConsensusRewardsGwei: %v != %v", day.ConsensusRewardsGwei, 92800000)
-	}
-	if !day.TxFeesSumWei.Equal(execWei) {
-		t.Errorf("wrong TxFeesSumWei: %v != %v", day.TxFeesSumWei, execWei)
-	}
-	if !day.TotalRewardsWei.Equal(consWei.Add(execWei)) {
-		t.Errorf("wrong TotalRewardsWei: %v != %v", day.TotalRewardsWei, consWei.Add(execWei))
-	}
+	return bnServer, elServer
 }
 
 func createTx(feeGwei uint64) []byte {
@@ -287,3 +551,119 @@ func createTx(feeGwei uint64) []byte {
 	}
 	return b
 }
+
+func TestAprConvenienceFields(t *testing.T) {
+	tests := []struct {
+		apr     string
+		bps     string
+		percent string
+	}{
+		{"0.0621640625", "621.64", "6.2164"},
+		{"0.04123455", "412.35", "4.1235"},
+		{"0.041234449", "412.34", "4.1234"},
+		{"-0.00001235", "-0.12", "-0.0012"},
+		{"0", "0", "0"},
+	}
+	for _, tt := range tests {
+		apr := decimal.RequireFromString(tt.apr)
+		if bps := aprBps(apr); !bps.Equal(decimal.RequireFromString(tt.bps)) {
+			t.Errorf("wrong aprBps of %v: %v != %v", tt.apr, bps, tt.bps)
+		}
+		if percent := aprPercent(apr); !percent.Equal(decimal.RequireFromString(tt.percent)) {
+			t.Errorf("wrong aprPercent of %v: %v != %v", tt.apr, percent, tt.percent)
+		}
+	}
+}
+
+func TestSyncParticipation(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all mocked blocks have the same sync aggregate with 415 of 512 bits set
+	if expected := decimal.NewFromInt(415).Div(decimal.NewFromInt(512)); !day.SyncParticipation.Equal(expected) {
+		t.Errorf("wrong sync participation: %v != %v", day.SyncParticipation, expected)
+	}
+}
+
+func TestBurnedFees(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all mocked blocks use 230800 gas at a base fee of 10 wei, the base fees of all blocks are burned
+	blocks := decimal.NewFromInt(7200).Sub(day.MissedSlots)
+	if expected := blocks.Mul(decimal.NewFromInt(230800 * 10)); !day.BurnedFeesWei.Equal(expected) {
+		t.Errorf("wrong burned fees: %v != %v", day.BurnedFeesWei, expected)
+	}
+	// the net issuance includes the validators that are not eth.store validators, the 32 validators that did not exit
+	// before the day earn 3200000 gwei each
+	if expected := decimal.NewFromInt(32 * 3200000 * 1e9).Sub(day.BurnedFeesWei); day.NetIssuanceWei == nil || !day.NetIssuanceWei.Equal(expected) {
+		t.Errorf("wrong net issuance: %v != %v", day.NetIssuanceWei, expected)
+	}
+}
+
+func TestGasStats(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, validatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := day.GasStats
+	if g == nil {
+		t.Fatal("no gas stats")
+	}
+	// all mocked blocks use 230800 of 30000000 gas at a base fee of 10 wei
+	blocks := decimal.NewFromInt(7200).Sub(day.MissedSlots)
+	if !g.Blocks.Equal(blocks) {
+		t.Errorf("wrong blocks: %v != %v", g.Blocks, blocks)
+	}
+	if expected := blocks.Mul(decimal.NewFromInt(230800)); !g.GasUsed.Equal(expected) {
+		t.Errorf("wrong gas used: %v != %v", g.GasUsed, expected)
+	}
+	if expected := decimal.NewFromInt(230800).Div(decimal.NewFromInt(30000000)); !g.GasUtilization.Equal(expected) {
+		t.Errorf("wrong gas utilization: %v != %v", g.GasUtilization, expected)
+	}
+	if !g.AvgBaseFeePerGasWei.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("wrong average base fee: %v", g.AvgBaseFeePerGasWei)
+	}
+	for index, d := range validatorDays {
+		if d.GasStats != nil {
+			t.Errorf("unexpected gas stats for validator %v", index)
+		}
+	}
+}
+
+func TestCalculateConcurrency(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	// the blocks finish in a different order with every concurrency, the result must not depend on it
+	var expected *Day
+	for _, concurrency := range []int{0, 1, 64} {
+		day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", concurrency)
+		if err != nil {
+			t.Fatalf("concurrency %v: %v", concurrency, err)
+		}
+		day.Provenance = nil
+		if expected == nil {
+			expected = day
+			continue
+		}
+		if !reflect.DeepEqual(day, expected) {
+			t.Errorf("concurrency %v: result differs: %+v != %+v", concurrency, day, expected)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ethstore
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+var exitWatchList []phase0.ValidatorIndex
+var exitWatchListMu = sync.Mutex{}
+
+// ValidatorExit holds the exit of a watched validator as of the end of a day. The exit and withdrawable epochs are
+// only set if the validator is exiting, the countdowns are the epochs from the end of the day and are negative once
+// the epoch has passed.
+type ValidatorExit struct {
+	Index                   uint64     `json:"index"`
+	Status                  string     `json:"status"`
+	ExitEpoch               *uint64    `json:"exitEpoch,omitempty"`
+	ExitTime                *time.Time `json:"exitTime,omitempty"`
+	EpochsUntilExit         *int64     `json:"epochsUntilExit,omitempty"`
+	WithdrawableEpoch       *uint64    `json:"withdrawableEpoch,omitempty"`
+	WithdrawableTime        *time.Time `json:"withdrawableTime,omitempty"`
+	EpochsUntilWithdrawable *int64     `json:"epochsUntilWithdrawable,omitempty"`
+}
+
+// SetExitWatchList sets the validators whose exits are reported with every calculated day, no validators disable the
+// report.
+func SetExitWatchList(indices ...phase0.ValidatorIndex) {
+	exitWatchListMu.Lock()
+	defer exitWatchListMu.Unlock()
+	exitWatchList = indices
+}
+
+func GetExitWatchList() []phase0.ValidatorIndex {
+	exitWatchListMu.Lock()
+	defer exitWatchListMu.Unlock()
+	return exitWatchList
+}
+
+// getValidatorExits returns the exits of the watched validators in the validator snapshot at the end of the day,
+// validators that are not part of the snapshot yet are skipped.
+func getValidatorExits(validators map[phase0.ValidatorIndex]*v1.Validator, watchList []phase0.ValidatorIndex, endEpoch uint64, t *ChainTiming) []ValidatorExit {
+	if len(watchList) == 0 {
+		return nil
+	}
+	epochTime := func(epoch phase0.Epoch) *time.Time {
+		et := time.Unix(t.Genesis.Unix()+int64(uint64(epoch)*t.SlotsPerEpoch*t.SecondsPerSlot), 0).UTC()
+		return &et
+	}
+	exits := []ValidatorExit{}
+	for _, index := range watchList {
+		val, exists := validators[index]
+		if !exists {
+			continue
+		}
+		e := ValidatorExit{Index: uint64(index), Status: strings.ToLower(val.Status.String())}
+		if val.Validator.ExitEpoch != farFutureEpoch {
+			exitEpoch := uint64(val.Validator.ExitEpoch)
+			untilExit := int64(exitEpoch) - int64(endEpoch)
+			e.ExitEpoch, e.ExitTime, e.EpochsUntilExit = &exitEpoch, epochTime(val.Validator.ExitEpoch), &untilExit
+		}
+		if val.Validator.WithdrawableEpoch != farFutureEpoch {
+			withdrawableEpoch := uint64(val.Validator.WithdrawableEpoch)
+			untilWithdrawable := int64(withdrawableEpoch) - int64(endEpoch)
+			e.WithdrawableEpoch, e.WithdrawableTime, e.EpochsUntilWithdrawable = &withdrawableEpoch, epochTime(val.Validator.WithdrawableEpoch), &untilWithdrawable
+		}
+		exits = append(exits, e)
+	}
+	sort.Slice(exits, func(i, j int) bool { return exits[i].Index < exits[j].Index })
+	return exits
+}
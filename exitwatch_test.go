@@ -0,0 +1,62 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestGetValidatorExits(t *testing.T) {
+	validators := map[phase0.ValidatorIndex]*v1.Validator{
+		1: {Status: v1.ValidatorStateActiveOngoing, Validator: &phase0.Validator{ExitEpoch: farFutureEpoch, WithdrawableEpoch: farFutureEpoch}},
+		2: {Status: v1.ValidatorStateActiveExiting, Validator: &phase0.Validator{ExitEpoch: 110, WithdrawableEpoch: 366}},
+		3: {Status: v1.ValidatorStateExitedUnslashed, Validator: &phase0.Validator{ExitEpoch: 90, WithdrawableEpoch: 346}},
+	}
+	timing := &ChainTiming{Genesis: time.Unix(1606824023, 0), SecondsPerSlot: 12, SlotsPerEpoch: 32}
+	exits := getValidatorExits(validators, []phase0.ValidatorIndex{3, 2, 1, 4}, 100, timing)
+	if len(exits) != 3 || exits[0].Index != 1 || exits[1].Index != 2 || exits[2].Index != 3 {
+		t.Fatalf("wrong exits: %+v", exits)
+	}
+	if exits[0].ExitEpoch != nil || exits[0].WithdrawableEpoch != nil {
+		t.Errorf("exit of validator that is not exiting: %+v", exits[0])
+	}
+	e := exits[1]
+	if e.ExitEpoch == nil || *e.ExitEpoch != 110 || *e.EpochsUntilExit != 10 || *e.EpochsUntilWithdrawable != 266 {
+		t.Fatalf("wrong exit: %+v", e)
+	}
+	if !e.ExitTime.Equal(timing.Genesis.Add(110 * 32 * 12 * time.Second)) {
+		t.Errorf("wrong exit time: %v", e.ExitTime)
+	}
+	if e.Status != "active_exiting" {
+		t.Errorf("wrong status: %v", e.Status)
+	}
+	if *exits[2].EpochsUntilExit != -10 {
+		t.Errorf("wrong countdown of exited validator: %v", *exits[2].EpochsUntilExit)
+	}
+	if exits := getValidatorExits(validators, nil, 100, timing); exits != nil {
+		t.Errorf("exits without watch list: %+v", exits)
+	}
+}
+
+func TestValidatorExitsOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	SetExitWatchList(1, 4)
+	defer SetExitWatchList()
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// validator 1 exited on the last epoch of day 10
+	if len(day.ValidatorExits) != 2 || day.ValidatorExits[0].ExitEpoch == nil || *day.ValidatorExits[0].ExitEpoch != 11*225-1 || *day.ValidatorExits[0].EpochsUntilExit != -1 {
+		t.Fatalf("wrong validator exits: %+v", day.ValidatorExits)
+	}
+	if day.ValidatorExits[1].ExitEpoch != nil {
+		t.Errorf("exit of validator that is not exiting: %+v", day.ValidatorExits[1])
+	}
+}
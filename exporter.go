@@ -0,0 +1,104 @@
+package ethstore
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	exporterDayDesc            = prometheus.NewDesc("ethstore_day", "Latest calculated eth.store day.", nil, nil)
+	exporterUpdatedDesc        = prometheus.NewDesc("ethstore_last_update_timestamp_seconds", "Time of the last calculation.", nil, nil)
+	exporterAprDesc            = prometheus.NewDesc("ethstore_apr", "Apr of the latest day.", []string{"scope"}, nil)
+	exporterValidatorsDesc     = prometheus.NewDesc("ethstore_validators", "Validators of the latest day.", []string{"scope"}, nil)
+	exporterConsensusDesc      = prometheus.NewDesc("ethstore_consensus_rewards_gwei", "Consensus rewards of the latest day in Gwei.", []string{"scope"}, nil)
+	exporterExecutionDesc      = prometheus.NewDesc("ethstore_execution_rewards_wei", "Execution rewards (tx fees) of the latest day in Wei.", []string{"scope"}, nil)
+	exporterTotalDesc          = prometheus.NewDesc("ethstore_total_rewards_wei", "Total rewards of the latest day in Wei.", []string{"scope"}, nil)
+	exporterShareDesc          = prometheus.NewDesc("ethstore_execution_reward_share", "Share of the execution rewards of the total rewards of the latest day.", []string{"scope"}, nil)
+	exporterValidatorAprDesc   = prometheus.NewDesc("ethstore_validator_apr", "Apr of a validator of the subset on the latest day.", []string{"validator"}, nil)
+	exporterValidatorTotalDesc = prometheus.NewDesc("ethstore_validator_total_rewards_wei", "Total rewards of a validator of the subset on the latest day in Wei.", []string{"validator"}, nil)
+)
+
+// Exporter is a prometheus collector of the apr and the reward split of the latest calculated day of the network
+// (scope "network") and of a subset of validators (scope "subset").
+type Exporter struct {
+	validators []uint64
+
+	mu            sync.Mutex
+	day           *Day
+	subset        *Day
+	validatorDays map[uint64]*Day
+	updated       time.Time
+}
+
+// NewExporter returns an exporter for the given subset of validators.
+func NewExporter(validators []uint64) *Exporter {
+	return &Exporter{validators: validators}
+}
+
+// Update replaces the day of the exporter with the given day and its per-validator days as returned by Calculate.
+func (e *Exporter) Update(d *Day, validatorDays map[uint64]*Day) {
+	subset := &Day{}
+	subsetDays := map[uint64]*Day{}
+	for _, index := range e.validators {
+		vd, exists := validatorDays[index]
+		if !exists {
+			continue
+		}
+		subsetDays[index] = vd
+		subset.Validators = subset.Validators.Add(decimal.NewFromInt(1))
+		subset.EffectiveBalanceGwei = subset.EffectiveBalanceGwei.Add(vd.EffectiveBalanceGwei)
+		subset.ConsensusRewardsGwei = subset.ConsensusRewardsGwei.Add(vd.ConsensusRewardsGwei)
+		subset.TxFeesSumWei = subset.TxFeesSumWei.Add(vd.TxFeesSumWei)
+		subset.TotalRewardsWei = subset.TotalRewardsWei.Add(vd.TotalRewardsWei)
+	}
+	subset.Apr = groupApr(subset.TotalRewardsWei, subset.EffectiveBalanceGwei)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.day = d
+	e.subset = subset
+	e.validatorDays = subsetDays
+	e.updated = time.Now()
+}
+
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{exporterDayDesc, exporterUpdatedDesc, exporterAprDesc, exporterValidatorsDesc, exporterConsensusDesc, exporterExecutionDesc, exporterTotalDesc, exporterShareDesc, exporterValidatorAprDesc, exporterValidatorTotalDesc} {
+		ch <- desc
+	}
+}
+
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.day == nil {
+		return
+	}
+	gauge := func(desc *prometheus.Desc, value decimal.Decimal, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value.InexactFloat64(), labels...)
+	}
+	gauge(exporterDayDesc, e.day.Day)
+	ch <- prometheus.MustNewConstMetric(exporterUpdatedDesc, prometheus.GaugeValue, float64(e.updated.Unix()))
+	for scope, d := range map[string]*Day{"network": e.day, "subset": e.subset} {
+		if scope == "subset" && len(e.validators) == 0 {
+			continue
+		}
+		gauge(exporterAprDesc, d.Apr, scope)
+		gauge(exporterValidatorsDesc, d.Validators, scope)
+		gauge(exporterConsensusDesc, d.ConsensusRewardsGwei, scope)
+		gauge(exporterExecutionDesc, d.TxFeesSumWei, scope)
+		gauge(exporterTotalDesc, d.TotalRewardsWei, scope)
+		share := decimal.Zero
+		if !d.TotalRewardsWei.IsZero() {
+			share = d.TxFeesSumWei.Div(d.TotalRewardsWei)
+		}
+		gauge(exporterShareDesc, share, scope)
+	}
+	for index, vd := range e.validatorDays {
+		gauge(exporterValidatorAprDesc, vd.Apr, fmt.Sprintf("%d", index))
+		gauge(exporterValidatorTotalDesc, vd.TotalRewardsWei, fmt.Sprintf("%d", index))
+	}
+}
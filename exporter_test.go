@@ -0,0 +1,69 @@
+package ethstore
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+)
+
+func TestExporter(t *testing.T) {
+	e := NewExporter([]uint64{1, 2, 5})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 0 {
+		t.Errorf("metrics before the first update: %v", len(families))
+	}
+
+	validatorDay := func(consensusRewardsGwei, txFeesGwei int64) *Day {
+		return &Day{
+			Apr:                  groupApr(decimal.NewFromInt((consensusRewardsGwei+txFeesGwei)*1e9), decimal.NewFromInt(32e9)),
+			EffectiveBalanceGwei: decimal.NewFromInt(32e9),
+			ConsensusRewardsGwei: decimal.NewFromInt(consensusRewardsGwei),
+			TxFeesSumWei:         decimal.NewFromInt(txFeesGwei * 1e9),
+			TotalRewardsWei:      decimal.NewFromInt((consensusRewardsGwei + txFeesGwei) * 1e9),
+		}
+	}
+	e.Update(&Day{Day: decimal.NewFromInt(10), Apr: decimal.RequireFromString("0.04"), Validators: decimal.NewFromInt(3)}, map[uint64]*Day{
+		1: validatorDay(3000000, 1000000),
+		2: validatorDay(3000000, 0),
+		3: validatorDay(9000000, 0),
+	})
+	families, err = registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			name := f.GetName()
+			for _, l := range m.GetLabel() {
+				name += "/" + l.GetValue()
+			}
+			values[name] = m.GetGauge().GetValue()
+		}
+	}
+	expected := map[string]float64{
+		"ethstore_day":                           10,
+		"ethstore_apr/network":                   0.04,
+		"ethstore_validators/subset":             2,
+		"ethstore_consensus_rewards_gwei/subset": 6000000,
+		"ethstore_execution_rewards_wei/subset":  1e15,
+		"ethstore_execution_reward_share/subset": decimal.NewFromInt(1).Div(decimal.NewFromInt(7)).InexactFloat64(),
+		"ethstore_apr/subset":                    groupApr(decimal.NewFromInt(7e15), decimal.NewFromInt(64e9)).InexactFloat64(),
+		"ethstore_validator_total_rewards_wei/1": 4e15,
+		"ethstore_validator_apr/2":               validatorDay(3000000, 0).Apr.InexactFloat64(),
+	}
+	for name, value := range expected {
+		if v, exists := values[name]; !exists || v != value {
+			t.Errorf("wrong metric %v: %v != %v", name, v, value)
+		}
+	}
+	if _, exists := values["ethstore_validator_apr/3"]; exists {
+		t.Errorf("metric of validator outside of the subset")
+	}
+}
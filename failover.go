@@ -0,0 +1,126 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout is the timeout of the health check of a consensus node of a failover pool.
+const healthCheckTimeout = 10 * time.Second
+
+// failoverPool holds the endpoints of a consensus node that was given as several addresses or resolved to several
+// endpoints. The beacon api requests of blocks and states are sent to the endpoint that answered the last request and
+// retried on the other endpoints in order if it fails.
+type failoverPool struct {
+	mu        sync.Mutex
+	endpoints []string
+	// current is the index of the endpoint that answered the last request
+	current int
+}
+
+// failoverPools holds the failover pools keyed by the address of the endpoint the client connected to.
+var failoverPools = map[string]*failoverPool{}
+var failoverPoolsMu = &sync.Mutex{}
+
+func setFailoverPool(address string, endpoints []string) {
+	failoverPoolsMu.Lock()
+	defer failoverPoolsMu.Unlock()
+	if len(endpoints) < 2 {
+		delete(failoverPools, address)
+		return
+	}
+	failoverPools[address] = &failoverPool{endpoints: endpoints}
+}
+
+func getFailoverPool(address string) *failoverPool {
+	failoverPoolsMu.Lock()
+	defer failoverPoolsMu.Unlock()
+	return failoverPools[address]
+}
+
+// failoverEndpoints returns the endpoints a request for the given address is sent to in order, which is only the address
+// itself unless it belongs to a failover pool.
+func failoverEndpoints(address string) []string {
+	p := getFailoverPool(address)
+	if p == nil {
+		return []string{address}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append(append([]string{}, p.endpoints[p.current:]...), p.endpoints[:p.current]...)
+}
+
+// failoverAnswered records that the endpoint answered a request for the given address, the following requests are
+// sent to it first.
+func failoverAnswered(address, endpoint string) {
+	p := getFailoverPool(address)
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, e := range p.endpoints {
+		if e == endpoint && i != p.current {
+			log.Printf("consensus node %v answered, sending the following requests to it first", endpoint)
+			p.current = i
+		}
+	}
+}
+
+// retryOnNextEndpoint returns whether a request that failed with the given status or error is retried on the next
+// endpoint of a failover pool. Responses with a status below 500 are answers of a healthy node, e.g. 404 for a missed
+// slot.
+func retryOnNextEndpoint(ctx context.Context, status int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return err != nil || status >= http.StatusInternalServerError
+}
+
+// checkNodeHealth requests the health of the consensus node at the endpoint, it returns an error unless the node is
+// synced and ready.
+func checkNodeHealth(ctx context.Context, endpoint string) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/eth/v1/node/health", nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	switch res.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusPartialContent:
+		return fmt.Errorf("node is syncing")
+	default:
+		return fmt.Errorf("status %v", res.StatusCode)
+	}
+}
+
+// orderByHealth returns the endpoints with the healthy ones first, the order of the healthy and of the unhealthy
+// endpoints is kept.
+func orderByHealth(ctx context.Context, endpoints []string) []string {
+	healthy := make([]string, 0, len(endpoints))
+	unhealthy := []string{}
+	for _, endpoint := range endpoints {
+		if err := checkNodeHealth(ctx, endpoint); err != nil {
+			log.Printf("consensus node %v is not healthy, preferring the other endpoints: %v", endpoint, err)
+			unhealthy = append(unhealthy, endpoint)
+			continue
+		}
+		healthy = append(healthy, endpoint)
+	}
+	return append(healthy, unhealthy...)
+}
@@ -0,0 +1,84 @@
+package ethstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEndpointsList(t *testing.T) {
+	list, err := endpoints(context.Background(), "localhost:4000, http://10.0.0.1:5052,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0] != "http://localhost:4000" || list[1] != "http://10.0.0.1:5052" {
+		t.Errorf("wrong endpoints: %v", list)
+	}
+	if _, err := endpoints(context.Background(), " , "); err == nil {
+		t.Errorf("expected error without address")
+	}
+}
+
+func TestFailover(t *testing.T) {
+	withHealth := func(mocks map[string]string) {
+		mocks["/eth/v1/node/health"] = ""
+	}
+	bnServer, elServer := newEthstoreMockServersWithMocks(t, nil, withHealth)
+	defer bnServer.Close()
+	defer elServer.Close()
+	otherBnServer, otherElServer := newEthstoreMockServersWithMocks(t, nil, withHealth)
+	defer otherBnServer.Close()
+	defer otherElServer.Close()
+
+	// the flaky node is healthy but fails to serve the blocks of the day
+	var healthy int32 = 1
+	var flakyBlockRequests int32
+	otherURL, _ := url.Parse(otherBnServer.URL)
+	proxy := httputil.NewSingleHostReverseProxy(otherURL)
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/eth/v1/node/health" && atomic.LoadInt32(&healthy) == 0:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case strings.HasPrefix(r.URL.Path, "/eth/v2/beacon/blocks/7"):
+			atomic.AddInt32(&flakyBlockRequests, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			proxy.ServeHTTP(w, r)
+		}
+	}))
+	defer flaky.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failoverDay, _, err := Calculate(context.Background(), flaky.URL+","+bnServer.URL, elServer.URL, "10", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !failoverDay.Apr.Equal(day.Apr) || !failoverDay.TotalRewardsWei.Equal(day.TotalRewardsWei) {
+		t.Errorf("wrong day with failover: %v != %v", failoverDay.Apr, day.Apr)
+	}
+	// the blocks after the first failed one are requested from the other node first
+	if n := atomic.LoadInt32(&flakyBlockRequests); n != 1 {
+		t.Errorf("wrong number of block requests to the flaky node: %v", n)
+	}
+
+	// an unhealthy node is only used if no other node is healthy
+	atomic.StoreInt32(&healthy, 0)
+	client, err := newConsClient(context.Background(), flaky.URL+","+bnServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Address() != bnServer.URL {
+		t.Errorf("wrong preferred node: %v", client.Address())
+	}
+	if endpoints := failoverEndpoints(client.Address()); len(endpoints) != 2 || endpoints[1] != flaky.URL {
+		t.Errorf("wrong failover endpoints: %v", endpoints)
+	}
+}
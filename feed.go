@@ -0,0 +1,89 @@
+package ethstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Feed serves the last days as static json-documents suitable for caching by a CDN: /feed/latest.json holds the
+// latest day and /feed/history.json holds the last days in ascending order.
+type Feed struct {
+	days   int
+	maxAge time.Duration
+
+	mu       sync.RWMutex
+	latest   []byte
+	history  []byte
+	modified time.Time
+}
+
+// NewFeed returns a feed of the given number of last days that allows caching its documents for maxAge.
+func NewFeed(days int, maxAge time.Duration) *Feed {
+	return &Feed{days: days, maxAge: maxAge}
+}
+
+// Update replaces the days of the feed, the documents are only rebuilt if the days changed.
+func (f *Feed) Update(days []*Day) error {
+	if len(days) == 0 {
+		return fmt.Errorf("no days to publish")
+	}
+	sorted := make([]*Day, len(days))
+	copy(sorted, days)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Day.LessThan(sorted[j].Day)
+	})
+	if f.days > 0 && len(sorted) > f.days {
+		sorted = sorted[len(sorted)-f.days:]
+	}
+	latest, err := json.Marshal(sorted[len(sorted)-1])
+	if err != nil {
+		return fmt.Errorf("error marshaling latest day: %w", err)
+	}
+	history, err := json.Marshal(sorted)
+	if err != nil {
+		return fmt.Errorf("error marshaling history: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if bytes.Equal(latest, f.latest) && bytes.Equal(history, f.history) {
+		return nil
+	}
+	f.latest = latest
+	f.history = history
+	f.modified = time.Now().UTC().Truncate(time.Second)
+	return nil
+}
+
+func (f *Feed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	f.mu.RLock()
+	var body []byte
+	switch r.URL.Path {
+	case "/feed/latest.json":
+		body = f.latest
+	case "/feed/history.json":
+		body = f.history
+	}
+	modified := f.modified
+	f.mu.RUnlock()
+	if body == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(f.maxAge/time.Second)))
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("ETag", fmt.Sprintf("\"%x\"", sha256.Sum256(body)))
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
@@ -0,0 +1,86 @@
+package ethstore
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFeed(t *testing.T) {
+	feed := NewFeed(2, 5*time.Minute)
+	server := httptest.NewServer(feed)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/feed/latest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("wrong status of empty feed: %v", res.StatusCode)
+	}
+
+	days := []*Day{}
+	for _, d := range []int64{12, 10, 11} {
+		days = append(days, &Day{Day: decimal.NewFromInt(d), Apr: decimal.RequireFromString("0.04")})
+	}
+	err = feed.Update(days)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = http.Get(server.URL + "/feed/history.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := []*Day{}
+	err = json.NewDecoder(res.Body).Decode(&history)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || !history[0].Day.Equal(decimal.NewFromInt(11)) || !history[1].Day.Equal(decimal.NewFromInt(12)) {
+		t.Fatalf("wrong history: %+v", history)
+	}
+	if cc := res.Header.Get("Cache-Control"); cc != "public, max-age=300" {
+		t.Errorf("wrong cache-control: %v", cc)
+	}
+
+	res, err = http.Get(server.URL + "/feed/latest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := &Day{}
+	err = json.NewDecoder(res.Body).Decode(latest)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !latest.Day.Equal(decimal.NewFromInt(12)) {
+		t.Errorf("wrong latest day: %v", latest.Day)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/feed/latest.json", nil)
+	req.Header.Set("If-None-Match", res.Header.Get("ETag"))
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotModified {
+		t.Errorf("wrong status of unchanged document: %v", res.StatusCode)
+	}
+
+	res, err = http.Post(server.URL+"/feed/latest.json", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("wrong status of post: %v", res.StatusCode)
+	}
+}
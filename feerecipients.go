@@ -0,0 +1,62 @@
+package ethstore
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var expectedFeeRecipients *FeeRecipients
+var expectedFeeRecipientsMu = sync.Mutex{}
+
+// FeeRecipients maps validators to the fee recipient their blocks are expected to pay to, validators without an entry
+// are expected to pay to the default fee recipient if it is set.
+type FeeRecipients struct {
+	Default    *common.Address           `json:"default"`
+	Validators map[uint64]common.Address `json:"validators"`
+}
+
+// FeeRecipientMismatch describes a block of an eth.store validator that pays its tx fees to an unexpected fee recipient.
+type FeeRecipientMismatch struct {
+	Slot                 uint64         `json:"slot"`
+	ProposerIndex        uint64         `json:"proposerIndex"`
+	FeeRecipient         common.Address `json:"feeRecipient"`
+	ExpectedFeeRecipient common.Address `json:"expectedFeeRecipient"`
+}
+
+// SetExpectedFeeRecipients enables comparing the fee recipients of the blocks of the eth.store validators against the
+// given fee recipients, nil disables the comparison.
+func SetExpectedFeeRecipients(fr *FeeRecipients) {
+	expectedFeeRecipientsMu.Lock()
+	defer expectedFeeRecipientsMu.Unlock()
+	expectedFeeRecipients = fr
+}
+
+func GetExpectedFeeRecipients() *FeeRecipients {
+	expectedFeeRecipientsMu.Lock()
+	defer expectedFeeRecipientsMu.Unlock()
+	return expectedFeeRecipients
+}
+
+// check returns a mismatch if the proposer is expected to pay to a different fee recipient.
+func (fr *FeeRecipients) check(slot, proposerIndex uint64, feeRecipient common.Address) *FeeRecipientMismatch {
+	if fr == nil {
+		return nil
+	}
+	expected, exists := fr.Validators[proposerIndex]
+	if !exists {
+		if fr.Default == nil {
+			return nil
+		}
+		expected = *fr.Default
+	}
+	if feeRecipient == expected {
+		return nil
+	}
+	return &FeeRecipientMismatch{
+		Slot:                 slot,
+		ProposerIndex:        proposerIndex,
+		FeeRecipient:         feeRecipient,
+		ExpectedFeeRecipient: expected,
+	}
+}
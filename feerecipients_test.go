@@ -0,0 +1,50 @@
+package ethstore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestFeeRecipientsCheck(t *testing.T) {
+	var fr FeeRecipients
+	err := json.Unmarshal([]byte(`{"default":"0x388c818ca8b9251b393131c08a736a67ccb19297","validators":{"7":"0x4675c7e5baafbffbca748158becba61ef3b0a263"}}`), &fr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultRecipient := common.HexToAddress("0x388c818ca8b9251b393131c08a736a67ccb19297")
+	validatorRecipient := common.HexToAddress("0x4675c7e5baafbffbca748158becba61ef3b0a263")
+	other := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	tests := []struct {
+		name          string
+		fr            *FeeRecipients
+		proposerIndex uint64
+		feeRecipient  common.Address
+		expected      *common.Address
+	}{
+		{name: "no mapping", proposerIndex: 1, feeRecipient: other},
+		{name: "default match", fr: &fr, proposerIndex: 1, feeRecipient: defaultRecipient},
+		{name: "default mismatch", fr: &fr, proposerIndex: 1, feeRecipient: other, expected: &defaultRecipient},
+		{name: "validator match", fr: &fr, proposerIndex: 7, feeRecipient: validatorRecipient},
+		{name: "validator mismatch", fr: &fr, proposerIndex: 7, feeRecipient: defaultRecipient, expected: &validatorRecipient},
+		{name: "no default", fr: &FeeRecipients{Validators: fr.Validators}, proposerIndex: 1, feeRecipient: other},
+	}
+	for _, tt := range tests {
+		m := tt.fr.check(100, tt.proposerIndex, tt.feeRecipient)
+		if tt.expected == nil {
+			if m != nil {
+				t.Errorf("%v: unexpected mismatch: %+v", tt.name, m)
+			}
+			continue
+		}
+		if m == nil {
+			t.Errorf("%v: expected mismatch", tt.name)
+			continue
+		}
+		if m.Slot != 100 || m.ProposerIndex != tt.proposerIndex || m.FeeRecipient != tt.feeRecipient || m.ExpectedFeeRecipient != *tt.expected {
+			t.Errorf("%v: wrong mismatch: %+v", tt.name, m)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotFinalized is returned when the end of the requested day is not finalized yet, the results of such a day can
+// still change with a reorg and its states may not be available at all.
+var ErrNotFinalized = errors.New("day is not finalized")
+
+var allowUnfinalized bool
+var allowUnfinalizedMu = &sync.Mutex{}
+
+// SetAllowUnfinalized sets whether days whose end epoch is not finalized yet are calculated with a warning instead of
+// being refused with ErrNotFinalized. The day still has to have ended at the head of the consensus node.
+//
+// Deprecated: use WithAllowUnfinalized.
+func SetAllowUnfinalized(allow bool) {
+	allowUnfinalizedMu.Lock()
+	defer allowUnfinalizedMu.Unlock()
+	allowUnfinalized = allow
+}
+
+func GetAllowUnfinalized() bool {
+	allowUnfinalizedMu.Lock()
+	defer allowUnfinalizedMu.Unlock()
+	return allowUnfinalized
+}
+
+// getFinalizedEpoch returns the finalized epoch of the head state of the consensus node, the state at its first slot
+// is the latest state that can not change anymore.
+func getFinalizedEpoch(ctx context.Context, client BeaconClient) (uint64, error) {
+	finality, err := client.Finality(ctx, "head")
+	if err != nil {
+		return 0, fmt.Errorf("error getting finality checkpoints of the head state: %w", err)
+	}
+	if finality.Finalized == nil {
+		return 0, fmt.Errorf("no finalized checkpoint in the head state")
+	}
+	return uint64(finality.Finalized.Epoch), nil
+}
+
+// latestFinalizedDay returns the latest day whose end is finalized according to both the finalized block header and
+// the finality checkpoints of the head state. The finalized block can precede the first slot of the finalized epoch
+// if that slot was missed.
+func latestFinalizedDay(ctx context.Context, client BeaconClient, timing *ChainTiming, boundary DayBoundary) (day, finalizedSlot uint64, err error) {
+	h, err := client.BeaconBlockHeader(ctx, "finalized")
+	if err != nil {
+		return 0, 0, err
+	}
+	finalizedSlot = uint64(h.Header.Message.Slot)
+	finalizedEpoch, err := getFinalizedEpoch(ctx, client)
+	if err != nil {
+		return 0, 0, err
+	}
+	if epochSlot := finalizedEpoch * timing.SlotsPerEpoch; epochSlot < finalizedSlot {
+		finalizedSlot = epochSlot
+	}
+	if boundary.Day(finalizedSlot, timing) == 0 {
+		return 0, 0, fmt.Errorf("%w: the first day has not ended at the finalized slot %v", ErrNotFinalized, finalizedSlot)
+	}
+	return boundary.Day(finalizedSlot, timing) - 1, finalizedSlot, nil
+}
+
+// CalculateLatest calculates the most recent day whose end epoch is finalized like Calculate.
+func CalculateLatest(ctx context.Context, bnAddress, elAddress string, concurrency int) (*Day, map[uint64]*Day, error) {
+	day, err := GetFinalizedDay(ctx, bnAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Calculate(ctx, bnAddress, elAddress, fmt.Sprintf("%d", day), concurrency)
+}
@@ -0,0 +1,70 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gobitfly/eth.store/fixture"
+)
+
+func TestFinalityGuard(t *testing.T) {
+	f, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bnServer, elServer := f.Servers()
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	latest, err := GetFinalizedDay(context.Background(), bnServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != 10 {
+		t.Errorf("wrong latest finalized day: %v", latest)
+	}
+	day, _, err := CalculateLatest(context.Background(), bnServer.URL, elServer.URL, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.Day.IntPart() != 10 || !day.Apr.Equal(f.Expected.Apr) {
+		t.Errorf("wrong latest day: %v %v != %v", day.Day, day.Apr, f.Expected.Apr)
+	}
+
+	// the finalized block is after the day but the head state has only finalized an epoch in the middle of the day
+	finalizedEpoch := (10*fixture.SlotsPerDay + fixture.SlotsPerDay/2) / fixture.SlotsPerEpoch
+	bn := f.BeaconHandler()
+	lagging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/eth/v1/beacon/states/head/finality_checkpoints" {
+			fmt.Fprintf(w, `{"data":{"previous_justified":{"epoch":"%[1]d","root":"%[2]s"},"current_justified":{"epoch":"%[1]d","root":"%[2]s"},"finalized":{"epoch":"%[1]d","root":"%[2]s"}}}`, finalizedEpoch, "0x0000000000000000000000000000000000000000000000000000000000000000")
+			return
+		}
+		bn.ServeHTTP(w, r)
+	}))
+	defer lagging.Close()
+
+	if latest, err := GetFinalizedDay(context.Background(), lagging.URL); err != nil || latest != 9 {
+		t.Errorf("wrong latest finalized day of lagging node: %v %v", latest, err)
+	}
+	if _, _, err := Calculate(context.Background(), lagging.URL, elServer.URL, "10", 10); !errors.Is(err, ErrNotFinalized) {
+		t.Errorf("expected ErrNotFinalized, got %v", err)
+	}
+
+	SetAllowUnfinalized(true)
+	defer SetAllowUnfinalized(false)
+	day, _, err = Calculate(context.Background(), lagging.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.Apr.Equal(f.Expected.Apr) {
+		t.Errorf("wrong apr of unfinalized day: %v != %v", day.Apr, f.Expected.Apr)
+	}
+	// a day that has not ended at the head is still refused
+	if _, _, err := Calculate(context.Background(), lagging.URL, elServer.URL, "12", 10); err == nil {
+		t.Errorf("expected error for a day that has not ended")
+	}
+}
@@ -0,0 +1,441 @@
+// Package fixture generates synthetic, self-consistent data sets of a day of a beacon chain and its execution chain. A
+// data set is described by a Scenario, Generate returns a Fixture that serves it as beacon api and execution json-rpc
+// and holds the day eth.store is expected to calculate from it. Fixtures replace hand-written mocks in tests and let
+// users script their own methodology scenarios.
+//
+// The chain of a fixture uses the mainnet genesis, slot time and preset. All validators are active from the start of
+// the chain unless the scenario activates them later, they earn the same consensus rewards per active slot and propose
+// the slots of an epoch in turns. Every block has a single transaction that pays the same fee to the proposer.
+package fixture
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/prysmaticlabs/prysm/v3/contracts/deposit"
+	"github.com/prysmaticlabs/prysm/v3/crypto/bls"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	GenesisTime    = 1606824023
+	SecondsPerSlot = 12
+	SlotsPerEpoch  = 32
+	EpochsPerDay   = 225
+	SlotsPerDay    = SlotsPerEpoch * EpochsPerDay
+
+	farFutureEpoch = math.MaxUint64
+	// withdrawabilityDelay is the number of epochs between the exit of a validator and its withdrawable epoch.
+	withdrawabilityDelay    = 256
+	maxEffectiveBalanceGwei = 32e9
+	effectiveBalanceGwei    = 1e9
+	defaultStartBalanceGwei = 32e9
+	// txGasUsed is the gas used by the transaction of every block, 1 Gwei of fee is a priority fee of 1e4 Wei per gas.
+	txGasUsed            = 100000
+	txGasPriceWeiPerGwei = 1e9 / txGasUsed
+)
+
+// Scenario describes the data set of a fixture. Slots of events are slots of the day starting at 0, epochs of
+// activations and exits are epochs of the day starting at 0 and may be negative for epochs before the day.
+type Scenario struct {
+	Day        uint64 `json:"day"`
+	Validators int    `json:"validators"`
+	// StartBalanceGwei is the balance of every validator at the start of the day, 32 Eth if 0.
+	StartBalanceGwei uint64 `json:"startBalanceGwei"`
+	// ConsensusRewardGwei is the consensus reward a validator earns if it is active during the whole day.
+	ConsensusRewardGwei uint64 `json:"consensusRewardGwei"`
+	// TxFeeGwei is the fee the transaction of every block pays to the proposer.
+	TxFeeGwei   uint64       `json:"txFeeGwei"`
+	BaseFeeGwei uint64       `json:"baseFeeGwei"`
+	MissedSlots []uint64     `json:"missedSlots"`
+	Activations []Activation `json:"activations"`
+	Exits       []Exit       `json:"exits"`
+	Deposits    []Deposit    `json:"deposits"`
+	Withdrawals []Withdrawal `json:"withdrawals"`
+}
+
+type Activation struct {
+	Validator int   `json:"validator"`
+	Epoch     int64 `json:"epoch"`
+}
+
+type Exit struct {
+	Validator int   `json:"validator"`
+	Epoch     int64 `json:"epoch"`
+}
+
+type Deposit struct {
+	Validator  int    `json:"validator"`
+	Slot       uint64 `json:"slot"`
+	AmountGwei uint64 `json:"amountGwei"`
+}
+
+type Withdrawal struct {
+	Validator  int    `json:"validator"`
+	Slot       uint64 `json:"slot"`
+	AmountGwei uint64 `json:"amountGwei"`
+}
+
+// Expected holds the fields of the day eth.store calculates from a fixture, they are named like the fields of the day.
+type Expected struct {
+	Day                  uint64          `json:"day"`
+	Apr                  decimal.Decimal `json:"apr"`
+	Validators           int             `json:"validators"`
+	EffectiveBalanceGwei decimal.Decimal `json:"effectiveBalanceGwei"`
+	StartBalanceGwei     decimal.Decimal `json:"startBalanceGwei"`
+	EndBalanceGwei       decimal.Decimal `json:"endBalanceGwei"`
+	DepositsSumGwei      decimal.Decimal `json:"depositsSumGwei"`
+	WithdrawalsSumGwei   decimal.Decimal `json:"withdrawalsSumGwei"`
+	ConsensusRewardsGwei decimal.Decimal `json:"consensusRewardsGwei"`
+	TxFeesSumWei         decimal.Decimal `json:"txFeesSumWei"`
+	TotalRewardsWei      decimal.Decimal `json:"totalRewardsWei"`
+	MissedSlots          int             `json:"missedSlots"`
+}
+
+type validator struct {
+	index                 uint64
+	pubkey                []byte
+	withdrawalCredentials []byte
+	activationEpoch       uint64
+	exitEpoch             uint64
+	deposits              []*blockDeposit
+	withdrawals           []Withdrawal
+}
+
+// blockDeposit is a deposit of the scenario with its signed deposit data.
+type blockDeposit struct {
+	Deposit
+	pubkey                []byte
+	withdrawalCredentials []byte
+	signature             []byte
+}
+
+// Fixture is the generated data set of a scenario.
+type Fixture struct {
+	Scenario Scenario
+	Expected Expected
+
+	firstSlot        uint64
+	startBalanceGwei uint64
+	validators       []*validator
+	missed           map[uint64]bool
+	// deposits and withdrawals are keyed by the slot of the day of their block
+	deposits    map[uint64][]*blockDeposit
+	withdrawals map[uint64][]Withdrawal
+	tx          []byte
+	txHash      common.Hash
+	// blockSlots are the slots of the execution blocks keyed by their lowercase hash
+	blockSlots     map[string]uint64
+	blockSlotsOnce sync.Once
+}
+
+// Generate validates the scenario and generates its fixture.
+func Generate(s Scenario) (*Fixture, error) {
+	if s.Validators < 1 {
+		return nil, fmt.Errorf("scenario without validators")
+	}
+	f := &Fixture{
+		Scenario:         s,
+		firstSlot:        s.Day * SlotsPerDay,
+		startBalanceGwei: s.StartBalanceGwei,
+		missed:           map[uint64]bool{},
+		deposits:         map[uint64][]*blockDeposit{},
+		withdrawals:      map[uint64][]Withdrawal{},
+	}
+	if f.startBalanceGwei == 0 {
+		f.startBalanceGwei = defaultStartBalanceGwei
+	}
+	firstEpoch := int64(s.Day * EpochsPerDay)
+
+	keys := make([]bls.SecretKey, s.Validators)
+	f.validators = make([]*validator, s.Validators)
+	for i := range f.validators {
+		key, err := validatorKey(i)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+		f.validators[i] = &validator{
+			index:                 uint64(i),
+			pubkey:                key.PublicKey().Marshal(),
+			withdrawalCredentials: deposit.WithdrawalCredentialsHash(key),
+			exitEpoch:             farFutureEpoch,
+		}
+	}
+	checkValidator := func(kind string, i int) error {
+		if i < 0 || i >= s.Validators {
+			return fmt.Errorf("%v of unknown validator %v", kind, i)
+		}
+		return nil
+	}
+	checkSlot := func(kind string, slot uint64) error {
+		if slot >= SlotsPerDay {
+			return fmt.Errorf("%v at slot %v outside of the day", kind, slot)
+		}
+		return nil
+	}
+	for _, slot := range s.MissedSlots {
+		if err := checkSlot("missed slot", slot); err != nil {
+			return nil, err
+		}
+		f.missed[slot] = true
+	}
+	for _, a := range s.Activations {
+		if err := checkValidator("activation", a.Validator); err != nil {
+			return nil, err
+		}
+		if firstEpoch+a.Epoch < 0 {
+			return nil, fmt.Errorf("activation of validator %v before genesis", a.Validator)
+		}
+		f.validators[a.Validator].activationEpoch = uint64(firstEpoch + a.Epoch)
+	}
+	for _, e := range s.Exits {
+		if err := checkValidator("exit", e.Validator); err != nil {
+			return nil, err
+		}
+		v := f.validators[e.Validator]
+		if firstEpoch+e.Epoch <= int64(v.activationEpoch) {
+			return nil, fmt.Errorf("exit of validator %v before its activation", e.Validator)
+		}
+		v.exitEpoch = uint64(firstEpoch + e.Epoch)
+	}
+	for _, d := range s.Deposits {
+		if err := checkValidator("deposit", d.Validator); err != nil {
+			return nil, err
+		}
+		if err := checkSlot("deposit", d.Slot); err != nil {
+			return nil, err
+		}
+		if f.missed[d.Slot] {
+			return nil, fmt.Errorf("deposit at missed slot %v", d.Slot)
+		}
+		data, _, err := deposit.DepositInput(keys[d.Validator], keys[d.Validator], d.AmountGwei)
+		if err != nil {
+			return nil, fmt.Errorf("error signing deposit of validator %v: %w", d.Validator, err)
+		}
+		bd := &blockDeposit{Deposit: d, pubkey: data.PublicKey, withdrawalCredentials: data.WithdrawalCredentials, signature: data.Signature}
+		f.deposits[d.Slot] = append(f.deposits[d.Slot], bd)
+		v := f.validators[d.Validator]
+		v.deposits = append(v.deposits, bd)
+	}
+	withdrawals := append([]Withdrawal{}, s.Withdrawals...)
+	sort.SliceStable(withdrawals, func(i, j int) bool { return withdrawals[i].Slot < withdrawals[j].Slot })
+	for _, w := range withdrawals {
+		if err := checkValidator("withdrawal", w.Validator); err != nil {
+			return nil, err
+		}
+		if err := checkSlot("withdrawal", w.Slot); err != nil {
+			return nil, err
+		}
+		if f.missed[w.Slot] {
+			return nil, fmt.Errorf("withdrawal at missed slot %v", w.Slot)
+		}
+		v := f.validators[w.Validator]
+		if balance := f.balance(v, f.firstSlot+w.Slot); balance < w.AmountGwei {
+			return nil, fmt.Errorf("withdrawal of %v Gwei of validator %v exceeds its balance of %v Gwei", w.AmountGwei, w.Validator, balance)
+		}
+		f.withdrawals[w.Slot] = append(f.withdrawals[w.Slot], w)
+		v.withdrawals = append(v.withdrawals, w)
+	}
+
+	tx, err := createTx()
+	if err != nil {
+		return nil, err
+	}
+	f.tx = tx
+	var decTx types.Transaction
+	if err := decTx.UnmarshalBinary(tx); err != nil {
+		return nil, err
+	}
+	f.txHash = decTx.Hash()
+
+	f.Expected = f.expected()
+	return f, nil
+}
+
+// validatorKey returns the deterministic bls key of the validator with the given index.
+func validatorKey(index int) (bls.SecretKey, error) {
+	b := make([]byte, 32)
+	binary.BigEndian.PutUint64(b[24:], uint64(index)+1)
+	key, err := bls.SecretKeyFromBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("error creating key of validator %v: %w", index, err)
+	}
+	return key, nil
+}
+
+// createTx returns the signed transaction of every block, the fees are set by the receipt.
+func createTx() ([]byte, error) {
+	privateKey, err := crypto.HexToECDSA("fad9c8855b740a0b7ed4c221dbad0f33a83a49cad6b3fe8d5817ac83d38b6a19")
+	if err != nil {
+		return nil, err
+	}
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	tx := types.NewTransaction(1, to, big.NewInt(1e18), txGasUsed, big.NewInt(1e9), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(1)), privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return signedTx.MarshalBinary()
+}
+
+// endSlot returns the first slot after the day.
+func (f *Fixture) endSlot() uint64 {
+	return f.firstSlot + SlotsPerDay
+}
+
+func (f *Fixture) active(v *validator, epoch uint64) bool {
+	return v.activationEpoch <= epoch && epoch < v.exitEpoch
+}
+
+// activeSlots returns the number of slots of the day before the given slot the validator is active at.
+func (f *Fixture) activeSlots(v *validator, slot uint64) uint64 {
+	lo := v.activationEpoch * SlotsPerEpoch
+	if lo < f.firstSlot {
+		lo = f.firstSlot
+	}
+	hi := slot
+	if hi > f.endSlot() {
+		hi = f.endSlot()
+	}
+	if v.exitEpoch != farFutureEpoch && v.exitEpoch*SlotsPerEpoch < hi {
+		hi = v.exitEpoch * SlotsPerEpoch
+	}
+	if hi <= lo {
+		return 0
+	}
+	return hi - lo
+}
+
+// balance returns the balance of the validator at the start of the slot, the rewards of the active slots and the
+// deposits and withdrawals of the blocks before the slot are included.
+func (f *Fixture) balance(v *validator, slot uint64) uint64 {
+	b := f.startBalanceGwei + f.Scenario.ConsensusRewardGwei*f.activeSlots(v, slot)/SlotsPerDay
+	for _, d := range v.deposits {
+		if f.firstSlot+d.Slot < slot {
+			b += d.AmountGwei
+		}
+	}
+	for _, w := range v.withdrawals {
+		if f.firstSlot+w.Slot < slot {
+			b -= w.AmountGwei
+		}
+	}
+	return b
+}
+
+func (f *Fixture) effectiveBalance() uint64 {
+	e := f.startBalanceGwei / effectiveBalanceGwei * effectiveBalanceGwei
+	if e > maxEffectiveBalanceGwei {
+		e = maxEffectiveBalanceGwei
+	}
+	return e
+}
+
+// status returns the status of the validator at the epoch as the beacon api names it.
+func (f *Fixture) status(v *validator, epoch uint64) string {
+	switch {
+	case epoch < v.activationEpoch:
+		return "pending_queued"
+	case epoch < v.exitEpoch && v.exitEpoch == farFutureEpoch:
+		return "active_ongoing"
+	case epoch < v.exitEpoch:
+		return "active_exiting"
+	case epoch < v.exitEpoch+withdrawabilityDelay:
+		return "exited_unslashed"
+	default:
+		return "withdrawal_possible"
+	}
+}
+
+// proposer returns the proposer of the slot, the active validators propose the slots of an epoch in turns. It returns
+// false if no validator is active.
+func (f *Fixture) proposer(slot uint64) (*validator, bool) {
+	epoch := slot / SlotsPerEpoch
+	active := make([]*validator, 0, len(f.validators))
+	for _, v := range f.validators {
+		if f.active(v, epoch) {
+			active = append(active, v)
+		}
+	}
+	if len(active) == 0 {
+		return nil, false
+	}
+	return active[slot%uint64(len(active))], true
+}
+
+// txFeeWei returns the fee the transaction of every block pays to the proposer.
+func (f *Fixture) txFeeWei() *big.Int {
+	return new(big.Int).Mul(f.priorityFeePerGas(), big.NewInt(txGasUsed))
+}
+
+func (f *Fixture) priorityFeePerGas() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(f.Scenario.TxFeeGwei), big.NewInt(txGasPriceWeiPerGwei))
+}
+
+func (f *Fixture) baseFeePerGas() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(f.Scenario.BaseFeeGwei), big.NewInt(1e9))
+}
+
+// expected applies the methodology to the fixture: the validators that are active at the start and at the end of the
+// day are included, their rewards are the change of their balances without deposits and withdrawals and the fees of
+// the blocks they proposed.
+func (f *Fixture) expected() Expected {
+	firstEpoch := f.firstSlot / SlotsPerEpoch
+	endEpoch := f.endSlot() / SlotsPerEpoch
+	eligible := map[uint64]bool{}
+	var effective, start, end, deposits, withdrawals uint64
+	for _, v := range f.validators {
+		if !f.active(v, firstEpoch) || v.activationEpoch >= endEpoch || v.exitEpoch < endEpoch {
+			continue
+		}
+		eligible[v.index] = true
+		effective += f.effectiveBalance()
+		start += f.balance(v, f.firstSlot)
+		end += f.balance(v, f.endSlot())
+		for _, d := range v.deposits {
+			deposits += d.AmountGwei
+		}
+		for _, w := range v.withdrawals {
+			withdrawals += w.AmountGwei
+		}
+	}
+	txFees := new(big.Int)
+	for slot := f.firstSlot; slot < f.endSlot(); slot++ {
+		if f.missed[slot-f.firstSlot] {
+			continue
+		}
+		if p, ok := f.proposer(slot); ok && eligible[p.index] {
+			txFees.Add(txFees, f.txFeeWei())
+		}
+	}
+
+	consensusRewardsGwei := decimal.NewFromInt(int64(end) - int64(start) - int64(deposits) + int64(withdrawals))
+	txFeesWei := decimal.NewFromBigInt(txFees, 0)
+	totalRewardsWei := consensusRewardsGwei.Mul(decimal.NewFromInt(1e9)).Add(txFeesWei)
+	apr := decimal.Zero
+	if effective > 0 {
+		apr = decimal.NewFromInt(365).Mul(totalRewardsWei).Div(decimal.NewFromInt(int64(effective)).Mul(decimal.NewFromInt(1e9)))
+	}
+	return Expected{
+		Day:                  f.Scenario.Day,
+		Apr:                  apr,
+		Validators:           len(eligible),
+		EffectiveBalanceGwei: decimal.NewFromInt(int64(effective)),
+		StartBalanceGwei:     decimal.NewFromInt(int64(start)),
+		EndBalanceGwei:       decimal.NewFromInt(int64(end)),
+		DepositsSumGwei:      decimal.NewFromInt(int64(deposits)),
+		WithdrawalsSumGwei:   decimal.NewFromInt(int64(withdrawals)),
+		ConsensusRewardsGwei: consensusRewardsGwei,
+		TxFeesSumWei:         txFeesWei,
+		TotalRewardsWei:      totalRewardsWei,
+		MissedSlots:          len(f.missed),
+	}
+}
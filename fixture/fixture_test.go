@@ -0,0 +1,146 @@
+package fixture
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestGenerate(t *testing.T) {
+	f, err := Generate(Scenario{
+		Day:                 10,
+		Validators:          4,
+		ConsensusRewardGwei: 7200,
+		TxFeeGwei:           100,
+		MissedSlots:         []uint64{1},
+		Exits:               []Exit{{Validator: 3, Epoch: -1}},
+		Deposits:            []Deposit{{Validator: 0, Slot: 10, AmountGwei: 1e9}},
+		Withdrawals:         []Withdrawal{{Validator: 1, Slot: 20, AmountGwei: 500}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := f.Expected
+	// validator 3 exited before the day, the other 3 validators propose every slot but the missed one in turns
+	if e.Validators != 3 || e.MissedSlots != 1 {
+		t.Errorf("wrong validators or missed slots: %v %v", e.Validators, e.MissedSlots)
+	}
+	want := map[string]decimal.Decimal{
+		"effectiveBalanceGwei": decimal.NewFromInt(3 * 32e9),
+		"startBalanceGwei":     decimal.NewFromInt(3 * 32e9),
+		"endBalanceGwei":       decimal.NewFromInt(3*32e9 + 3*7200 + 1e9 - 500),
+		"depositsSumGwei":      decimal.NewFromInt(1e9),
+		"withdrawalsSumGwei":   decimal.NewFromInt(500),
+		"consensusRewardsGwei": decimal.NewFromInt(3 * 7200),
+		"txFeesSumWei":         decimal.NewFromInt(7199 * 100e9),
+		"totalRewardsWei":      decimal.NewFromInt(3*7200e9 + 7199*100e9),
+	}
+	got := map[string]decimal.Decimal{
+		"effectiveBalanceGwei": e.EffectiveBalanceGwei,
+		"startBalanceGwei":     e.StartBalanceGwei,
+		"endBalanceGwei":       e.EndBalanceGwei,
+		"depositsSumGwei":      e.DepositsSumGwei,
+		"withdrawalsSumGwei":   e.WithdrawalsSumGwei,
+		"consensusRewardsGwei": e.ConsensusRewardsGwei,
+		"txFeesSumWei":         e.TxFeesSumWei,
+		"totalRewardsWei":      e.TotalRewardsWei,
+	}
+	for field, w := range want {
+		if !got[field].Equal(w) {
+			t.Errorf("wrong %v: %v != %v", field, got[field], w)
+		}
+	}
+	apr := decimal.NewFromInt(365).Mul(want["totalRewardsWei"]).Div(decimal.NewFromInt(3 * 32e9).Shift(9))
+	if !e.Apr.Equal(apr) {
+		t.Errorf("wrong apr: %v != %v", e.Apr, apr)
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	for _, s := range []Scenario{
+		{},
+		{Validators: 1, Deposits: []Deposit{{Validator: 1}}},
+		{Validators: 1, Deposits: []Deposit{{Slot: SlotsPerDay}}},
+		{Validators: 1, MissedSlots: []uint64{5}, Withdrawals: []Withdrawal{{Slot: 5}}},
+		{Validators: 1, Withdrawals: []Withdrawal{{AmountGwei: 33e9}}},
+		{Day: 1, Validators: 1, Activations: []Activation{{Epoch: -EpochsPerDay - 1}}},
+		{Validators: 2, Activations: []Activation{{Validator: 1, Epoch: 5}}, Exits: []Exit{{Validator: 1, Epoch: 5}}},
+	} {
+		if _, err := Generate(s); err == nil {
+			t.Errorf("expected error for scenario %+v", s)
+		}
+	}
+}
+
+func TestServers(t *testing.T) {
+	f, err := Generate(Scenario{Day: 10, Validators: 3, ConsensusRewardGwei: 7200, TxFeeGwei: 100, BaseFeeGwei: 1, MissedSlots: []uint64{2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bnServer, elServer := f.Servers()
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	get := func(path string, v interface{}) int {
+		res, err := http.Get(bnServer.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if v != nil && res.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return res.StatusCode
+	}
+	var validators struct {
+		Data []struct {
+			Index   string `json:"index"`
+			Balance string `json:"balance"`
+			Status  string `json:"status"`
+		} `json:"data"`
+	}
+	get("/eth/v1/beacon/states/79200/validators?id=1,2", &validators)
+	if len(validators.Data) != 2 || validators.Data[0].Index != "1" || validators.Data[0].Balance != "32000007200" || validators.Data[0].Status != "active_ongoing" {
+		t.Errorf("wrong validators: %+v", validators.Data)
+	}
+	if status := get(fmt.Sprintf("/eth/v2/beacon/blocks/%d", 72002), nil); status != http.StatusNotFound {
+		t.Errorf("wrong status of missed slot: %v", status)
+	}
+	var block struct {
+		Version string `json:"version"`
+	}
+	if status := get("/eth/v2/beacon/blocks/72003", &block); status != http.StatusOK || block.Version != "capella" {
+		t.Errorf("wrong block: %v %v", status, block.Version)
+	}
+
+	batch := fmt.Sprintf(`[{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionReceipt","params":["%s"]},{"jsonrpc":"2.0","id":2,"method":"eth_unknown","params":[]}]`, f.txHash.Hex())
+	res, err := http.Post(elServer.URL, "application/json", strings.NewReader(batch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var responses []struct {
+		ID     int `json:"id"`
+		Result *struct {
+			EffectiveGasPrice string `json:"effectiveGasPrice"`
+			GasUsed           string `json:"gasUsed"`
+		} `json:"result"`
+		Error *jsonrpcError `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&responses); err != nil {
+		t.Fatal(err)
+	}
+	// 1 Gwei base fee and 1e6 Wei priority fee per gas for a fee of 100 Gwei at 100000 gas
+	if len(responses) != 2 || responses[0].ID != 1 || responses[0].Result == nil || responses[0].Result.EffectiveGasPrice != fmt.Sprintf("%#x", uint64(1e9+1e6)) || responses[0].Result.GasUsed != "0x186a0" {
+		t.Errorf("wrong receipt: %+v", responses)
+	}
+	if responses[1].ID != 2 || responses[1].Error == nil {
+		t.Errorf("expected error of unknown method: %+v", responses[1])
+	}
+}
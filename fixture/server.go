@@ -0,0 +1,407 @@
+package fixture
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// spec is the mainnet config of the beacon api with all forks up to capella at genesis.
+const spec = `{"data":{"CONFIG_NAME":"mainnet","PRESET_BASE":"mainnet","TERMINAL_TOTAL_DIFFICULTY":"115792089237316195423570985008687907853269984665640564039457584007913129638912","TERMINAL_BLOCK_HASH":"0x0000000000000000000000000000000000000000000000000000000000000000","TERMINAL_BLOCK_HASH_ACTIVATION_EPOCH":"18446744073709551615","SAFE_SLOTS_TO_IMPORT_OPTIMISTICALLY":"128","MIN_GENESIS_ACTIVE_VALIDATOR_COUNT":"16384","MIN_GENESIS_TIME":"1606824000","GENESIS_FORK_VERSION":"0x00000000","GENESIS_DELAY":"604800","ALTAIR_FORK_VERSION":"0x01000000","ALTAIR_FORK_EPOCH":"0","BELLATRIX_FORK_VERSION":"0x02000000","BELLATRIX_FORK_EPOCH":"0","CAPELLA_FORK_VERSION":"0x03000000","CAPELLA_FORK_EPOCH":"0","SECONDS_PER_SLOT":"12","SECONDS_PER_ETH1_BLOCK":"14","MIN_VALIDATOR_WITHDRAWABILITY_DELAY":"256","SHARD_COMMITTEE_PERIOD":"256","ETH1_FOLLOW_DISTANCE":"2048","INACTIVITY_SCORE_BIAS":"4","INACTIVITY_SCORE_RECOVERY_RATE":"16","EJECTION_BALANCE":"16000000000","MIN_PER_EPOCH_CHURN_LIMIT":"4","CHURN_LIMIT_QUOTIENT":"65536","PROPOSER_SCORE_BOOST":"40","DEPOSIT_CHAIN_ID":"1","DEPOSIT_NETWORK_ID":"1","DEPOSIT_CONTRACT_ADDRESS":"0x00000000219ab540356cbb839cbe05303d7705fa","MAX_COMMITTEES_PER_SLOT":"64","TARGET_COMMITTEE_SIZE":"128","MAX_VALIDATORS_PER_COMMITTEE":"2048","SHUFFLE_ROUND_COUNT":"90","HYSTERESIS_QUOTIENT":"4","HYSTERESIS_DOWNWARD_MULTIPLIER":"1","HYSTERESIS_UPWARD_MULTIPLIER":"5","SAFE_SLOTS_TO_UPDATE_JUSTIFIED":"8","MIN_DEPOSIT_AMOUNT":"1000000000","MAX_EFFECTIVE_BALANCE":"32000000000","EFFECTIVE_BALANCE_INCREMENT":"1000000000","MIN_ATTESTATION_INCLUSION_DELAY":"1","SLOTS_PER_EPOCH":"32","MIN_SEED_LOOKAHEAD":"1","MAX_SEED_LOOKAHEAD":"4","EPOCHS_PER_ETH1_VOTING_PERIOD":"64","SLOTS_PER_HISTORICAL_ROOT":"8192","MIN_EPOCHS_TO_INACTIVITY_PENALTY":"4","EPOCHS_PER_HISTORICAL_VECTOR":"65536","EPOCHS_PER_SLASHINGS_VECTOR":"8192","HISTORICAL_ROOTS_LIMIT":"16777216","VALIDATOR_REGISTRY_LIMIT":"1099511627776","BASE_REWARD_FACTOR":"64","WHISTLEBLOWER_REWARD_QUOTIENT":"512","PROPOSER_REWARD_QUOTIENT":"8","INACTIVITY_PENALTY_QUOTIENT":"67108864","MIN_SLASHING_PENALTY_QUOTIENT":"128","PROPORTIONAL_SLASHING_MULTIPLIER":"1","MAX_PROPOSER_SLASHINGS":"16","MAX_ATTESTER_SLASHINGS":"2","MAX_ATTESTATIONS":"128","MAX_DEPOSITS":"16","MAX_VOLUNTARY_EXITS":"16","INACTIVITY_PENALTY_QUOTIENT_ALTAIR":"50331648","MIN_SLASHING_PENALTY_QUOTIENT_ALTAIR":"64","PROPORTIONAL_SLASHING_MULTIPLIER_ALTAIR":"2","SYNC_COMMITTEE_SIZE":"512","EPOCHS_PER_SYNC_COMMITTEE_PERIOD":"256","MIN_SYNC_COMMITTEE_PARTICIPANTS":"1","RANDOM_SUBNETS_PER_VALIDATOR":"1","EPOCHS_PER_RANDOM_SUBNET_SUBSCRIPTION":"256","DOMAIN_DEPOSIT":"0x03000000","DOMAIN_SELECTION_PROOF":"0x05000000","DOMAIN_BEACON_ATTESTER":"0x01000000","BLS_WITHDRAWAL_PREFIX":"0x00","TARGET_AGGREGATORS_PER_COMMITTEE":"16","DOMAIN_BEACON_PROPOSER":"0x00000000","DOMAIN_VOLUNTARY_EXIT":"0x04000000","DOMAIN_RANDAO":"0x02000000","DOMAIN_AGGREGATE_AND_PROOF":"0x06000000"}}`
+
+const (
+	genesis         = `{"data":{"genesis_time":"1606824023","genesis_validators_root":"0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95","genesis_fork_version":"0x00000000"}}`
+	depositContract = `{"data":{"chain_id":"1","address":"0x00000000219ab540356cbb839cbe05303d7705fa"}}`
+	forkSchedule    = `{"data":[{"previous_version":"0x00000000","current_version":"0x00000000","epoch":"0"},{"previous_version":"0x00000000","current_version":"0x01000000","epoch":"0"},{"previous_version":"0x01000000","current_version":"0x02000000","epoch":"0"},{"previous_version":"0x02000000","current_version":"0x03000000","epoch":"0"}]}`
+	nodeVersion     = `{"data":{"version":"eth.store-fixture"}}`
+	notFound        = `{"code":404,"message":"NOT_FOUND"}`
+
+	zeroRoot      = "0x0000000000000000000000000000000000000000000000000000000000000000"
+	zeroAddress   = "0x0000000000000000000000000000000000000000"
+	zeroSignature = "0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+)
+
+// Servers starts a beacon node and an execution node that serve the fixture, the caller has to close them.
+func (f *Fixture) Servers() (bnServer, elServer *httptest.Server) {
+	return httptest.NewServer(f.BeaconHandler()), httptest.NewServer(f.ExecutionHandler())
+}
+
+// finalizedSlot returns the slot of the finalized head of the fixture, the day after the day of the scenario is
+// finalized.
+func (f *Fixture) finalizedSlot() uint64 {
+	return f.endSlot() + SlotsPerDay
+}
+
+// BeaconHandler returns the handler of the beacon api of the fixture. States are served for the slots from the start
+// of the day up to the finalized head, blocks for all slots that are not missed.
+func (f *Fixture) BeaconHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := r.URL.Path
+		switch {
+		case path == "/eth/v1/beacon/genesis":
+			w.Write([]byte(genesis))
+		case path == "/eth/v1/config/spec":
+			w.Write([]byte(spec))
+		case path == "/eth/v1/config/deposit_contract":
+			w.Write([]byte(depositContract))
+		case path == "/eth/v1/config/fork_schedule":
+			w.Write([]byte(forkSchedule))
+		case path == "/eth/v1/node/version":
+			w.Write([]byte(nodeVersion))
+		case path == "/eth/v1/node/health":
+		case path == "/eth/v1/node/syncing":
+			fmt.Fprintf(w, `{"data":{"head_slot":"%d","sync_distance":"0","is_syncing":false,"is_optimistic":false,"el_offline":false}}`, f.finalizedSlot())
+		case path == "/eth/v1/beacon/headers/finalized" || path == "/eth/v1/beacon/headers/head":
+			slot := f.finalizedSlot()
+			fmt.Fprintf(w, `{"data":{"root":"%s","canonical":true,"header":{"message":{"slot":"%d","proposer_index":"0","parent_root":"%s","state_root":"%s","body_root":"%s"},"signature":"%s"}}}`, slotRoot("block", slot), slot, zeroRoot, slotRoot("state", slot), zeroRoot, zeroSignature)
+		case strings.HasPrefix(path, "/eth/v1/beacon/states/"):
+			f.serveState(w, r, strings.Split(strings.TrimPrefix(path, "/eth/v1/beacon/states/"), "/"))
+		case strings.HasPrefix(path, "/eth/v2/beacon/blocks/"):
+			slot, err := strconv.ParseUint(strings.TrimPrefix(path, "/eth/v2/beacon/blocks/"), 10, 64)
+			if err != nil {
+				http.Error(w, notFound, http.StatusNotFound)
+				return
+			}
+			block, ok := f.block(slot)
+			if !ok {
+				http.Error(w, notFound, http.StatusNotFound)
+				return
+			}
+			w.Write(block)
+		case strings.HasPrefix(path, "/eth/v1/beacon/blinded_blocks/"):
+			slot, err := strconv.ParseUint(strings.TrimPrefix(path, "/eth/v1/beacon/blinded_blocks/"), 10, 64)
+			if err != nil {
+				http.Error(w, notFound, http.StatusNotFound)
+				return
+			}
+			block, ok := f.blindedBlock(slot)
+			if !ok {
+				http.Error(w, notFound, http.StatusNotFound)
+				return
+			}
+			w.Write(block)
+		default:
+			http.Error(w, notFound, http.StatusNotFound)
+		}
+	})
+}
+
+// serveState serves the requests of a state, the parts are the path after /eth/v1/beacon/states/.
+func (f *Fixture) serveState(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) != 2 {
+		http.Error(w, notFound, http.StatusNotFound)
+		return
+	}
+	slot, err := strconv.ParseUint(parts[0], 10, 64)
+	switch {
+	case parts[0] == "head" || parts[0] == "finalized":
+		slot = f.finalizedSlot()
+	case err != nil || slot < f.firstSlot || slot > f.finalizedSlot():
+		http.Error(w, notFound, http.StatusNotFound)
+		return
+	}
+	epoch := slot / SlotsPerEpoch
+	switch parts[1] {
+	case "root":
+		fmt.Fprintf(w, `{"data":{"root":"%s"}}`, slotRoot("state", slot))
+	case "finality_checkpoints":
+		finalized := uint64(0)
+		if epoch > 2 {
+			finalized = epoch - 2
+		}
+		fmt.Fprintf(w, `{"data":{"previous_justified":{"epoch":"%[1]d","root":"%[2]s"},"current_justified":{"epoch":"%[3]d","root":"%[2]s"},"finalized":{"epoch":"%[1]d","root":"%[2]s"}}}`, finalized, zeroRoot, finalized+1)
+	case "validators", "validator_balances":
+		type validatorData struct {
+			Pubkey                     string `json:"pubkey"`
+			WithdrawalCredentials      string `json:"withdrawal_credentials"`
+			EffectiveBalance           string `json:"effective_balance"`
+			Slashed                    bool   `json:"slashed"`
+			ActivationEligibilityEpoch string `json:"activation_eligibility_epoch"`
+			ActivationEpoch            string `json:"activation_epoch"`
+			ExitEpoch                  string `json:"exit_epoch"`
+			WithdrawableEpoch          string `json:"withdrawable_epoch"`
+		}
+		type validatorResponse struct {
+			Index     string         `json:"index"`
+			Balance   string         `json:"balance"`
+			Status    string         `json:"status,omitempty"`
+			Validator *validatorData `json:"validator,omitempty"`
+		}
+		data := []validatorResponse{}
+		for _, v := range f.requestedValidators(r.URL.Query().Get("id")) {
+			res := validatorResponse{Index: fmt.Sprintf("%d", v.index), Balance: fmt.Sprintf("%d", f.balance(v, slot))}
+			if parts[1] == "validators" {
+				withdrawable := uint64(farFutureEpoch)
+				if v.exitEpoch != farFutureEpoch {
+					withdrawable = v.exitEpoch + withdrawabilityDelay
+				}
+				res.Status = f.status(v, epoch)
+				res.Validator = &validatorData{
+					Pubkey:                     hexutil.Encode(v.pubkey),
+					WithdrawalCredentials:      hexutil.Encode(v.withdrawalCredentials),
+					EffectiveBalance:           fmt.Sprintf("%d", f.effectiveBalance()),
+					ActivationEligibilityEpoch: "0",
+					ActivationEpoch:            fmt.Sprintf("%d", v.activationEpoch),
+					ExitEpoch:                  fmt.Sprintf("%d", v.exitEpoch),
+					WithdrawableEpoch:          fmt.Sprintf("%d", withdrawable),
+				}
+			}
+			data = append(data, res)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"execution_optimistic": false, "finalized": true, "data": data})
+	default:
+		http.Error(w, notFound, http.StatusNotFound)
+	}
+}
+
+// requestedValidators returns the validators of the comma-separated indices or pubkeys of an id query, all validators
+// if it is empty.
+func (f *Fixture) requestedValidators(ids string) []*validator {
+	if ids == "" {
+		return f.validators
+	}
+	requested := []*validator{}
+	for _, id := range strings.Split(ids, ",") {
+		for _, v := range f.validators {
+			if id == fmt.Sprintf("%d", v.index) || strings.EqualFold(id, hexutil.Encode(v.pubkey)) {
+				requested = append(requested, v)
+				break
+			}
+		}
+	}
+	return requested
+}
+
+// block returns the capella block of the slot as the beacon api serves it.
+func (f *Fixture) block(slot uint64) ([]byte, bool) {
+	inDay := slot >= f.firstSlot && slot < f.endSlot()
+	if inDay && f.missed[slot-f.firstSlot] {
+		return nil, false
+	}
+	proposer, ok := f.proposer(slot)
+	if !ok {
+		return nil, false
+	}
+	deposits := []string{}
+	withdrawals := []string{}
+	if inDay {
+		proof := make([]string, 33)
+		for i := range proof {
+			proof[i] = `"` + zeroRoot + `"`
+		}
+		for _, d := range f.deposits[slot-f.firstSlot] {
+			deposits = append(deposits, fmt.Sprintf(`{"proof":[%s],"data":{"pubkey":"%#x","withdrawal_credentials":"%#x","amount":"%d","signature":"%#x"}}`, strings.Join(proof, ","), d.pubkey, d.withdrawalCredentials, d.AmountGwei, d.signature))
+		}
+		for i, wd := range f.withdrawals[slot-f.firstSlot] {
+			withdrawals = append(withdrawals, fmt.Sprintf(`{"index":"%d","validator_index":"%d","address":"%s","amount":"%d"}`, slot*16+uint64(i), wd.Validator, zeroAddress, wd.AmountGwei))
+		}
+	}
+	return []byte(fmt.Sprintf(`{"version":"capella","execution_optimistic":false,"finalized":true,"data":{"message":{"slot":"%[1]d","proposer_index":"%[2]d","parent_root":"%[3]s","state_root":"%[4]s","body":{"randao_reveal":"%[5]s","eth1_data":{"deposit_root":"%[6]s","deposit_count":"0","block_hash":"%[6]s"},"graffiti":"%[6]s","proposer_slashings":[],"attester_slashings":[],"attestations":[],"deposits":[%[7]s],"voluntary_exits":[],"sync_aggregate":{"sync_committee_bits":"0x%[8]s","sync_committee_signature":"%[5]s"},"execution_payload":{"parent_hash":"%[9]s","fee_recipient":"%[10]s","state_root":"%[4]s","receipts_root":"%[6]s","logs_bloom":"0x%[11]s","prev_randao":"%[6]s","block_number":"%[1]d","gas_limit":"30000000","gas_used":"%[12]d","timestamp":"%[13]d","extra_data":"0x","base_fee_per_gas":"%[14]s","block_hash":"%[15]s","transactions":["%#[16]x"],"withdrawals":[%[17]s]},"bls_to_execution_changes":[]}},"signature":"%[5]s"}}`,
+		slot, proposer.index, slotRoot("block", slot-1), slotRoot("state", slot), zeroSignature, zeroRoot, strings.Join(deposits, ","), strings.Repeat("f", 128),
+		f.executionBlockHash(slot-1), zeroAddress, strings.Repeat("0", 512), txGasUsed, GenesisTime+slot*SecondsPerSlot, f.baseFeePerGas(), f.executionBlockHash(slot), f.tx, strings.Join(withdrawals, ","))), true
+}
+
+// blindedBlock returns the blinded block of the slot as the beacon api serves it, the transactions and withdrawals of
+// its execution payload are served by the execution node.
+func (f *Fixture) blindedBlock(slot uint64) ([]byte, bool) {
+	data, ok := f.block(slot)
+	if !ok {
+		return nil, false
+	}
+	var block struct {
+		Version string `json:"version"`
+		Data    struct {
+			Message   map[string]json.RawMessage `json:"message"`
+			Signature string                     `json:"signature"`
+		} `json:"data"`
+	}
+	var body, header map[string]json.RawMessage
+	if err := json.Unmarshal(data, &block); err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(block.Data.Message["body"], &body); err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(body["execution_payload"], &header); err != nil {
+		panic(err)
+	}
+	delete(header, "transactions")
+	delete(header, "withdrawals")
+	header["transactions_root"] = json.RawMessage(`"` + zeroRoot + `"`)
+	header["withdrawals_root"] = json.RawMessage(`"` + zeroRoot + `"`)
+	delete(body, "execution_payload")
+	body["execution_payload_header"], _ = json.Marshal(header)
+	block.Data.Message["body"], _ = json.Marshal(body)
+	res, _ := json.Marshal(map[string]interface{}{"version": block.Version, "execution_optimistic": false, "finalized": true, "data": block.Data})
+	return res, true
+}
+
+// slotRoot returns a deterministic root of the given kind for the slot.
+func slotRoot(kind string, slot uint64) string {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, slot)
+	return crypto.Keccak256Hash([]byte(kind), b).Hex()
+}
+
+// executionBlockHash returns the hash of the execution block of the slot. Like the hash of a real block it commits to
+// the fees of the block, so fixtures of scenarios with different fees have different blocks.
+func (f *Fixture) executionBlockHash(slot uint64) string {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, slot)
+	return crypto.Keccak256Hash([]byte("execution"), b, f.baseFeePerGas().Bytes(), f.priorityFeePerGas().Bytes()).Hex()
+}
+
+// slotOfExecutionBlock returns the slot of the execution block with the given hash, missed slots have no block.
+func (f *Fixture) slotOfExecutionBlock(hash string) (uint64, bool) {
+	f.blockSlotsOnce.Do(func() {
+		f.blockSlots = map[string]uint64{}
+		for slot := f.firstSlot; slot < f.endSlot(); slot++ {
+			if !f.missed[slot-f.firstSlot] {
+				f.blockSlots[strings.ToLower(f.executionBlockHash(slot))] = slot
+			}
+		}
+	})
+	slot, exists := f.blockSlots[strings.ToLower(hash)]
+	return slot, exists
+}
+
+type jsonrpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type jsonrpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ExecutionHandler returns the handler of the json-rpc of the execution node of the fixture, it serves single and
+// batched requests of the chain id, of the blocks of the day by hash and of the receipt of the transaction of the
+// blocks.
+func (f *Fixture) ExecutionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		body = []byte(strings.TrimSpace(string(body)))
+		if len(body) > 0 && body[0] == '[' {
+			var requests []jsonrpcRequest
+			if err := json.Unmarshal(body, &requests); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			responses := make([]jsonrpcResponse, len(requests))
+			for i, req := range requests {
+				responses[i] = f.executionResponse(req)
+			}
+			json.NewEncoder(w).Encode(responses)
+			return
+		}
+		var req jsonrpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(f.executionResponse(req))
+	})
+}
+
+func (f *Fixture) executionResponse(req jsonrpcRequest) jsonrpcResponse {
+	res := jsonrpcResponse{Version: "2.0", ID: req.ID}
+	switch req.Method {
+	case "eth_chainId":
+		res.Result = "0x1"
+	case "eth_getTransactionReceipt":
+		var hash common.Hash
+		if len(req.Params) != 1 || json.Unmarshal(req.Params[0], &hash) != nil || hash != f.txHash {
+			// unknown transactions have no receipt
+			res.Result = json.RawMessage("null")
+			return res
+		}
+		res.Result = f.receipt()
+	case "eth_getBlockByHash":
+		var hash string
+		if len(req.Params) != 2 || json.Unmarshal(req.Params[0], &hash) != nil {
+			res.Result = json.RawMessage("null")
+			return res
+		}
+		slot, exists := f.slotOfExecutionBlock(hash)
+		if !exists {
+			res.Result = json.RawMessage("null")
+			return res
+		}
+		{
+			withdrawals := []map[string]string{}
+			for i, wd := range f.withdrawals[slot-f.firstSlot] {
+				withdrawals = append(withdrawals, map[string]string{
+					"index":          hexutil.EncodeUint64(slot*16 + uint64(i)),
+					"validatorIndex": hexutil.EncodeUint64(uint64(wd.Validator)),
+					"address":        zeroAddress,
+					"amount":         hexutil.EncodeUint64(wd.AmountGwei),
+				})
+			}
+			res.Result = map[string]interface{}{
+				"hash":         f.executionBlockHash(slot),
+				"number":       hexutil.EncodeUint64(slot),
+				"transactions": []string{f.txHash.Hex()},
+				"withdrawals":  withdrawals,
+			}
+		}
+	case "eth_getBlockReceipts":
+		var hash string
+		if len(req.Params) != 1 || json.Unmarshal(req.Params[0], &hash) != nil {
+			res.Result = json.RawMessage("null")
+			return res
+		}
+		if _, exists := f.slotOfExecutionBlock(hash); !exists {
+			res.Result = json.RawMessage("null")
+			return res
+		}
+		res.Result = []interface{}{f.receipt()}
+	default:
+		res.Error = &jsonrpcError{Code: -32601, Message: fmt.Sprintf("the method %v does not exist/is not available", req.Method)}
+	}
+	return res
+}
+
+// receipt returns the receipt of the transaction of every block.
+func (f *Fixture) receipt() map[string]interface{} {
+	return map[string]interface{}{
+		"blockHash":         zeroRoot,
+		"blockNumber":       "0x0",
+		"contractAddress":   nil,
+		"cumulativeGasUsed": hexutil.EncodeUint64(txGasUsed),
+		"effectiveGasPrice": hexutil.EncodeBig(new(big.Int).Add(f.baseFeePerGas(), f.priorityFeePerGas())),
+		"from":              zeroAddress,
+		"gasUsed":           hexutil.EncodeUint64(txGasUsed),
+		"logs":              []interface{}{},
+		"logsBloom":         "0x" + strings.Repeat("0", 512),
+		"status":            "0x1",
+		"to":                "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d",
+		"transactionHash":   f.txHash.Hex(),
+		"transactionIndex":  "0x0",
+		"type":              "0x0",
+	}
+}
@@ -0,0 +1,81 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gobitfly/eth.store/fixture"
+)
+
+func TestFixtureScenarios(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario fixture.Scenario
+	}{
+		{
+			name:     "steady",
+			scenario: fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000, BaseFeeGwei: 10},
+		},
+		{
+			name: "lifecycle",
+			scenario: fixture.Scenario{
+				Day:                 10,
+				Validators:          20,
+				ConsensusRewardGwei: 2500000,
+				TxFeeGwei:           20000,
+				MissedSlots:         []uint64{0, 17, 7199},
+				// validator 0 exited before the day, 1 exits at the last epoch of the day and 2 is activated during the day
+				Activations: []fixture.Activation{{Validator: 2, Epoch: 1}, {Validator: 3, Epoch: -10}},
+				Exits:       []fixture.Exit{{Validator: 0, Epoch: -1}, {Validator: 1, Epoch: fixture.EpochsPerDay - 1}},
+				Deposits:    []fixture.Deposit{{Validator: 4, Slot: 1, AmountGwei: 1e9}, {Validator: 5, Slot: 100, AmountGwei: 32e9}, {Validator: 2, Slot: 5, AmountGwei: 1e9}},
+				Withdrawals: []fixture.Withdrawal{{Validator: 6, Slot: 3000, AmountGwei: 15000}, {Validator: 4, Slot: 7000, AmountGwei: 1e9}},
+			},
+		},
+		{
+			name:     "underfunded",
+			scenario: fixture.Scenario{Day: 3, Validators: 8, StartBalanceGwei: 31.5e9, ConsensusRewardGwei: 1000000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := fixture.Generate(tt.scenario)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bnServer, elServer := f.Servers()
+			defer bnServer.Close()
+			defer elServer.Close()
+
+			day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, fmt.Sprintf("%d", tt.scenario.Day), 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := f.Expected
+			if day.Validators.IntPart() != int64(e.Validators) {
+				t.Errorf("wrong validators: %v != %v", day.Validators, e.Validators)
+			}
+			if day.MissedSlots.IntPart() != int64(e.MissedSlots) {
+				t.Errorf("wrong missed slots: %v != %v", day.MissedSlots, e.MissedSlots)
+			}
+			for _, c := range []struct {
+				field     string
+				got, want interface{ String() string }
+			}{
+				{"apr", day.Apr, e.Apr},
+				{"effectiveBalanceGwei", day.EffectiveBalanceGwei, e.EffectiveBalanceGwei},
+				{"startBalanceGwei", day.StartBalanceGwei, e.StartBalanceGwei},
+				{"endBalanceGwei", day.EndBalanceGwei, e.EndBalanceGwei},
+				{"depositsSumGwei", day.DepositsSumGwei, e.DepositsSumGwei},
+				{"withdrawalsSumGwei", day.WithdrawalsSumGwei, e.WithdrawalsSumGwei},
+				{"consensusRewardsGwei", day.ConsensusRewardsGwei, e.ConsensusRewardsGwei},
+				{"txFeesSumWei", day.TxFeesSumWei, e.TxFeesSumWei},
+				{"totalRewardsWei", day.TotalRewardsWei, e.TotalRewardsWei},
+			} {
+				if c.got.String() != c.want.String() {
+					t.Errorf("wrong %v: %v != %v", c.field, c.got, c.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package ethstore
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+type AprForecast struct {
+	Horizon    int             `json:"horizon"`
+	Days       int             `json:"days"`
+	Apr        decimal.Decimal `json:"apr"`
+	StdDev     decimal.Decimal `json:"stdDev"`
+	LowerBound decimal.Decimal `json:"lowerBound"`
+	UpperBound decimal.Decimal `json:"upperBound"`
+}
+
+// Forecast projects the average apr of the next horizon days from the given trailing window of days.
+// The projection is the exponentially weighted moving average (alpha = 2/(len(days)+1)) of the daily aprs,
+// the band is the 95% interval derived from the exponentially weighted variance of the daily aprs around it.
+func Forecast(days []Day, horizon int) (*AprForecast, error) {
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no days to forecast from")
+	}
+	if horizon < 1 {
+		return nil, fmt.Errorf("invalid horizon: %v", horizon)
+	}
+
+	sorted := make([]Day, len(days))
+	copy(sorted, days)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Day.LessThan(sorted[j].Day)
+	})
+
+	alpha := 2 / (float64(len(sorted)) + 1)
+	ewma := sorted[0].Apr.InexactFloat64()
+	ewvar := 0.0
+	for _, d := range sorted[1:] {
+		diff := d.Apr.InexactFloat64() - ewma
+		ewma += alpha * diff
+		ewvar = (1 - alpha) * (ewvar + alpha*diff*diff)
+	}
+
+	// the average over the horizon varies by stdDev/sqrt(horizon) around the level, the level itself
+	// is only known up to the standard error of the ewma
+	stdDev := math.Sqrt(ewvar)
+	bandWidth := 1.96 * stdDev * math.Sqrt(1/float64(horizon)+alpha/(2-alpha))
+
+	return &AprForecast{
+		Horizon:    horizon,
+		Days:       len(sorted),
+		Apr:        decimal.NewFromFloat(ewma),
+		StdDev:     decimal.NewFromFloat(stdDev),
+		LowerBound: decimal.NewFromFloat(ewma - bandWidth),
+		UpperBound: decimal.NewFromFloat(ewma + bandWidth),
+	}, nil
+}
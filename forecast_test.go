@@ -0,0 +1,57 @@
+package ethstore
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestForecast(t *testing.T) {
+	if _, err := Forecast(nil, 7); err == nil {
+		t.Errorf("expected error for empty days")
+	}
+	if _, err := Forecast([]Day{{Apr: decimal.NewFromFloat(0.04)}}, 0); err == nil {
+		t.Errorf("expected error for horizon 0")
+	}
+
+	// a constant apr forecasts itself without any uncertainty
+	constant := []Day{}
+	for i := 0; i < 30; i++ {
+		constant = append(constant, Day{Day: decimal.NewFromInt(int64(i)), Apr: decimal.NewFromFloat(0.04)})
+	}
+	f, err := Forecast(constant, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Apr.Equal(decimal.NewFromFloat(0.04)) || !f.LowerBound.Equal(f.Apr) || !f.UpperBound.Equal(f.Apr) {
+		t.Errorf("wrong forecast for constant apr: %+v", f)
+	}
+
+	// alternating aprs around 0.04 forecast roughly 0.04 with a symmetric band that narrows for longer horizons,
+	// the order of the given days must not matter
+	alternating := []Day{}
+	for i := 29; i >= 0; i-- {
+		apr := 0.03
+		if i%2 == 0 {
+			apr = 0.05
+		}
+		alternating = append(alternating, Day{Day: decimal.NewFromInt(int64(i)), Apr: decimal.NewFromFloat(apr)})
+	}
+	short, err := Forecast(alternating, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	long, err := Forecast(alternating, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if short.Apr.Sub(decimal.NewFromFloat(0.04)).Abs().GreaterThan(decimal.NewFromFloat(0.002)) {
+		t.Errorf("wrong forecast apr: %v", short.Apr)
+	}
+	if !short.Apr.Sub(short.LowerBound).Sub(short.UpperBound.Sub(short.Apr)).Abs().LessThan(decimal.NewFromFloat(1e-12)) {
+		t.Errorf("asymmetric forecast band: %+v", short)
+	}
+	if !long.UpperBound.Sub(long.LowerBound).LessThan(short.UpperBound.Sub(short.LowerBound)) {
+		t.Errorf("forecast band does not narrow for longer horizon: %+v, %+v", short, long)
+	}
+}
@@ -0,0 +1,37 @@
+package ethstore
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// GasStats holds the gas used and the base fees of all execution blocks of a day, not only of the blocks of the
+// eth.store validators, as context for the tx fees of the day.
+type GasStats struct {
+	Blocks              decimal.Decimal `json:"blocks"`
+	GasUsed             decimal.Decimal `json:"gasUsed"`
+	GasLimit            decimal.Decimal `json:"gasLimit"`
+	GasUtilization      decimal.Decimal `json:"gasUtilization"`
+	BaseFeePerGasSumWei decimal.Decimal `json:"baseFeePerGasSumWei"`
+	AvgBaseFeePerGasWei decimal.Decimal `json:"avgBaseFeePerGasWei"`
+}
+
+// add counts an execution block.
+func (s *GasStats) add(exec *executionBlock) {
+	s.Blocks = s.Blocks.Add(decimal.NewFromInt(1))
+	s.GasUsed = s.GasUsed.Add(decimal.NewFromInt(int64(exec.GasUsed)))
+	s.GasLimit = s.GasLimit.Add(decimal.NewFromInt(int64(exec.GasLimit)))
+	s.BaseFeePerGasSumWei = s.BaseFeePerGasSumWei.Add(decimal.NewFromBigInt(exec.BaseFeePerGas, 0))
+}
+
+// finalize sets the utilization, which is the gas used of all blocks relative to their gas limits, and the average
+// base fee of the blocks.
+func (s *GasStats) finalize() {
+	s.GasUtilization = decimal.Zero
+	if s.GasLimit.IsPositive() {
+		s.GasUtilization = s.GasUsed.Div(s.GasLimit)
+	}
+	s.AvgBaseFeePerGasWei = decimal.Zero
+	if s.Blocks.IsPositive() {
+		s.AvgBaseFeePerGasWei = s.BaseFeePerGasSumWei.Div(s.Blocks)
+	}
+}
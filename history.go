@@ -0,0 +1,201 @@
+package ethstore
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// historyColumn maps a column of an imported history to a field of Day, the value is shifted by shift decimal places,
+// e.g. -9 to convert Wei to Gwei.
+type historyColumn struct {
+	field func(d *Day) *decimal.Decimal
+	shift int32
+}
+
+// historyColumns are the columns of imported histories keyed by their lowercase name without underscores, they cover
+// the columns of the eth.store exports of beaconcha.in (in Wei) and the fields of the json output of eth.store.
+var historyColumns = map[string]historyColumn{
+	"apr":                     {func(d *Day) *decimal.Decimal { return &d.Apr }, 0},
+	"validators":              {func(d *Day) *decimal.Decimal { return &d.Validators }, 0},
+	"effectivebalancessumwei": {func(d *Day) *decimal.Decimal { return &d.EffectiveBalanceGwei }, -9},
+	"effectivebalancegwei":    {func(d *Day) *decimal.Decimal { return &d.EffectiveBalanceGwei }, 0},
+	"startbalancessumwei":     {func(d *Day) *decimal.Decimal { return &d.StartBalanceGwei }, -9},
+	"startbalancegwei":        {func(d *Day) *decimal.Decimal { return &d.StartBalanceGwei }, 0},
+	"endbalancessumwei":       {func(d *Day) *decimal.Decimal { return &d.EndBalanceGwei }, -9},
+	"endbalancegwei":          {func(d *Day) *decimal.Decimal { return &d.EndBalanceGwei }, 0},
+	"depositssumwei":          {func(d *Day) *decimal.Decimal { return &d.DepositsSumGwei }, -9},
+	"depositssumgwei":         {func(d *Day) *decimal.Decimal { return &d.DepositsSumGwei }, 0},
+	"consensusrewardssumwei":  {func(d *Day) *decimal.Decimal { return &d.ConsensusRewardsWei }, 0},
+	"consensusrewardswei":     {func(d *Day) *decimal.Decimal { return &d.ConsensusRewardsWei }, 0},
+	"consensusrewardsgwei":    {func(d *Day) *decimal.Decimal { return &d.ConsensusRewardsGwei }, 0},
+	"txfeessumwei":            {func(d *Day) *decimal.Decimal { return &d.TxFeesSumWei }, 0},
+	"totalrewardswei":         {func(d *Day) *decimal.Decimal { return &d.TotalRewardsWei }, 0},
+}
+
+// ReadHistory reads the days of an eth.store history exported by another source, e.g. beaconcha.in, as csv with a
+// header line or as json array of objects (optionally wrapped in a "data" field) in the given format ("csv" or
+// "json"). The columns day and apr are required, unknown columns are ignored. The days are flagged as Unverified as
+// they have not been calculated from the chain by this eth.store and do not record the state roots of their inputs.
+func ReadHistory(r io.Reader, format string) ([]*Day, error) {
+	var records []map[string]string
+	var err error
+	switch format {
+	case "csv":
+		records, err = readCsvHistory(r)
+	case "json":
+		records, err = readJsonHistory(r)
+	default:
+		return nil, fmt.Errorf("unknown history format: %v", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	days := make([]*Day, 0, len(records))
+	for i, record := range records {
+		d, err := historyDay(record)
+		if err != nil {
+			return nil, fmt.Errorf("invalid day in record %v: %w", i+1, err)
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// historyDay returns the day of a record of a history keyed by normalized column names.
+func historyDay(record map[string]string) (*Day, error) {
+	d := &Day{Unverified: true}
+	day, exists := record["day"]
+	if !exists {
+		return nil, fmt.Errorf("no day")
+	}
+	dayNum, err := strconv.ParseUint(day, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day: %v", day)
+	}
+	d.Day = decimal.NewFromInt(int64(dayNum))
+	if _, exists := record["apr"]; !exists {
+		return nil, fmt.Errorf("no apr of day %v", day)
+	}
+	for name, value := range record {
+		column, exists := historyColumns[name]
+		if !exists || value == "" {
+			continue
+		}
+		v, err := decimal.NewFromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v of day %v: %v", name, day, value)
+		}
+		*column.field(d) = v.Shift(column.shift)
+	}
+	for _, name := range []string{"daystart", "daytime"} {
+		if value := record[name]; value != "" {
+			d.DayTime, err = parseHistoryTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v of day %v: %v", name, day, value)
+			}
+		}
+	}
+	// the consensus rewards are reported in Wei or Gwei, the other unit is derived
+	if d.ConsensusRewardsGwei.IsZero() {
+		d.ConsensusRewardsGwei = d.ConsensusRewardsWei.Shift(-9)
+	} else if d.ConsensusRewardsWei.IsZero() {
+		d.ConsensusRewardsWei = d.ConsensusRewardsGwei.Shift(9)
+	}
+	d.AprBps, d.AprPercent = aprBps(d.Apr), aprPercent(d.Apr)
+	return d, nil
+}
+
+// parseHistoryTime parses a time as RFC 3339, as "2006-01-02 15:04:05" in UTC or as unix timestamp.
+func parseHistoryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02 15:04:05", value); err == nil {
+		return t, nil
+	}
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0).UTC(), nil
+}
+
+// normalizeHistoryColumn returns the column name in lowercase and without underscores, so that "tx_fees_sum_wei" and
+// "txFeesSumWei" match.
+func normalizeHistoryColumn(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "")
+}
+
+func readCsvHistory(r io.Reader) ([]map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv header: %w", err)
+	}
+	for i := range header {
+		header[i] = normalizeHistoryColumn(header[i])
+	}
+	records := []map[string]string{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		record := make(map[string]string, len(row))
+		for i, value := range row {
+			record[header[i]] = strings.TrimSpace(value)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func readJsonHistory(r io.Reader) ([]map[string]string, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing json: %w", err)
+	}
+	// beaconcha.in wraps the days in a data field, a single day is an object
+	if obj, ok := v.(map[string]interface{}); ok {
+		if data, exists := obj["data"]; exists {
+			v = data
+		}
+	}
+	objs, ok := v.([]interface{})
+	if !ok {
+		objs = []interface{}{v}
+	}
+	records := make([]map[string]string, 0, len(objs))
+	for _, o := range objs {
+		obj, ok := o.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected json objects of days")
+		}
+		record := make(map[string]string, len(obj))
+		for name, value := range obj {
+			// nested values like the epoch series of eth.store days are ignored
+			switch value := value.(type) {
+			case string:
+				record[normalizeHistoryColumn(name)] = value
+			case json.Number:
+				record[normalizeHistoryColumn(name)] = value.String()
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,71 @@
+package ethstore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestReadHistory(t *testing.T) {
+	csvHistory := `day,day_start,apr,effective_balances_sum_wei,start_balances_sum_wei,end_balances_sum_wei,deposits_sum_wei,tx_fees_sum_wei,consensus_rewards_sum_wei,total_rewards_wei,cl_apr
+10,2021-01-11 12:00:23,0.05,32000000000000000000,32100000000000000000,32104000000000000000,0,1000000000000000,4000000000000000,5000000000000000,0.04
+11,2021-01-12 12:00:23,0.06,,,,,,,,`
+	jsonHistory := `{"status":"OK","data":[{"day":10,"day_start":"2021-01-11T12:00:23Z","apr":"0.05","effective_balances_sum_wei":"32000000000000000000","consensus_rewards_sum_wei":"4000000000000000","tx_fees_sum_wei":"1000000000000000","total_rewards_wei":"5000000000000000"}]}`
+	// the json output of eth.store
+	ownHistory := `[{"day":"10","dayTime":"2021-01-11T12:00:23Z","apr":"0.05","effectiveBalanceGwei":"32000000000","consensusRewardsGwei":"4000000","txFeesSumWei":"1000000000000000","totalRewardsWei":"5000000000000000","epochSeries":[{"epoch":"1"}]}]`
+
+	for _, tc := range []struct {
+		name    string
+		format  string
+		history string
+		days    int
+	}{
+		{"csv", "csv", csvHistory, 2},
+		{"beaconcha.in json", "json", jsonHistory, 1},
+		{"eth.store json", "json", ownHistory, 1},
+	} {
+		days, err := ReadHistory(strings.NewReader(tc.history), tc.format)
+		if err != nil {
+			t.Errorf("%v: %v", tc.name, err)
+			continue
+		}
+		if len(days) != tc.days {
+			t.Errorf("%v: wrong number of days: %v", tc.name, len(days))
+			continue
+		}
+		d := days[0]
+		if !d.Unverified {
+			t.Errorf("%v: imported day not flagged as unverified", tc.name)
+		}
+		if d.Day.IntPart() != 10 || !d.Apr.Equal(decimal.RequireFromString("0.05")) || !d.AprPercent.Equal(decimal.NewFromInt(5)) {
+			t.Errorf("%v: wrong day or apr: %v, %v, %v", tc.name, d.Day, d.Apr, d.AprPercent)
+		}
+		if !d.EffectiveBalanceGwei.Equal(decimal.NewFromInt(32e9)) || !d.ConsensusRewardsGwei.Equal(decimal.NewFromInt(4e6)) || !d.ConsensusRewardsWei.Equal(decimal.NewFromInt(4e15)) {
+			t.Errorf("%v: wrong balances or rewards: %v, %v, %v", tc.name, d.EffectiveBalanceGwei, d.ConsensusRewardsGwei, d.ConsensusRewardsWei)
+		}
+		if !d.TxFeesSumWei.Equal(decimal.NewFromInt(1e15)) || !d.TotalRewardsWei.Equal(decimal.NewFromInt(5e15)) {
+			t.Errorf("%v: wrong tx fees or total rewards: %v, %v", tc.name, d.TxFeesSumWei, d.TotalRewardsWei)
+		}
+		if !d.DayTime.Equal(time.Date(2021, 1, 11, 12, 0, 23, 0, time.UTC)) {
+			t.Errorf("%v: wrong day time: %v", tc.name, d.DayTime)
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		format  string
+		history string
+	}{
+		{"missing day", "csv", "apr\n0.05"},
+		{"missing apr", "json", `[{"day":10}]`},
+		{"invalid apr", "csv", "day,apr\n10,x"},
+		{"invalid day time", "json", `[{"day":10,"apr":0.05,"day_start":"yesterday"}]`},
+		{"unknown format", "xml", ""},
+	} {
+		if _, err := ReadHistory(strings.NewReader(tc.history), tc.format); err == nil {
+			t.Errorf("%v: expected error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+// DailyIncome is the income of a validator or of the validators of a withdrawal address on a day: the consensus
+// rewards and the execution rewards (tx fees) of the proposed blocks.
+type DailyIncome struct {
+	Day                 uint64          `json:"day"`
+	DayTime             time.Time       `json:"dayTime"`
+	Validators          int             `json:"validators,omitempty"`
+	ConsensusIncomeGwei decimal.Decimal `json:"consensusIncomeGwei"`
+	ExecutionIncomeWei  decimal.Decimal `json:"executionIncomeWei"`
+	TotalIncomeWei      decimal.Decimal `json:"totalIncomeWei"`
+}
+
+// ValidatorResultStore returns stored per-validator results keyed by day and validator index: ValidatorDays the results
+// of a single validator and AddressValidatorDays the results of the validators with the withdrawal address on the
+// stored days in [from, to]. The IncomeAPI only uses this interface, so the results can be kept in any storage that
+// can look them up by validator and withdrawal address.
+type ValidatorResultStore interface {
+	ValidatorDays(ctx context.Context, index, from, to uint64) (map[uint64]map[uint64]*Day, error)
+	AddressValidatorDays(ctx context.Context, address string, from, to uint64) (map[uint64]map[uint64]*Day, error)
+}
+
+// MemoryValidatorStore is a ValidatorResultStore that keeps the per-validator results in memory, indexed by validator
+// and by withdrawal address.
+type MemoryValidatorStore struct {
+	mu          sync.RWMutex
+	byValidator map[uint64]map[uint64]*Day
+	byAddress   map[string]map[uint64]map[uint64]*Day
+}
+
+// NewMemoryValidatorStore returns a memory store without any per-validator results.
+func NewMemoryValidatorStore() *MemoryValidatorStore {
+	return &MemoryValidatorStore{byValidator: map[uint64]map[uint64]*Day{}, byAddress: map[string]map[uint64]map[uint64]*Day{}}
+}
+
+// Update replaces the per-validator results of the store, keyed by day and validator index.
+func (s *MemoryValidatorStore) Update(validatorDaysByDay map[uint64]map[uint64]*Day) {
+	byValidator := map[uint64]map[uint64]*Day{}
+	byAddress := map[string]map[uint64]map[uint64]*Day{}
+	for day, validatorDays := range validatorDaysByDay {
+		for index, d := range validatorDays {
+			if byValidator[index] == nil {
+				byValidator[index] = map[uint64]*Day{}
+			}
+			byValidator[index][day] = d
+			address := strings.ToLower(WithdrawalAddress(d.WithdrawalCredentials))
+			if byAddress[address] == nil {
+				byAddress[address] = map[uint64]map[uint64]*Day{}
+			}
+			if byAddress[address][day] == nil {
+				byAddress[address][day] = map[uint64]*Day{}
+			}
+			byAddress[address][day][index] = d
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byValidator = byValidator
+	s.byAddress = byAddress
+}
+
+func (s *MemoryValidatorStore) ValidatorDays(ctx context.Context, index, from, to uint64) (map[uint64]map[uint64]*Day, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	validatorDays := map[uint64]map[uint64]*Day{}
+	for day, d := range s.byValidator[index] {
+		if day >= from && day <= to {
+			validatorDays[day] = map[uint64]*Day{index: d}
+		}
+	}
+	return validatorDays, nil
+}
+
+func (s *MemoryValidatorStore) AddressValidatorDays(ctx context.Context, address string, from, to uint64) (map[uint64]map[uint64]*Day, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	validatorDays := map[uint64]map[uint64]*Day{}
+	for day, days := range s.byAddress[strings.ToLower(address)] {
+		if day >= from && day <= to {
+			validatorDays[day] = days
+		}
+	}
+	return validatorDays, nil
+}
+
+// IncomeAPI serves the daily income of validators from their stored per-validator results:
+// GET /v1/validators/{index}/income?from=&to= returns the income of the validator on the stored days in [from, to] and
+// GET /v1/addresses/{address}/income?from=&to= returns the income of all validators with the withdrawal address.
+type IncomeAPI struct {
+	store ValidatorResultStore
+}
+
+// NewIncomeAPI returns an income api of the per-validator results of the store.
+func NewIncomeAPI(store ValidatorResultStore) *IncomeAPI {
+	return &IncomeAPI{store: store}
+}
+
+// ValidatorIncome returns the income of the validator on the stored days in [from, to] in ascending order, days on
+// which the validator is not part of the results are left out.
+func (a *IncomeAPI) ValidatorIncome(ctx context.Context, index, from, to uint64) ([]DailyIncome, error) {
+	validatorDays, err := a.store.ValidatorDays(ctx, index, from, to)
+	if err != nil {
+		return nil, err
+	}
+	income := sumDailyIncome(validatorDays)
+	// the number of validators is only reported for withdrawal addresses
+	for i := range income {
+		income[i].Validators = 0
+	}
+	return income, nil
+}
+
+// AddressIncome returns the summed income of the validators whose withdrawal credentials map to the withdrawal
+// address on the stored days in [from, to] in ascending order, days without such validators are left out.
+func (a *IncomeAPI) AddressIncome(ctx context.Context, address string, from, to uint64) ([]DailyIncome, error) {
+	validatorDays, err := a.store.AddressValidatorDays(ctx, strings.ToLower(address), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return sumDailyIncome(validatorDays), nil
+}
+
+// sumDailyIncome sums the income of the per-validator results keyed by day and validator index per day.
+func sumDailyIncome(validatorDaysByDay map[uint64]map[uint64]*Day) []DailyIncome {
+	income := []DailyIncome{}
+	for day, validatorDays := range validatorDaysByDay {
+		var sum *DailyIncome
+		for _, d := range validatorDays {
+			i := dailyIncome(day, d)
+			if sum == nil {
+				sum = &i
+			} else {
+				sum.ConsensusIncomeGwei = sum.ConsensusIncomeGwei.Add(i.ConsensusIncomeGwei)
+				sum.ExecutionIncomeWei = sum.ExecutionIncomeWei.Add(i.ExecutionIncomeWei)
+				sum.TotalIncomeWei = sum.TotalIncomeWei.Add(i.TotalIncomeWei)
+			}
+			sum.Validators++
+		}
+		if sum != nil {
+			income = append(income, *sum)
+		}
+	}
+	sort.Slice(income, func(i, j int) bool { return income[i].Day < income[j].Day })
+	return income
+}
+
+func dailyIncome(day uint64, d *Day) DailyIncome {
+	return DailyIncome{
+		Day:                 day,
+		DayTime:             d.DayTime,
+		ConsensusIncomeGwei: d.ConsensusRewardsGwei,
+		ExecutionIncomeWei:  d.TxFeesSumWei,
+		TotalIncomeWei:      d.ConsensusRewardsGwei.Mul(decimal.NewFromInt(1e9)).Add(d.TxFeesSumWei),
+	}
+}
+
+func (a *IncomeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// the routes are /v1/validators/{index}/income and /v1/addresses/{address}/income
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "v1" || parts[3] != "income" {
+		http.NotFound(w, r)
+		return
+	}
+	from, to, err := parseDayRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var income []DailyIncome
+	switch parts[1] {
+	case "validators":
+		index, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid validator index: %v", parts[2]), http.StatusBadRequest)
+			return
+		}
+		income, err = a.ValidatorIncome(r.Context(), index, from, to)
+	case "addresses":
+		if !common.IsHexAddress(parts[2]) {
+			http.Error(w, fmt.Sprintf("invalid address: %v", parts[2]), http.StatusBadRequest)
+			return
+		}
+		income, err = a.AddressIncome(r.Context(), parts[2], from, to)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(income) == 0 {
+		http.Error(w, fmt.Sprintf("no income of %v in the stored days", parts[2]), http.StatusNotFound)
+		return
+	}
+	writeJson(w, income)
+}
+
+// parseDayRange parses the optional query parameters from and to of a request, the range is unbounded by default.
+func parseDayRange(r *http.Request) (uint64, uint64, error) {
+	from, to := uint64(0), uint64(math.MaxUint64)
+	var err error
+	if s := r.URL.Query().Get("from"); s != "" {
+		from, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid from: %v", s)
+		}
+	}
+	if s := r.URL.Query().Get("to"); s != "" {
+		to, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid to: %v", s)
+		}
+	}
+	if to < from {
+		return 0, 0, fmt.Errorf("to is before from")
+	}
+	return from, to, nil
+}
+
+func writeJson(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
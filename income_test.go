@@ -0,0 +1,85 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIncomeAPI(t *testing.T) {
+	store := NewMemoryValidatorStore()
+	api := NewIncomeAPI(store)
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	validatorDaysByDay := map[uint64]map[uint64]*Day{}
+	for _, day := range []uint64{10, 11, 12} {
+		validatorDaysByDay[day] = map[uint64]*Day{
+			1: {Day: decimal.NewFromInt(int64(day)), ConsensusRewardsGwei: decimal.NewFromInt(2e6), TxFeesSumWei: decimal.NewFromInt(int64(day) * 1e15)},
+		}
+	}
+	// validator 2 is only part of day 11, it shares the withdrawal address with validator 1
+	validatorDaysByDay[11][2] = &Day{Day: decimal.NewFromInt(11), ConsensusRewardsGwei: decimal.NewFromInt(-1e5), TxFeesSumWei: decimal.Zero, WithdrawalCredentials: "0x010000000000000000000000aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}
+	for _, day := range []uint64{10, 11, 12} {
+		validatorDaysByDay[day][1].WithdrawalCredentials = "0x010000000000000000000000aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	}
+	store.Update(validatorDaysByDay)
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		days   []uint64
+	}{
+		{"/v1/validators/1/income", http.StatusOK, []uint64{10, 11, 12}},
+		{"/v1/validators/1/income?from=11", http.StatusOK, []uint64{11, 12}},
+		{"/v1/validators/1/income?from=10&to=11", http.StatusOK, []uint64{10, 11}},
+		{"/v1/validators/2/income?from=10&to=12", http.StatusOK, []uint64{11}},
+		{"/v1/validators/3/income", http.StatusNotFound, nil},
+		{"/v1/validators/1/income?from=12&to=10", http.StatusBadRequest, nil},
+		{"/v1/validators/x/income", http.StatusBadRequest, nil},
+		{"/v1/validators/1", http.StatusNotFound, nil},
+		{"/v1/addresses/0xAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA/income?to=11", http.StatusOK, []uint64{10, 11}},
+		{"/v1/addresses/0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb/income", http.StatusNotFound, nil},
+		{"/v1/addresses/0xaa/income", http.StatusBadRequest, nil},
+	} {
+		res, err := http.Get(server.URL + tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		income := []DailyIncome{}
+		if res.StatusCode == http.StatusOK {
+			err = json.NewDecoder(res.Body).Decode(&income)
+		}
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != tc.status {
+			t.Errorf("%v: wrong status: %v != %v", tc.path, res.StatusCode, tc.status)
+			continue
+		}
+		if len(income) != len(tc.days) {
+			t.Errorf("%v: wrong days: %+v", tc.path, income)
+			continue
+		}
+		for i, day := range tc.days {
+			if income[i].Day != day {
+				t.Errorf("%v: wrong day: %v != %v", tc.path, income[i].Day, day)
+			}
+		}
+	}
+
+	income, err := api.ValidatorIncome(context.Background(), 1, 12, 12)
+	if err != nil || len(income) != 1 || !income[0].ConsensusIncomeGwei.Equal(decimal.NewFromInt(2e6)) || !income[0].ExecutionIncomeWei.Equal(decimal.NewFromInt(12e15)) || !income[0].TotalIncomeWei.Equal(decimal.NewFromInt(14e15)) {
+		t.Errorf("wrong income: %+v", income)
+	}
+
+	income, err = api.AddressIncome(context.Background(), "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", 11, 11)
+	if err != nil || len(income) != 1 || income[0].Validators != 2 || !income[0].ConsensusIncomeGwei.Equal(decimal.NewFromInt(19e5)) || !income[0].TotalIncomeWei.Equal(decimal.NewFromInt(11e15+19e14)) {
+		t.Errorf("wrong address income: %+v", income)
+	}
+}
@@ -0,0 +1,92 @@
+package ethstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// aprEpsilon is the maximum difference between a stored apr and the apr recomputed from its components.
+var aprEpsilon = decimal.New(1, -12)
+
+// consistencyMismatches returns the derived values of d that do not match the values they are derived from.
+func consistencyMismatches(d *Day) []string {
+	mismatches := []string{}
+	totalRewardsWei := gweiToWei(d.ConsensusRewardsGwei).Add(d.TxFeesSumWei)
+	if !totalRewardsWei.Equal(d.TotalRewardsWei) {
+		mismatches = append(mismatches, fmt.Sprintf("totalRewardsWei %v does not match consensus rewards and tx fees (%v)", d.TotalRewardsWei, totalRewardsWei))
+	}
+	// the balances of a day only change by rewards, deposits and withdrawals, days stored before withdrawals were
+	// tracked have none
+	consensusRewardsGwei := d.EndBalanceGwei.Sub(d.StartBalanceGwei).Sub(d.DepositsSumGwei).Add(d.WithdrawalsSumGwei)
+	if !consensusRewardsGwei.Equal(d.ConsensusRewardsGwei) {
+		mismatches = append(mismatches, fmt.Sprintf("consensusRewardsGwei %v does not match balances, deposits and withdrawals (%v)", d.ConsensusRewardsGwei, consensusRewardsGwei))
+	}
+	// days stored before the fields in Wei were added have none
+	if !d.ConsensusRewardsWei.IsZero() && !d.ConsensusRewardsWei.Equal(gweiToWei(d.ConsensusRewardsGwei)) {
+		mismatches = append(mismatches, fmt.Sprintf("consensusRewardsWei %v does not match consensusRewardsGwei %v", d.ConsensusRewardsWei, d.ConsensusRewardsGwei))
+	}
+	if d.EffectiveBalanceGwei.IsPositive() {
+		apr := groupApr(d.TotalRewardsWei, d.EffectiveBalanceGwei)
+		if apr.Sub(d.Apr).Abs().GreaterThan(aprEpsilon) {
+			mismatches = append(mismatches, fmt.Sprintf("apr %v does not match total rewards and effective balance (%v)", d.Apr, apr))
+		}
+	}
+	// days stored before the convenience fields were added have none
+	if !d.AprBps.IsZero() || !d.AprPercent.IsZero() {
+		if !d.AprBps.Equal(aprBps(d.Apr)) || !d.AprPercent.Equal(aprPercent(d.Apr)) {
+			mismatches = append(mismatches, fmt.Sprintf("aprBps %v or aprPercent %v does not match apr %v", d.AprBps, d.AprPercent, d.Apr))
+		}
+	}
+	return mismatches
+}
+
+// checkInvariants returns an error if a calculated day is not consistent with itself or with the per-validator days
+// it is aggregated from, validatorDays is empty in low-memory mode.
+func checkInvariants(d *Day, validatorDays map[uint64]*Day) error {
+	mismatches := consistencyMismatches(d)
+
+	if len(validatorDays) > 0 {
+		if !decimal.NewFromInt(int64(len(validatorDays))).Equal(d.Validators) {
+			mismatches = append(mismatches, fmt.Sprintf("validators %v does not match number of per-validator days (%v)", d.Validators, len(validatorDays)))
+		}
+		sums := []struct {
+			name  string
+			value decimal.Decimal
+			field func(*Day) decimal.Decimal
+		}{
+			{"effectiveBalanceGwei", d.EffectiveBalanceGwei, func(v *Day) decimal.Decimal { return v.EffectiveBalanceGwei }},
+			{"startBalanceGwei", d.StartBalanceGwei, func(v *Day) decimal.Decimal { return v.StartBalanceGwei }},
+			{"endBalanceGwei", d.EndBalanceGwei, func(v *Day) decimal.Decimal { return v.EndBalanceGwei }},
+			{"depositsSumGwei", d.DepositsSumGwei, func(v *Day) decimal.Decimal { return v.DepositsSumGwei }},
+			{"withdrawalsSumGwei", d.WithdrawalsSumGwei, func(v *Day) decimal.Decimal { return v.WithdrawalsSumGwei }},
+			{"txFeesSumWei", d.TxFeesSumWei, func(v *Day) decimal.Decimal { return v.TxFeesSumWei }},
+			{"consensusRewardsGwei", d.ConsensusRewardsGwei, func(v *Day) decimal.Decimal { return v.ConsensusRewardsGwei }},
+			{"totalRewardsWei", d.TotalRewardsWei, func(v *Day) decimal.Decimal { return v.TotalRewardsWei }},
+		}
+		for _, s := range sums {
+			sum := decimal.Zero
+			for _, v := range validatorDays {
+				sum = sum.Add(s.field(v))
+			}
+			if !sum.Equal(s.value) {
+				mismatches = append(mismatches, fmt.Sprintf("%v %v does not match sum of per-validator days (%v)", s.name, s.value, sum))
+			}
+		}
+		contributions := decimal.Zero
+		for _, v := range validatorDays {
+			if v.AprContribution != nil {
+				contributions = contributions.Add(*v.AprContribution)
+			}
+		}
+		if contributions.Sub(d.Apr).Abs().GreaterThan(aprEpsilon) {
+			mismatches = append(mismatches, fmt.Sprintf("apr %v does not match sum of apr contributions of per-validator days (%v)", d.Apr, contributions))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("invariants of day %v do not hold: %v", d.Day, strings.Join(mismatches, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package ethstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, validatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkInvariants(day, validatorDays); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkInvariants(day, nil); err != nil {
+		t.Fatalf("unexpected error without per-validator days: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		corrupt  func(d *Day, validatorDays map[uint64]*Day)
+		expected string
+	}{
+		{"balances", func(d *Day, _ map[uint64]*Day) { d.EndBalanceGwei = d.EndBalanceGwei.Add(decimal.NewFromInt(1)) }, "consensusRewardsGwei"},
+		{"apr", func(d *Day, _ map[uint64]*Day) { d.Apr = d.Apr.Add(decimal.New(1, -9)) }, "apr"},
+		{"withdrawals", func(d *Day, _ map[uint64]*Day) {
+			d.WithdrawalsSumGwei = d.WithdrawalsSumGwei.Add(decimal.NewFromInt(1))
+		}, "consensusRewardsGwei"},
+		{"sum", func(_ *Day, validatorDays map[uint64]*Day) {
+			for _, v := range validatorDays {
+				v.TxFeesSumWei = v.TxFeesSumWei.Add(decimal.NewFromInt(1))
+				break
+			}
+		}, "txFeesSumWei"},
+		{"contribution", func(_ *Day, validatorDays map[uint64]*Day) {
+			for _, v := range validatorDays {
+				c := v.AprContribution.Add(decimal.New(1, -9))
+				v.AprContribution = &c
+				break
+			}
+		}, "apr contributions"},
+		{"validators", func(_ *Day, validatorDays map[uint64]*Day) {
+			for index := range validatorDays {
+				delete(validatorDays, index)
+				break
+			}
+		}, "validators"},
+	}
+	for _, tt := range tests {
+		d := *day
+		corrupted := make(map[uint64]*Day, len(validatorDays))
+		for index, v := range validatorDays {
+			c := *v
+			corrupted[index] = &c
+		}
+		tt.corrupt(&d, corrupted)
+		err := checkInvariants(&d, corrupted)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%v: expected error mentioning %v, got %v", tt.name, tt.expected, err)
+		}
+	}
+
+	// differences within the epsilon are rounding, not regressions
+	d := *day
+	d.Apr = d.Apr.Add(decimal.New(1, -14))
+	d.AprBps, d.AprPercent = aprBps(d.Apr), aprPercent(d.Apr)
+	if err := checkInvariants(&d, nil); err != nil {
+		t.Errorf("unexpected error within epsilon: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package ethstore
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// LeagueTableEntry holds the rewards of the validators of an entity over one or more days.
+type LeagueTableEntry struct {
+	Rank                 int             `json:"rank"`
+	Entity               string          `json:"entity"`
+	Validators           decimal.Decimal `json:"validators"`
+	EffectiveBalanceGwei decimal.Decimal `json:"effectiveBalanceGwei"`
+	ConsensusRewardsGwei decimal.Decimal `json:"consensusRewardsGwei"`
+	ConsensusRewardsWei  decimal.Decimal `json:"consensusRewardsWei"`
+	TxFeesSumWei         decimal.Decimal `json:"txFeesSumWei"`
+	TotalRewardsWei      decimal.Decimal `json:"totalRewardsWei"`
+	Apr                  decimal.Decimal `json:"apr"`
+	ExecutionRewardShare decimal.Decimal `json:"executionRewardShare"`
+}
+
+// LeagueTable ranks the entities of the labeled per-validator days (see LabelEntities) of one or more days by their
+// apr. The validators and effective balance of an entity are averaged over the days, validators without an entity are
+// left out.
+func LeagueTable(validatorDaysByDay []map[uint64]*Day) []LeagueTableEntry {
+	entriesByEntity := map[string]*LeagueTableEntry{}
+	for _, validatorDays := range validatorDaysByDay {
+		for _, d := range validatorDays {
+			if d.Entity == "" {
+				continue
+			}
+			e, exists := entriesByEntity[d.Entity]
+			if !exists {
+				e = &LeagueTableEntry{Entity: d.Entity}
+				entriesByEntity[d.Entity] = e
+			}
+			e.Validators = e.Validators.Add(decimal.NewFromInt(1))
+			e.EffectiveBalanceGwei = e.EffectiveBalanceGwei.Add(d.EffectiveBalanceGwei)
+			e.ConsensusRewardsGwei = e.ConsensusRewardsGwei.Add(d.ConsensusRewardsGwei)
+			e.ConsensusRewardsWei = e.ConsensusRewardsWei.Add(gweiToWei(d.ConsensusRewardsGwei))
+			e.TxFeesSumWei = e.TxFeesSumWei.Add(d.TxFeesSumWei)
+			e.TotalRewardsWei = e.TotalRewardsWei.Add(d.TotalRewardsWei)
+		}
+	}
+
+	days := decimal.NewFromInt(int64(len(validatorDaysByDay)))
+	entries := make([]LeagueTableEntry, 0, len(entriesByEntity))
+	for _, e := range entriesByEntity {
+		e.Apr = groupApr(e.TotalRewardsWei, e.EffectiveBalanceGwei)
+		if !e.TotalRewardsWei.IsZero() {
+			e.ExecutionRewardShare = e.TxFeesSumWei.Div(e.TotalRewardsWei)
+		}
+		e.Validators = e.Validators.Div(days)
+		e.EffectiveBalanceGwei = e.EffectiveBalanceGwei.Div(days)
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Apr.Equal(entries[j].Apr) {
+			return entries[i].Apr.GreaterThan(entries[j].Apr)
+		}
+		return entries[i].Entity < entries[j].Entity
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries
+}
@@ -0,0 +1,48 @@
+package ethstore
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLeagueTable(t *testing.T) {
+	validatorDay := func(entity string, consensusRewardsGwei, txFeesGwei int64) *Day {
+		return &Day{
+			Entity:               entity,
+			EffectiveBalanceGwei: decimal.NewFromInt(32e9),
+			ConsensusRewardsGwei: decimal.NewFromInt(consensusRewardsGwei),
+			TxFeesSumWei:         decimal.NewFromInt(txFeesGwei).Mul(decimal.NewFromInt(1e9)),
+			TotalRewardsWei:      decimal.NewFromInt(consensusRewardsGwei + txFeesGwei).Mul(decimal.NewFromInt(1e9)),
+		}
+	}
+	entries := LeagueTable([]map[uint64]*Day{
+		{
+			0: validatorDay("a", 3000000, 1000000),
+			1: validatorDay("a", 3000000, 0),
+			2: validatorDay("b", 3000000, 3000000),
+			3: validatorDay("", 9000000, 0),
+		},
+		{
+			0: validatorDay("a", 3000000, 0),
+			1: validatorDay("a", 3000000, 0),
+			2: validatorDay("b", 3000000, 1000000),
+		},
+	})
+	if len(entries) != 2 {
+		t.Fatalf("wrong number of entries: %v != %v", len(entries), 2)
+	}
+	b, a := entries[0], entries[1]
+	if b.Entity != "b" || b.Rank != 1 || a.Entity != "a" || a.Rank != 2 {
+		t.Fatalf("wrong ranking: %+v", entries)
+	}
+	if !a.Validators.Equal(decimal.NewFromInt(2)) || !a.EffectiveBalanceGwei.Equal(decimal.NewFromInt(64e9)) {
+		t.Errorf("wrong averages: validators: %v, effectiveBalanceGwei: %v", a.Validators, a.EffectiveBalanceGwei)
+	}
+	if !a.Apr.Equal(groupApr(decimal.NewFromInt(13e15), decimal.NewFromInt(128e9))) {
+		t.Errorf("wrong apr: %v", a.Apr)
+	}
+	if !b.ExecutionRewardShare.Equal(decimal.RequireFromString("0.4")) {
+		t.Errorf("wrong execution reward share: %v", b.ExecutionRewardShare)
+	}
+}
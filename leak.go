@@ -0,0 +1,97 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"golang.org/x/sync/errgroup"
+)
+
+var perEpochLeakDetection bool
+var perEpochLeakDetectionMu = sync.Mutex{}
+
+// SetPerEpochLeakDetection detects the inactivity leak of every epoch of a day from the finality of the state at its
+// start, which needs the historical states of an archive node and a request per epoch. Without it the leak epochs are
+// derived from the finality of the states at the start and end of the day, which misses leaks that end within the day.
+//
+// Deprecated: use WithPerEpochLeakDetection.
+func SetPerEpochLeakDetection(enabled bool) {
+	perEpochLeakDetectionMu.Lock()
+	defer perEpochLeakDetectionMu.Unlock()
+	perEpochLeakDetection = enabled
+}
+
+func GetPerEpochLeakDetection() bool {
+	perEpochLeakDetectionMu.Lock()
+	defer perEpochLeakDetectionMu.Unlock()
+	return perEpochLeakDetection
+}
+
+// InactivityPenaltyProvider is implemented by attestation providers that are able to report the inactivity
+// penalties of validators, which are only applied during an inactivity leak.
+type InactivityPenaltyProvider interface {
+	InactivityPenalties(ctx context.Context, epoch phase0.Epoch, effectiveBalances map[phase0.ValidatorIndex]phase0.Gwei) (phase0.Gwei, error)
+}
+
+// getInactivityLeakEpochs returns the epochs in [firstEpoch,lastEpoch] during which the chain was in an inactivity leak,
+// which is the case when the finalized checkpoint at the start of the epoch lags more than minEpochsToInactivityPenalty
+// epochs behind the previous epoch.
+func getInactivityLeakEpochs(ctx context.Context, client BeaconClient, firstEpoch, lastEpoch, slotsPerEpoch, minEpochsToInactivityPenalty uint64, concurrency int) ([]uint64, error) {
+	leakEpochs := []uint64{}
+	leakEpochsMu := sync.Mutex{}
+	g := new(errgroup.Group)
+	g.SetLimit(concurrency)
+	for e := firstEpoch; e <= lastEpoch; e++ {
+		e := e
+		if e < 1 {
+			continue
+		}
+		g.Go(func() error {
+			finality, err := client.Finality(ctx, fmt.Sprintf("%d", e*slotsPerEpoch))
+			if err != nil {
+				return fmt.Errorf("error getting finality checkpoints at epoch %v: %w", e, err)
+			}
+			if finality.Finalized == nil {
+				return fmt.Errorf("no finalized checkpoint at epoch %v", e)
+			}
+			if e-1 > uint64(finality.Finalized.Epoch)+minEpochsToInactivityPenalty {
+				leakEpochsMu.Lock()
+				leakEpochs = append(leakEpochs, e)
+				leakEpochsMu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	sort.Slice(leakEpochs, func(i, j int) bool { return leakEpochs[i] < leakEpochs[j] })
+	return leakEpochs, nil
+}
+
+func getInactivityPenalties(ctx context.Context, p InactivityPenaltyProvider, epochs []uint64, effectiveBalances map[phase0.ValidatorIndex]phase0.Gwei, concurrency int) (phase0.Gwei, error) {
+	var total phase0.Gwei
+	totalMu := sync.Mutex{}
+	g := new(errgroup.Group)
+	g.SetLimit(concurrency)
+	for _, e := range epochs {
+		e := e
+		g.Go(func() error {
+			penalties, err := p.InactivityPenalties(ctx, phase0.Epoch(e), effectiveBalances)
+			if err != nil {
+				return fmt.Errorf("error getting inactivity penalties at epoch %v: %w", e, err)
+			}
+			totalMu.Lock()
+			total += penalties
+			totalMu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,94 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetInactivityLeakEpochs(t *testing.T) {
+	bnServer, _ := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	client, err := newConsClient(context.Background(), bnServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// finality stalls at epoch 2295 during epochs 2297 to 2309, the leak starts once the previous epoch lags more than 4
+	// epochs behind it
+	leakEpochs, err := getInactivityLeakEpochs(context.Background(), client, 2290, 2315, 32, 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(leakEpochs, []uint64{2301, 2302, 2303, 2304, 2305, 2306, 2307, 2308, 2309}) {
+		t.Errorf("wrong leak epochs: %v", leakEpochs)
+	}
+	leakEpochs, err = getInactivityLeakEpochs(context.Background(), client, 2310, 2320, 32, 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leakEpochs) != 0 {
+		t.Errorf("wrong leak epochs after the leak: %v", leakEpochs)
+	}
+}
+
+func TestInactivityLeak(t *testing.T) {
+	var finalityRequests int32
+	countFinality := func(r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/finality_checkpoints") && !strings.Contains(r.URL.Path, "/head/") {
+			atomic.AddInt32(&finalityRequests, 1)
+		}
+	}
+	bnServer, elServer := newEthstoreMockServers(t, countFinality)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	// the leak of the mock ends within the day, which is not visible in the finality at the start and end of the day
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.InactivityLeak || atomic.LoadInt32(&finalityRequests) != 2 {
+		t.Errorf("wrong InactivityLeak: %v with %v finality requests", day.InactivityLeak, finalityRequests)
+	}
+
+	SetPerEpochLeakDetection(true)
+	atomic.StoreInt32(&finalityRequests, 0)
+	day, _, err = Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	SetPerEpochLeakDetection(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.InactivityLeak || day.InactivityLeakEpochs.IntPart() != 9 || atomic.LoadInt32(&finalityRequests) != 225 {
+		t.Errorf("wrong InactivityLeak: %v (%v epochs) with %v finality requests", day.InactivityLeak, day.InactivityLeakEpochs, finalityRequests)
+	}
+	if day.InactivityPenaltiesGwei != nil {
+		t.Errorf("wrong InactivityPenaltiesGwei: %v != %v", day.InactivityPenaltiesGwei, nil)
+	}
+
+	// a leak that lasts until the end of the day is derived from the finality at its end: finality stalls at epoch 2460
+	// from epoch 2462 on, so the epochs from 2466 to the last epoch 2474 are in the leak. The per-epoch detection also
+	// counts the 9 epochs of the leak that ends within the day.
+	bnServer, elServer = newEthstoreMockServersWithMocks(t, nil, func(mocks map[string]string) {
+		for e := 2462; e <= 2475; e++ {
+			mocks[fmt.Sprintf("/eth/v1/beacon/states/%d/finality_checkpoints", e*32)] = fmt.Sprintf(`{"data":{"previous_justified":{"epoch":"%[1]d","root":"%[2]s"},"current_justified":{"epoch":"%[1]d","root":"%[2]s"},"finalized":{"epoch":"%[1]d","root":"%[2]s"}}}`, 2460, "0x0000000000000000000000000000000000000000000000000000000000000000")
+		}
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+	for perEpoch, epochs := range map[bool]int64{false: 9, true: 18} {
+		SetPerEpochLeakDetection(perEpoch)
+		day, _, err = Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+		SetPerEpochLeakDetection(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !day.InactivityLeak || day.InactivityLeakEpochs.IntPart() != epochs {
+			t.Errorf("wrong InactivityLeak with per-epoch detection %v: %v (%v epochs) != %v epochs", perEpoch, day.InactivityLeak, day.InactivityLeakEpochs, epochs)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+const (
+	LifecycleActivation       = "activation"
+	LifecycleExit             = "exit"
+	LifecycleSlashing         = "slashing"
+	LifecycleCredentialChange = "credential_change"
+)
+
+// LifecycleEvent is an event in the lifecycle of a validator during a day. Activations and exits are reported at their
+// epoch, slashings and changes of the withdrawal credentials are only known to have happened between the snapshots at
+// the start and the end of the day and have no epoch. WithdrawalCredentials holds the new credentials of a change.
+type LifecycleEvent struct {
+	Day                   uint64  `json:"day"`
+	ValidatorIndex        uint64  `json:"validatorIndex"`
+	Event                 string  `json:"event"`
+	Epoch                 *uint64 `json:"epoch,omitempty"`
+	WithdrawalCredentials string  `json:"withdrawalCredentials,omitempty"`
+}
+
+// lifecycleEvents returns the lifecycle events of the day [firstEpoch, endEpoch) from the validator snapshots at its
+// start and its end ordered by validator index. A validator without snapshot at the start has not been deposited yet.
+func lifecycleEvents(day, firstEpoch, endEpoch uint64, start, end map[phase0.ValidatorIndex]*v1.Validator) []LifecycleEvent {
+	events := []LifecycleEvent{}
+	inDay := func(epoch phase0.Epoch) bool {
+		return epoch != farFutureEpoch && uint64(epoch) >= firstEpoch && uint64(epoch) < endEpoch
+	}
+	for index, v := range end {
+		if v.Validator == nil {
+			continue
+		}
+		if inDay(v.Validator.ActivationEpoch) {
+			epoch := uint64(v.Validator.ActivationEpoch)
+			events = append(events, LifecycleEvent{Day: day, ValidatorIndex: uint64(index), Event: LifecycleActivation, Epoch: &epoch})
+		}
+		if inDay(v.Validator.ExitEpoch) {
+			epoch := uint64(v.Validator.ExitEpoch)
+			events = append(events, LifecycleEvent{Day: day, ValidatorIndex: uint64(index), Event: LifecycleExit, Epoch: &epoch})
+		}
+		s, exists := start[index]
+		if !exists || s.Validator == nil {
+			continue
+		}
+		if v.Validator.Slashed && !s.Validator.Slashed {
+			events = append(events, LifecycleEvent{Day: day, ValidatorIndex: uint64(index), Event: LifecycleSlashing})
+		}
+		if !bytes.Equal(v.Validator.WithdrawalCredentials, s.Validator.WithdrawalCredentials) {
+			events = append(events, LifecycleEvent{Day: day, ValidatorIndex: uint64(index), Event: LifecycleCredentialChange, WithdrawalCredentials: fmt.Sprintf("%#x", v.Validator.WithdrawalCredentials)})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].ValidatorIndex != events[j].ValidatorIndex {
+			return events[i].ValidatorIndex < events[j].ValidatorIndex
+		}
+		return events[i].Event < events[j].Event
+	})
+	return events
+}
+
+// GetLifecycleTimeline returns the lifecycle events of all validators during the days [firstDay, lastDay] in the order
+// of the days, derived from the validator snapshots at the day boundaries. Every snapshot is requested once, the
+// snapshot at the end of a day is the snapshot at the start of the next day.
+func GetLifecycleTimeline(ctx context.Context, bnAddress string, firstDay, lastDay uint64) ([]LifecycleEvent, error) {
+	if lastDay < firstDay {
+		return nil, fmt.Errorf("last day %v is before first day %v", lastDay, firstDay)
+	}
+	client, err := newConsClient(ctx, bnAddress)
+	if err != nil {
+		return nil, err
+	}
+	timing, err := getChainTiming(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	boundary := GetDayBoundary()
+	firstSlot := boundary.FirstSlot(firstDay, timing)
+	start, err := GetValidators(ctx, client, fmt.Sprintf("%d", firstSlot))
+	if err != nil {
+		return nil, fmt.Errorf("error getting validators at slot %v: %w", firstSlot, err)
+	}
+	events := []LifecycleEvent{}
+	for day := firstDay; day <= lastDay; day++ {
+		endSlot := boundary.FirstSlot(day+1, timing)
+		end, err := GetValidators(ctx, client, fmt.Sprintf("%d", endSlot))
+		if err != nil {
+			return nil, fmt.Errorf("error getting validators at slot %v: %w", endSlot, err)
+		}
+		events = append(events, lifecycleEvents(day, firstSlot/timing.SlotsPerEpoch, endSlot/timing.SlotsPerEpoch, start, end)...)
+		start, firstSlot = end, endSlot
+	}
+	return events, nil
+}
+
+// WriteLifecycleCSV writes the events as csv with the columns day, validator_index, event, epoch and
+// withdrawal_credentials.
+func WriteLifecycleCSV(w io.Writer, events []LifecycleEvent) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"day", "validator_index", "event", "epoch", "withdrawal_credentials"})
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		epoch := ""
+		if e.Epoch != nil {
+			epoch = fmt.Sprintf("%d", *e.Epoch)
+		}
+		err = cw.Write([]string{fmt.Sprintf("%d", e.Day), fmt.Sprintf("%d", e.ValidatorIndex), e.Event, epoch, e.WithdrawalCredentials})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
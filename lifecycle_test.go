@@ -0,0 +1,70 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestLifecycleEvents(t *testing.T) {
+	validator := func(activation, exit phase0.Epoch, slashed bool, credentials byte) *v1.Validator {
+		return &v1.Validator{Validator: &phase0.Validator{ActivationEpoch: activation, ExitEpoch: exit, Slashed: slashed, WithdrawalCredentials: []byte{credentials, 1}}}
+	}
+	start := map[phase0.ValidatorIndex]*v1.Validator{
+		0: validator(0, farFutureEpoch, false, 0),
+		1: validator(0, farFutureEpoch, false, 0),
+		2: validator(0, 230, false, 1),
+	}
+	end := map[phase0.ValidatorIndex]*v1.Validator{
+		0: validator(0, farFutureEpoch, false, 1),
+		1: validator(0, 300, true, 0),
+		2: validator(0, 230, false, 1),
+		3: validator(226, farFutureEpoch, false, 1),
+		// activated after the end of the day
+		4: validator(450, farFutureEpoch, false, 1),
+	}
+	got := []string{}
+	for _, e := range lifecycleEvents(1, 225, 450, start, end) {
+		epoch := "-"
+		if e.Epoch != nil {
+			epoch = fmt.Sprintf("%d", *e.Epoch)
+		}
+		got = append(got, fmt.Sprintf("%v/%v/%v/%v", e.ValidatorIndex, e.Event, epoch, e.WithdrawalCredentials))
+	}
+	expected := "0/credential_change/-/0x0101,1/exit/300/,1/slashing/-/,2/exit/230/,3/activation/226/"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("wrong events: %v != %v", strings.Join(got, ","), expected)
+	}
+}
+
+func TestGetLifecycleTimeline(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	events, err := GetLifecycleTimeline(context.Background(), bnServer.URL, 10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// validator 0 exited on day 9, validator 1 exits on day 10 and validator 2 is activated on day 10
+	if len(events) != 2 || events[0].ValidatorIndex != 1 || events[0].Event != LifecycleExit || *events[0].Epoch != 11*225-1 || events[1].ValidatorIndex != 2 || events[1].Event != LifecycleActivation || *events[1].Epoch != 10*225+1 {
+		t.Errorf("wrong events: %+v", events)
+	}
+	if _, err := GetLifecycleTimeline(context.Background(), bnServer.URL, 11, 10); err == nil {
+		t.Errorf("expected error for empty range")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteLifecycleCSV(&buf, events); err != nil {
+		t.Fatal(err)
+	}
+	expected := "day,validator_index,event,epoch,withdrawal_credentials\n10,1,exit,2474,\n10,2,activation,2251,\n"
+	if buf.String() != expected {
+		t.Errorf("wrong csv:\n%v", buf.String())
+	}
+}
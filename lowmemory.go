@@ -0,0 +1,28 @@
+package ethstore
+
+import (
+	"sync"
+)
+
+// lowMemoryConcurrency is the maximum number of concurrent requests of Calculate in low-memory mode.
+const lowMemoryConcurrency = 2
+
+var lowMemory bool
+var lowMemoryMu = &sync.Mutex{}
+
+// SetLowMemory enables the low-memory mode for machines that run the calculation alongside their node: the
+// validator-sets are not cached and released as soon as the eth.store validators are known, Calculate returns no
+// per-validator days and fetches at most lowMemoryConcurrency blocks at a time.
+//
+// Deprecated: use WithLowMemory.
+func SetLowMemory(enabled bool) {
+	lowMemoryMu.Lock()
+	defer lowMemoryMu.Unlock()
+	lowMemory = enabled
+}
+
+func GetLowMemory() bool {
+	lowMemoryMu.Lock()
+	defer lowMemoryMu.Unlock()
+	return lowMemory
+}
@@ -0,0 +1,30 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLowMemory(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetLowMemory(true)
+	defer SetLowMemory(false)
+	lowMemoryDay, validatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(validatorDays) != 0 {
+		t.Errorf("per-validator days in low-memory mode: %v", len(validatorDays))
+	}
+	if !lowMemoryDay.Apr.Equal(day.Apr) || !lowMemoryDay.Validators.Equal(day.Validators) || !lowMemoryDay.ConsensusBaselineGwei.Equal(day.ConsensusBaselineGwei) {
+		t.Errorf("different results in low-memory mode: apr: %v != %v, validators: %v != %v", lowMemoryDay.Apr, day.Apr, lowMemoryDay.Validators, day.Validators)
+	}
+}
@@ -0,0 +1,47 @@
+package ethstore
+
+import (
+	"sync"
+)
+
+// methodologies are the methodology versions calculate is able to compute. When a change of the methodology is
+// introduced the previous version stays supported for the transition.
+var methodologies = []int{MethodologyVersion}
+
+// MethodologyTransition configures days that are calculated with the previous methodology version in addition to the
+// current one, so that the results of both can be compared while a breaking change of the methodology is introduced.
+type MethodologyTransition struct {
+	PreviousVersion int
+	FirstDay        uint64
+	LastDay         uint64
+}
+
+var methodologyTransition *MethodologyTransition
+var methodologyTransitionMu = sync.Mutex{}
+
+// SetMethodologyTransition sets the days to calculate with both methodology versions, the result of the previous
+// methodology is added to the day as PreviousMethodology. The transition is disabled if nil.
+func SetMethodologyTransition(t *MethodologyTransition) {
+	methodologyTransitionMu.Lock()
+	defer methodologyTransitionMu.Unlock()
+	methodologyTransition = t
+}
+
+func GetMethodologyTransition() *MethodologyTransition {
+	methodologyTransitionMu.Lock()
+	defer methodologyTransitionMu.Unlock()
+	return methodologyTransition
+}
+
+func (t *MethodologyTransition) overlaps(day uint64) bool {
+	return t != nil && day >= t.FirstDay && day <= t.LastDay
+}
+
+func supportedMethodology(version int) bool {
+	for _, v := range methodologies {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package ethstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMethodologyTransition(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	// pretend that the previous version is still supported, it is calculated like the current one
+	defer func(m []int) { methodologies = m }(methodologies)
+	methodologies = []int{MethodologyVersion - 1, MethodologyVersion}
+	defer SetMethodologyTransition(nil)
+
+	SetMethodologyTransition(&MethodologyTransition{PreviousVersion: MethodologyVersion - 1, FirstDay: 11, LastDay: 20})
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.PreviousMethodology != nil {
+		t.Errorf("previous methodology calculated outside of the transition")
+	}
+
+	SetMethodologyTransition(&MethodologyTransition{PreviousVersion: MethodologyVersion - 1, FirstDay: 5, LastDay: 10})
+	day, _, err = Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := day.PreviousMethodology
+	if p == nil {
+		t.Fatalf("no previous methodology calculated during the transition")
+	}
+	if day.Provenance.MethodologyVersion != MethodologyVersion || p.Provenance.MethodologyVersion != MethodologyVersion-1 {
+		t.Errorf("wrong methodology versions: %v, %v", day.Provenance.MethodologyVersion, p.Provenance.MethodologyVersion)
+	}
+	if p.InputHash == day.InputHash || !p.Apr.Equal(day.Apr) {
+		t.Errorf("wrong result of previous methodology: %+v", p)
+	}
+
+	SetMethodologyTransition(&MethodologyTransition{PreviousVersion: MethodologyVersion - 2, FirstDay: 5, LastDay: 10})
+	_, _, err = Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err == nil || !strings.Contains(err.Error(), "unsupported methodology version") {
+		t.Errorf("expected error for unsupported methodology version, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+)
+
+var includeMEV bool
+var includeMEVMu = sync.Mutex{}
+
+// SetIncludeMEV enables the detection of the payments of external builders to the proposers of their blocks, the
+// payments are reported as MevRewardsWei and are not part of the apr.
+//
+// Deprecated: use WithIncludeMEV.
+func SetIncludeMEV(enabled bool) {
+	includeMEVMu.Lock()
+	defer includeMEVMu.Unlock()
+	includeMEV = enabled
+}
+
+func GetIncludeMEV() bool {
+	includeMEVMu.Lock()
+	defer includeMEVMu.Unlock()
+	return includeMEV
+}
+
+// getBuilderPayment returns the value of the builder payment of a block or nil if the block was not built by an
+// external builder. A builder sets itself as fee recipient of the block and pays the proposer with the last
+// transaction of the block, which is therefore sent by the fee recipient to another address. The value of the last
+// transaction is taken from the decoded payload or requested from the execution node for blinded blocks.
+func getBuilderPayment(ctx context.Context, elClient *gethRPC.Client, exec *executionBlock, receipts []*TxReceipt) (*big.Int, error) {
+	if len(receipts) == 0 {
+		return nil, nil
+	}
+	last := receipts[len(receipts)-1]
+	if last.From == nil || last.To == nil || *last.From != exec.FeeRecipient || *last.To == exec.FeeRecipient || last.Status != 1 {
+		return nil, nil
+	}
+	if exec.LastTxValue != nil {
+		return exec.LastTxValue, nil
+	}
+	var tx struct {
+		Hash  common.Hash  `json:"hash"`
+		Value *hexutil.Big `json:"value"`
+	}
+	hash := exec.TxHashes[len(exec.TxHashes)-1]
+	err := elClient.CallContext(ctx, &tx, "eth_getTransactionByHash", hash.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("error getting transaction %v: %w", hash.Hex(), err)
+	}
+	if tx.Hash != hash || tx.Value == nil {
+		return nil, fmt.Errorf("transaction %v not found", hash.Hex())
+	}
+	return tx.Value.ToInt(), nil
+}
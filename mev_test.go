@@ -0,0 +1,80 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestGetBuilderPayment(t *testing.T) {
+	builder := common.HexToAddress("0x8b0c2c4c8eb078bc6c01f48523764c8942c0c6c4")
+	proposer := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	other := common.HexToAddress("0x9709ae4129ed4bb3fa6678e83a9976b7cc81abd1")
+	paymentHash := common.HexToHash("0xa515aea9c1b298c2947454902af1738af230030553943ba5cc738cbabfca9a4e")
+
+	elServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"eth_getTransactionByHash"`) {
+			t.Errorf("unexpected request: %s", body)
+		}
+		if strings.Contains(string(body), paymentHash.Hex()) {
+			w.Write([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"result":{"hash":"%s","value":"0xde0b6b3a7640000"}}`, paymentHash.Hex())))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+	}))
+	defer elServer.Close()
+	elClient, err := gethRPC.Dial(elServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt := func(from, to common.Address, status uint64) *TxReceipt {
+		return &TxReceipt{From: &from, To: &to, Status: hexutil.Uint64(status)}
+	}
+	tests := []struct {
+		name     string
+		exec     *executionBlock
+		receipts []*TxReceipt
+		expected string
+	}{
+		{"full block", &executionBlock{FeeRecipient: builder, LastTxValue: big.NewInt(5)}, []*TxReceipt{receipt(other, builder, 1), receipt(builder, proposer, 1)}, "5"},
+		{"blinded block", &executionBlock{FeeRecipient: builder, TxHashes: []common.Hash{paymentHash}}, []*TxReceipt{receipt(builder, proposer, 1)}, "1000000000000000000"},
+		{"locally built block", &executionBlock{FeeRecipient: proposer, LastTxValue: big.NewInt(5)}, []*TxReceipt{receipt(other, builder, 1)}, "<nil>"},
+		{"transfer to itself", &executionBlock{FeeRecipient: builder, LastTxValue: big.NewInt(5)}, []*TxReceipt{receipt(builder, builder, 1)}, "<nil>"},
+		{"failed payment", &executionBlock{FeeRecipient: builder, LastTxValue: big.NewInt(5)}, []*TxReceipt{receipt(builder, proposer, 0)}, "<nil>"},
+		{"empty block", &executionBlock{FeeRecipient: builder}, nil, "<nil>"},
+	}
+	for _, tt := range tests {
+		payment, err := getBuilderPayment(context.Background(), elClient, tt.exec, tt.receipts)
+		if err != nil {
+			t.Errorf("%v: %v", tt.name, err)
+			continue
+		}
+		if got := fmt.Sprintf("%v", payment); got != tt.expected {
+			t.Errorf("%v: wrong payment: %v != %v", tt.name, got, tt.expected)
+		}
+	}
+
+	// the execution node does not know the payment
+	unknown := &executionBlock{FeeRecipient: builder, TxHashes: []common.Hash{{1}}}
+	if _, err := getBuilderPayment(context.Background(), elClient, unknown, []*TxReceipt{receipt(builder, proposer, 1)}); err == nil {
+		t.Errorf("expected error for unknown transaction")
+	}
+
+	// the value of the last transaction of a full block is decoded from the payload
+	exec := executionBlockFromPayload(&bellatrix.ExecutionPayload{Transactions: []bellatrix.Transaction{createTx(1), createTx(2)}}, nil)
+	if exec.LastTxValue == nil || exec.LastTxValue.Cmp(big.NewInt(1e18)) != 0 {
+		t.Errorf("wrong LastTxValue: %v", exec.LastTxValue)
+	}
+}
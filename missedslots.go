@@ -0,0 +1,70 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/go-eth2-client/spec"
+)
+
+// missedSlotErrors lists the error messages that beacon clients return instead of a 404 when there is no
+// block at the requested slot. A 200 with an empty body is recognized on the response by getSignedBeaconBlock.
+var missedSlotErrors = map[string][]string{
+	"prysm": {
+		"could not find requested block",
+		"signed beacon block can't be nil",
+		"code = notfound desc = could not find block",
+	},
+	"teku":     {"block not found"},
+	"nimbus":   {"block not found"},
+	"lodestar": {"no block found for id"},
+}
+
+// getBeaconClientName returns the lowercase name of the beacon client, e.g. "lighthouse" for "Lighthouse/v2.3.1-564d7da/x86_64-linux".
+func getBeaconClientName(ctx context.Context, client eth2client.NodeVersionProvider) string {
+	nodeVersion, err := client.NodeVersion(ctx)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.SplitN(nodeVersion, "/", 2)[0])
+}
+
+// normalizeBlockResponse maps the different ways beacon clients respond to a request for a missed slot
+// (404, 200 with an empty body or without data, non-404 errors) to a nil block without an error. A block without a
+// message is an error.
+func normalizeBlockResponse(clientName string, block *SignedBeaconBlock, err error) (*SignedBeaconBlock, error) {
+	if err != nil {
+		if isMissedSlotError(clientName, err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+	var hasMessage bool
+	switch block.Version {
+	case spec.DataVersionPhase0:
+		hasMessage = block.Phase0 != nil && block.Phase0.Message != nil
+	case spec.DataVersionAltair:
+		hasMessage = block.Altair != nil && block.Altair.Message != nil
+	case spec.DataVersionBellatrix:
+		hasMessage = block.Bellatrix != nil && block.Bellatrix.Message != nil
+	}
+	if !hasMessage {
+		return nil, fmt.Errorf("%v block without a message", block.Version)
+	}
+	return block, nil
+}
+
+func isMissedSlotError(clientName string, err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, m := range missedSlotErrors[clientName] {
+		if strings.Contains(msg, strings.ToLower(m)) {
+			return true
+		}
+	}
+	return false
+}
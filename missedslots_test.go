@@ -0,0 +1,157 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	ethhttp "github.com/attestantio/go-eth2-client/http"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/rs/zerolog"
+)
+
+func TestNormalizeBlockResponse(t *testing.T) {
+	phase0Block := &spec.VersionedSignedBeaconBlock{Version: spec.DataVersionPhase0, Phase0: &phase0.SignedBeaconBlock{Message: &phase0.BeaconBlock{}}}
+	altairBlock := &spec.VersionedSignedBeaconBlock{Version: spec.DataVersionAltair, Altair: &altair.SignedBeaconBlock{Message: &altair.BeaconBlock{}}}
+	bellatrixBlock := &spec.VersionedSignedBeaconBlock{Version: spec.DataVersionBellatrix, Bellatrix: &bellatrix.SignedBeaconBlock{Message: &bellatrix.BeaconBlock{}}}
+
+	tests := []struct {
+		name       string
+		clientName string
+		block      *spec.VersionedSignedBeaconBlock
+		err        error
+		missed     bool
+		failed     bool
+	}{
+		{name: "phase0 block", clientName: "lighthouse", block: phase0Block},
+		{name: "altair block", clientName: "lighthouse", block: altairBlock},
+		{name: "bellatrix block", clientName: "lighthouse", block: bellatrixBlock},
+		{name: "404", clientName: "lighthouse", missed: true},
+		{name: "block without data", clientName: "lighthouse", block: &spec.VersionedSignedBeaconBlock{}, failed: true},
+		{name: "block without phase0 message", clientName: "lighthouse", block: &spec.VersionedSignedBeaconBlock{Version: spec.DataVersionPhase0, Phase0: &phase0.SignedBeaconBlock{}}, failed: true},
+		{name: "block without altair data", clientName: "lighthouse", block: &spec.VersionedSignedBeaconBlock{Version: spec.DataVersionAltair}, failed: true},
+		{name: "block without bellatrix data", clientName: "lighthouse", block: &spec.VersionedSignedBeaconBlock{Version: spec.DataVersionBellatrix}, failed: true},
+		{name: "parse error with EOF", clientName: "lighthouse", err: errors.New("failed to parse response: EOF"), failed: true},
+		{name: "parse error with EOF unknown client", clientName: "", err: errors.New("failed to parse response: EOF"), failed: true},
+		{name: "prysm could not find block", clientName: "prysm", err: errors.New(`failed to request signed beacon block: GET failed with status 500: {"code":500,"message":"Could not find requested block: signed beacon block can't be nil"}`), missed: true},
+		{name: "prysm grpc not found", clientName: "prysm", err: errors.New(`GET failed with status 500: rpc error: code = NotFound desc = Could not find block`), missed: true},
+		{name: "teku block not found", clientName: "teku", err: errors.New(`GET failed with status 500: {"code":500,"message":"Block not found"}`), missed: true},
+		{name: "nimbus block not found", clientName: "nimbus", err: errors.New(`GET failed with status 500: {"code":500,"message":"Block not found"}`), missed: true},
+		{name: "lodestar no block found", clientName: "lodestar", err: errors.New(`GET failed with status 500: {"message":"No block found for id '4'"}`), missed: true},
+		{name: "prysm message from other client", clientName: "lighthouse", err: errors.New(`GET failed with status 500: Could not find requested block`), failed: true},
+		{name: "truncated body", clientName: "lighthouse", err: errors.New("failed to parse response: unexpected EOF"), failed: true},
+		{name: "internal error", clientName: "teku", err: errors.New(`GET failed with status 500: {"code":500,"message":"Internal server error"}`), failed: true},
+		{name: "bad gateway", clientName: "prysm", err: errors.New(`GET failed with status 502: bad gateway`), failed: true},
+		{name: "timeout", clientName: "nimbus", err: context.DeadlineExceeded, failed: true},
+		{name: "state not found", clientName: "teku", err: errors.New(`GET failed with status 500: {"code":500,"message":"State not found"}`), failed: true},
+	}
+
+	for _, tt := range tests {
+		block, err := normalizeBlockResponse(tt.clientName, newSignedBeaconBlock(tt.block), tt.err)
+		if tt.failed {
+			if err == nil {
+				t.Errorf("%v: expected error, got none", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if tt.missed && block != nil {
+			t.Errorf("%v: expected missed slot, got block", tt.name)
+		}
+		if !tt.missed && block == nil {
+			t.Errorf("%v: expected block, got missed slot", tt.name)
+		}
+	}
+}
+
+func TestNormalizeBlockResponseFromNode(t *testing.T) {
+	responses := map[string]struct {
+		status int
+		body   string
+		missed bool
+		failed bool
+	}{
+		"1":  {status: 404, body: `{"code":404,"message":"NOT_FOUND: beacon block at slot 1"}`, missed: true},
+		"2":  {status: 200, body: ``, missed: true},
+		"3":  {status: 200, body: `{"data":null}`, missed: true},
+		"4":  {status: 200, body: `{"version":"bellatrix","data":null}`, missed: true},
+		"5":  {status: 200, body: `{}`, missed: true},
+		"10": {status: 200, body: `{"version":"phase0","data":{}}`, failed: true},
+		"11": {status: 500, body: ``, failed: true},
+		"6":  {status: 500, body: `{"code":500,"message":"Could not find requested block: signed beacon block can't be nil"}`, missed: true},
+		"7":  {status: 500, body: `{"code":500,"message":"internal error"}`, failed: true},
+		"8":  {status: 503, body: `{"code":503,"message":"node is syncing"}`, failed: true},
+		"9":  {status: 200, body: `{"version":"bellatrix","data":{"message":`, failed: true},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/node/version":
+			w.Write([]byte(`{"data":{"version":"Prysm/v2.1.3/linux-amd64"}}`))
+			return
+		case "/eth/v1/beacon/genesis":
+			w.Write([]byte(`{"data":{"genesis_time":"1606824023","genesis_validators_root":"0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95","genesis_fork_version":"0x00000000"}}`))
+			return
+		case "/eth/v1/config/spec":
+			w.Write([]byte(`{"data":{"SECONDS_PER_SLOT":"12","SLOTS_PER_EPOCH":"32"}}`))
+			return
+		case "/eth/v1/config/deposit_contract":
+			w.Write([]byte(`{"data":{"chain_id":"1","address":"0x00000000219ab540356cbb839cbe05303d7705fa"}}`))
+			return
+		case "/eth/v1/config/fork_schedule":
+			w.Write([]byte(`{"data":[{"previous_version":"0x00000000","current_version":"0x00000000","epoch":"0"}]}`))
+			return
+		case "/eth/v2/beacon/blocks/0":
+			// requested by the client on startup
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for slot, res := range responses {
+			if r.URL.Path == fmt.Sprintf("/eth/v2/beacon/blocks/%s", slot) {
+				w.WriteHeader(res.status)
+				w.Write([]byte(res.body))
+				return
+			}
+		}
+		t.Errorf("unexpected request: %v", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	service, err := ethhttp.New(context.Background(), ethhttp.WithAddress(server.URL), ethhttp.WithLogLevel(zerolog.Disabled))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := service.(*ethhttp.Service)
+	clientName := getBeaconClientName(context.Background(), client)
+	if clientName != "prysm" {
+		t.Errorf("wrong client name: %v != %v", clientName, "prysm")
+	}
+
+	for slot, res := range responses {
+		s, _ := strconv.ParseUint(slot, 10, 64)
+		block, err := getSignedBeaconBlock(context.Background(), server.URL, s)
+		normalized, err := normalizeBlockResponse(clientName, block, err)
+		if res.failed {
+			if err == nil {
+				t.Errorf("slot %v: expected error, got none", slot)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("slot %v: unexpected error: %v", slot, err)
+		}
+		if res.missed && normalized != nil {
+			t.Errorf("slot %v: expected missed slot, got block", slot)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// responseMutations turn a recorded response of the beacon api into a malformed one, they return nil if the response
+// can not be mutated that way.
+var responseMutations = map[string]func(body []byte) []byte{
+	"truncated": func(body []byte) []byte {
+		return body[:len(body)/2]
+	},
+	// the first number is replaced by one that does not fit into an uint64
+	"out-of-range": func(body []byte) []byte {
+		loc := regexp.MustCompile(`"[0-9]+"`).FindIndex(body)
+		if loc == nil {
+			return nil
+		}
+		return append(append(append([]byte{}, body[:loc[0]]...), `"18446744073709551616"`...), body[loc[1]:]...)
+	},
+	// the first field of the first object of the data is removed
+	"missing-field": func(body []byte) []byte {
+		var res map[string]interface{}
+		if err := json.Unmarshal(body, &res); err != nil {
+			return nil
+		}
+		data := res["data"]
+		if list, ok := data.([]interface{}); ok && len(list) > 0 {
+			data = list[0]
+		}
+		obj, ok := data.(map[string]interface{})
+		if !ok || len(obj) == 0 {
+			return nil
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		delete(obj, keys[0])
+		mutated, err := json.Marshal(res)
+		if err != nil {
+			return nil
+		}
+		return mutated
+	},
+}
+
+// newMutatingProxy returns a proxy of the beacon node that applies the mutation to the responses to the given path.
+func newMutatingProxy(t *testing.T, bnAddress, path string, mutate func(body []byte) []byte) *httptest.Server {
+	target, err := url.Parse(bnAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ModifyResponse = func(res *http.Response) error {
+		if res.Request.URL.Path != path {
+			return nil
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+		if mutated := mutate(body); mutated != nil {
+			body = mutated
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		res.ContentLength = int64(len(body))
+		res.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		return nil
+	}
+	return httptest.NewServer(proxy)
+}
+
+func TestCalculateWithMutatedResponses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("replays the calculation of a day for every mutation")
+	}
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	expected, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{
+		"/eth/v1/beacon/genesis",
+		"/eth/v1/config/spec",
+		"/eth/v1/beacon/headers/finalized",
+		"/eth/v1/beacon/states/72000/validators",
+		"/eth/v1/beacon/states/79200/root",
+	}
+	names := make([]string, 0, len(responseMutations))
+	for name := range responseMutations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, path := range paths {
+		for _, name := range names {
+			proxy := newMutatingProxy(t, bnServer.URL, path, responseMutations[name])
+			done := make(chan struct{})
+			var day *Day
+			go func() {
+				defer close(done)
+				day, _, err = Calculate(context.Background(), proxy.URL, elServer.URL, "10", 10)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Minute):
+				t.Fatalf("%v of %v: calculation did not finish", name, path)
+			}
+			proxy.Close()
+			// a malformed response must either fail the calculation or not change its result
+			if err == nil && !day.Apr.Equal(expected.Apr) {
+				t.Errorf("%v of %v: silently changed apr: %v != %v", name, path, day.Apr, expected.Apr)
+			}
+			if testing.Verbose() {
+				t.Logf("%v of %v: %v", name, path, err)
+			}
+		}
+	}
+}
+
+// FuzzDecodeJSONBlock checks that malformed blocks fail to decode instead of panicking, the seed corpus is a recorded
+// block and its mutations.
+func FuzzDecodeJSONBlock(f *testing.F) {
+	block := []byte(fmt.Sprintf(`{"version":"phase0","data":{"message":{"slot":"1","proposer_index":"2","parent_root":"0x%064x","state_root":"0x%064x","body":{"randao_reveal":"0x%0192x","eth1_data":{"deposit_root":"0x%064x","deposit_count":"0","block_hash":"0x%064x"},"graffiti":"0x%064x","proposer_slashings":[],"attester_slashings":[],"attestations":[],"deposits":[],"voluntary_exits":[]}},"signature":"0x%0192x"}}`, 0, 0, 0, 0, 0, 0, 0))
+	f.Add(block)
+	for _, mutate := range responseMutations {
+		if mutated := mutate(block); mutated != nil {
+			f.Add(mutated)
+		}
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		b, err := decodeJSONBlock(body, func() error { return nil })
+		if err == nil && b != nil && b.Phase0 == nil && b.Altair == nil && b.Bellatrix == nil {
+			t.Errorf("decoded block without data: %s", body)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var maxSyncWait time.Duration
+var maxSyncWaitMu = sync.Mutex{}
+var maxSyncDistance = uint64(1)
+var maxSyncDistanceMu = sync.Mutex{}
+
+// syncPollInterval is the interval the sync status of a lagging node is polled in.
+var syncPollInterval = time.Second * 12
+
+// SetMaxSyncWait sets how long a calculation is paused while the consensus node is syncing or optimistic before it
+// fails, the sync status of the node is checked before the calculation and at every epoch. The check is disabled if 0.
+func SetMaxSyncWait(dur time.Duration) {
+	maxSyncWaitMu.Lock()
+	defer maxSyncWaitMu.Unlock()
+	maxSyncWait = dur
+}
+
+func GetMaxSyncWait() time.Duration {
+	maxSyncWaitMu.Lock()
+	defer maxSyncWaitMu.Unlock()
+	return maxSyncWait
+}
+
+// SetMaxSyncDistance sets the number of slots the consensus node may lag behind the head of the chain.
+func SetMaxSyncDistance(slots uint64) {
+	maxSyncDistanceMu.Lock()
+	defer maxSyncDistanceMu.Unlock()
+	maxSyncDistance = slots
+}
+
+func GetMaxSyncDistance() uint64 {
+	maxSyncDistanceMu.Lock()
+	defer maxSyncDistanceMu.Unlock()
+	return maxSyncDistance
+}
+
+type syncStatus struct {
+	HeadSlot     string `json:"head_slot"`
+	SyncDistance string `json:"sync_distance"`
+	IsSyncing    bool   `json:"is_syncing"`
+	IsOptimistic bool   `json:"is_optimistic"`
+	ElOffline    bool   `json:"el_offline"`
+}
+
+// notReadyReason returns why the consensus node at address can not be used for a calculation or an empty string if
+// it is ready.
+func notReadyReason(ctx context.Context, address string) string {
+	var res struct {
+		Data syncStatus `json:"data"`
+	}
+	found, err := getBeaconJson(ctx, address, "/eth/v1/node/syncing", &res)
+	if err != nil {
+		return fmt.Sprintf("error getting sync status: %v", err)
+	}
+	if !found {
+		return "sync status not available"
+	}
+	var reasons []string
+	distance, err := strconv.ParseUint(res.Data.SyncDistance, 10, 64)
+	if err != nil {
+		reasons = append(reasons, fmt.Sprintf("invalid sync distance %q", res.Data.SyncDistance))
+	} else if distance > GetMaxSyncDistance() {
+		reasons = append(reasons, fmt.Sprintf("syncing with distance %v at head slot %v", distance, res.Data.HeadSlot))
+	}
+	if res.Data.IsOptimistic {
+		reasons = append(reasons, "optimistic")
+	}
+	if res.Data.ElOffline {
+		reasons = append(reasons, "execution client offline")
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// waitForSync blocks until the consensus node at address is synced and not optimistic, it fails if the node is not
+// ready after the max sync wait.
+func waitForSync(ctx context.Context, address string) error {
+	maxWait := GetMaxSyncWait()
+	if maxWait == 0 {
+		return nil
+	}
+	start := time.Now()
+	paused := false
+	for {
+		reason := notReadyReason(ctx, address)
+		if reason == "" {
+			if paused {
+				log.Printf("consensus node %v is ready again after %v, resuming calculation", address, time.Since(start).Round(time.Second))
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if time.Since(start) >= maxWait {
+			return fmt.Errorf("consensus node %v not ready after waiting %v: %v", address, maxWait, reason)
+		}
+		log.Printf("consensus node %v is not ready (%v), pausing calculation", address, reason)
+		paused = true
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(syncPollInterval):
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForSync(t *testing.T) {
+	defer func(interval time.Duration) { syncPollInterval = interval }(syncPollInterval)
+	syncPollInterval = time.Millisecond
+	defer SetMaxSyncWait(GetMaxSyncWait())
+
+	// the node is syncing, optimistic and then ready
+	statuses := []string{
+		`{"head_slot":"100","sync_distance":"50","is_syncing":true,"is_optimistic":false,"el_offline":false}`,
+		`{"head_slot":"150","sync_distance":"0","is_syncing":false,"is_optimistic":true,"el_offline":false}`,
+		`{"head_slot":"151","sync_distance":"1","is_syncing":false,"is_optimistic":false,"el_offline":false}`,
+	}
+	requests := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/eth/v1/node/syncing" {
+			t.Errorf("unexpected request: %v", r.URL.Path)
+			return
+		}
+		n := int(atomic.AddInt32(&requests, 1)) - 1
+		if n >= len(statuses) {
+			n = len(statuses) - 1
+		}
+		fmt.Fprintf(w, `{"data":%s}`, statuses[n])
+	}))
+	defer server.Close()
+
+	SetMaxSyncWait(0)
+	if err := waitForSync(context.Background(), server.URL); err != nil || requests != 0 {
+		t.Errorf("disabled sync check requested the node %v times (err: %v)", requests, err)
+	}
+
+	SetMaxSyncWait(time.Minute)
+	if err := waitForSync(context.Background(), server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("wrong number of sync status requests: %v != %v", requests, 3)
+	}
+
+	statuses = statuses[:1]
+	atomic.StoreInt32(&requests, 0)
+	SetMaxSyncWait(time.Millisecond * 20)
+	err := waitForSync(context.Background(), server.URL)
+	if err == nil || !strings.Contains(err.Error(), "syncing with distance 50") {
+		t.Errorf("expected error of lagging node, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	SetMaxSyncWait(time.Minute)
+	if err := waitForSync(ctx, server.URL); err != context.Canceled {
+		t.Errorf("expected cancelled context, got %v", err)
+	}
+}
@@ -0,0 +1,398 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Option configures a calculation of CalculateWithOptions. Options override the package settings of the same name for
+// this calculation only, settings without an option apply as usual.
+type Option func(*calculateOptions)
+
+// DefaultConcurrency is the number of blocks CalculateWithOptions fetches and decodes concurrently without
+// WithConcurrency, it matches the default of the cli.
+const DefaultConcurrency = 10
+
+type calculateOptions struct {
+	concurrency      int
+	timeout          time.Duration
+	requestTimeout   time.Duration
+	client           BeaconClient
+	methodology      int
+	debugLevel       *uint64
+	txFeeBreakdown   *bool
+	includeMEV       *bool
+	boundary         DayBoundary
+	progressSinks    []ProgressSink
+	receiptsMode     *ReceiptsMode
+	network          *NetworkOverrides
+	lowMemory        *bool
+	twoStates        *bool
+	endBalances      *bool
+	sszStates        *bool
+	allowUnfinalized *bool
+	perEpochLeak     *bool
+	cacheDir         *string
+	eligibility      EligibilityRules
+	// exclusive is set by the options whose calculations are not coalesced with other calculations of the same day,
+	// the other options that change the result are part of coalesceKey
+	exclusive bool
+}
+
+// WithConcurrency sets the number of blocks that are fetched and decoded concurrently, it is DefaultConcurrency without
+// this option.
+func WithConcurrency(n int) Option {
+	return func(o *calculateOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithTimeout limits the duration of the whole calculation, see WithRequestTimeout to limit the single requests.
+func WithTimeout(d time.Duration) Option {
+	return func(o *calculateOptions) {
+		o.timeout = d
+	}
+}
+
+// WithRequestTimeout limits the duration of every request to the consensus and execution node of the calculation, it
+// overrides SetConsTimeout and SetExecTimeout.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(o *calculateOptions) {
+		o.requestTimeout = d
+	}
+}
+
+// WithBeaconClient uses the given client for the consensus node instead of connecting to it by address, see
+// CalculateWithClient.
+func WithBeaconClient(client BeaconClient) Option {
+	return func(o *calculateOptions) {
+		o.client = client
+		o.exclusive = true
+	}
+}
+
+// WithMethodology calculates the day with the given methodology version instead of MethodologyVersion, a methodology
+// transition is not applied.
+func WithMethodology(version int) Option {
+	return func(o *calculateOptions) {
+		o.methodology = version
+		o.exclusive = true
+	}
+}
+
+// WithDebugLevel overrides SetDebugLevel.
+func WithDebugLevel(lvl uint64) Option {
+	return func(o *calculateOptions) {
+		o.debugLevel = &lvl
+		o.exclusive = true
+	}
+}
+
+// WithTxFeeBreakdown overrides SetTxFeeBreakdown.
+func WithTxFeeBreakdown(enabled bool) Option {
+	return func(o *calculateOptions) {
+		o.txFeeBreakdown = &enabled
+	}
+}
+
+// WithIncludeMEV overrides SetIncludeMEV.
+func WithIncludeMEV(enabled bool) Option {
+	return func(o *calculateOptions) {
+		o.includeMEV = &enabled
+	}
+}
+
+// WithDayBoundary overrides SetDayBoundary, e.g. to calculate the day of a network whose days do not start at genesis.
+func WithDayBoundary(b DayBoundary) Option {
+	return func(o *calculateOptions) {
+		o.boundary = b
+		o.exclusive = true
+	}
+}
+
+// WithReceiptsMode sets how the receipts of the transactions are requested from the execution node, it is
+// ReceiptsModeTransaction without this option.
+func WithReceiptsMode(mode ReceiptsMode) Option {
+	return func(o *calculateOptions) {
+		o.receiptsMode = &mode
+	}
+}
+
+// NetworkOverrides replaces parameters of the network that are otherwise read from the consensus node, e.g. for a devnet
+// whose node reports an incomplete spec. Zero values are not overridden.
+type NetworkOverrides struct {
+	// Name is the network the day is recorded for in the spec history.
+	Name string
+	// Genesis is the genesis time of the network.
+	Genesis time.Time
+	// Spec replaces values of the spec by key, the values have the types the spec is decoded to, e.g. uint64 for
+	// SLOTS_PER_EPOCH and time.Duration for SECONDS_PER_SLOT.
+	Spec map[string]interface{}
+}
+
+// spec returns the spec of the node with the overridden values, the spec of the node is not modified.
+func (n *NetworkOverrides) spec(apiSpec map[string]interface{}) map[string]interface{} {
+	if n == nil || len(n.Spec) == 0 {
+		return apiSpec
+	}
+	overridden := make(map[string]interface{}, len(apiSpec)+len(n.Spec))
+	for key, value := range apiSpec {
+		overridden[key] = value
+	}
+	for key, value := range n.Spec {
+		overridden[key] = value
+	}
+	return overridden
+}
+
+func (n *NetworkOverrides) genesis(genesis time.Time) time.Time {
+	if n == nil || n.Genesis.IsZero() {
+		return genesis
+	}
+	return n.Genesis
+}
+
+func (n *NetworkOverrides) name(name string) string {
+	if n == nil || n.Name == "" {
+		return name
+	}
+	return n.Name
+}
+
+// WithNetworkOverrides replaces parameters of the network that are otherwise read from the consensus node.
+func WithNetworkOverrides(n NetworkOverrides) Option {
+	return func(o *calculateOptions) {
+		o.network = &n
+		o.exclusive = true
+	}
+}
+
+// WithLowMemory overrides SetLowMemory.
+func WithLowMemory(enabled bool) Option {
+	return func(o *calculateOptions) {
+		o.lowMemory = &enabled
+	}
+}
+
+// WithTwoStates overrides SetTwoStates.
+func WithTwoStates(enabled bool) Option {
+	return func(o *calculateOptions) {
+		o.twoStates = &enabled
+	}
+}
+
+// WithEndBalances overrides SetEndBalances.
+func WithEndBalances(enabled bool) Option {
+	return func(o *calculateOptions) {
+		o.endBalances = &enabled
+	}
+}
+
+// WithSSZStates overrides SetSSZStates.
+func WithSSZStates(enabled bool) Option {
+	return func(o *calculateOptions) {
+		o.sszStates = &enabled
+	}
+}
+
+// WithAllowUnfinalized overrides SetAllowUnfinalized.
+func WithAllowUnfinalized(allow bool) Option {
+	return func(o *calculateOptions) {
+		o.allowUnfinalized = &allow
+	}
+}
+
+// WithPerEpochLeakDetection overrides SetPerEpochLeakDetection.
+func WithPerEpochLeakDetection(enabled bool) Option {
+	return func(o *calculateOptions) {
+		o.perEpochLeak = &enabled
+	}
+}
+
+// WithCacheDir overrides SetCacheDir.
+func WithCacheDir(path string) Option {
+	return func(o *calculateOptions) {
+		o.cacheDir = &path
+	}
+}
+
+// WithEligibilityRules overrides SetEligibilityRules.
+func WithEligibilityRules(r EligibilityRules) Option {
+	return func(o *calculateOptions) {
+		o.eligibility = r
+	}
+}
+
+// WithProgress sends the progress of the calculation to the given sinks in addition to the sinks of SetProgressSinks.
+func WithProgress(sinks ...ProgressSink) Option {
+	return func(o *calculateOptions) {
+		o.progressSinks = append(o.progressSinks, sinks...)
+		o.exclusive = true
+	}
+}
+
+// ProgressFunc is a ProgressSink that calls the function, e.g. WithProgress(ProgressFunc(func(...) error {...})).
+type ProgressFunc func(ctx context.Context, p DayProgress) error
+
+func (f ProgressFunc) Progress(ctx context.Context, p DayProgress) error {
+	return f(ctx, p)
+}
+
+// the methods of calculateOptions return the package settings if the receiver is nil or the option is not set
+
+func (o *calculateOptions) getDebugLevel() uint64 {
+	if o == nil || o.debugLevel == nil {
+		return GetDebugLevel()
+	}
+	return *o.debugLevel
+}
+
+func (o *calculateOptions) getTxFeeBreakdown() bool {
+	if o == nil || o.txFeeBreakdown == nil {
+		return GetTxFeeBreakdown()
+	}
+	return *o.txFeeBreakdown
+}
+
+func (o *calculateOptions) getIncludeMEV() bool {
+	if o == nil || o.includeMEV == nil {
+		return GetIncludeMEV()
+	}
+	return *o.includeMEV
+}
+
+func (o *calculateOptions) getDayBoundary() DayBoundary {
+	if o == nil || o.boundary == nil {
+		return GetDayBoundary()
+	}
+	return o.boundary
+}
+
+func (o *calculateOptions) getConsTimeout() time.Duration {
+	if o == nil || o.requestTimeout == 0 {
+		return GetConsTimeout()
+	}
+	return o.requestTimeout
+}
+
+func (o *calculateOptions) getExecTimeout() time.Duration {
+	if o == nil || o.requestTimeout == 0 {
+		return GetExecTimeout()
+	}
+	return o.requestTimeout
+}
+
+func (o *calculateOptions) getReceiptsMode() ReceiptsMode {
+	if o == nil || o.receiptsMode == nil {
+		return ReceiptsModeTransaction
+	}
+	return *o.receiptsMode
+}
+
+func (o *calculateOptions) getNetworkOverrides() *NetworkOverrides {
+	if o == nil {
+		return nil
+	}
+	return o.network
+}
+
+func (o *calculateOptions) getLowMemory() bool {
+	if o == nil || o.lowMemory == nil {
+		return GetLowMemory()
+	}
+	return *o.lowMemory
+}
+
+func (o *calculateOptions) getTwoStates() bool {
+	if o == nil || o.twoStates == nil {
+		return GetTwoStates()
+	}
+	return *o.twoStates
+}
+
+func (o *calculateOptions) getEndBalances() bool {
+	if o == nil || o.endBalances == nil {
+		return GetEndBalances()
+	}
+	return *o.endBalances
+}
+
+func (o *calculateOptions) getSSZStates() bool {
+	if o == nil || o.sszStates == nil {
+		return GetSSZStates()
+	}
+	return *o.sszStates
+}
+
+func (o *calculateOptions) getAllowUnfinalized() bool {
+	if o == nil || o.allowUnfinalized == nil {
+		return GetAllowUnfinalized()
+	}
+	return *o.allowUnfinalized
+}
+
+func (o *calculateOptions) getPerEpochLeakDetection() bool {
+	if o == nil || o.perEpochLeak == nil {
+		return GetPerEpochLeakDetection()
+	}
+	return *o.perEpochLeak
+}
+
+func (o *calculateOptions) getCacheDir() string {
+	if o == nil || o.cacheDir == nil {
+		return GetCacheDir()
+	}
+	return *o.cacheDir
+}
+
+func (o *calculateOptions) getEligibilityRules() EligibilityRules {
+	if o == nil || o.eligibility == nil {
+		return GetEligibilityRules()
+	}
+	return o.eligibility
+}
+
+// coalesceKey returns the settings of the options that change the result of a calculation, calculations of the same
+// day are only coalesced if their keys are equal.
+func (o *calculateOptions) coalesceKey() string {
+	return fmt.Sprintf("txFeeBreakdown=%v,includeMEV=%v,lowMemory=%v,endBalances=%v,allowUnfinalized=%v,perEpochLeak=%v,eligibility=%v", o.getTxFeeBreakdown(), o.getIncludeMEV(), o.getLowMemory(), o.getEndBalances(), o.getAllowUnfinalized(), o.getPerEpochLeakDetection(), o.getEligibilityRules())
+}
+
+type calculateOptionsKey struct{}
+
+// withCalculateOptions returns a context that carries the options of a calculation to the requests it makes.
+func withCalculateOptions(ctx context.Context, o *calculateOptions) context.Context {
+	return context.WithValue(ctx, calculateOptionsKey{}, o)
+}
+
+// calculateOptionsOf returns the options of the calculation of the context, it is nil outside of a calculation and the
+// methods of the options return the package settings then.
+func calculateOptionsOf(ctx context.Context) *calculateOptions {
+	o, _ := ctx.Value(calculateOptionsKey{}).(*calculateOptions)
+	return o
+}
+
+func (o *calculateOptions) getProgressSinks() []ProgressSink {
+	sinks := GetProgressSinks()
+	if o == nil || len(o.progressSinks) == 0 {
+		return sinks
+	}
+	return append(append([]ProgressSink{}, sinks...), o.progressSinks...)
+}
+
+// CalculateWithOptions calculates the eth.store of the given day like Calculate, configured by the options. Calculations
+// are coalesced with concurrent calculations of the same day whose options lead to the same result, unless an option
+// observes the calculation or replaces its client, methodology or network.
+func CalculateWithOptions(ctx context.Context, bnAddress, elAddress, dayStr string, options ...Option) (*Day, map[uint64]*Day, error) {
+	o := &calculateOptions{concurrency: DefaultConcurrency}
+	for _, option := range options {
+		option(o)
+	}
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	return calculateCoalesced(ctx, bnAddress, elAddress, dayStr, o)
+}
@@ -0,0 +1,136 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gobitfly/eth.store/fixture"
+)
+
+func TestCalculateWithOptions(t *testing.T) {
+	f, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000, BaseFeeGwei: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bnServer, elServer := f.Servers()
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := CalculateWithOptions(context.Background(), bnServer.URL, elServer.URL, "10", WithConcurrency(10), WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.Apr.Equal(f.Expected.Apr) || day.TxFeeBreakdown != nil {
+		t.Errorf("wrong day: %v != %v, tx fee breakdown: %v", day.Apr, f.Expected.Apr, day.TxFeeBreakdown)
+	}
+
+	// the options only apply to their calculation
+	var mu sync.Mutex
+	var results []*Day
+	progress := ProgressFunc(func(ctx context.Context, p DayProgress) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if p.Result != nil {
+			results = append(results, p.Result)
+		}
+		return nil
+	})
+	day, _, err = CalculateWithOptions(context.Background(), bnServer.URL, elServer.URL, "10", WithConcurrency(10), WithTxFeeBreakdown(true), WithProgress(progress))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.TxFeeBreakdown == nil || !day.Apr.Equal(f.Expected.Apr) {
+		t.Errorf("tx fee breakdown option not applied: %v", day.TxFeeBreakdown)
+	}
+	if len(results) != 1 || results[0] != day {
+		t.Errorf("wrong progress results: %v", results)
+	}
+	if GetTxFeeBreakdown() || len(GetProgressSinks()) != 0 {
+		t.Errorf("options changed the package settings")
+	}
+
+	if _, _, err := CalculateWithOptions(context.Background(), bnServer.URL, elServer.URL, "10", WithMethodology(MethodologyVersion+1)); err == nil {
+		t.Errorf("expected error of unsupported methodology")
+	}
+	if _, _, err := CalculateWithOptions(context.Background(), bnServer.URL, elServer.URL, "10", WithTimeout(time.Nanosecond)); err == nil {
+		t.Errorf("expected error after the timeout")
+	}
+}
+
+func TestCalculateWithSettingOptions(t *testing.T) {
+	f, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000, BaseFeeGwei: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bnServer := httptest.NewServer(f.BeaconHandler())
+	defer bnServer.Close()
+	el := f.ExecutionHandler()
+	var mu sync.Mutex
+	methods := map[string]int{}
+	elServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		for _, method := range []string{"eth_getTransactionReceipt", "eth_getBlockReceipts"} {
+			methods[method] += strings.Count(string(body), `"`+method+`"`)
+		}
+		mu.Unlock()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		el.ServeHTTP(w, r)
+	}))
+	defer elServer.Close()
+
+	// the receipts of every block are requested at once and only the balances are requested at the end of the day
+	day, _, err := CalculateWithOptions(context.Background(), bnServer.URL, elServer.URL, "10", WithReceiptsMode(ReceiptsModeBlock), WithEndBalances(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.Apr.Equal(f.Expected.Apr) || day.CompositionEnd != nil {
+		t.Errorf("wrong day: %v != %v, composition at the end: %+v", day.Apr, f.Expected.Apr, day.CompositionEnd)
+	}
+	if methods["eth_getTransactionReceipt"] != 0 || methods["eth_getBlockReceipts"] == 0 {
+		t.Errorf("wrong receipt requests: %v", methods)
+	}
+	if GetEndBalances() {
+		t.Errorf("options changed the package settings")
+	}
+
+	// the genesis of the network overrides the genesis of the node, the days start 10 days after it
+	genesis := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	day, _, err = CalculateWithOptions(context.Background(), bnServer.URL, elServer.URL, "10", WithNetworkOverrides(NetworkOverrides{Name: "devnet", Genesis: genesis}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.Window.StartTime.Equal(genesis.Add(10 * 24 * time.Hour)) {
+		t.Errorf("wrong start of day with network overrides: %v", day.Window.StartTime)
+	}
+
+	if _, _, err := CalculateWithOptions(context.Background(), bnServer.URL, elServer.URL, "10", WithRequestTimeout(time.Nanosecond)); err == nil {
+		t.Errorf("expected error after the request timeout")
+	}
+}
+
+func TestNetworkOverrides(t *testing.T) {
+	apiSpec := map[string]interface{}{"SLOTS_PER_EPOCH": uint64(32), "CONFIG_NAME": "mainnet"}
+	n := &NetworkOverrides{Name: "devnet", Spec: map[string]interface{}{"SLOTS_PER_EPOCH": uint64(8)}}
+	overridden := n.spec(apiSpec)
+	if overridden["SLOTS_PER_EPOCH"] != uint64(8) || overridden["CONFIG_NAME"] != "mainnet" || apiSpec["SLOTS_PER_EPOCH"] != uint64(32) {
+		t.Errorf("wrong overridden spec: %v, spec of the node: %v", overridden, apiSpec)
+	}
+	if n.name("mainnet") != "devnet" || n.genesis(time.Unix(1, 0)) != time.Unix(1, 0) {
+		t.Errorf("wrong overrides: %v, %v", n.name("mainnet"), n.genesis(time.Unix(1, 0)))
+	}
+	var none *NetworkOverrides
+	if none.name("mainnet") != "mainnet" || len(none.spec(apiSpec)) != 2 {
+		t.Errorf("nil overrides changed the network")
+	}
+}
@@ -0,0 +1,92 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+var orphanedBlocks bool
+var orphanedBlocksMu = sync.Mutex{}
+
+// SetOrphanedBlocks enables the detection of orphaned blocks at the missed slots of a day, so that missed proposals
+// can be told apart from blocks that were proposed but did not become canonical. The orphaned blocks are taken from
+// the non-canonical headers the consensus node still knows at the slots, nodes that prune them after finalization
+// report fewer orphaned blocks.
+func SetOrphanedBlocks(enabled bool) {
+	orphanedBlocksMu.Lock()
+	defer orphanedBlocksMu.Unlock()
+	orphanedBlocks = enabled
+}
+
+func GetOrphanedBlocks() bool {
+	orphanedBlocksMu.Lock()
+	defer orphanedBlocksMu.Unlock()
+	return orphanedBlocks
+}
+
+// OrphanedBlock is a block at a missed slot that did not become part of the canonical chain.
+type OrphanedBlock struct {
+	Slot          uint64 `json:"slot"`
+	ProposerIndex uint64 `json:"proposerIndex"`
+	Root          string `json:"root"`
+}
+
+// OrphanStats holds the orphaned blocks of a day, OrphanedProposals are those proposed by the eth.store validators.
+type OrphanStats struct {
+	OrphanedBlocks    decimal.Decimal `json:"orphanedBlocks"`
+	OrphanedProposals decimal.Decimal `json:"orphanedProposals"`
+	Blocks            []OrphanedBlock `json:"blocks,omitempty"`
+}
+
+// add counts the orphaned blocks of a missed slot.
+func (s *OrphanStats) add(blocks []OrphanedBlock, validatorsByIndex map[phase0.ValidatorIndex]*Validator) {
+	for _, b := range blocks {
+		s.OrphanedBlocks = s.OrphanedBlocks.Add(decimal.NewFromInt(1))
+		if _, exists := validatorsByIndex[phase0.ValidatorIndex(b.ProposerIndex)]; exists {
+			s.OrphanedProposals = s.OrphanedProposals.Add(decimal.NewFromInt(1))
+		}
+		s.Blocks = append(s.Blocks, b)
+	}
+}
+
+func (s *OrphanStats) clone() *OrphanStats {
+	c := *s
+	c.Blocks = append([]OrphanedBlock{}, s.Blocks...)
+	return &c
+}
+
+// getOrphanedBlocks returns the non-canonical blocks the consensus node knows at the given slot.
+func getOrphanedBlocks(ctx context.Context, address string, slot uint64) ([]OrphanedBlock, error) {
+	var res struct {
+		Data []struct {
+			Root      string `json:"root"`
+			Canonical bool   `json:"canonical"`
+			Header    struct {
+				Message struct {
+					ProposerIndex string `json:"proposer_index"`
+				} `json:"message"`
+			} `json:"header"`
+		} `json:"data"`
+	}
+	_, err := getBeaconJson(ctx, address, fmt.Sprintf("/eth/v1/beacon/headers?slot=%d", slot), &res)
+	if err != nil {
+		return nil, fmt.Errorf("error getting headers at slot %v: %w", slot, err)
+	}
+	var blocks []OrphanedBlock
+	for _, h := range res.Data {
+		if h.Canonical {
+			continue
+		}
+		proposerIndex, err := strconv.ParseUint(h.Header.Message.ProposerIndex, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proposer_index of header %v at slot %v: %w", h.Root, slot, err)
+		}
+		blocks = append(blocks, OrphanedBlock{Slot: slot, ProposerIndex: proposerIndex, Root: h.Root})
+	}
+	return blocks, nil
+}
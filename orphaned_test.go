@@ -0,0 +1,60 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestOrphanedBlocks(t *testing.T) {
+	header := func(root string, canonical bool, proposerIndex int) string {
+		return fmt.Sprintf(`{"root":"%s","canonical":%v,"header":{"message":{"slot":"100","proposer_index":"%d"}}}`, root, canonical, proposerIndex)
+	}
+	mocks := map[string]string{
+		// an orphaned block of an eth.store validator and one of another validator
+		"100": fmt.Sprintf(`{"data":[%s,%s]}`, header("0xbb", false, 2), header("0xaa", false, 1)),
+		// a truly missed slot
+		"101": `{"data":[]}`,
+		// a canonical block
+		"102": fmt.Sprintf(`{"data":[%s]}`, header("0xcc", true, 1)),
+		"103": fmt.Sprintf(`{"data":[%s]}`, header("0xdd", false, -1)),
+	}
+	bnServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mock, exists := mocks[r.URL.Query().Get("slot")]
+		if r.URL.Path != "/eth/v1/beacon/headers" || !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(mock))
+	}))
+	defer bnServer.Close()
+
+	stats := &OrphanStats{}
+	validatorsByIndex := map[phase0.ValidatorIndex]*Validator{1: {}}
+	for _, slot := range []uint64{100, 101, 102, 104} {
+		blocks, err := getOrphanedBlocks(context.Background(), bnServer.URL, slot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats.add(blocks, validatorsByIndex)
+	}
+	if stats.OrphanedBlocks.IntPart() != 2 || stats.OrphanedProposals.IntPart() != 1 {
+		t.Errorf("wrong orphan stats: %+v", stats)
+	}
+	if fmt.Sprintf("%v", stats.Blocks) != "[{100 2 0xbb} {100 1 0xaa}]" {
+		t.Errorf("wrong orphaned blocks: %v", stats.Blocks)
+	}
+	if _, err := getOrphanedBlocks(context.Background(), bnServer.URL, 103); err == nil {
+		t.Errorf("expected error for invalid proposer_index")
+	}
+
+	c := stats.clone()
+	c.add([]OrphanedBlock{{Slot: 105}}, validatorsByIndex)
+	if len(stats.Blocks) != 2 || stats.OrphanedBlocks.IntPart() != 2 {
+		t.Errorf("clone modified the stats: %+v", stats)
+	}
+}
@@ -0,0 +1,209 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// forkNames are the forks of the consensus specs in the order of their activation.
+var forkNames = []string{"phase0", "altair", "bellatrix", "capella", "deneb", "electra"}
+
+// latencySamples is the number of requests to measure the latency of the consensus node with.
+const latencySamples = 3
+
+// Plan describes the calculation of a day without fetching its blocks.
+type Plan struct {
+	Day               uint64         `json:"day"`
+	DayBoundary       string         `json:"dayBoundary"`
+	FinalizedDay      uint64         `json:"finalizedDay"`
+	FirstSlot         uint64         `json:"firstSlot"`
+	EndSlot           uint64         `json:"endSlot"`
+	FirstEpoch        uint64         `json:"firstEpoch"`
+	LastEpoch         uint64         `json:"lastEpoch"`
+	StartTime         time.Time      `json:"startTime"`
+	EndTime           time.Time      `json:"endTime"`
+	ConsAddress       string         `json:"consAddress"`
+	ConsNotReady      string         `json:"consNotReady,omitempty"`
+	ExecChainId       string         `json:"execChainId,omitempty"`
+	ExecError         string         `json:"execError,omitempty"`
+	Forks             []string       `json:"forks"`
+	UnsupportedForks  []string       `json:"unsupportedForks,omitempty"`
+	States            []PlannedState `json:"states"`
+	ConsRequests      uint64         `json:"consRequests"`
+	ExecRequests      uint64         `json:"execRequests"`
+	ConsLatency       time.Duration  `json:"consLatency"`
+	EstimatedDuration time.Duration  `json:"estimatedDuration"`
+}
+
+// PlannedState is a historical state that the calculation of a day requests.
+type PlannedState struct {
+	Slot  uint64    `json:"slot"`
+	Epoch uint64    `json:"epoch"`
+	Time  time.Time `json:"time"`
+}
+
+// forksOfEpochs returns the forks that are active at any epoch of the given epochs, forks that are not scheduled in
+// the spec are left out.
+func forksOfEpochs(apiSpec map[string]interface{}, firstEpoch, lastEpoch uint64) []string {
+	forks := []string{}
+	for i, name := range forkNames {
+		start := uint64(0)
+		if i > 0 {
+			epoch, err := getSpecUint64(apiSpec, strings.ToUpper(name)+"_FORK_EPOCH")
+			if err != nil {
+				continue
+			}
+			start = epoch
+		}
+		if start > lastEpoch {
+			continue
+		}
+		// a fork that is superseded before the first epoch is not active
+		if len(forks) > 0 && start <= firstEpoch {
+			forks = forks[:0]
+		}
+		forks = append(forks, name)
+	}
+	return forks
+}
+
+// GetPlan resolves the slots and epochs of the given day, checks the consensus and execution nodes and estimates the
+// number of requests and the duration of the calculation of the day without fetching any blocks. The options are the
+// options of the planned calculation, see CalculateWithOptions.
+func GetPlan(ctx context.Context, bnAddress, elAddress, dayStr string, concurrency int, options ...Option) (*Plan, error) {
+	o := &calculateOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	ctx = withCalculateOptions(ctx, o)
+	client, err := newConsClient(ctx, bnAddress)
+	if err != nil {
+		return nil, err
+	}
+	apiSpec, err := client.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timing, err := getChainTiming(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	boundary := o.getDayBoundary()
+	p := &Plan{DayBoundary: boundary.String(), ConsAddress: client.Address()}
+
+	// the finalized header is requested several times to measure the latency of the node
+	var finalizedSlot uint64
+	start := time.Now()
+	for i := 0; i < latencySamples; i++ {
+		h, err := client.BeaconBlockHeader(ctx, "finalized")
+		if err != nil {
+			return nil, err
+		}
+		finalizedSlot = uint64(h.Header.Message.Slot)
+	}
+	p.ConsLatency = time.Since(start) / latencySamples
+
+	p.FinalizedDay = boundary.Day(finalizedSlot, timing) - 1
+	switch dayStr {
+	case "finalized":
+		p.Day = p.FinalizedDay
+	case "head":
+		p.Day = boundary.Day(finalizedSlot, timing)
+	default:
+		p.Day, err = strconv.ParseUint(dayStr, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.Day > p.FinalizedDay {
+		return nil, fmt.Errorf("requested to plan eth.store for a future day (last finalized day: %v, requested day: %v)", p.FinalizedDay, p.Day)
+	}
+	p.FirstSlot = boundary.FirstSlot(p.Day, timing)
+	p.EndSlot = boundary.FirstSlot(p.Day+1, timing)
+	if p.EndSlot > finalizedSlot {
+		p.EndSlot = finalizedSlot
+	}
+	p.FirstEpoch = p.FirstSlot / timing.SlotsPerEpoch
+	p.LastEpoch = (p.EndSlot - 1) / timing.SlotsPerEpoch
+	for _, slot := range stateSlots(p.FirstSlot, p.EndSlot, timing.SlotsPerEpoch, o.getPerEpochLeakDetection()) {
+		p.States = append(p.States, PlannedState{
+			Slot:  slot,
+			Epoch: slot / timing.SlotsPerEpoch,
+			Time:  timing.Genesis.Add(time.Duration(slot*timing.SecondsPerSlot) * time.Second).UTC(),
+		})
+	}
+	p.StartTime = timing.Genesis.Add(time.Duration(p.FirstSlot*timing.SecondsPerSlot) * time.Second)
+	p.EndTime = timing.Genesis.Add(time.Duration((p.EndSlot-1)*timing.SecondsPerSlot) * time.Second)
+
+	p.ConsNotReady = notReadyReason(ctx, p.ConsAddress)
+	elClient, err := newExecClient(ctx, elAddress)
+	if err == nil {
+		var chainId json.RawMessage
+		err = elClient.CallContext(ctx, &chainId, "eth_chainId")
+		elClient.Close()
+		p.ExecChainId = strings.Trim(string(chainId), "\"")
+	}
+	if err != nil {
+		p.ExecError = err.Error()
+	}
+
+	p.Forks = forksOfEpochs(apiSpec, p.FirstEpoch, p.LastEpoch)
+	for _, fork := range p.Forks {
+		supported := false
+		for _, s := range supportedForks {
+			supported = supported || s == fork
+		}
+		if !supported {
+			p.UnsupportedForks = append(p.UnsupportedForks, fork)
+		}
+	}
+
+	// the request mix of calculate: the validator-sets and state roots at the start and end of the day, every block,
+	// the finality at the start and end of the day or of every epoch and the optional checks
+	slots := p.EndSlot - p.FirstSlot
+	epochs := p.LastEpoch - p.FirstEpoch + 1
+	p.ConsRequests = 4 + slots + 2
+	if o.getPerEpochLeakDetection() {
+		p.ConsRequests = 4 + slots + epochs
+	}
+	if GetMaxSyncWait() > 0 {
+		p.ConsRequests += epochs
+	}
+	if GetEpochSeries() {
+		p.ConsRequests += epochs + 1
+	}
+	if GetAttestationProvider() != nil {
+		p.ConsRequests += epochs
+	}
+	if GetBlobVerification() {
+		p.ConsRequests += slots
+	}
+	// the receipts of the transactions of a block are requested in one batch
+	if bellatrixEpoch, err := getSpecUint64(apiSpec, "BELLATRIX_FORK_EPOCH"); err == nil && bellatrixEpoch <= p.LastEpoch {
+		executionSlots := slots
+		if bellatrixSlot := bellatrixEpoch * timing.SlotsPerEpoch; bellatrixSlot > p.FirstSlot {
+			executionSlots = p.EndSlot - bellatrixSlot
+		}
+		p.ExecRequests = executionSlots
+		if GetDepositCheck() {
+			p.ExecRequests++
+		}
+	}
+
+	parallel := uint64(concurrency)
+	if maxConcurrent := GetMaxConcurrentRequests(); maxConcurrent > 0 && uint64(maxConcurrent) < parallel {
+		parallel = uint64(maxConcurrent)
+	}
+	if parallel == 0 {
+		parallel = 1
+	}
+	p.EstimatedDuration = time.Duration(p.ConsRequests/parallel) * p.ConsLatency
+	if minDuration := time.Duration(p.ConsRequests) * GetRequestDelay(); minDuration > p.EstimatedDuration {
+		p.EstimatedDuration = minDuration
+	}
+	return p, nil
+}
@@ -0,0 +1,110 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestForksOfEpochs(t *testing.T) {
+	apiSpec := map[string]interface{}{"ALTAIR_FORK_EPOCH": uint64(100), "BELLATRIX_FORK_EPOCH": uint64(200)}
+	tests := []struct {
+		firstEpoch, lastEpoch uint64
+		forks                 []string
+	}{
+		{0, 50, []string{"phase0"}},
+		{50, 150, []string{"phase0", "altair"}},
+		{100, 150, []string{"altair"}},
+		{150, 250, []string{"altair", "bellatrix"}},
+		{300, 400, []string{"bellatrix"}},
+	}
+	for _, tt := range tests {
+		forks := forksOfEpochs(apiSpec, tt.firstEpoch, tt.lastEpoch)
+		if len(forks) != len(tt.forks) {
+			t.Errorf("wrong forks of epochs %v-%v: %v != %v", tt.firstEpoch, tt.lastEpoch, forks, tt.forks)
+			continue
+		}
+		for i := range forks {
+			if forks[i] != tt.forks[i] {
+				t.Errorf("wrong forks of epochs %v-%v: %v != %v", tt.firstEpoch, tt.lastEpoch, forks, tt.forks)
+			}
+		}
+	}
+}
+
+func TestGetPlan(t *testing.T) {
+	var blockRequests int
+	bnServer, _ := newEthstoreMockServers(t, func(r *http.Request) {
+		// the client requests the genesis block when it connects
+		if strings.HasPrefix(r.URL.Path, "/eth/v2/beacon/blocks/") && r.URL.Path != "/eth/v2/beacon/blocks/0" {
+			blockRequests++
+		}
+	})
+	defer bnServer.Close()
+	elServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.Id) + `,"result":"0x1"}`))
+	}))
+	defer elServer.Close()
+
+	p, err := GetPlan(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockRequests != 0 {
+		t.Errorf("blocks fetched for the plan: %v", blockRequests)
+	}
+	if p.Day != 10 || p.FirstSlot != 72000 || p.EndSlot != 79200 || p.FirstEpoch != 2250 || p.LastEpoch != 2474 {
+		t.Errorf("wrong window: %+v", p)
+	}
+	if p.ConsNotReady != "" || p.ExecChainId != "0x1" || p.ExecError != "" {
+		t.Errorf("wrong node checks: consNotReady: %v, execChainId: %v, execError: %v", p.ConsNotReady, p.ExecChainId, p.ExecError)
+	}
+	if len(p.Forks) != 1 || p.Forks[0] != "phase0" || len(p.UnsupportedForks) != 0 {
+		t.Errorf("wrong forks: %v, unsupported: %v", p.Forks, p.UnsupportedForks)
+	}
+	// validator-sets and state roots, blocks and the finality at the start and end of the day, no execution blocks
+	// before bellatrix
+	if p.ConsRequests != 4+7200+2 || p.ExecRequests != 0 {
+		t.Errorf("wrong number of requests: cons: %v, exec: %v", p.ConsRequests, p.ExecRequests)
+	}
+
+	// the per-epoch leak detection requests the finality of every epoch
+	SetPerEpochLeakDetection(true)
+	p, err = GetPlan(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	SetPerEpochLeakDetection(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ConsRequests != 4+7200+225 {
+		t.Errorf("wrong number of requests with per-epoch leak detection: %v", p.ConsRequests)
+	}
+	if len(p.States) != 226 || p.States[0].Slot != 72000 || p.States[225].Slot != 79200 {
+		t.Errorf("wrong states: %v", len(p.States))
+	}
+
+	// only the states at the start and end of the day are requested with two states
+	SetTwoStates(true)
+	defer SetTwoStates(false)
+	p, err = GetPlan(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.States) != 2 || p.States[0].Slot != 72000 || p.States[1].Slot != 79200 || p.States[1].Epoch != 2475 || !p.States[0].Time.Equal(time.Unix(1606824023+72000*12, 0)) {
+		t.Errorf("wrong states with two states: %+v", p.States)
+	}
+	if p.ConsRequests != 4+7200+2 {
+		t.Errorf("wrong number of requests with two states: %v", p.ConsRequests)
+	}
+
+	if _, err := GetPlan(context.Background(), bnServer.URL, elServer.URL, "1000", 10); err == nil {
+		t.Errorf("expected error for future day")
+	}
+}
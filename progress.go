@@ -0,0 +1,197 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/shopspring/decimal"
+)
+
+var progressSinks []ProgressSink
+var progressSinksMu = sync.Mutex{}
+
+// DayProgress is an intermediate update of the calculation of a day, it is sent in the order of the epochs after all
+// blocks of an epoch have been processed and holds the sums of the processed epochs. The consensus rewards are only
+// known at the end of the day, the last update holds the Result.
+type DayProgress struct {
+	Day             uint64          `json:"day"`
+	Epoch           uint64          `json:"epoch"`
+	EpochsProcessed uint64          `json:"epochsProcessed"`
+	Epochs          uint64          `json:"epochs"`
+	Proposals       uint64          `json:"proposals"`
+	MissedSlots     uint64          `json:"missedSlots"`
+	TxFeesSumWei    decimal.Decimal `json:"txFeesSumWei"`
+	DepositsSumGwei decimal.Decimal `json:"depositsSumGwei"`
+	Result          *Day            `json:"result,omitempty"`
+}
+
+// ProgressSink receives the intermediate updates of the calculation of a day, e.g. for a dashboard.
+type ProgressSink interface {
+	Progress(ctx context.Context, p DayProgress) error
+}
+
+// SetProgressSinks sets the sinks that receive the progress of the calculation of days, no sinks disable the updates.
+func SetProgressSinks(sinks ...ProgressSink) {
+	progressSinksMu.Lock()
+	defer progressSinksMu.Unlock()
+	progressSinks = sinks
+}
+
+func GetProgressSinks() []ProgressSink {
+	progressSinksMu.Lock()
+	defer progressSinksMu.Unlock()
+	return progressSinks
+}
+
+// WebhookProgressSink posts the progress as json to an url.
+type WebhookProgressSink struct {
+	URL string
+}
+
+func (s WebhookProgressSink) Progress(ctx context.Context, p DayProgress) error {
+	if err := postJson(ctx, s.URL, p); err != nil {
+		return fmt.Errorf("error posting progress to webhook: %w", err)
+	}
+	return nil
+}
+
+// progressReporter collects the sums of the blocks of every epoch and sends them to the sinks once all blocks of an
+// epoch have been processed, a nil reporter ignores all calls.
+type progressReporter struct {
+	ctx      context.Context
+	sinks    []ProgressSink
+	mu       sync.Mutex
+	progress DayProgress
+	// epochs holds the sums of the epochs that have not been sent yet
+	epochs    map[uint64]*DayProgress
+	done      map[uint64]bool
+	nextEpoch uint64
+	pending   sync.WaitGroup
+}
+
+// newProgressReporter returns a reporter for the epochs [firstEpoch, endEpoch) of the day or nil if there are no sinks.
+func newProgressReporter(ctx context.Context, sinks []ProgressSink, day, firstEpoch, endEpoch uint64) *progressReporter {
+	if len(sinks) == 0 {
+		return nil
+	}
+	return &progressReporter{
+		ctx:       ctx,
+		sinks:     sinks,
+		progress:  DayProgress{Day: day, Epochs: endEpoch - firstEpoch},
+		epochs:    map[uint64]*DayProgress{},
+		done:      map[uint64]bool{},
+		nextEpoch: firstEpoch,
+	}
+}
+
+func (r *progressReporter) epoch(epoch uint64) *DayProgress {
+	p, exists := r.epochs[epoch]
+	if !exists {
+		p = &DayProgress{}
+		r.epochs[epoch] = p
+	}
+	return p
+}
+
+// addCheckpoint adds the sums of the slots processed before a checkpoint to the progress.
+func (r *progressReporter) addCheckpoint(c *Checkpoint) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress.MissedSlots += c.MissedSlots
+	r.progress.Proposals += uint64(len(c.ProposalTxFeesWei))
+	for _, fee := range c.ProposalTxFeesWei {
+		r.progress.TxFeesSumWei = r.progress.TxFeesSumWei.Add(fee)
+	}
+	for epoch, amount := range c.EpochDepositsGwei {
+		p := r.epoch(epoch)
+		p.DepositsSumGwei = p.DepositsSumGwei.Add(decimal.NewFromInt(int64(amount)))
+	}
+}
+
+func (r *progressReporter) addMissedSlot(epoch uint64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.epoch(epoch).MissedSlots++
+}
+
+func (r *progressReporter) addProposal(epoch uint64, txFeesWei *big.Int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.epoch(epoch)
+	p.Proposals++
+	p.TxFeesSumWei = p.TxFeesSumWei.Add(decimal.NewFromBigInt(txFeesWei, 0))
+}
+
+func (r *progressReporter) addDeposit(epoch uint64, amount phase0.Gwei) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.epoch(epoch)
+	p.DepositsSumGwei = p.DepositsSumGwei.Add(decimal.NewFromInt(int64(amount)))
+}
+
+// endEpoch sends the progress once the blocks of the epoch that are tracked by wg have been processed.
+func (r *progressReporter) endEpoch(epoch uint64, wg *sync.WaitGroup) {
+	if r == nil {
+		return
+	}
+	r.pending.Add(1)
+	go func() {
+		defer r.pending.Done()
+		wg.Wait()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.done[epoch] = true
+		// epochs can finish out of order, they are sent in order
+		for r.done[r.nextEpoch] {
+			if p, exists := r.epochs[r.nextEpoch]; exists {
+				r.progress.Proposals += p.Proposals
+				r.progress.MissedSlots += p.MissedSlots
+				r.progress.TxFeesSumWei = r.progress.TxFeesSumWei.Add(p.TxFeesSumWei)
+				r.progress.DepositsSumGwei = r.progress.DepositsSumGwei.Add(p.DepositsSumGwei)
+				delete(r.epochs, r.nextEpoch)
+			}
+			delete(r.done, r.nextEpoch)
+			r.progress.Epoch = r.nextEpoch
+			r.progress.EpochsProcessed++
+			r.send(r.progress)
+			r.nextEpoch++
+		}
+	}()
+}
+
+// finish sends the result of the day after the progress of all epochs has been sent.
+func (r *progressReporter) finish(d *Day) {
+	if r == nil {
+		return
+	}
+	r.pending.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.progress
+	p.Result = d
+	r.send(p)
+}
+
+func (r *progressReporter) send(p DayProgress) {
+	for _, s := range r.sinks {
+		if err := s.Progress(r.ctx, p); err != nil {
+			log.Printf("error sending progress of day %v: %v", p.Day, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+type recordingProgressSink struct {
+	mu       sync.Mutex
+	received []DayProgress
+}
+
+func (s *recordingProgressSink) Progress(ctx context.Context, p DayProgress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = append(s.received, p)
+	return nil
+}
+
+func TestProgressOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	sink := &recordingProgressSink{}
+	SetProgressSinks(sink)
+	defer SetProgressSinks()
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// one update per epoch and the result
+	if len(sink.received) != 226 {
+		t.Fatalf("wrong number of updates: %v", len(sink.received))
+	}
+	for i, p := range sink.received[:225] {
+		if p.Day != 10 || p.Epochs != 225 || p.Epoch != 2250+uint64(i) || p.EpochsProcessed != uint64(i)+1 || p.Result != nil {
+			t.Fatalf("wrong update %v: %+v", i, p)
+		}
+		if i > 0 && p.TxFeesSumWei.LessThan(sink.received[i-1].TxFeesSumWei) {
+			t.Errorf("tx fees of update %v decreased", i)
+		}
+	}
+	last := sink.received[225]
+	if last.Result != day {
+		t.Fatalf("last update does not hold the result: %+v", last)
+	}
+	if last.EpochsProcessed != last.Epochs {
+		t.Errorf("not all epochs processed: %v of %v", last.EpochsProcessed, last.Epochs)
+	}
+	if !last.TxFeesSumWei.Equal(day.TxFeesSumWei) || !last.DepositsSumGwei.Equal(day.DepositsSumGwei) {
+		t.Errorf("wrong sums: %v != %v, %v != %v", last.TxFeesSumWei, day.TxFeesSumWei, last.DepositsSumGwei, day.DepositsSumGwei)
+	}
+	if !decimal.NewFromInt(int64(last.Proposals)).Equal(day.ProposalsActual) || !decimal.NewFromInt(int64(last.MissedSlots)).Equal(day.MissedSlots) {
+		t.Errorf("wrong proposals or missed slots: %+v", last)
+	}
+}
+
+func TestWebhookProgressSink(t *testing.T) {
+	received := []DayProgress{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var p DayProgress
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("invalid progress: %s", body)
+		}
+		received = append(received, p)
+		if p.Epoch == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	s := WebhookProgressSink{URL: server.URL}
+	if err := s.Progress(context.Background(), DayProgress{Day: 10, Epoch: 0, Result: &Day{Day: decimal.NewFromInt(10)}}); err != nil {
+		t.Error(err)
+	}
+	if err := s.Progress(context.Background(), DayProgress{Day: 10, Epoch: 1}); err == nil {
+		t.Errorf("expected error for status 500")
+	}
+	if len(received) != 2 || received[0].Result == nil || !received[0].Result.Day.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("wrong progress received: %+v", received)
+	}
+}
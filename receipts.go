@@ -0,0 +1,50 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gethRPC "github.com/ethereum/go-ethereum/rpc"
+)
+
+// ReceiptsMode is the way the receipts of the transactions of a block are requested from the execution node.
+type ReceiptsMode string
+
+const (
+	// ReceiptsModeTransaction requests the receipt of every transaction with eth_getTransactionReceipt in one batch.
+	ReceiptsModeTransaction ReceiptsMode = "transaction"
+	// ReceiptsModeBlock requests the receipts of the block with eth_getBlockReceipts, which not every execution node
+	// supports.
+	ReceiptsModeBlock ReceiptsMode = "block"
+)
+
+// ParseReceiptsMode parses the receipts mode "transaction" or "block".
+func ParseReceiptsMode(s string) (ReceiptsMode, error) {
+	switch m := ReceiptsMode(strings.ToLower(s)); m {
+	case ReceiptsModeTransaction, ReceiptsModeBlock:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown receipts mode %q, expected \"transaction\" or \"block\"", s)
+	}
+}
+
+// requestReceipts requests the receipts of the transactions of the block in the order of its transactions.
+func requestReceipts(ctx context.Context, elClient *gethRPC.Client, mode ReceiptsMode, exec *executionBlock) ([]*TxReceipt, error) {
+	if mode != ReceiptsModeBlock {
+		return batchRequestReceipts(ctx, elClient, exec.TxHashes)
+	}
+	var receipts []*TxReceipt
+	if err := elClient.CallContext(ctx, &receipts, "eth_getBlockReceipts", exec.BlockHash.Hex()); err != nil {
+		return nil, fmt.Errorf("error when fetching block receipts: %w", err)
+	}
+	if len(receipts) != len(exec.TxHashes) {
+		return nil, fmt.Errorf("got %v block receipts for %v transactions", len(receipts), len(exec.TxHashes))
+	}
+	for i, r := range receipts {
+		if r == nil || r.TransactionHash == nil || *r.TransactionHash != exec.TxHashes[i] {
+			return nil, fmt.Errorf("block receipt %v does not belong to transaction %v", i, exec.TxHashes[i])
+		}
+	}
+	return receipts, nil
+}
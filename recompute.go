@@ -0,0 +1,60 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrInputsChanged is returned by Recompute if the state roots of a recomputed day differ from the recorded ones.
+var ErrInputsChanged = errors.New("state roots differ from the recorded ones")
+
+// Recompute recalculates the stored days with the given methodology version, at most parallel days at a time, and
+// returns the recomputed days in ascending order. The recorded state roots of a stored day pin the inputs of its
+// recomputation: a recomputed day whose state roots differ fails with ErrInputsChanged, so the results of both
+// methodology versions are based on the same states. Stored days without recorded state roots are not checked. The
+// options configure the recomputations like those of CalculateWithOptions.
+func Recompute(ctx context.Context, bnAddress, elAddress string, stored []*Day, methodology, parallel, concurrency int, options ...Option) ([]*Day, error) {
+	if !supportedMethodology(methodology) {
+		return nil, fmt.Errorf("unsupported methodology version %v", methodology)
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+	o := &calculateOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	recomputed := make([]*Day, 0, len(stored))
+	recomputedMu := sync.Mutex{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(parallel)
+	for _, s := range stored {
+		s := s
+		g.Go(func() error {
+			d, _, err := calculate(gCtx, bnAddress, elAddress, s.Day.String(), concurrency, nil, methodology, nil, o)
+			if err != nil {
+				return fmt.Errorf("error recomputing day %v with methodology version %v: %w", s.Day, methodology, err)
+			}
+			if s.StartStateRoot != "" && s.StartStateRoot != d.StartStateRoot {
+				return fmt.Errorf("%w: day %v: start state root %v, recorded %v", ErrInputsChanged, s.Day, d.StartStateRoot, s.StartStateRoot)
+			}
+			if s.EndStateRoot != "" && s.EndStateRoot != d.EndStateRoot {
+				return fmt.Errorf("%w: day %v: end state root %v, recorded %v", ErrInputsChanged, s.Day, d.EndStateRoot, s.EndStateRoot)
+			}
+			recomputedMu.Lock()
+			recomputed = append(recomputed, d)
+			recomputedMu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	sort.Slice(recomputed, func(i, j int) bool { return recomputed[i].Day.LessThan(recomputed[j].Day) })
+	return recomputed, nil
+}
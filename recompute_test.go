@@ -0,0 +1,35 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecompute(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recomputed, err := Recompute(context.Background(), bnServer.URL, elServer.URL, []*Day{day}, MethodologyVersion, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recomputed) != 1 || !recomputed[0].Apr.Equal(day.Apr) || recomputed[0].InputHash != day.InputHash {
+		t.Errorf("wrong recomputed days: %+v", recomputed)
+	}
+
+	changed := *day
+	changed.EndStateRoot = "0x0000000000000000000000000000000000000000000000000000000000000001"
+	if _, err := Recompute(context.Background(), bnServer.URL, elServer.URL, []*Day{&changed}, MethodologyVersion, 2, 10); !errors.Is(err, ErrInputsChanged) {
+		t.Errorf("expected ErrInputsChanged, got: %v", err)
+	}
+	if _, err := Recompute(context.Background(), bnServer.URL, elServer.URL, []*Day{day}, MethodologyVersion+1, 2, 10); err == nil {
+		t.Errorf("expected error for unsupported methodology version")
+	}
+}
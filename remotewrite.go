@@ -0,0 +1,163 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// RemoteWriter pushes metrics to a Prometheus remote-write endpoint like the ones of Grafana Cloud or Mimir, so the
+// series end up in a hosted stack without a local Prometheus that scrapes the exporter. Username and Password are sent
+// as basic auth and Tenant as X-Scope-OrgID if they are set, Labels are added to every series.
+type RemoteWriter struct {
+	URL      string
+	Username string
+	Password string
+	Tenant   string
+	Labels   map[string]string
+}
+
+// remoteWriteSeries is a time series of a remote-write request with a single sample.
+type remoteWriteSeries struct {
+	labels    [][2]string
+	value     float64
+	timestamp int64
+}
+
+// Push sends the gauges, counters and untyped metrics of the gatherer as samples at the given time, histograms and
+// summaries are left out. The samples of a day are pushed at the time of the push rather than at the time of the day,
+// as remote-write endpoints reject samples that are too old.
+func (w *RemoteWriter) Push(ctx context.Context, g prometheus.Gatherer, t time.Time) error {
+	families, err := g.Gather()
+	if err != nil {
+		return fmt.Errorf("error gathering metrics: %w", err)
+	}
+	series := []remoteWriteSeries{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			var value float64
+			switch f.GetType() {
+			case dto.MetricType_GAUGE:
+				value = m.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				value = m.GetCounter().GetValue()
+			case dto.MetricType_UNTYPED:
+				value = m.GetUntyped().GetValue()
+			default:
+				continue
+			}
+			labels := map[string]string{}
+			for name, value := range w.Labels {
+				labels[name] = value
+			}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			labels["__name__"] = f.GetName()
+			s := remoteWriteSeries{value: value, timestamp: t.UnixMilli()}
+			for name, value := range labels {
+				s.labels = append(s.labels, [2]string{name, value})
+			}
+			// remote-write requires the labels of a series sorted by name
+			sort.Slice(s.labels, func(i, j int) bool {
+				return s.labels[i][0] < s.labels[j][0]
+			})
+			series = append(series, s)
+		}
+	}
+	if len(series) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, GetConsTimeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(snappyEncode(encodeWriteRequest(series))))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.Username != "" || w.Password != "" {
+		req.SetBasicAuth(w.Username, w.Password)
+	}
+	if w.Tenant != "" {
+		req.Header.Set("X-Scope-OrgID", w.Tenant)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error pushing metrics: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("error pushing metrics: status %v: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// encodeWriteRequest encodes the series as prometheus.WriteRequest protobuf message:
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(series []remoteWriteSeries) []byte {
+	var req []byte
+	for _, s := range series {
+		var ts []byte
+		for _, l := range s.labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, l[0])
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, l[1])
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(s.timestamp))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	return req
+}
+
+// snappyEncode encodes data in the snappy block format that remote-write requires. The data is stored as literals
+// without compression, which every snappy decoder accepts and which is good enough for a few dozen series.
+func snappyEncode(data []byte) []byte {
+	out := protowire.AppendVarint(nil, uint64(len(data)))
+	for len(data) > 0 {
+		n := len(data)
+		if n > 1<<16 {
+			n = 1 << 16
+		}
+		// the tag of a literal holds its length minus one, lengths above 60 follow the tag in 1 or 2 bytes
+		switch l := n - 1; {
+		case l < 60:
+			out = append(out, byte(l)<<2)
+		case l < 1<<8:
+			out = append(out, 60<<2, byte(l))
+		default:
+			out = append(out, 61<<2, byte(l), byte(l>>8))
+		}
+		out = append(out, data[:n]...)
+		data = data[n:]
+	}
+	return out
+}
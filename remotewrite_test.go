@@ -0,0 +1,165 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// snappyDecodeLiterals decodes snappy blocks that only consist of literals like the ones of snappyEncode.
+func snappyDecodeLiterals(data []byte) ([]byte, error) {
+	length, n := protowire.ConsumeVarint(data)
+	if n < 0 {
+		return nil, fmt.Errorf("invalid length")
+	}
+	data = data[n:]
+	var out []byte
+	for len(data) > 0 {
+		tag := data[0]
+		if tag&3 != 0 {
+			return nil, fmt.Errorf("unexpected copy element")
+		}
+		l, skip := int(tag>>2), 1
+		switch l {
+		case 60:
+			l, skip = int(data[1]), 2
+		case 61:
+			l, skip = int(data[1])|int(data[2])<<8, 3
+		}
+		out = append(out, data[skip:skip+l+1]...)
+		data = data[skip+l+1:]
+	}
+	if uint64(len(out)) != length {
+		return nil, fmt.Errorf("wrong length: %v != %v", len(out), length)
+	}
+	return out, nil
+}
+
+// decodeWriteRequest returns the series of a WriteRequest as "name{label=value,...}" keyed strings.
+func decodeWriteRequest(t *testing.T, req []byte) (map[string]float64, int64) {
+	values := map[string]float64{}
+	var timestamp int64
+	fields := func(b []byte, f func(num protowire.Number, typ protowire.Type, b []byte) int) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			if n < 0 {
+				t.Fatalf("invalid tag")
+			}
+			b = b[n:]
+			n = f(num, typ, b)
+			if n < 0 {
+				t.Fatalf("invalid field %v", num)
+			}
+			b = b[n:]
+		}
+	}
+	fields(req, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		ts, n := protowire.ConsumeBytes(b)
+		var name string
+		var labels []string
+		var value float64
+		fields(ts, func(num protowire.Number, typ protowire.Type, b []byte) int {
+			msg, n := protowire.ConsumeBytes(b)
+			var key, val string
+			fields(msg, func(num protowire.Number, typ protowire.Type, b []byte) int {
+				switch {
+				case typ == protowire.BytesType:
+					s, n := protowire.ConsumeString(b)
+					if num == 1 {
+						key = s
+					} else {
+						val = s
+					}
+					return n
+				case typ == protowire.Fixed64Type:
+					v, n := protowire.ConsumeFixed64(b)
+					value = math.Float64frombits(v)
+					return n
+				default:
+					v, n := protowire.ConsumeVarint(b)
+					timestamp = int64(v)
+					return n
+				}
+			})
+			if num == 1 && key == "__name__" {
+				name = val
+			} else if num == 1 {
+				labels = append(labels, key+"="+val)
+			}
+			return n
+		})
+		values[name+"{"+strings.Join(labels, ",")+"}"] = value
+		return n
+	})
+	return values, timestamp
+}
+
+func TestRemoteWriter(t *testing.T) {
+	var values map[string]float64
+	var timestamp int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Content-Type") != "application/x-protobuf" || r.Header.Get("X-Scope-OrgID") != "tenant" {
+			t.Errorf("wrong headers: %v", r.Header)
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "password" {
+			t.Errorf("wrong basic auth: %v %v", user, password)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		req, err := snappyDecodeLiterals(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values, timestamp = decodeWriteRequest(t, req)
+	}))
+	defer server.Close()
+
+	e := NewExporter([]uint64{1})
+	e.Update(&Day{Day: decimal.NewFromInt(10), Apr: decimal.RequireFromString("0.04"), Validators: decimal.NewFromInt(3)}, map[uint64]*Day{1: {Apr: decimal.RequireFromString("0.05")}})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+	w := &RemoteWriter{URL: server.URL, Username: "user", Password: "password", Tenant: "tenant", Labels: map[string]string{"network": "mainnet"}}
+	now := time.Unix(1700000000, 0)
+	if err := w.Push(context.Background(), registry, now); err != nil {
+		t.Fatal(err)
+	}
+	if timestamp != now.UnixMilli() {
+		t.Errorf("wrong timestamp: %v", timestamp)
+	}
+	for name, expected := range map[string]float64{
+		"ethstore_day{network=mainnet}":                                  10,
+		"ethstore_apr{network=mainnet,scope=network}":                    0.04,
+		"ethstore_validators{network=mainnet,scope=network}":             3,
+		"ethstore_validator_apr{network=mainnet,validator=1}":            0.05,
+		"ethstore_validators{network=mainnet,scope=subset}":              1,
+		"ethstore_apr{network=mainnet,scope=subset}":                     0,
+		"ethstore_execution_reward_share{network=mainnet,scope=network}": 0,
+	} {
+		if v, exists := values[name]; !exists || v != expected {
+			t.Errorf("wrong value of %v: %v (exists: %v)", name, v, exists)
+		}
+	}
+
+	// the literals of large requests are split
+	data := []byte(strings.Repeat("x", 1<<17+100))
+	decoded, err := snappyDecodeLiterals(snappyEncode(data))
+	if err != nil || string(decoded) != string(data) {
+		t.Errorf("wrong snappy round trip: %v", err)
+	}
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	w.URL = notFound.URL
+	if err := w.Push(context.Background(), registry, now); err == nil {
+		t.Errorf("expected error for status 404")
+	}
+}
@@ -0,0 +1,279 @@
+package ethstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+var cacheDir string
+var cacheDirMu = sync.Mutex{}
+
+// SetCacheDir sets the directory that the blocks and validator snapshots of finalized slots are cached in, so that
+// calculating a day again does not request them from the consensus node again. The responses of each network are
+// cached in a directory of their own that is cleared if the fork schedule of the node changes. An empty path disables
+// the cache.
+//
+// Deprecated: use WithCacheDir.
+func SetCacheDir(path string) {
+	cacheDirMu.Lock()
+	defer cacheDirMu.Unlock()
+	cacheDir = path
+}
+
+func GetCacheDir() string {
+	cacheDirMu.Lock()
+	defer cacheDirMu.Unlock()
+	return cacheDir
+}
+
+// cachedResponsePath matches the paths of the responses that only depend on the slot.
+var cachedResponsePath = regexp.MustCompile(`^/eth/v[12]/beacon/(blocks|blinded_blocks|blob_sidecars)/(\d+)$`)
+
+// responseCache stores the responses of a consensus node for slots up to the finalized slot on disk.
+type responseCache struct {
+	dir           string
+	finalizedSlot uint64
+}
+
+// responseCaches holds the caches of the consensus nodes, keyed by address.
+var responseCaches = map[string]*responseCache{}
+var responseCachesMu = &sync.Mutex{}
+
+func getResponseCache(address string) *responseCache {
+	responseCachesMu.Lock()
+	defer responseCachesMu.Unlock()
+	return responseCaches[address]
+}
+
+// openResponseCache opens the cache of the network of the given spec snapshot in dir for the consensus node at the
+// given address, a cache that has been filled with a different fork schedule is cleared. An empty dir closes the cache
+// of the address.
+func openResponseCache(dir, address string, snapshot *SpecSnapshot, finalizedSlot uint64) error {
+	if dir == "" {
+		responseCachesMu.Lock()
+		defer responseCachesMu.Unlock()
+		delete(responseCaches, address)
+		return nil
+	}
+	networkDir := filepath.Join(dir, fmt.Sprintf("%s-%d", snapshot.Values["GENESIS_FORK_VERSION"], snapshot.GenesisTime))
+	forks := map[string]string{}
+	for key, value := range snapshot.Values {
+		if strings.HasSuffix(key, "_FORK_VERSION") || strings.HasSuffix(key, "_FORK_EPOCH") {
+			forks[key] = value
+		}
+	}
+	forksFile := filepath.Join(networkDir, "forks.json")
+	data, err := ioutil.ReadFile(forksFile)
+	if err == nil {
+		var cachedForks map[string]string
+		if err := json.Unmarshal(data, &cachedForks); err != nil || !reflect.DeepEqual(cachedForks, forks) {
+			log.Printf("fork schedule of the cache in %v does not match the consensus node, clearing it", networkDir)
+			if err := os.RemoveAll(networkDir); err != nil {
+				return fmt.Errorf("error clearing cache: %w", err)
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading fork schedule of cache: %w", err)
+	}
+	for _, sub := range []string{"responses", "validators"} {
+		if err := os.MkdirAll(filepath.Join(networkDir, sub), 0755); err != nil {
+			return fmt.Errorf("error creating cache: %w", err)
+		}
+	}
+	data, err = json.Marshal(forks)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(forksFile, data); err != nil {
+		return fmt.Errorf("error writing fork schedule of cache: %w", err)
+	}
+	responseCachesMu.Lock()
+	defer responseCachesMu.Unlock()
+	responseCaches[address] = &responseCache{dir: networkDir, finalizedSlot: finalizedSlot}
+	return nil
+}
+
+// finalized returns if the slot, given as a number, is not after the finalized slot of the cache.
+func (c *responseCache) finalized(slot string) bool {
+	s, err := strconv.ParseUint(slot, 10, 64)
+	return err == nil && s <= c.finalizedSlot
+}
+
+// responseFile returns the file of the response of the given media type to the request of the path, or an empty string
+// if the response depends on more than a finalized slot.
+func (c *responseCache) responseFile(path, mediaType string) string {
+	m := cachedResponsePath.FindStringSubmatch(path)
+	if m == nil || !c.finalized(m[2]) {
+		return ""
+	}
+	return filepath.Join(c.dir, "responses", fmt.Sprintf("%x", sha256.Sum256([]byte(path+"\n"+mediaType))))
+}
+
+// cachedResponse holds the headers of a response that the response is decoded with.
+type cachedResponse struct {
+	ContentType      string `json:"contentType"`
+	ConsensusVersion string `json:"consensusVersion,omitempty"`
+}
+
+// response returns the cached header and body of a response to the request of the path with the given Accept header,
+// the media types of the Accept header are tried in their order. A nil cache has none.
+func (c *responseCache) response(path, accept string) (http.Header, []byte, bool) {
+	if c == nil {
+		return nil, nil, false
+	}
+	for _, a := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(a)
+		if err != nil {
+			continue
+		}
+		file := c.responseFile(path, mediaType)
+		if file == "" {
+			return nil, nil, false
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		i := bytes.IndexByte(data, '\n')
+		var r cachedResponse
+		if i < 0 || json.Unmarshal(data[:i], &r) != nil {
+			continue
+		}
+		header := http.Header{}
+		header.Set("Content-Type", r.ContentType)
+		if r.ConsensusVersion != "" {
+			header.Set("Eth-Consensus-Version", r.ConsensusVersion)
+		}
+		return header, data[i+1:], true
+	}
+	return nil, nil, false
+}
+
+// setResponse caches the response to the request of the path if it only depends on a finalized slot.
+func (c *responseCache) setResponse(path string, header http.Header, body []byte) {
+	if c == nil {
+		return
+	}
+	// responses that are not ssz are decoded as json
+	mediaType := contentTypeJSON
+	if t, _, _ := mime.ParseMediaType(header.Get("Content-Type")); t == contentTypeSSZ {
+		mediaType = contentTypeSSZ
+	}
+	file := c.responseFile(path, mediaType)
+	if file == "" {
+		return
+	}
+	data, err := json.Marshal(cachedResponse{ContentType: header.Get("Content-Type"), ConsensusVersion: header.Get("Eth-Consensus-Version")})
+	if err != nil {
+		return
+	}
+	err = writeFileAtomic(file, append(append(data, '\n'), body...))
+	if err != nil {
+		log.Printf("error caching response of %v: %v", path, err)
+	}
+}
+
+func (c *responseCache) validatorsFile(stateID string) string {
+	if !c.finalized(stateID) {
+		return ""
+	}
+	return filepath.Join(c.dir, "validators", stateID+".json.gz")
+}
+
+// validators returns the cached validators of the state at the given slot, a nil cache has none.
+func (c *responseCache) validators(stateID string) (map[phase0.ValidatorIndex]*v1.Validator, bool) {
+	if c == nil {
+		return nil, false
+	}
+	file := c.validatorsFile(stateID)
+	if file == "" {
+		return nil, false
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, false
+	}
+	var list []*v1.Validator
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		log.Printf("error reading cached validators of slot %v: %v", stateID, err)
+		return nil, false
+	}
+	vals := make(map[phase0.ValidatorIndex]*v1.Validator, len(list))
+	for _, v := range list {
+		vals[v.Index] = v
+	}
+	return vals, true
+}
+
+// setValidators caches the validators of the state if it is the state at a finalized slot.
+func (c *responseCache) setValidators(stateID string, vals map[phase0.ValidatorIndex]*v1.Validator) {
+	if c == nil {
+		return
+	}
+	file := c.validatorsFile(stateID)
+	if file == "" {
+		return
+	}
+	list := make([]*v1.Validator, 0, len(vals))
+	for _, v := range vals {
+		list = append(list, v)
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		log.Printf("error caching validators of slot %v: %v", stateID, err)
+		return
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		log.Printf("error caching validators of slot %v: %v", stateID, err)
+		return
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("error caching validators of slot %v: %v", stateID, err)
+		return
+	}
+	if err := writeFileAtomic(file, buf.Bytes()); err != nil {
+		log.Printf("error caching validators of slot %v: %v", stateID, err)
+	}
+}
+
+// writeFileAtomic writes the file via a temporary file, so that an interrupted write does not leave a partial file.
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
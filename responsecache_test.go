@@ -0,0 +1,127 @@
+package ethstore
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResponseCache(t *testing.T) {
+	var blockRequests, validatorsRequests int32
+	bnServer, elServer := newEthstoreMockServers(t, func(r *http.Request) {
+		// the client requests the genesis block when it connects
+		if strings.HasPrefix(r.URL.Path, "/eth/v2/beacon/blocks/") && r.URL.Path != "/eth/v2/beacon/blocks/0" {
+			atomic.AddInt32(&blockRequests, 1)
+		}
+		if strings.HasSuffix(r.URL.Path, "/validators") {
+			atomic.AddInt32(&validatorsRequests, 1)
+		}
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	dir := t.TempDir()
+	SetCacheDir(dir)
+	defer SetCacheDir("")
+	resetValidatorsCache := func() {
+		validatorsCacheMu.Lock()
+		defer validatorsCacheMu.Unlock()
+		validatorsCache = nil
+	}
+	resetValidatorsCache()
+
+	expected, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&blockRequests) == 0 || atomic.LoadInt32(&validatorsRequests) == 0 {
+		t.Fatalf("expected requests without cache, got %v block and %v validators requests", blockRequests, validatorsRequests)
+	}
+
+	// the blocks and validators of the second calculation are taken from the cache
+	resetValidatorsCache()
+	atomic.StoreInt32(&blockRequests, 0)
+	atomic.StoreInt32(&validatorsRequests, 0)
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, m := atomic.LoadInt32(&blockRequests), atomic.LoadInt32(&validatorsRequests); n != 0 || m != 0 {
+		t.Errorf("expected no requests with cache, got %v block and %v validators requests", n, m)
+	}
+	if !day.Apr.Equal(expected.Apr) || !day.TotalRewardsWei.Equal(expected.TotalRewardsWei) || !day.Validators.Equal(expected.Validators) {
+		t.Errorf("wrong day from cache: apr: %v != %v, totalRewardsWei: %v != %v, validators: %v != %v", day.Apr, expected.Apr, day.TotalRewardsWei, expected.TotalRewardsWei, day.Validators, expected.Validators)
+	}
+
+	// a different fork schedule clears the cache
+	networkDirs, err := filepath.Glob(filepath.Join(dir, "*", "forks.json"))
+	if err != nil || len(networkDirs) != 1 {
+		t.Fatalf("expected one network in cache: %v, %v", networkDirs, err)
+	}
+	networkDir := filepath.Dir(networkDirs[0])
+	if err := ioutil.WriteFile(networkDirs[0], []byte(`{"ALTAIR_FORK_EPOCH":"1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resetValidatorsCache()
+	if _, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&blockRequests); n == 0 {
+		t.Errorf("expected block requests after the cache was cleared")
+	}
+	if _, err := os.Stat(filepath.Join(networkDir, "validators")); err != nil {
+		t.Errorf("expected cache to be refilled: %v", err)
+	}
+
+	// slots after the finalized slot are not cached
+	cache := getResponseCache(bnServer.URL)
+	if cache == nil {
+		t.Fatal("no cache")
+	}
+	if cache.responseFile("/eth/v2/beacon/blocks/80000000", contentTypeJSON) != "" || cache.validatorsFile("head") != "" {
+		t.Errorf("expected slots that are not finalized not to be cached")
+	}
+
+	// disabling the cache closes it
+	SetCacheDir("")
+	if _, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10); err != nil {
+		t.Fatal(err)
+	}
+	if getResponseCache(bnServer.URL) != nil {
+		t.Errorf("expected cache to be closed")
+	}
+}
+
+func TestCachedResponseContentTypes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "responses"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cache := &responseCache{dir: dir, finalizedSlot: 10}
+	header := http.Header{}
+	header.Set("Content-Type", contentTypeSSZ)
+	header.Set("Eth-Consensus-Version", "bellatrix")
+	cache.setResponse("/eth/v2/beacon/blocks/10", header, []byte{1, 2, 3})
+	cache.setResponse("/eth/v2/beacon/blocks/11", header, []byte{1, 2, 3})
+
+	h, body, ok := cache.response("/eth/v2/beacon/blocks/10", acceptSSZOrJSON)
+	if !ok || h.Get("Content-Type") != contentTypeSSZ || h.Get("Eth-Consensus-Version") != "bellatrix" || string(body) != string([]byte{1, 2, 3}) {
+		t.Errorf("wrong cached response: %v, %v, %v", ok, h, body)
+	}
+	// json is requested separately, e.g. for the blob verification of a block
+	if _, _, ok := cache.response("/eth/v2/beacon/blocks/10", contentTypeJSON); ok {
+		t.Errorf("expected no json response")
+	}
+	if _, _, ok := cache.response("/eth/v2/beacon/blocks/11", acceptSSZOrJSON); ok {
+		t.Errorf("expected no response after the finalized slot")
+	}
+	var nilCache *responseCache
+	if _, _, ok := nilCache.response("/eth/v2/beacon/blocks/10", acceptSSZOrJSON); ok {
+		t.Errorf("expected no response of nil cache")
+	}
+}
@@ -0,0 +1,136 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// ErrResponseTooLarge is returned if the body of a response of the beacon api exceeds the maximum response size.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ErrDecodeTimeout is returned if decoding a response of the beacon api takes longer than the maximum decode time.
+var ErrDecodeTimeout = errors.New("decoding response timed out")
+
+var maxResponseSize int64
+var maxDecodeTime time.Duration
+var responseGuardMu = sync.Mutex{}
+
+// SetMaxResponseSize sets the maximum size of the body of a response of the beacon api in bytes, larger responses are
+// not read and fail with ErrResponseTooLarge. With a limit, the validators are always requested in chunks, a chunk
+// that exceeds the limit is requested in smaller chunks. A size of 0 disables the limit.
+func SetMaxResponseSize(size int64) {
+	responseGuardMu.Lock()
+	defer responseGuardMu.Unlock()
+	maxResponseSize = size
+}
+
+func GetMaxResponseSize() int64 {
+	responseGuardMu.Lock()
+	defer responseGuardMu.Unlock()
+	return maxResponseSize
+}
+
+// SetMaxDecodeTime sets the maximum duration of decoding a response of the beacon api, decoding that takes longer
+// fails with ErrDecodeTimeout. A duration of 0 disables the limit.
+func SetMaxDecodeTime(dur time.Duration) {
+	responseGuardMu.Lock()
+	defer responseGuardMu.Unlock()
+	maxDecodeTime = dur
+}
+
+func GetMaxDecodeTime() time.Duration {
+	responseGuardMu.Lock()
+	defer responseGuardMu.Unlock()
+	return maxDecodeTime
+}
+
+// readResponse reads the body of the response to the given path, it fails without reading the whole body if the
+// announced or the actual size of the body exceeds the maximum response size.
+func readResponse(path string, contentLength int64, body io.Reader) ([]byte, error) {
+	r, err := limitResponse(path, contentLength, body)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// limitResponse returns a reader of the body of the response to the given path that fails with ErrResponseTooLarge
+// once the body exceeds the maximum response size, a body whose announced size exceeds it is not read at all.
+func limitResponse(path string, contentLength int64, body io.Reader) (io.Reader, error) {
+	limit := GetMaxResponseSize()
+	if limit <= 0 {
+		return body, nil
+	}
+	if contentLength > limit {
+		return nil, fmt.Errorf("%w: response to %v has %v bytes, the limit is %v bytes", ErrResponseTooLarge, path, contentLength, limit)
+	}
+	return &limitedReader{r: body, path: path, limit: limit, remaining: limit}, nil
+}
+
+type limitedReader struct {
+	r         io.Reader
+	path      string
+	limit     int64
+	remaining int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return 0, fmt.Errorf("%w: response to %v exceeds the limit of %v bytes", ErrResponseTooLarge, r.path, r.limit)
+	}
+	return n, err
+}
+
+// decodeResponse runs decode with a check that fails with ErrDecodeTimeout once decoding takes longer than the maximum
+// decode time, or with the error of the context once it is done. decode calls the check while it decodes and stops
+// with its error, so a decode that takes too long does not keep running.
+func decodeResponse(ctx context.Context, path string, decode func(check func() error) error) error {
+	limit := GetMaxDecodeTime()
+	deadline := time.Now().Add(limit)
+	return decode(func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if limit > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("%w: decoding the response to %v took longer than %v", ErrDecodeTimeout, path, limit)
+		}
+		return nil
+	})
+}
+
+// checkedReadSize is the maximum number of bytes read from a checkedReader at once.
+const checkedReadSize = 64 << 10
+
+// checkedReader runs the check before every read and fails with its error.
+type checkedReader struct {
+	r     io.Reader
+	check func() error
+}
+
+func (r *checkedReader) Read(p []byte) (int, error) {
+	if err := r.check(); err != nil {
+		return 0, err
+	}
+	if len(p) > checkedReadSize {
+		p = p[:checkedReadSize]
+	}
+	return r.r.Read(p)
+}
+
+// unmarshalJSON decodes the json data into dst like json.Unmarshal but stops with the error of the check, which runs
+// while the data is read.
+func unmarshalJSON(data []byte, dst interface{}, check func() error) error {
+	return json.NewDecoder(&checkedReader{r: bytes.NewReader(data), check: check}).Decode(dst)
+}
@@ -0,0 +1,131 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// no content length is announced for flushed responses
+			w.Write([]byte(`{"data":"`))
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(fmt.Sprintf(`{"data":"%s"}`, strings.Repeat("a", 100))))
+	}))
+	defer server.Close()
+
+	SetMaxResponseSize(50)
+	defer SetMaxResponseSize(0)
+	for _, path := range []string{"/announced", "/chunked"} {
+		var res struct{}
+		if _, err := getBeaconJson(context.Background(), server.URL, path, &res); !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("expected too large response of %v, got %v", path, err)
+		}
+	}
+	SetMaxResponseSize(200)
+	var res struct {
+		Data string `json:"data"`
+	}
+	if _, err := getBeaconJson(context.Background(), server.URL, "/announced", &res); err != nil || len(res.Data) != 100 {
+		t.Errorf("unexpected response within limit: %v (%v)", res.Data, err)
+	}
+}
+
+func TestMaxDecodeTime(t *testing.T) {
+	SetMaxDecodeTime(10 * time.Millisecond)
+	defer SetMaxDecodeTime(0)
+	steps := 0
+	err := decodeResponse(context.Background(), "/slow", func(check func() error) error {
+		for ; steps < 100; steps++ {
+			if err := check(); err != nil {
+				return err
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrDecodeTimeout) {
+		t.Errorf("expected decode timeout, got %v", err)
+	}
+	if steps == 100 {
+		t.Errorf("decoding did not stop after the timeout")
+	}
+	decodeErr := errors.New("malformed")
+	if err := decodeResponse(context.Background(), "/fast", func(check func() error) error { return decodeErr }); err != decodeErr {
+		t.Errorf("wrong error of decoding: %v", err)
+	}
+
+	// a json decode reads in steps and stops with the error of the check
+	data := []byte(fmt.Sprintf(`{"data":"%s"}`, strings.Repeat("a", 10*checkedReadSize)))
+	reads := 0
+	var res struct {
+		Data string `json:"data"`
+	}
+	err = unmarshalJSON(data, &res, func() error {
+		if reads++; reads > 2 {
+			return ErrDecodeTimeout
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrDecodeTimeout) || reads != 3 {
+		t.Errorf("expected json decode to stop after 2 reads, got %v after %v checks", err, reads)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := decodeResponse(ctx, "/cancelled", func(check func() error) error { return check() }); err != context.Canceled {
+		t.Errorf("expected cancelled decode, got %v", err)
+	}
+}
+
+func TestMaxResponseSizeOfValidatorsStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := []string{}
+		for i := 0; i < 100; i++ {
+			data = append(data, fmt.Sprintf(`{"index":"%d","balance":"32000000000","status":"active_ongoing","validator":{"pubkey":"%#096x","withdrawal_credentials":"0x%064x","effective_balance":"32000000000","slashed":false,"activation_eligibility_epoch":"0","activation_epoch":"0","exit_epoch":"18446744073709551615","withdrawable_epoch":"18446744073709551615"}}`, i, 0, 0))
+		}
+		w.Write([]byte(`{"data":[`))
+		// no content length is announced for flushed responses
+		w.(http.Flusher).Flush()
+		w.Write([]byte(strings.Join(data, ",") + "]}"))
+	}))
+	defer server.Close()
+
+	SetMaxResponseSize(10000)
+	defer SetMaxResponseSize(0)
+	if _, _, err := requestValidatorsStreamEndpoint(context.Background(), server.URL, "100"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected too large streamed response, got %v", err)
+	}
+}
+
+func TestValidatorsChunkedWithinMaxResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := []string{}
+		for _, id := range strings.Split(r.URL.Query().Get("id"), ",") {
+			if len(id) < 3 {
+				data = append(data, fmt.Sprintf(`{"index":"%s","balance":"32000000000","status":"active_ongoing","validator":{"pubkey":"%#096x","withdrawal_credentials":"0x%064x","effective_balance":"32000000000","slashed":false,"activation_eligibility_epoch":"0","activation_epoch":"0","exit_epoch":"18446744073709551615","withdrawable_epoch":"18446744073709551615"}}`, id, 0, 0))
+			}
+		}
+		w.Write([]byte(fmt.Sprintf(`{"data":[%s]}`, strings.Join(data, ","))))
+	}))
+	defer server.Close()
+
+	// a validator takes about 450 bytes, so a response of more than 20 validators exceeds the limit
+	SetMaxResponseSize(10000)
+	defer SetMaxResponseSize(0)
+	vals, err := requestValidatorsChunked(context.Background(), server.URL, "100", 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vals) != 100 {
+		t.Errorf("wrong number of validators: %v != %v", len(vals), 100)
+	}
+}
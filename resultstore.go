@@ -0,0 +1,173 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ErrDayNotFound is returned by a ResultStore for a day that is not stored.
+var ErrDayNotFound = errors.New("day not found")
+
+// ResultStore stores the calculated days. Put stores a day and replaces an earlier calculation of it, Get returns a
+// single day, Range the days within [from, to] in ascending order and Latest the latest stored day. Get and Latest
+// return ErrDayNotFound if there is no such day. The serve command and the DaysAPI only use this interface, so the
+// days can be kept in any storage by implementing it.
+type ResultStore interface {
+	Put(ctx context.Context, d *Day) error
+	Get(ctx context.Context, day uint64) (*Day, error)
+	Range(ctx context.Context, from, to uint64) ([]*Day, error)
+	Latest(ctx context.Context) (*Day, error)
+}
+
+// MemoryStore is a ResultStore that keeps the days in memory.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	days map[uint64]*Day
+}
+
+// NewMemoryStore returns a memory store without any days.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{days: map[uint64]*Day{}}
+}
+
+func (s *MemoryStore) Put(ctx context.Context, d *Day) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.days[uint64(d.Day.IntPart())] = d
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, day uint64) (*Day, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, exists := s.days[day]
+	if !exists {
+		return nil, ErrDayNotFound
+	}
+	return d, nil
+}
+
+func (s *MemoryStore) Range(ctx context.Context, from, to uint64) ([]*Day, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	days := make([]*Day, 0, len(s.days))
+	for day, d := range s.days {
+		if day >= from && day <= to {
+			days = append(days, d)
+		}
+	}
+	sortDays(days)
+	return days, nil
+}
+
+func (s *MemoryStore) Latest(ctx context.Context) (*Day, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var latest *Day
+	for _, d := range s.days {
+		if latest == nil || d.Day.GreaterThan(latest.Day) {
+			latest = d
+		}
+	}
+	if latest == nil {
+		return nil, ErrDayNotFound
+	}
+	return latest, nil
+}
+
+// FileStore is a ResultStore that keeps the days in a json file in the format of json.file: a recalculated day is
+// added as a new revision and the earlier revisions are kept as superseded, Get, Range and Latest only return the
+// latest revisions.
+type FileStore struct {
+	mu   sync.RWMutex
+	path string
+	days []*Day
+}
+
+// NewFileStore returns a file store of the days in the given file, the file is created with the first stored day if
+// it does not exist.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, days: []*Day{}}
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %v: %w", path, err)
+	}
+	err = json.Unmarshal(data, &s.days)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %v: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Put(ctx context.Context, d *Day) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	days := AddRevision(s.days, d)
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Day.LessThan(days[j].Day)
+	})
+	data, err := json.MarshalIndent(&days, "", "\t")
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(s.path, data)
+	if err != nil {
+		return fmt.Errorf("error writing %v: %w", s.path, err)
+	}
+	s.days = days
+	return nil
+}
+
+func (s *FileStore) Get(ctx context.Context, day uint64) (*Day, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, d := range LatestDays(s.days) {
+		if uint64(d.Day.IntPart()) == day {
+			return d, nil
+		}
+	}
+	return nil, ErrDayNotFound
+}
+
+func (s *FileStore) Range(ctx context.Context, from, to uint64) ([]*Day, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	days := []*Day{}
+	for _, d := range LatestDays(s.days) {
+		if day := uint64(d.Day.IntPart()); day >= from && day <= to {
+			days = append(days, d)
+		}
+	}
+	sortDays(days)
+	return days, nil
+}
+
+func (s *FileStore) Latest(ctx context.Context) (*Day, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var latest *Day
+	for _, d := range LatestDays(s.days) {
+		if latest == nil || d.Day.GreaterThan(latest.Day) {
+			latest = d
+		}
+	}
+	if latest == nil {
+		return nil, ErrDayNotFound
+	}
+	return latest, nil
+}
+
+func sortDays(days []*Day) {
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Day.LessThan(days[j].Day)
+	})
+}
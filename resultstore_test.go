@@ -0,0 +1,61 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func testResultStore(t *testing.T, name string, store ResultStore) {
+	ctx := context.Background()
+	if _, err := store.Latest(ctx); !errors.Is(err, ErrDayNotFound) {
+		t.Errorf("%v: expected ErrDayNotFound for latest day without days: %v", name, err)
+	}
+	for _, d := range []*Day{{Day: decimal.NewFromInt(12), Apr: decimal.NewFromFloat(0.1)}, {Day: decimal.NewFromInt(10)}, {Day: decimal.NewFromInt(11)}, {Day: decimal.NewFromInt(12), Apr: decimal.NewFromFloat(0.2)}} {
+		if err := store.Put(ctx, d); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+	}
+	if _, err := store.Get(ctx, 9); !errors.Is(err, ErrDayNotFound) {
+		t.Errorf("%v: expected ErrDayNotFound for missing day: %v", name, err)
+	}
+	d, err := store.Get(ctx, 12)
+	if err != nil || !d.Apr.Equal(decimal.NewFromFloat(0.2)) {
+		t.Errorf("%v: expected the recalculated day: %+v, %v", name, d, err)
+	}
+	latest, err := store.Latest(ctx)
+	if err != nil || latest.Day.IntPart() != 12 || !latest.Apr.Equal(decimal.NewFromFloat(0.2)) {
+		t.Errorf("%v: wrong latest day: %+v, %v", name, latest, err)
+	}
+	days, err := store.Range(ctx, 11, 20)
+	if err != nil || len(days) != 2 || days[0].Day.IntPart() != 11 || days[1].Day.IntPart() != 12 {
+		t.Errorf("%v: wrong range: %v, %v", name, days, err)
+	}
+}
+
+func TestResultStore(t *testing.T) {
+	testResultStore(t, "memory", NewMemoryStore())
+
+	path := filepath.Join(t.TempDir(), "days.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testResultStore(t, "file", store)
+
+	// the days and their revisions are read back from the file
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.days) != 4 {
+		t.Errorf("wrong number of stored revisions: %v", len(store.days))
+	}
+	d, err := store.Get(context.Background(), 12)
+	if err != nil || d.Revision != 1 || d.Supersedes == nil || *d.Supersedes != 0 {
+		t.Errorf("wrong revision of the recalculated day: %+v, %v", d, err)
+	}
+}
@@ -0,0 +1,106 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures the retries of requests to the consensus node that failed with a transient error, the wait
+// before a retry grows exponentially and is jittered so that concurrent requests do not retry at the same time.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request, requests are not retried if it is 1 or less
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, it doubles with every further retry up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxElapsed is the time since the first attempt after which a request is not retried anymore (unlimited if 0)
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy unless SetRetryPolicy is called.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 30 * time.Second, MaxElapsed: 2 * time.Minute}
+
+var retryPolicy = DefaultRetryPolicy
+var retryPolicyMu = &sync.Mutex{}
+
+// SetRetryPolicy sets the retry policy of the requests of blocks, states and validators to the consensus node.
+func SetRetryPolicy(p RetryPolicy) {
+	retryPolicyMu.Lock()
+	defer retryPolicyMu.Unlock()
+	retryPolicy = p
+}
+
+func GetRetryPolicy() RetryPolicy {
+	retryPolicyMu.Lock()
+	defer retryPolicyMu.Unlock()
+	return retryPolicy
+}
+
+// backoff returns the jittered wait before the given retry (starting at 1), it is between half and all of the
+// exponential backoff.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryableResponse returns whether a request that failed with the given status or error is retried: connection
+// errors, timeouts, truncated responses and the statuses of overloaded nodes and proxies (429, 502, 503, 504). Other
+// statuses are an answer of the node, e.g. 404 for a missed slot or 500 for a response that is too large, and are
+// not retried.
+func retryableResponse(ctx context.Context, status int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ECONNRESET)
+	}
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// withRetries calls request until it does not fail with a retryable error (see retryableResponse) or the retry policy
+// is exhausted, the results of the last attempt are kept by request. what describes the request in the logs.
+func withRetries(ctx context.Context, what string, request func() (status int, err error)) {
+	p := GetRetryPolicy()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		status, err := request()
+		if attempt >= p.MaxAttempts || !retryableResponse(ctx, status, err) {
+			return
+		}
+		wait := p.backoff(attempt)
+		if p.MaxElapsed > 0 && time.Since(start)+wait > p.MaxElapsed {
+			return
+		}
+		if err == nil {
+			err = errors.New(http.StatusText(status))
+		}
+		log.Printf("error requesting %v from consensus node, retrying in %v (attempt %v of %v): %v", what, wait.Round(time.Millisecond), attempt, p.MaxAttempts, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
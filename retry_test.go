@@ -0,0 +1,114 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for retry, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		for i := 0; i < 10; i++ {
+			if d := p.backoff(retry); d < max/2 || d > max {
+				t.Errorf("wrong backoff of retry %v: %v", retry, d)
+			}
+		}
+	}
+	if d := (RetryPolicy{}).backoff(1); d != 0 {
+		t.Errorf("wrong backoff without initial backoff: %v", d)
+	}
+}
+
+func TestRetryableResponse(t *testing.T) {
+	ctx := context.Background()
+	for status, want := range map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusNotFound:            false,
+		http.StatusInternalServerError: false,
+		http.StatusTooManyRequests:     true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+	} {
+		if got := retryableResponse(ctx, status, nil); got != want {
+			t.Errorf("wrong retryable of status %v: %v", status, got)
+		}
+	}
+	if !retryableResponse(ctx, 0, &net.OpError{Op: "dial", Err: errors.New("connection refused")}) {
+		t.Errorf("connection error not retryable")
+	}
+	if retryableResponse(ctx, 0, errors.New("invalid request")) {
+		t.Errorf("unexpected retryable error")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if retryableResponse(canceled, http.StatusBadGateway, nil) {
+		t.Errorf("retryable after the context was canceled")
+	}
+}
+
+func TestRequestRetries(t *testing.T) {
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	defer SetRetryPolicy(DefaultRetryPolicy)
+
+	var requests int32
+	var failures int32
+	bnServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		switch {
+		case r.URL.Path == "/missed":
+			w.WriteHeader(http.StatusNotFound)
+		case n <= atomic.LoadInt32(&failures):
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"data":{}}`))
+		}
+	}))
+	defer bnServer.Close()
+
+	var dst struct{}
+	// the request succeeds on the last attempt
+	atomic.StoreInt32(&failures, 2)
+	if found, err := getBeaconJson(context.Background(), bnServer.URL, "/flaky", &dst); err != nil || !found {
+		t.Errorf("request not retried: %v %v", found, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("wrong number of attempts: %v", n)
+	}
+
+	// the policy is exhausted
+	atomic.StoreInt32(&requests, 0)
+	atomic.StoreInt32(&failures, 3)
+	if _, err := getBeaconJson(context.Background(), bnServer.URL, "/down", &dst); err == nil {
+		t.Errorf("expected error after the last attempt")
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("wrong number of attempts: %v", n)
+	}
+
+	// a missed slot is not retried
+	atomic.StoreInt32(&requests, 0)
+	if found, err := getBeaconJson(context.Background(), bnServer.URL, "/missed", &dst); err != nil || found {
+		t.Errorf("wrong result of missed slot: %v %v", found, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("missed slot requested %v times", n)
+	}
+
+	// no retry would end before the maximum elapsed time
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour, MaxElapsed: time.Minute})
+	atomic.StoreInt32(&requests, 0)
+	atomic.StoreInt32(&failures, 10)
+	if _, err := getBeaconJson(context.Background(), bnServer.URL, "/slow", &dst); err == nil {
+		t.Errorf("expected error after the maximum elapsed time")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("wrong number of attempts: %v", n)
+	}
+}
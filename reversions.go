@@ -0,0 +1,31 @@
+package ethstore
+
+import (
+	"context"
+)
+
+// StateRootsChanged reports whether the state roots at the start and at the end of a stored day differ from the ones
+// of the beacon node, e.g. because non-finalized data was used or the node was rolled back after the day was
+// published. Days without recorded state roots are reported as unchanged.
+func StateRootsChanged(ctx context.Context, bnAddress string, d *Day) (bool, error) {
+	if d.StartStateRoot == "" || d.EndStateRoot == "" {
+		return false, nil
+	}
+	startStateRoot, err := parseRoot(d.StartStateRoot)
+	if err != nil {
+		return false, err
+	}
+	endStateRoot, err := parseRoot(d.EndStateRoot)
+	if err != nil {
+		return false, err
+	}
+	boundary, err := dayBoundaryOf(d)
+	if err != nil {
+		return false, err
+	}
+	nodeStartStateRoot, nodeEndStateRoot, err := nodeStateRoots(ctx, bnAddress, uint64(d.Day.IntPart()), boundary)
+	if err != nil {
+		return false, err
+	}
+	return nodeStartStateRoot != startStateRoot || nodeEndStateRoot != endStateRoot, nil
+}
@@ -0,0 +1,41 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStateRootsChanged(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := StateRootsChanged(context.Background(), bnServer.URL, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Errorf("state roots of unchanged day reported as changed")
+	}
+
+	reverted := *day
+	reverted.EndStateRoot = "0x0000000000000000000000000000000000000000000000000000000000000001"
+	changed, err = StateRootsChanged(context.Background(), bnServer.URL, &reverted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("changed end state root not detected")
+	}
+
+	legacy := *day
+	legacy.StartStateRoot, legacy.EndStateRoot = "", ""
+	changed, err = StateRootsChanged(context.Background(), bnServer.URL, &legacy)
+	if err != nil || changed {
+		t.Errorf("day without state roots reported as changed: %v, %v", changed, err)
+	}
+}
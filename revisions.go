@@ -0,0 +1,30 @@
+package ethstore
+
+// AddRevision adds a recalculated day to the stored days. Earlier revisions of the day are kept but marked as
+// superseded, the new revision links to the revision it supersedes. The day is appended if it is not stored yet.
+func AddRevision(days []*Day, d *Day) []*Day {
+	var latest *Day
+	for _, stored := range days {
+		if stored.Day.Equal(d.Day) && !stored.Superseded {
+			latest = stored
+		}
+	}
+	if latest != nil {
+		latest.Superseded = true
+		supersedes := latest.Revision
+		d.Revision = latest.Revision + 1
+		d.Supersedes = &supersedes
+	}
+	return append(days, d)
+}
+
+// LatestDays returns the latest revisions of the stored days, superseded revisions are left out.
+func LatestDays(days []*Day) []*Day {
+	latest := make([]*Day, 0, len(days))
+	for _, d := range days {
+		if !d.Superseded {
+			latest = append(latest, d)
+		}
+	}
+	return latest
+}
@@ -0,0 +1,39 @@
+package ethstore
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAddRevision(t *testing.T) {
+	days := []*Day{
+		{Day: decimal.NewFromInt(10), Apr: decimal.RequireFromString("0.04")},
+		{Day: decimal.NewFromInt(11), Apr: decimal.RequireFromString("0.04")},
+	}
+	days = AddRevision(days, &Day{Day: decimal.NewFromInt(10), Apr: decimal.RequireFromString("0.05")})
+	days = AddRevision(days, &Day{Day: decimal.NewFromInt(10), Apr: decimal.RequireFromString("0.06")})
+	days = AddRevision(days, &Day{Day: decimal.NewFromInt(12), Apr: decimal.RequireFromString("0.04")})
+	if len(days) != 5 {
+		t.Fatalf("wrong number of stored days: %v != %v", len(days), 5)
+	}
+	if !days[0].Superseded || !days[2].Superseded || days[1].Superseded {
+		t.Errorf("wrong superseded revisions")
+	}
+	if days[3].Revision != 2 || days[3].Supersedes == nil || *days[3].Supersedes != 1 || days[3].Superseded {
+		t.Errorf("wrong latest revision: %+v", days[3])
+	}
+	if days[4].Revision != 0 || days[4].Supersedes != nil {
+		t.Errorf("wrong first revision: %+v", days[4])
+	}
+
+	latest := LatestDays(days)
+	if len(latest) != 3 {
+		t.Fatalf("wrong number of latest days: %v != %v", len(latest), 3)
+	}
+	for _, d := range latest {
+		if d.Day.Equal(decimal.NewFromInt(10)) && !d.Apr.Equal(decimal.RequireFromString("0.06")) {
+			t.Errorf("superseded revision of day 10 exposed: %v", d.Apr)
+		}
+	}
+}
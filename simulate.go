@@ -0,0 +1,117 @@
+package ethstore
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+var simulationPercentiles = []int{1, 5, 10, 25, 50, 75, 90, 95, 99}
+
+type Simulation struct {
+	Validators  int                  `json:"validators"`
+	Runs        int                  `json:"runs"`
+	Percentiles []SimulationQuantile `json:"percentiles"`
+}
+
+type SimulationQuantile struct {
+	Percentile int             `json:"percentile"`
+	Apr        decimal.Decimal `json:"apr"`
+}
+
+// Simulate runs a monte carlo simulation of the yearly apr of an operator running the given number of validators.
+// Every simulated day draws one of the given days, a poisson distributed number of proposals with the expected
+// proposal rate of that day and the tx fees of every proposal from the tx fees of that day's proposals.
+// Consensus rewards are assumed to be the average consensus rewards of the eth.store validators of that day.
+func Simulate(days []*Day, validators, runs int, seed int64) (*Simulation, error) {
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no days to simulate from")
+	}
+	if validators < 1 || runs < 1 {
+		return nil, fmt.Errorf("invalid simulation parameters: validators: %v, runs: %v", validators, runs)
+	}
+
+	type simulationDay struct {
+		proposalsExpected float64
+		consensusRewards  float64
+		txFees            []float64
+	}
+	simDays := make([]simulationDay, 0, len(days))
+	effectiveBalanceWei := 0.0
+	for _, d := range days {
+		if d.Validators.IsZero() {
+			return nil, fmt.Errorf("no validators on day %v", d.Day)
+		}
+		if len(d.ProposalTxFeesWei) == 0 && !d.ProposalsActual.IsZero() {
+			return nil, fmt.Errorf("no tx fees of proposals available for day %v", d.Day)
+		}
+		sd := simulationDay{
+			proposalsExpected: d.ProposalsExpected.Div(d.Validators).InexactFloat64() * float64(validators),
+			consensusRewards:  gweiToWei(d.ConsensusRewardsGwei).Div(d.Validators).InexactFloat64() * float64(validators),
+			txFees:            make([]float64, len(d.ProposalTxFeesWei)),
+		}
+		for i, f := range d.ProposalTxFeesWei {
+			sd.txFees[i] = f.InexactFloat64()
+		}
+		simDays = append(simDays, sd)
+		effectiveBalanceWei += d.EffectiveBalanceGwei.Mul(decimal.NewFromInt(1e9)).Div(d.Validators).InexactFloat64() * float64(validators)
+	}
+	effectiveBalanceWei /= float64(len(days))
+
+	rng := rand.New(rand.NewSource(seed))
+	aprs := make([]float64, runs)
+	for r := 0; r < runs; r++ {
+		rewards := 0.0
+		for i := 0; i < 365; i++ {
+			sd := simDays[rng.Intn(len(simDays))]
+			rewards += sd.consensusRewards
+			if len(sd.txFees) == 0 {
+				continue
+			}
+			proposals := poisson(rng, sd.proposalsExpected)
+			for j := 0; j < proposals; j++ {
+				rewards += sd.txFees[rng.Intn(len(sd.txFees))]
+			}
+		}
+		aprs[r] = rewards / effectiveBalanceWei
+	}
+	sort.Float64s(aprs)
+
+	sim := &Simulation{
+		Validators:  validators,
+		Runs:        runs,
+		Percentiles: make([]SimulationQuantile, 0, len(simulationPercentiles)),
+	}
+	for _, p := range simulationPercentiles {
+		i := int(math.Ceil(float64(p)/100*float64(runs))) - 1
+		if i < 0 {
+			i = 0
+		}
+		sim.Percentiles = append(sim.Percentiles, SimulationQuantile{Percentile: p, Apr: decimal.NewFromFloat(aprs[i])})
+	}
+	return sim, nil
+}
+
+func poisson(rng *rand.Rand, lambda float64) int {
+	if lambda > 30 {
+		// the normal approximation is accurate enough for large lambdas and avoids underflowing exp(-lambda)
+		n := int(math.Round(lambda + math.Sqrt(lambda)*rng.NormFloat64()))
+		if n < 0 {
+			return 0
+		}
+		return n
+	}
+	l := math.Exp(-lambda)
+	k := 0
+	p := 1.0
+	for {
+		p *= rng.Float64()
+		if p <= l {
+			return k
+		}
+		k++
+	}
+}
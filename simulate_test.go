@@ -0,0 +1,59 @@
+package ethstore
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSimulate(t *testing.T) {
+	// 1000 validators with 32 eth earn 1000 * 0.002 eth consensus rewards per day (apr: 0.0228125) and are
+	// expected to propose 10 blocks per day with 0.1 eth tx fees each (apr: 0.0114063)
+	day := &Day{
+		Day:                  decimal.NewFromInt(1),
+		Validators:           decimal.NewFromInt(1000),
+		EffectiveBalanceGwei: decimal.NewFromInt(1000 * 32e9),
+		ConsensusRewardsGwei: decimal.NewFromInt(1000 * 2e6),
+		ProposalsExpected:    decimal.NewFromInt(10),
+		ProposalsActual:      decimal.NewFromInt(10),
+		ProposalTxFeesWei:    []decimal.Decimal{},
+	}
+	for i := 0; i < 10; i++ {
+		day.ProposalTxFeesWei = append(day.ProposalTxFeesWei, decimal.NewFromInt(1e17))
+	}
+
+	if _, err := Simulate(nil, 1, 100, 1); err == nil {
+		t.Errorf("expected error for empty days")
+	}
+	if _, err := Simulate([]*Day{day}, 0, 100, 1); err == nil {
+		t.Errorf("expected error for 0 validators")
+	}
+
+	solo, err := Simulate([]*Day{day}, 1, 2000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	large, err := Simulate([]*Day{day}, 1000, 2000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(solo.Percentiles) != len(simulationPercentiles) {
+		t.Fatalf("wrong number of percentiles: %v != %v", len(solo.Percentiles), len(simulationPercentiles))
+	}
+
+	expectedApr := decimal.NewFromFloat(0.0228125 + 0.01140625)
+	median := large.Percentiles[4]
+	if median.Percentile != 50 || median.Apr.Sub(expectedApr).Abs().GreaterThan(decimal.NewFromFloat(0.0005)) {
+		t.Errorf("wrong median apr for 1000 validators: %+v != %v", median, expectedApr)
+	}
+	// a single validator proposes ~3.65 blocks per year, so its apr spreads a lot more than the apr of 1000 validators
+	soloSpread := solo.Percentiles[8].Apr.Sub(solo.Percentiles[0].Apr)
+	largeSpread := large.Percentiles[8].Apr.Sub(large.Percentiles[0].Apr)
+	if !soloSpread.GreaterThan(largeSpread.Mul(decimal.NewFromInt(10))) {
+		t.Errorf("spread of solo-staker apr too small: %v vs %v", soloSpread, largeSpread)
+	}
+	// a validator that never proposes earns at least the consensus apr
+	if solo.Percentiles[0].Apr.LessThan(decimal.NewFromFloat(0.0228124)) {
+		t.Errorf("apr below consensus apr: %v", solo.Percentiles[0].Apr)
+	}
+}
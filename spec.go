@@ -0,0 +1,251 @@
+package ethstore
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// ErrSpecMismatch is returned by Calculate if the beacon node reports a different spec than the one recorded for the
+// network in the spec history, e.g. after the endpoint has been swapped by accident.
+var ErrSpecMismatch = errors.New("spec mismatch")
+
+// specKeys are the keys of the spec the calculation of a day depends on, the fork versions and epochs are included as
+// well.
+var specKeys = []string{
+	"CONFIG_NAME",
+	"PRESET_BASE",
+	"DEPOSIT_CHAIN_ID",
+	"DEPOSIT_CONTRACT_ADDRESS",
+	"DOMAIN_DEPOSIT",
+	"SECONDS_PER_SLOT",
+	"SLOTS_PER_EPOCH",
+	"BASE_REWARD_FACTOR",
+	"EFFECTIVE_BALANCE_INCREMENT",
+	"MAX_EFFECTIVE_BALANCE",
+	"MIN_EPOCHS_TO_INACTIVITY_PENALTY",
+}
+
+var specHistoryFile string
+var specHistoryMu = sync.Mutex{}
+
+// SetSpecHistoryFile sets the path of the json-file the spec used for each calculated day is recorded in, the spec of
+// the beacon node is checked against it before a day is calculated. An empty path disables the history.
+func SetSpecHistoryFile(path string) {
+	specHistoryMu.Lock()
+	defer specHistoryMu.Unlock()
+	specHistoryFile = path
+}
+
+func GetSpecHistoryFile() string {
+	specHistoryMu.Lock()
+	defer specHistoryMu.Unlock()
+	return specHistoryFile
+}
+
+// SpecSnapshot holds the parts of the spec and the fork schedule of a network that a calculation depends on and the
+// days that have been calculated with them.
+type SpecSnapshot struct {
+	Network     string            `json:"network"`
+	GenesisTime int64             `json:"genesisTime"`
+	Values      map[string]string `json:"values"`
+	Days        []uint64          `json:"days"`
+}
+
+// SpecHistory holds the spec snapshots in the order they have been recorded.
+type SpecHistory struct {
+	Snapshots []*SpecSnapshot `json:"snapshots"`
+}
+
+func newSpecSnapshot(apiSpec map[string]interface{}, genesis time.Time) *SpecSnapshot {
+	s := &SpecSnapshot{GenesisTime: genesis.Unix(), Values: map[string]string{}}
+	for key, value := range apiSpec {
+		if strings.HasSuffix(key, "_FORK_VERSION") || strings.HasSuffix(key, "_FORK_EPOCH") {
+			s.Values[key] = specValueString(value)
+		}
+	}
+	for _, key := range specKeys {
+		if value, exists := apiSpec[key]; exists {
+			s.Values[key] = specValueString(value)
+		}
+	}
+	s.Network = s.Values["CONFIG_NAME"]
+	if s.Network == "" {
+		s.Network = s.Values["GENESIS_FORK_VERSION"]
+	}
+	return s
+}
+
+// specValueString returns the value of the spec as it is encoded by the beacon api.
+func specValueString(value interface{}) string {
+	switch v := value.(type) {
+	case phase0.Version:
+		return fmt.Sprintf("%#x", v[:])
+	case phase0.DomainType:
+		return fmt.Sprintf("%#x", v[:])
+	case []byte:
+		return fmt.Sprintf("%#x", v)
+	case time.Duration:
+		return fmt.Sprintf("%d", int64(v.Seconds()))
+	case time.Time:
+		return fmt.Sprintf("%d", v.Unix())
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Hash returns the sha256 hash of the genesis time and the values of the snapshot.
+func (s *SpecSnapshot) Hash() string {
+	keys := make([]string, 0, len(s.Values))
+	for key := range s.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	fmt.Fprintf(h, "GENESIS_TIME=%d\n", s.GenesisTime)
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\n", key, s.Values[key])
+	}
+	return fmt.Sprintf("%#x", h.Sum(nil))
+}
+
+// Differences returns the values of the snapshot that differ from the values of the previous snapshot. Values that
+// only one of them holds and forks that have been scheduled since the previous snapshot are no differences.
+func (s *SpecSnapshot) Differences(previous *SpecSnapshot) []string {
+	differences := []string{}
+	if s.GenesisTime != previous.GenesisTime {
+		differences = append(differences, fmt.Sprintf("genesis time: %v != %v", s.GenesisTime, previous.GenesisTime))
+	}
+	for key, value := range s.Values {
+		previousValue, exists := previous.Values[key]
+		if !exists || value == previousValue {
+			continue
+		}
+		if strings.HasSuffix(key, "_FORK_EPOCH") && previousValue == fmt.Sprintf("%d", farFutureEpoch) {
+			continue
+		}
+		differences = append(differences, fmt.Sprintf("%v: %v != %v", key, value, previousValue))
+	}
+	sort.Strings(differences)
+	return differences
+}
+
+func (s *SpecSnapshot) hasDay(day uint64) bool {
+	for _, d := range s.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns an error wrapping ErrSpecMismatch if the snapshot differs from the last snapshot recorded for its
+// network or if the day has been recorded for a different network.
+func (h *SpecHistory) Check(day uint64, s *SpecSnapshot) error {
+	var last *SpecSnapshot
+	for _, previous := range h.Snapshots {
+		if previous.Network == s.Network {
+			last = previous
+		} else if previous.hasDay(day) {
+			return fmt.Errorf("%w: day %v has been calculated for network %v, the beacon node is on network %v", ErrSpecMismatch, day, previous.Network, s.Network)
+		}
+	}
+	if last == nil {
+		return nil
+	}
+	if differences := s.Differences(last); len(differences) > 0 {
+		return fmt.Errorf("%w of network %v: %v", ErrSpecMismatch, s.Network, strings.Join(differences, ", "))
+	}
+	return nil
+}
+
+// Record adds the day to the snapshot in the history that equals the given one, the snapshot is appended to the
+// history if there is none.
+func (h *SpecHistory) Record(day uint64, s *SpecSnapshot) {
+	hash := s.Hash()
+	var snapshot *SpecSnapshot
+	for _, previous := range h.Snapshots {
+		if previous.Network == s.Network && previous.Hash() == hash {
+			snapshot = previous
+		}
+	}
+	if snapshot == nil {
+		snapshot = &SpecSnapshot{Network: s.Network, GenesisTime: s.GenesisTime, Values: s.Values}
+		h.Snapshots = append(h.Snapshots, snapshot)
+	}
+	if !snapshot.hasDay(day) {
+		snapshot.Days = append(snapshot.Days, day)
+		sort.Slice(snapshot.Days, func(i, j int) bool { return snapshot.Days[i] < snapshot.Days[j] })
+	}
+}
+
+// ReadSpecHistory reads the spec history from the given path, a missing file is an empty history.
+func ReadSpecHistory(path string) (*SpecHistory, error) {
+	h := &SpecHistory{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading spec history: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("error parsing spec history: %w", err)
+	}
+	return h, nil
+}
+
+// WriteSpecHistory writes the spec history to the given path.
+func WriteSpecHistory(path string, h *SpecHistory) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling spec history: %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing spec history: %w", err)
+	}
+	return nil
+}
+
+// checkSpecHistory checks the snapshot against the spec history file if it is set.
+func checkSpecHistory(day uint64, s *SpecSnapshot) error {
+	path := GetSpecHistoryFile()
+	if path == "" {
+		return nil
+	}
+	specHistoryMu.Lock()
+	defer specHistoryMu.Unlock()
+	h, err := ReadSpecHistory(path)
+	if err != nil {
+		return err
+	}
+	if err := h.Check(day, s); err != nil {
+		return fmt.Errorf("%w (remove the snapshot from %v if the change is intended)", err, path)
+	}
+	return nil
+}
+
+// recordSpecHistory records the snapshot as the spec of the calculated day in the spec history file if it is set.
+func recordSpecHistory(day uint64, s *SpecSnapshot) error {
+	path := GetSpecHistoryFile()
+	if path == "" {
+		return nil
+	}
+	specHistoryMu.Lock()
+	defer specHistoryMu.Unlock()
+	h, err := ReadSpecHistory(path)
+	if err != nil {
+		return err
+	}
+	h.Record(day, s)
+	return WriteSpecHistory(path, h)
+}
@@ -0,0 +1,119 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestSpecSnapshot(t *testing.T) {
+	apiSpec := map[string]interface{}{
+		"CONFIG_NAME":          "mainnet",
+		"GENESIS_FORK_VERSION": phase0.Version{0, 0, 0, 0},
+		"ALTAIR_FORK_VERSION":  phase0.Version{1, 0, 0, 0},
+		"ALTAIR_FORK_EPOCH":    uint64(74240),
+		"CAPELLA_FORK_EPOCH":   uint64(farFutureEpoch),
+		"DOMAIN_DEPOSIT":       phase0.DomainType{3, 0, 0, 0},
+		"SECONDS_PER_SLOT":     12 * time.Second,
+		"SLOTS_PER_EPOCH":      uint64(32),
+		"MAX_DEPOSITS":         uint64(16),
+	}
+	genesis := time.Unix(1606824023, 0)
+	s := newSpecSnapshot(apiSpec, genesis)
+	expected := map[string]string{
+		"CONFIG_NAME":          "mainnet",
+		"GENESIS_FORK_VERSION": "0x00000000",
+		"ALTAIR_FORK_VERSION":  "0x01000000",
+		"ALTAIR_FORK_EPOCH":    "74240",
+		"CAPELLA_FORK_EPOCH":   "18446744073709551615",
+		"DOMAIN_DEPOSIT":       "0x03000000",
+		"SECONDS_PER_SLOT":     "12",
+		"SLOTS_PER_EPOCH":      "32",
+	}
+	if s.Network != "mainnet" || len(s.Values) != len(expected) {
+		t.Fatalf("wrong snapshot: %+v", s)
+	}
+	for key, value := range expected {
+		if s.Values[key] != value {
+			t.Errorf("wrong value of %v: %v != %v", key, s.Values[key], value)
+		}
+	}
+
+	// scheduling a fork is no difference, changing the schedule is
+	apiSpec["CAPELLA_FORK_EPOCH"] = uint64(194048)
+	scheduled := newSpecSnapshot(apiSpec, genesis)
+	if d := scheduled.Differences(s); len(d) != 0 {
+		t.Errorf("unexpected differences: %v", d)
+	}
+	if s.Hash() == scheduled.Hash() {
+		t.Errorf("same hash of different snapshots")
+	}
+	apiSpec["CAPELLA_FORK_EPOCH"] = uint64(200000)
+	if d := newSpecSnapshot(apiSpec, genesis).Differences(scheduled); len(d) != 1 {
+		t.Errorf("wrong differences: %v", d)
+	}
+	if d := newSpecSnapshot(apiSpec, genesis.Add(time.Hour)).Differences(scheduled); len(d) != 2 {
+		t.Errorf("wrong differences: %v", d)
+	}
+
+	h := &SpecHistory{}
+	if err := h.Check(10, s); err != nil {
+		t.Errorf("unexpected error of empty history: %v", err)
+	}
+	h.Record(10, s)
+	h.Record(11, s)
+	h.Record(10, s)
+	h.Record(12, scheduled)
+	if len(h.Snapshots) != 2 || len(h.Snapshots[0].Days) != 2 || len(h.Snapshots[1].Days) != 1 {
+		t.Errorf("wrong history: %+v", h.Snapshots)
+	}
+	if err := h.Check(13, scheduled); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := h.Check(13, newSpecSnapshot(apiSpec, genesis)); !errors.Is(err, ErrSpecMismatch) {
+		t.Errorf("expected spec mismatch, got %v", err)
+	}
+	apiSpec["CONFIG_NAME"] = "goerli"
+	if err := h.Check(20, newSpecSnapshot(apiSpec, genesis)); err != nil {
+		t.Errorf("unexpected error of other network: %v", err)
+	}
+	if err := h.Check(11, newSpecSnapshot(apiSpec, genesis)); !errors.Is(err, ErrSpecMismatch) {
+		t.Errorf("expected spec mismatch for a day of another network, got %v", err)
+	}
+}
+
+func TestSpecHistoryOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	path := filepath.Join(t.TempDir(), "spec.json")
+	SetSpecHistoryFile(path)
+	defer SetSpecHistoryFile("")
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := ReadSpecHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Snapshots) != 1 || h.Snapshots[0].Network != "mainnet" || len(h.Snapshots[0].Days) != 1 || h.Snapshots[0].Days[0] != 10 {
+		t.Fatalf("wrong history: %+v", h.Snapshots)
+	}
+	if day.Provenance.SpecHash != h.Snapshots[0].Hash() {
+		t.Errorf("wrong spec hash of day: %v != %v", day.Provenance.SpecHash, h.Snapshots[0].Hash())
+	}
+
+	h.Snapshots[0].Values["SLOTS_PER_EPOCH"] = "16"
+	if err := WriteSpecHistory(path, h); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10); !errors.Is(err, ErrSpecMismatch) {
+		t.Errorf("expected spec mismatch, got %v", err)
+	}
+}
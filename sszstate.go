@@ -0,0 +1,127 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+const (
+	sszStateEndpoint = "/eth/v2/debug/beacon/states"
+	// sszValidatorSize is the size of an ssz encoded validator, it has not changed since phase0
+	sszValidatorSize = 121
+)
+
+var sszStates bool
+var sszStatesMu = &sync.Mutex{}
+
+// SetSSZStates requests the validator snapshots as ssz encoded states from the debug api instead of the validators as
+// json. The state is a fraction of the size of the validators as json and faster to decode, but the whole state is
+// held in memory while its validators are decoded, so it is not used in low memory mode. If a beacon node does not
+// serve ssz states, the validators are requested as json from then on.
+//
+// Deprecated: use WithSSZStates.
+func SetSSZStates(enabled bool) {
+	sszStatesMu.Lock()
+	defer sszStatesMu.Unlock()
+	sszStates = enabled
+}
+
+func GetSSZStates() bool {
+	sszStatesMu.Lock()
+	defer sszStatesMu.Unlock()
+	return sszStates
+}
+
+// requestValidatorsSSZ requests the state as ssz and returns its validators with their balances and their status at
+// the epoch of the state. errContentType is returned if the node does not serve the state as ssz.
+func requestValidatorsSSZ(ctx context.Context, client BeaconClient, stateID string) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+	apiSpec, err := client.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slotsPerHistoricalRoot, err := getSpecUint64(apiSpec, "SLOTS_PER_HISTORICAL_ROOT")
+	if err != nil {
+		return nil, err
+	}
+	slotsPerEpoch, err := getSpecUint64(apiSpec, "SLOTS_PER_EPOCH")
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("%s/%s", sszStateEndpoint, stateID)
+	status, header, body, err := getBeacon(ctx, client.Address(), path, contentTypeSSZ)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case status == http.StatusNotFound || status == http.StatusNotAcceptable || status == http.StatusUnsupportedMediaType:
+		// the debug api is optional, nodes without it respond with 404
+		return nil, fmt.Errorf("%w %v: status %v: %s", errContentType, contentTypeSSZ, status, body)
+	case status != http.StatusOK:
+		return nil, fmt.Errorf("status %v: %s", status, body)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type")); mediaType != contentTypeSSZ {
+		return nil, fmt.Errorf("%w %v: response is %v", errContentType, contentTypeSSZ, mediaType)
+	}
+	var vals map[phase0.ValidatorIndex]*v1.Validator
+	err = decodeResponse(ctx, path, func(check func() error) (err error) {
+		vals, err = decodeSSZStateValidators(body, slotsPerHistoricalRoot, slotsPerEpoch, check)
+		return err
+	})
+	if err != nil && !errors.Is(err, ErrDecodeTimeout) && ctx.Err() == nil {
+		return nil, fmt.Errorf("%w %v: %v", errContentType, contentTypeSSZ, err)
+	}
+	return vals, err
+}
+
+// decodeSSZStateValidators decodes the validators and the balances of an ssz encoded beacon state of any fork. The
+// fields before the balances have the same layout in all forks, only the sizes of the block and state roots depend
+// on the preset. The status of the validators is derived from the epoch of the state like the beacon api does. Decoding
+// stops with the error of the check, which runs for every 1024 validators.
+func decodeSSZStateValidators(data []byte, slotsPerHistoricalRoot, slotsPerEpoch uint64, check func() error) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+	// genesis_time, genesis_validators_root, slot, fork, latest_block_header, block_roots, state_roots,
+	// historical_roots (offset), eth1_data, eth1_data_votes (offset), eth1_deposit_index
+	const slotOffset = 8 + 32
+	validatorsOffsetPos := uint64(8+32+8+16+112) + 2*slotsPerHistoricalRoot*32 + 4 + 72 + 4 + 8
+	if slotsPerEpoch == 0 || uint64(len(data)) < validatorsOffsetPos+8 {
+		return nil, fmt.Errorf("state of %v bytes is too short", len(data))
+	}
+	epoch := phase0.Epoch(binary.LittleEndian.Uint64(data[slotOffset:]) / slotsPerEpoch)
+	validatorsOffset := uint64(binary.LittleEndian.Uint32(data[validatorsOffsetPos:]))
+	balancesOffset := uint64(binary.LittleEndian.Uint32(data[validatorsOffsetPos+4:]))
+	if validatorsOffset < validatorsOffsetPos+8 || validatorsOffset > balancesOffset || balancesOffset > uint64(len(data)) || (balancesOffset-validatorsOffset)%sszValidatorSize != 0 {
+		return nil, fmt.Errorf("invalid offsets of validators %v and balances %v", validatorsOffset, balancesOffset)
+	}
+	count := (balancesOffset - validatorsOffset) / sszValidatorSize
+	if balancesOffset+count*8 > uint64(len(data)) {
+		return nil, fmt.Errorf("state of %v bytes is too short for the balances of %v validators", len(data), count)
+	}
+	vals := make(map[phase0.ValidatorIndex]*v1.Validator, count)
+	for i := uint64(0); i < count; i++ {
+		if i%1024 == 0 {
+			if err := check(); err != nil {
+				return nil, err
+			}
+		}
+		validator := &phase0.Validator{}
+		pos := validatorsOffset + i*sszValidatorSize
+		err := validator.UnmarshalSSZ(data[pos : pos+sszValidatorSize])
+		if err != nil {
+			return nil, fmt.Errorf("error decoding validator %v: %w", i, err)
+		}
+		balance := phase0.Gwei(binary.LittleEndian.Uint64(data[balancesOffset+i*8:]))
+		state := v1.ValidatorToState(validator, epoch, farFutureEpoch)
+		if state == v1.ValidatorStateWithdrawalPossible && balance == 0 {
+			state = v1.ValidatorStateWithdrawalDone
+		}
+		vals[phase0.ValidatorIndex(i)] = &v1.Validator{Index: phase0.ValidatorIndex(i), Balance: balance, Status: state, Validator: validator}
+	}
+	return vals, nil
+}
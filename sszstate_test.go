@@ -0,0 +1,156 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// sszState returns an ssz encoded state of the mainnet preset at the given slot with the validators and balances. The
+// fields the validators are not decoded from are zero, the fixed fields after the balances are left out.
+func sszState(t *testing.T, slot uint64, validators []*phase0.Validator, balances []uint64) []byte {
+	// genesis_time, genesis_validators_root, slot, fork, latest_block_header, block_roots, state_roots
+	data := make([]byte, 8+32+8+16+112+2*8192*32)
+	binary.LittleEndian.PutUint64(data[40:], slot)
+	offset := uint32(len(data) + 4 + 72 + 4 + 8 + 4 + 4 + 100)
+	// historical_roots and eth1_data_votes are empty
+	data = appendUint32(data, offset)
+	data = append(data, make([]byte, 72)...)
+	data = appendUint32(data, offset)
+	data = append(data, make([]byte, 8)...)
+	data = appendUint32(data, offset)
+	data = appendUint32(data, offset+uint32(len(validators))*sszValidatorSize)
+	data = append(data, make([]byte, 100)...)
+	for _, v := range validators {
+		ssz, err := v.MarshalSSZ()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, ssz...)
+	}
+	for _, b := range balances {
+		data = append(data, make([]byte, 8)...)
+		binary.LittleEndian.PutUint64(data[len(data)-8:], b)
+	}
+	return data
+}
+
+func appendUint32(data []byte, v uint32) []byte {
+	data = append(data, make([]byte, 4)...)
+	binary.LittleEndian.PutUint32(data[len(data)-4:], v)
+	return data
+}
+
+func sszStateValidators() ([]*phase0.Validator, []uint64) {
+	validator := func(i byte, activation, exit, withdrawable phase0.Epoch) *phase0.Validator {
+		pubkey := phase0.BLSPubKey{}
+		pubkey[47] = i
+		return &phase0.Validator{
+			PublicKey:                  pubkey,
+			WithdrawalCredentials:      make([]byte, 32),
+			EffectiveBalance:           32000000000,
+			ActivationEligibilityEpoch: 0,
+			ActivationEpoch:            activation,
+			ExitEpoch:                  exit,
+			WithdrawableEpoch:          withdrawable,
+		}
+	}
+	return []*phase0.Validator{
+		validator(0, 0, farFutureEpoch, farFutureEpoch),
+		validator(1, 0, 100, 200),
+		validator(2, 3000, farFutureEpoch, farFutureEpoch),
+	}, []uint64{32000000001, 0, 32000000000}
+}
+
+func TestDecodeSSZStateValidators(t *testing.T) {
+	validators, balances := sszStateValidators()
+	data := sszState(t, 72000, validators, balances)
+	vals, err := decodeSSZStateValidators(data, 8192, 32, func() error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vals) != 3 {
+		t.Fatalf("wrong number of validators: %v", len(vals))
+	}
+	for i, status := range []v1.ValidatorState{v1.ValidatorStateActiveOngoing, v1.ValidatorStateWithdrawalDone, v1.ValidatorStatePendingQueued} {
+		v := vals[phase0.ValidatorIndex(i)]
+		if v.Index != phase0.ValidatorIndex(i) || v.Status != status || uint64(v.Balance) != balances[i] || v.Validator.PublicKey[47] != byte(i) || v.Validator.ActivationEpoch != validators[i].ActivationEpoch {
+			t.Errorf("wrong validator %v: %+v %+v", i, v, v.Validator)
+		}
+	}
+
+	if _, err := decodeSSZStateValidators(data[:1000], 8192, 32, func() error { return nil }); err == nil {
+		t.Errorf("expected error for truncated state")
+	}
+	// the offsets are read at other positions with another preset
+	if _, err := decodeSSZStateValidators(data, 64, 32, func() error { return nil }); err == nil {
+		t.Errorf("expected error for wrong preset")
+	}
+}
+
+func TestRequestValidatorsSSZ(t *testing.T) {
+	SetSSZStates(true)
+	defer SetSSZStates(false)
+	validators, balances := sszStateValidators()
+	state := sszState(t, 72000, validators, balances)
+
+	for _, serveSSZ := range []bool{true, false} {
+		var stateRequests, validatorsRequests int32
+		bnServer, elServer := newEthstoreMockServers(t, func(r *http.Request) {
+			if r.URL.Path == "/eth/v1/beacon/states/72000/validators" && r.URL.RawQuery == "" {
+				atomic.AddInt32(&validatorsRequests, 1)
+			}
+		})
+		defer bnServer.Close()
+		defer elServer.Close()
+		bnURL, _ := url.Parse(bnServer.URL)
+		proxy := httputil.NewSingleHostReverseProxy(bnURL)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != sszStateEndpoint+"/72000" {
+				proxy.ServeHTTP(w, r)
+				return
+			}
+			atomic.AddInt32(&stateRequests, 1)
+			if !serveSSZ || r.Header.Get("Accept") != contentTypeSSZ {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", contentTypeSSZ)
+			w.Write(state)
+		}))
+		defer server.Close()
+
+		client, err := newConsClient(context.Background(), server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			vals, err := requestValidators(context.Background(), client, "72000")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if serveSSZ && (len(vals) != 3 || vals[0].Balance != 32000000001) {
+				t.Errorf("wrong validators of ssz state: %v", vals)
+			}
+			if !serveSSZ && len(vals) != 33 {
+				t.Errorf("wrong validators after falling back to json: %v", len(vals))
+			}
+		}
+		// a node without ssz states is requested as json from then on
+		expectedState, expectedValidators := int32(2), int32(0)
+		if !serveSSZ {
+			expectedState, expectedValidators = 1, 2
+		}
+		if atomic.LoadInt32(&stateRequests) != expectedState || atomic.LoadInt32(&validatorsRequests) != expectedValidators {
+			t.Errorf("wrong requests with ssz %v: %v states, %v validators", serveSSZ, stateRequests, validatorsRequests)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrStatesRequired is returned by Calculate if an enabled feature requires historical states besides the states at the
+// start and end of the day while only these may be requested.
+var ErrStatesRequired = errors.New("feature requires the state of every epoch")
+
+var twoStates bool
+var twoStatesMu = sync.Mutex{}
+
+// SetTwoStates restricts the state queries of a day to the states at its first slot and at the first slot of the next
+// day, so a node that keeps these states suffices instead of an archive node. The epoch series, the per-epoch leak
+// detection and the attestation provider require the state of every epoch and can not be combined with it.
+//
+// Deprecated: use WithTwoStates.
+func SetTwoStates(enabled bool) {
+	twoStatesMu.Lock()
+	defer twoStatesMu.Unlock()
+	twoStates = enabled
+}
+
+func GetTwoStates() bool {
+	twoStatesMu.Lock()
+	defer twoStatesMu.Unlock()
+	return twoStates
+}
+
+// checkTwoStates returns an error wrapping ErrStatesRequired if only two states may be requested but an enabled feature
+// requires more.
+func checkTwoStates(options *calculateOptions) error {
+	if !options.getTwoStates() {
+		return nil
+	}
+	if GetEpochSeries() {
+		return fmt.Errorf("%w: epoch series", ErrStatesRequired)
+	}
+	if options.getPerEpochLeakDetection() {
+		return fmt.Errorf("%w: per-epoch leak detection", ErrStatesRequired)
+	}
+	if GetAttestationProvider() != nil {
+		return fmt.Errorf("%w: attestation effectiveness", ErrStatesRequired)
+	}
+	return nil
+}
+
+// stateSlots returns the slots of the historical states that the calculation of the day [firstSlot, endSlot) requests
+// in ascending order: the states at its start and end, the finality of every epoch for the per-epoch leak detection
+// and the balances of every epoch for the epoch series.
+func stateSlots(firstSlot, endSlot, slotsPerEpoch uint64, perEpochLeakDetection bool) []uint64 {
+	slots := map[uint64]bool{firstSlot: true, endSlot: true}
+	if perEpochLeakDetection {
+		for e := firstSlot / slotsPerEpoch; e <= (endSlot-1)/slotsPerEpoch; e++ {
+			if e > 0 {
+				slots[e*slotsPerEpoch] = true
+			}
+		}
+	}
+	if GetEpochSeries() {
+		for slot := firstSlot + slotsPerEpoch; slot < endSlot; slot += slotsPerEpoch {
+			slots[slot] = true
+		}
+	}
+	sorted := make([]uint64, 0, len(slots))
+	for slot := range slots {
+		sorted = append(sorted, slot)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// getInactivityLeakEpochsOfTwoStates returns the epochs in [firstEpoch,lastEpoch] that are in an inactivity leak
+// according to the finality of the states at the start and the end of the day. The finalized checkpoint only moves
+// forward, so an epoch whose previous epoch lags more than minEpochsToInactivityPenalty epochs behind the finalized
+// checkpoint at the end of the day was in a leak, leaks that end during the day are only detected at the first epoch.
+func getInactivityLeakEpochsOfTwoStates(ctx context.Context, client BeaconClient, firstSlot, endSlot, slotsPerEpoch, minEpochsToInactivityPenalty uint64) ([]uint64, error) {
+	firstEpoch, lastEpoch := firstSlot/slotsPerEpoch, (endSlot-1)/slotsPerEpoch
+	finalized := map[uint64]uint64{}
+	for _, slot := range []uint64{firstSlot, endSlot} {
+		finality, err := client.Finality(ctx, fmt.Sprintf("%d", slot))
+		if err != nil {
+			return nil, fmt.Errorf("error getting finality checkpoints at slot %v: %w", slot, err)
+		}
+		if finality.Finalized == nil {
+			return nil, fmt.Errorf("no finalized checkpoint at slot %v", slot)
+		}
+		finalized[slot] = uint64(finality.Finalized.Epoch)
+	}
+	leakEpochs := []uint64{}
+	for e := firstEpoch; e <= lastEpoch; e++ {
+		if e < 1 {
+			continue
+		}
+		if e-1 > finalized[endSlot]+minEpochsToInactivityPenalty || (e == firstEpoch && e-1 > finalized[firstSlot]+minEpochsToInactivityPenalty) {
+			leakEpochs = append(leakEpochs, e)
+		}
+	}
+	return leakEpochs, nil
+}
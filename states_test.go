@@ -0,0 +1,122 @@
+package ethstore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStateSlots(t *testing.T) {
+	defer SetTwoStates(false)
+	defer SetEpochSeries(false)
+
+	if slots := stateSlots(72000, 79200, 32, false); !reflect.DeepEqual(slots, []uint64{72000, 79200}) {
+		t.Errorf("wrong state slots: %v", slots)
+	}
+	if slots := stateSlots(72000, 79200, 32, true); len(slots) != 226 || slots[0] != 72000 || slots[225] != 79200 {
+		t.Errorf("wrong state slots with per-epoch leak detection: %v (%v-%v)", len(slots), slots[0], slots[len(slots)-1])
+	}
+	// the first day starts at genesis, which has no finality to request
+	if slots := stateSlots(0, 7200, 32, true); len(slots) != 226 || slots[0] != 0 {
+		t.Errorf("wrong state slots of day 0: %v", len(slots))
+	}
+	SetEpochSeries(true)
+	if slots := stateSlots(72000, 79200, 32, false); len(slots) != 226 {
+		t.Errorf("wrong state slots with epoch series: %v", len(slots))
+	}
+	SetEpochSeries(false)
+	SetTwoStates(true)
+	if slots := stateSlots(72000, 79200, 32, false); !reflect.DeepEqual(slots, []uint64{72000, 79200}) {
+		t.Errorf("wrong state slots with two states: %v", slots)
+	}
+}
+
+func TestTwoStates(t *testing.T) {
+	stateSlotsRequested := map[uint64]bool{}
+	stateSlotsMu := sync.Mutex{}
+	bnServer, elServer := newEthstoreMockServers(t, func(r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/eth/v1/beacon/states/") {
+			return
+		}
+		slot, err := strconv.ParseUint(strings.Split(strings.TrimPrefix(r.URL.Path, "/eth/v1/beacon/states/"), "/")[0], 10, 64)
+		if err != nil {
+			return
+		}
+		stateSlotsMu.Lock()
+		stateSlotsRequested[slot] = true
+		stateSlotsMu.Unlock()
+	})
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	// the per-epoch leak detection requests the state of every epoch
+	SetPerEpochLeakDetection(true)
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	SetPerEpochLeakDetection(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stateSlotsRequested) != 226 {
+		t.Errorf("wrong number of states requested: %v", len(stateSlotsRequested))
+	}
+
+	SetTwoStates(true)
+	defer SetTwoStates(false)
+	stateSlotsRequested = map[uint64]bool{}
+	twoStatesDay, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stateSlotsRequested, map[uint64]bool{72000: true, 79200: true}) {
+		t.Errorf("wrong states requested: %v", stateSlotsRequested)
+	}
+	if !twoStatesDay.Apr.Equal(day.Apr) || !twoStatesDay.Validators.Equal(day.Validators) {
+		t.Errorf("different results with two states: apr: %v != %v, validators: %v != %v", twoStatesDay.Apr, day.Apr, twoStatesDay.Validators, day.Validators)
+	}
+	// the inactivity leak of the mock ends within the day, which is not visible in the states at its start and end
+	if twoStatesDay.InactivityLeak {
+		t.Errorf("inactivity leak detected from the finality at the start and end of the day")
+	}
+
+	SetPerEpochLeakDetection(true)
+	if _, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10); !errors.Is(err, ErrStatesRequired) {
+		t.Errorf("expected ErrStatesRequired with per-epoch leak detection, got: %v", err)
+	}
+	SetPerEpochLeakDetection(false)
+	SetEpochSeries(true)
+	defer SetEpochSeries(false)
+	if _, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10); !errors.Is(err, ErrStatesRequired) {
+		t.Errorf("expected ErrStatesRequired with epoch series, got: %v", err)
+	}
+}
+
+func TestGetInactivityLeakEpochsOfTwoStates(t *testing.T) {
+	bnServer, _ := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	client, err := newConsClient(context.Background(), bnServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the leak ends before the end of the range, only the first epoch is known to be in the leak
+	leakEpochs, err := getInactivityLeakEpochsOfTwoStates(context.Background(), client, 2305*32, 2311*32, 32, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(leakEpochs, []uint64{2305}) {
+		t.Errorf("wrong leak epochs: %v", leakEpochs)
+	}
+	// the finality at the end lags 14 epochs behind, so all epochs since 2300 are in the leak
+	leakEpochs, err = getInactivityLeakEpochsOfTwoStates(context.Background(), client, 2298*32, 2309*32, 32, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(leakEpochs, []uint64{2301, 2302, 2303, 2304, 2305, 2306, 2307, 2308}) {
+		t.Errorf("wrong leak epochs: %v", leakEpochs)
+	}
+}
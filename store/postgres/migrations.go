@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFiles are the migrations of the schema, every version has a file <version>_<name>.up.sql that migrates the
+// schema of the previous version up and a file <version>_<name>.down.sql that reverts it. The versions start at 1 and
+// have no gaps, a released migration is never changed but followed by a new one.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const (
+	createMigrationsTable = `CREATE TABLE IF NOT EXISTS ethstore_schema_migrations (
+		version    BIGINT      PRIMARY KEY,
+		name       TEXT        NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`
+	lockMigrations  = `LOCK TABLE ethstore_schema_migrations IN EXCLUSIVE MODE`
+	selectVersion   = `SELECT COALESCE(MAX(version), 0) FROM ethstore_schema_migrations`
+	insertMigration = `INSERT INTO ethstore_schema_migrations (version, name) VALUES ($1, $2)`
+	deleteMigration = `DELETE FROM ethstore_schema_migrations WHERE version = $1`
+)
+
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// readMigrations returns the embedded migrations ordered by version.
+func readMigrations() ([]*migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %w", err)
+	}
+	byVersion := map[int]*migration{}
+	for _, e := range entries {
+		file := e.Name()
+		base, direction := strings.TrimSuffix(file, ".sql"), ""
+		switch {
+		case strings.HasSuffix(base, ".up"):
+			base, direction = strings.TrimSuffix(base, ".up"), "up"
+		case strings.HasSuffix(base, ".down"):
+			base, direction = strings.TrimSuffix(base, ".down"), "down"
+		default:
+			return nil, fmt.Errorf("migration %v is neither up nor down", file)
+		}
+		versionStr, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %v has no valid version", file)
+		}
+		data, err := migrationFiles.ReadFile(path.Join("migrations", file))
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %v: %w", file, err)
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &migration{version: version, name: name}
+			byVersion[version] = m
+		}
+		if m.name != name {
+			return nil, fmt.Errorf("migrations of version %v have different names: %v and %v", version, m.name, name)
+		}
+		if direction == "up" {
+			m.up = string(data)
+		} else {
+			m.down = string(data)
+		}
+	}
+	migrations := make([]*migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("migration of version %v is missing", i+1)
+		}
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration of version %v has no up or no down file", m.version)
+		}
+	}
+	return migrations, nil
+}
+
+// Version returns the version of the schema of the database, 0 if no migration is applied.
+func (s *Store) Version(ctx context.Context) (int, error) {
+	_, err := s.db.ExecContext(ctx, createMigrationsTable)
+	if err != nil {
+		return 0, fmt.Errorf("error creating migrations table: %w", err)
+	}
+	var version int
+	err = s.db.QueryRowContext(ctx, selectVersion).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error querying schema version: %w", err)
+	}
+	return version, nil
+}
+
+// Migrate applies the pending migrations of the schema, each within its own transaction. It fails if the schema of the
+// database is newer than the migrations of this version.
+func (s *Store) Migrate(ctx context.Context) error {
+	migrations, err := readMigrations()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, createMigrationsTable)
+	if err != nil {
+		return fmt.Errorf("error creating migrations table: %w", err)
+	}
+	for _, m := range migrations {
+		m := m
+		err := s.migrate(ctx, func(tx *sql.Tx, version int) error {
+			if version > len(migrations) {
+				return fmt.Errorf("schema version %v is newer than the latest migration %v", version, len(migrations))
+			}
+			if version >= m.version {
+				return nil
+			}
+			_, err := tx.ExecContext(ctx, m.up)
+			if err != nil {
+				return fmt.Errorf("error migrating up to version %v (%v): %w", m.version, m.name, err)
+			}
+			_, err = tx.ExecContext(ctx, insertMigration, m.version, m.name)
+			if err != nil {
+				return fmt.Errorf("error recording migration %v: %w", m.version, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateDown reverts the latest n applied migrations of the schema, each within its own transaction.
+func (s *Store) MigrateDown(ctx context.Context, n int) error {
+	migrations, err := readMigrations()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, createMigrationsTable)
+	if err != nil {
+		return fmt.Errorf("error creating migrations table: %w", err)
+	}
+	for i := 0; i < n; i++ {
+		err := s.migrate(ctx, func(tx *sql.Tx, version int) error {
+			if version == 0 {
+				return fmt.Errorf("no migration to revert")
+			}
+			if version > len(migrations) {
+				return fmt.Errorf("schema version %v is newer than the latest migration %v", version, len(migrations))
+			}
+			m := migrations[version-1]
+			_, err := tx.ExecContext(ctx, m.down)
+			if err != nil {
+				return fmt.Errorf("error migrating down from version %v (%v): %w", m.version, m.name, err)
+			}
+			_, err = tx.ExecContext(ctx, deleteMigration, m.version)
+			if err != nil {
+				return fmt.Errorf("error recording migration %v: %w", m.version, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate runs f with the current schema version within a transaction that holds the lock of the migrations table, so
+// concurrent migrations of the same database do not interfere.
+func (s *Store) migrate(ctx context.Context, f func(tx *sql.Tx, version int) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error migrating schema: %w", err)
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, lockMigrations)
+	if err != nil {
+		return fmt.Errorf("error locking migrations table: %w", err)
+	}
+	var version int
+	err = tx.QueryRowContext(ctx, selectVersion).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("error querying schema version: %w", err)
+	}
+	err = f(tx, version)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error migrating schema: %w", err)
+	}
+	return nil
+}
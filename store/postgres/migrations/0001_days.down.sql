@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ethstore_days;
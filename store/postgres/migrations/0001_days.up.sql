@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS ethstore_days (
+	network                TEXT        NOT NULL,
+	day                    BIGINT      NOT NULL,
+	day_time               TIMESTAMPTZ NOT NULL,
+	apr                    NUMERIC     NOT NULL,
+	validators             NUMERIC     NOT NULL,
+	effective_balance_gwei NUMERIC     NOT NULL,
+	start_balance_gwei     NUMERIC     NOT NULL,
+	end_balance_gwei       NUMERIC     NOT NULL,
+	deposits_sum_gwei      NUMERIC     NOT NULL,
+	consensus_rewards_gwei NUMERIC     NOT NULL,
+	tx_fees_sum_wei        NUMERIC     NOT NULL,
+	total_rewards_wei      NUMERIC     NOT NULL,
+	revision               BIGINT      NOT NULL,
+	supersedes             BIGINT,
+	superseded             BOOLEAN     NOT NULL DEFAULT false,
+	data                   JSONB       NOT NULL,
+	updated_at             TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (network, day, revision)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS ethstore_validator_days (
+	network                TEXT    NOT NULL,
+	day                    BIGINT  NOT NULL,
+	validator_index        BIGINT  NOT NULL,
+	apr                    NUMERIC NOT NULL,
+	effective_balance_gwei NUMERIC NOT NULL,
+	consensus_rewards_gwei NUMERIC NOT NULL,
+	tx_fees_sum_wei        NUMERIC NOT NULL,
+	total_rewards_wei      NUMERIC NOT NULL,
+	data                   JSONB   NOT NULL,
+	PRIMARY KEY (network, day, validator_index)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ethstore_validator_days_address;
+DROP INDEX IF EXISTS ethstore_validator_days_validator;
+ALTER TABLE ethstore_validator_days DROP COLUMN IF EXISTS withdrawal_address;
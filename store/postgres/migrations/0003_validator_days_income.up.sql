@@ -0,0 +1,7 @@
+ALTER TABLE ethstore_validator_days ADD COLUMN IF NOT EXISTS withdrawal_address TEXT NOT NULL DEFAULT '';
+UPDATE ethstore_validator_days SET withdrawal_address = CASE
+	WHEN length(data->>'withdrawalCredentials') = 66 AND left(data->>'withdrawalCredentials', 4) IN ('0x01', '0x02') THEN lower('0x' || substr(data->>'withdrawalCredentials', 27))
+	ELSE lower(coalesce(data->>'withdrawalCredentials', ''))
+END;
+CREATE INDEX IF NOT EXISTS ethstore_validator_days_validator ON ethstore_validator_days (network, validator_index, day);
+CREATE INDEX IF NOT EXISTS ethstore_validator_days_address ON ethstore_validator_days (network, withdrawal_address, day);
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestReadMigrations(t *testing.T) {
+	migrations, err := readMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 3 || migrations[0].name != "days" || migrations[1].name != "validator_days" || migrations[2].name != "validator_days_income" {
+		t.Errorf("wrong migrations: %+v", migrations)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	fake := newFakeDB()
+	sql.Register("fake-postgres-migrate", fake)
+	db, err := sql.Open("fake-postgres-migrate", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	s := New(db, "mainnet")
+	version, err := s.Version(ctx)
+	if err != nil || version != 0 {
+		t.Errorf("wrong version of a new database: %v, %v", version, err)
+	}
+	// migrating twice applies every migration once
+	for i := 0; i < 2; i++ {
+		if err := s.Migrate(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(fake.migrations) != 3 || !fake.tables["ethstore_days"] || !fake.tables["ethstore_validator_days"] {
+		t.Errorf("wrong migrations after migrating up: %v, tables: %v", fake.migrations, fake.tables)
+	}
+	if err := s.MigrateDown(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	version, err = s.Version(ctx)
+	if err != nil || version != 1 || !fake.tables["ethstore_days"] || fake.tables["ethstore_validator_days"] {
+		t.Errorf("wrong version after migrating down: %v, %v, tables: %v", version, err, fake.tables)
+	}
+	if err := s.MigrateDown(ctx, 2); err == nil {
+		t.Errorf("expected an error when migrating down below version 0")
+	}
+	if len(fake.migrations) != 0 || fake.tables["ethstore_days"] {
+		t.Errorf("wrong migrations after migrating down: %v, tables: %v", fake.migrations, fake.tables)
+	}
+	// a schema of a newer version is not migrated
+	fake.migrations = []int64{1, 2, 3, 4}
+	if err := s.Migrate(ctx); err == nil {
+		t.Errorf("expected an error for a newer schema version")
+	}
+}
@@ -0,0 +1,295 @@
+// Package postgres stores the days calculated by eth.store in a PostgreSQL database, so that frontends can read them
+// straight from the database. It uses database/sql with the driver registered as "postgres", e.g. github.com/lib/pq,
+// which the program has to import, the eth.store binary does not link a driver.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	ethstore "github.com/gobitfly/eth.store"
+)
+
+// validatorsPerInsert is the number of per-validator rows inserted with a single statement, it keeps the number of
+// parameters of a statement below the limit of 65535.
+const validatorsPerInsert = 1000
+
+const insertDay = `INSERT INTO ethstore_days (network, day, day_time, apr, validators, effective_balance_gwei, start_balance_gwei, end_balance_gwei, deposits_sum_gwei, consensus_rewards_gwei, tx_fees_sum_wei, total_rewards_wei, revision, supersedes, data)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+const (
+	selectLatestRevision = `SELECT revision FROM ethstore_days WHERE network = $1 AND day = $2 AND NOT superseded FOR UPDATE`
+	supersedeDay         = `UPDATE ethstore_days SET superseded = true, data = data || '{"superseded": true}'::jsonb WHERE network = $1 AND day = $2 AND revision = $3`
+)
+
+const upsertValidatorDays = `INSERT INTO ethstore_validator_days (network, day, validator_index, apr, effective_balance_gwei, consensus_rewards_gwei, tx_fees_sum_wei, total_rewards_wei, withdrawal_address, data)
+	VALUES %s
+	ON CONFLICT (network, day, validator_index) DO UPDATE SET apr = EXCLUDED.apr, effective_balance_gwei = EXCLUDED.effective_balance_gwei, consensus_rewards_gwei = EXCLUDED.consensus_rewards_gwei, tx_fees_sum_wei = EXCLUDED.tx_fees_sum_wei, total_rewards_wei = EXCLUDED.total_rewards_wei, withdrawal_address = EXCLUDED.withdrawal_address, data = EXCLUDED.data`
+
+const (
+	selectValidatorDays        = `SELECT day, validator_index, data FROM ethstore_validator_days WHERE network = $1 AND validator_index = $2 AND day >= $3 AND day <= $4`
+	selectAddressValidatorDays = `SELECT day, validator_index, data FROM ethstore_validator_days WHERE network = $1 AND withdrawal_address = $2 AND day >= $3 AND day <= $4`
+)
+
+const deleteValidatorDays = `DELETE FROM ethstore_validator_days WHERE network = $1 AND day < $2`
+
+const (
+	selectDay    = `SELECT data FROM ethstore_days WHERE network = $1 AND day = $2 AND NOT superseded`
+	selectRange  = `SELECT data FROM ethstore_days WHERE network = $1 AND day >= $2 AND day <= $3 AND NOT superseded ORDER BY day`
+	selectLatest = `SELECT data FROM ethstore_days WHERE network = $1 AND NOT superseded ORDER BY day DESC LIMIT 1`
+)
+
+// Store is an ethstore.ResultStore that keeps the days of a network in PostgreSQL. Like the json file of
+// ethstore.FileStore a recalculated day is inserted as a new revision that links to the revision it supersedes, the
+// earlier revisions are kept as superseded and Get, Range and Latest only return the latest revisions. The columns hold
+// the main values of a day for queries and the data column the whole day as json.
+type Store struct {
+	db      *sql.DB
+	network string
+}
+
+// Open connects to the database of the dsn and applies the pending migrations of its schema.
+func Open(ctx context.Context, dsn, network string) (*Store, error) {
+	s, err := Connect(ctx, dsn, network)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Migrate(ctx)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Connect connects to the database of the dsn without migrating its schema, e.g. to migrate it down.
+func Connect(ctx context.Context, dsn, network string) (*Store, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %w", err)
+	}
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to database: %w", err)
+	}
+	return New(db, network), nil
+}
+
+// New returns a store of the days of the network in the database.
+func New(db *sql.DB, network string) *Store {
+	return &Store{db: db, network: network}
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put inserts the day as a new revision within a single transaction, the stored revision of the day is marked as
+// superseded.
+func (s *Store) Put(ctx context.Context, d *ethstore.Day) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error storing day %v: %w", d.Day, err)
+	}
+	defer tx.Rollback()
+	var latest int64
+	err = tx.QueryRowContext(ctx, selectLatestRevision, s.network, d.Day.IntPart()).Scan(&latest)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error storing day %v: %w", d.Day, err)
+	}
+	var supersedes interface{}
+	if err == nil {
+		_, err = tx.ExecContext(ctx, supersedeDay, s.network, d.Day.IntPart(), latest)
+		if err != nil {
+			return fmt.Errorf("error storing day %v: %w", d.Day, err)
+		}
+		revision := int(latest)
+		d.Revision = revision + 1
+		d.Supersedes = &revision
+		supersedes = latest
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertDay, s.network, d.Day.IntPart(), d.DayTime, d.Apr.String(), d.Validators.String(), d.EffectiveBalanceGwei.String(), d.StartBalanceGwei.String(), d.EndBalanceGwei.String(), d.DepositsSumGwei.String(), d.ConsensusRewardsGwei.String(), d.TxFeesSumWei.String(), d.TotalRewardsWei.String(), int64(d.Revision), supersedes, data)
+	if err != nil {
+		return fmt.Errorf("error storing day %v: %w", d.Day, err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error storing day %v: %w", d.Day, err)
+	}
+	return nil
+}
+
+// PutValidators upserts the per-validator results of a day, keyed by validator index, within a single transaction.
+func (s *Store) PutValidators(ctx context.Context, day uint64, validatorDays map[uint64]*ethstore.Day) error {
+	indices := make([]uint64, 0, len(validatorDays))
+	for index := range validatorDays {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i] < indices[j]
+	})
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error storing validators of day %v: %w", day, err)
+	}
+	defer tx.Rollback()
+	for start := 0; start < len(indices); start += validatorsPerInsert {
+		end := start + validatorsPerInsert
+		if end > len(indices) {
+			end = len(indices)
+		}
+		values := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*10)
+		for _, index := range indices[start:end] {
+			d := validatorDays[index]
+			data, err := json.Marshal(d)
+			if err != nil {
+				return err
+			}
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10))
+			args = append(args, s.network, int64(day), int64(index), d.Apr.String(), d.EffectiveBalanceGwei.String(), d.ConsensusRewardsGwei.String(), d.TxFeesSumWei.String(), d.TotalRewardsWei.String(), strings.ToLower(ethstore.WithdrawalAddress(d.WithdrawalCredentials)), data)
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(upsertValidatorDays, strings.Join(values, ", ")), args...)
+		if err != nil {
+			return fmt.Errorf("error storing validators of day %v: %w", day, err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error storing validators of day %v: %w", day, err)
+	}
+	return nil
+}
+
+// ValidatorDays returns the per-validator results of the validator on the stored days in [from, to], keyed by day and
+// validator index.
+func (s *Store) ValidatorDays(ctx context.Context, index, from, to uint64) (map[uint64]map[uint64]*ethstore.Day, error) {
+	return s.queryValidatorDays(ctx, selectValidatorDays, int64(index), from, to)
+}
+
+// AddressValidatorDays returns the per-validator results of the validators with the withdrawal address on the stored
+// days in [from, to], keyed by day and validator index.
+func (s *Store) AddressValidatorDays(ctx context.Context, address string, from, to uint64) (map[uint64]map[uint64]*ethstore.Day, error) {
+	return s.queryValidatorDays(ctx, selectAddressValidatorDays, strings.ToLower(address), from, to)
+}
+
+// queryValidatorDays returns the per-validator results of a query that selects the day, validator index and data of
+// the validators matching the key within [from, to].
+func (s *Store) queryValidatorDays(ctx context.Context, query string, key interface{}, from, to uint64) (map[uint64]map[uint64]*ethstore.Day, error) {
+	validatorDays := map[uint64]map[uint64]*ethstore.Day{}
+	// BIGINT is signed, so the end of an open range is capped
+	if to > 1<<63-1 {
+		to = 1<<63 - 1
+	}
+	if from > to {
+		return validatorDays, nil
+	}
+	rows, err := s.db.QueryContext(ctx, query, s.network, key, int64(from), int64(to))
+	if err != nil {
+		return nil, fmt.Errorf("error querying validators: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day, index int64
+		var data []byte
+		err := rows.Scan(&day, &index, &data)
+		if err != nil {
+			return nil, fmt.Errorf("error querying validators: %w", err)
+		}
+		d := &ethstore.Day{}
+		err = json.Unmarshal(data, d)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding validator: %w", err)
+		}
+		if validatorDays[uint64(day)] == nil {
+			validatorDays[uint64(day)] = map[uint64]*ethstore.Day{}
+		}
+		validatorDays[uint64(day)][uint64(index)] = d
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error querying validators: %w", err)
+	}
+	return validatorDays, nil
+}
+
+// CompactValidators deletes the per-validator results of the days before the given day and returns the number of
+// deleted rows, the days themselves are kept.
+func (s *Store) CompactValidators(ctx context.Context, before uint64) (int64, error) {
+	res, err := s.db.ExecContext(ctx, deleteValidatorDays, s.network, int64(before))
+	if err != nil {
+		return 0, fmt.Errorf("error compacting validators before day %v: %w", before, err)
+	}
+	return res.RowsAffected()
+}
+
+func (s *Store) Get(ctx context.Context, day uint64) (*ethstore.Day, error) {
+	return s.queryDay(ctx, selectDay, s.network, int64(day))
+}
+
+func (s *Store) Range(ctx context.Context, from, to uint64) ([]*ethstore.Day, error) {
+	// BIGINT is signed, so the end of an open range is capped
+	if to > 1<<63-1 {
+		to = 1<<63 - 1
+	}
+	if from > to {
+		return []*ethstore.Day{}, nil
+	}
+	rows, err := s.db.QueryContext(ctx, selectRange, s.network, int64(from), int64(to))
+	if err != nil {
+		return nil, fmt.Errorf("error querying days: %w", err)
+	}
+	defer rows.Close()
+	days := []*ethstore.Day{}
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("error querying days: %w", err)
+		}
+		d := &ethstore.Day{}
+		err = json.Unmarshal(data, d)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding day: %w", err)
+		}
+		days = append(days, d)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error querying days: %w", err)
+	}
+	return days, nil
+}
+
+func (s *Store) Latest(ctx context.Context) (*ethstore.Day, error) {
+	return s.queryDay(ctx, selectLatest, s.network)
+}
+
+// queryDay returns the day of a query that selects the data of a single day or ethstore.ErrDayNotFound.
+func (s *Store) queryDay(ctx context.Context, query string, args ...interface{}) (*ethstore.Day, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ethstore.ErrDayNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying day: %w", err)
+	}
+	d := &ethstore.Day{}
+	err = json.Unmarshal(data, d)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding day: %w", err)
+	}
+	return d, nil
+}
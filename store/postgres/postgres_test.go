@@ -0,0 +1,325 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	ethstore "github.com/gobitfly/eth.store"
+	"github.com/shopspring/decimal"
+)
+
+// fakeDB is a database/sql driver that understands the statements of the store, it keeps the created tables, the
+// applied migrations, the revisions of the days by network and the data column of the validator rows keyed by network,
+// day and validator index.
+type fakeDB struct {
+	tables     map[string]bool
+	migrations []int64
+	days       map[string][]*fakeDay
+	validators map[string][]byte
+	addresses  map[string]string
+	commits    int
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{tables: map[string]bool{}, days: map[string][]*fakeDay{}, validators: map[string][]byte{}, addresses: map[string]string{}}
+}
+
+type fakeDay struct {
+	day        int64
+	revision   int64
+	supersedes interface{}
+	superseded bool
+	data       []byte
+}
+
+func (db *fakeDB) Open(name string) (driver.Conn, error) {
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+	tx bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.tx = true
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.tx = false
+	c.db.commits++
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.tx = false
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE IF NOT EXISTS"):
+		db.tables[strings.Fields(s.query)[5]] = true
+	case strings.HasPrefix(s.query, "ALTER TABLE"), strings.HasPrefix(s.query, "DROP INDEX"):
+	case strings.HasPrefix(s.query, "DROP TABLE IF EXISTS"):
+		delete(db.tables, strings.TrimSuffix(strings.Fields(s.query)[4], ";"))
+	case s.query == lockMigrations:
+		if !s.conn.tx {
+			return nil, errors.New("migrations locked outside of a transaction")
+		}
+	case s.query == insertMigration:
+		db.migrations = append(db.migrations, args[0].(int64))
+	case s.query == deleteMigration:
+		if len(db.migrations) == 0 || db.migrations[len(db.migrations)-1] != args[0].(int64) {
+			return nil, fmt.Errorf("migration %v is not the latest", args[0])
+		}
+		db.migrations = db.migrations[:len(db.migrations)-1]
+	case strings.HasPrefix(s.query, "INSERT INTO ethstore_days"):
+		if !s.conn.tx {
+			return nil, errors.New("day inserted outside of a transaction")
+		}
+		network := args[0].(string)
+		for _, d := range db.days[network] {
+			if d.day == args[1].(int64) && d.revision == args[12].(int64) {
+				return nil, errors.New("duplicate key")
+			}
+		}
+		db.days[network] = append(db.days[network], &fakeDay{day: args[1].(int64), revision: args[12].(int64), supersedes: args[13], data: args[14].([]byte)})
+	case s.query == supersedeDay:
+		for _, d := range db.days[args[0].(string)] {
+			if d.day == args[1].(int64) && d.revision == args[2].(int64) {
+				d.superseded = true
+			}
+		}
+	case strings.HasPrefix(s.query, "INSERT INTO ethstore_validator_days"):
+		if !s.conn.tx {
+			return nil, errors.New("validators inserted outside of a transaction")
+		}
+		for i := 0; i < len(args); i += 10 {
+			key := fmt.Sprintf("%v/%v/%v", args[i], args[i+1], args[i+2])
+			db.validators[key] = args[i+9].([]byte)
+			db.addresses[key] = args[i+8].(string)
+		}
+	case s.query == deleteValidatorDays:
+		deleted := 0
+		for key := range db.validators {
+			var network string
+			var day, index int64
+			fmt.Sscanf(strings.ReplaceAll(key, "/", " "), "%s %d %d", &network, &day, &index)
+			if network == args[0].(string) && day < args[1].(int64) {
+				delete(db.validators, key)
+				deleted++
+			}
+		}
+		return driver.RowsAffected(deleted), nil
+	default:
+		return nil, fmt.Errorf("unexpected statement: %v", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.query == selectVersion {
+		version := int64(0)
+		if n := len(s.conn.db.migrations); n > 0 {
+			version = s.conn.db.migrations[n-1]
+		}
+		return &fakeRows{values: []driver.Value{version}}, nil
+	}
+	if s.query == selectValidatorDays || s.query == selectAddressValidatorDays {
+		rows := &fakeRows{columns: 3}
+		for key, data := range s.conn.db.validators {
+			var network string
+			var day, index int64
+			fmt.Sscanf(strings.ReplaceAll(key, "/", " "), "%s %d %d", &network, &day, &index)
+			if network != args[0].(string) || day < args[2].(int64) || day > args[3].(int64) {
+				continue
+			}
+			if s.query == selectValidatorDays && index != args[1].(int64) || s.query == selectAddressValidatorDays && s.conn.db.addresses[key] != args[1].(string) {
+				continue
+			}
+			rows.values = append(rows.values, day, index, data)
+		}
+		return rows, nil
+	}
+	var matches []*fakeDay
+	for _, d := range s.conn.db.days[args[0].(string)] {
+		if d.superseded {
+			continue
+		}
+		switch s.query {
+		case selectDay, selectLatestRevision:
+			if d.day == args[1].(int64) {
+				matches = append(matches, d)
+			}
+		case selectRange:
+			if d.day >= args[1].(int64) && d.day <= args[2].(int64) {
+				matches = append(matches, d)
+			}
+		case selectLatest:
+			if len(matches) == 0 || d.day > matches[0].day {
+				matches = []*fakeDay{d}
+			}
+		default:
+			return nil, fmt.Errorf("unexpected query: %v", s.query)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].day < matches[j].day
+	})
+	rows := &fakeRows{}
+	for _, d := range matches {
+		if s.query == selectLatestRevision {
+			rows.values = append(rows.values, d.revision)
+		} else {
+			rows.values = append(rows.values, d.data)
+		}
+	}
+	return rows, nil
+}
+
+// fakeRows returns the values row by row, rows have a single column unless columns is set.
+type fakeRows struct {
+	columns int
+	values  []driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	if r.columns == 3 {
+		return []string{"day", "validator_index", "data"}
+	}
+	return []string{"data"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	n := copy(dest, r.values)
+	r.values = r.values[n:]
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	fake := newFakeDB()
+	sql.Register("fake-postgres", fake)
+	db, err := sql.Open("fake-postgres", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	s := New(db, "mainnet")
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// the days of other networks are kept apart
+	if err := New(db, "holesky").Put(ctx, &ethstore.Day{Day: decimal.NewFromInt(20)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Latest(ctx); !errors.Is(err, ethstore.ErrDayNotFound) {
+		t.Errorf("expected ErrDayNotFound for latest day without days: %v", err)
+	}
+	// a recalculated day is inserted as a new revision, the earlier revisions are kept as superseded
+	for _, d := range []*ethstore.Day{{Day: decimal.NewFromInt(12), Apr: decimal.NewFromFloat(0.1)}, {Day: decimal.NewFromInt(10)}, {Day: decimal.NewFromInt(11)}, {Day: decimal.NewFromInt(12), Apr: decimal.NewFromFloat(0.2)}, {Day: decimal.NewFromInt(12), Apr: decimal.NewFromFloat(0.3)}} {
+		if err := s.Put(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(fake.days["mainnet"]) != 5 {
+		t.Errorf("wrong number of rows: %v", len(fake.days["mainnet"]))
+	}
+	for _, row := range fake.days["mainnet"] {
+		if row.day == 12 && row.superseded != (row.revision < 2) {
+			t.Errorf("wrong superseded flag of revision %v: %v", row.revision, row.superseded)
+		}
+		if row.day == 12 && row.revision == 2 && row.supersedes != int64(1) {
+			t.Errorf("wrong superseded revision: %v", row.supersedes)
+		}
+	}
+	if _, err := s.Get(ctx, 9); !errors.Is(err, ethstore.ErrDayNotFound) {
+		t.Errorf("expected ErrDayNotFound for missing day: %v", err)
+	}
+	d, err := s.Get(ctx, 12)
+	if err != nil || !d.Apr.Equal(decimal.NewFromFloat(0.3)) || d.Revision != 2 || d.Supersedes == nil || *d.Supersedes != 1 {
+		t.Errorf("expected the latest revision of the recalculated day: %+v, %v", d, err)
+	}
+	latest, err := s.Latest(ctx)
+	if err != nil || latest.Day.IntPart() != 12 {
+		t.Errorf("wrong latest day: %+v, %v", latest, err)
+	}
+	days, err := s.Range(ctx, 11, ^uint64(0))
+	if err != nil || len(days) != 2 || days[0].Day.IntPart() != 11 || days[1].Day.IntPart() != 12 {
+		t.Errorf("wrong range: %v, %v", days, err)
+	}
+
+	validatorDays := map[uint64]*ethstore.Day{}
+	for i := uint64(0); i < validatorsPerInsert+1; i++ {
+		validatorDays[i] = &ethstore.Day{Day: decimal.NewFromInt(12)}
+	}
+	if err := s.PutValidators(ctx, 12, validatorDays); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.validators) != validatorsPerInsert+1 || fake.validators["mainnet/12/1000"] == nil || fake.commits != 10 {
+		t.Errorf("wrong validator rows: %v rows, %v commits", len(fake.validators), fake.commits)
+	}
+
+	// the per-validator results are looked up by validator and withdrawal address
+	if err := s.PutValidators(ctx, 11, map[uint64]*ethstore.Day{1: {Day: decimal.NewFromInt(11), WithdrawalCredentials: "0x010000000000000000000000AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}}); err != nil {
+		t.Fatal(err)
+	}
+	byValidator, err := s.ValidatorDays(ctx, 1, 11, ^uint64(0))
+	if err != nil || len(byValidator) != 2 || byValidator[11][1] == nil || byValidator[12][1] == nil {
+		t.Errorf("wrong validator days: %v, %v", byValidator, err)
+	}
+	byAddress, err := s.AddressValidatorDays(ctx, "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", 0, 12)
+	if err != nil || len(byAddress) != 1 || len(byAddress[11]) != 1 || byAddress[11][1] == nil {
+		t.Errorf("wrong address validator days: %v, %v", byAddress, err)
+	}
+	delete(fake.validators, "mainnet/11/1")
+
+	// compacting keeps the per-validator results of the days from the given day on
+	if err := s.PutValidators(ctx, 13, map[uint64]*ethstore.Day{0: {Day: decimal.NewFromInt(13)}}); err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := s.CompactValidators(ctx, 13)
+	if err != nil || deleted != validatorsPerInsert+1 || len(fake.validators) != 1 || fake.validators["mainnet/13/0"] == nil {
+		t.Errorf("wrong validator rows after compacting: %v deleted, %v rows, %v", deleted, len(fake.validators), err)
+	}
+	if _, err := s.Get(ctx, 12); err != nil {
+		t.Errorf("expected the day to be kept after compacting: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestThrottle limits the concurrent requests to the consensus node and spaces them by a minimum delay, so that
+// calculations can share a node with a validator client.
+type requestThrottle struct {
+	maxConcurrent int
+	delay         time.Duration
+	sem           chan struct{}
+	mu            sync.Mutex
+	next          time.Time
+}
+
+var throttle = &requestThrottle{}
+var throttleMu = sync.Mutex{}
+
+// SetMaxConcurrentRequests limits the number of concurrent requests to the consensus node over all calculations, it
+// is not limited if 0.
+func SetMaxConcurrentRequests(n int) {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	throttle = newRequestThrottle(n, throttle.delay)
+}
+
+func GetMaxConcurrentRequests() int {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	return throttle.maxConcurrent
+}
+
+// SetRequestDelay sets the minimum delay between the starts of two requests to the consensus node.
+func SetRequestDelay(dur time.Duration) {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	throttle = newRequestThrottle(throttle.maxConcurrent, dur)
+}
+
+func GetRequestDelay() time.Duration {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	return throttle.delay
+}
+
+func newRequestThrottle(maxConcurrent int, delay time.Duration) *requestThrottle {
+	t := &requestThrottle{maxConcurrent: maxConcurrent, delay: delay}
+	if maxConcurrent > 0 {
+		t.sem = make(chan struct{}, maxConcurrent)
+	}
+	return t
+}
+
+// acquireRequest blocks until a request to the consensus node may be started, the returned function has to be called
+// when the request is done.
+func acquireRequest(ctx context.Context) (func(), error) {
+	throttleMu.Lock()
+	t := throttle
+	throttleMu.Unlock()
+
+	release := func() {}
+	if t.sem != nil {
+		select {
+		case t.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-t.sem }
+	}
+	if t.delay > 0 {
+		t.mu.Lock()
+		now := time.Now()
+		start := t.next
+		if start.Before(now) {
+			start = now
+		}
+		t.next = start.Add(t.delay)
+		t.mu.Unlock()
+		select {
+		case <-time.After(start.Sub(now)):
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// TimeWindow is a daily time window in UTC, windows that end before they start span midnight.
+type TimeWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseTimeWindows parses comma-separated daily time windows in UTC, e.g. "22:00-06:00,12:00-13:30".
+func ParseTimeWindows(windowsStr string) ([]TimeWindow, error) {
+	var windows []TimeWindow
+	for _, w := range strings.Split(windowsStr, ",") {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		parts := strings.Split(w, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid time window %q, expected format \"hh:mm-hh:mm\"", w)
+		}
+		start, err := time.Parse("15:04", parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of time window %q: %w", w, err)
+		}
+		end, err := time.Parse("15:04", parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of time window %q: %w", w, err)
+		}
+		windows = append(windows, TimeWindow{
+			Start: time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+			End:   time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+		})
+	}
+	return windows, nil
+}
+
+// UntilWindow returns how long it takes from t until one of the windows is open, it is 0 if a window is open at t or
+// if there are no windows.
+func UntilWindow(t time.Time, windows []TimeWindow) time.Duration {
+	if len(windows) == 0 {
+		return 0
+	}
+	t = t.UTC()
+	sinceMidnight := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+	until := time.Duration(-1)
+	for _, w := range windows {
+		open := false
+		if w.Start <= w.End {
+			open = sinceMidnight >= w.Start && sinceMidnight < w.End
+		} else {
+			open = sinceMidnight >= w.Start || sinceMidnight < w.End
+		}
+		if open {
+			return 0
+		}
+		d := w.Start - sinceMidnight
+		if d < 0 {
+			d += 24 * time.Hour
+		}
+		if until < 0 || d < until {
+			until = d
+		}
+	}
+	return until
+}
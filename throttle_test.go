@@ -0,0 +1,106 @@
+package ethstore
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireRequest(t *testing.T) {
+	defer SetMaxConcurrentRequests(GetMaxConcurrentRequests())
+	defer SetRequestDelay(GetRequestDelay())
+
+	SetMaxConcurrentRequests(2)
+	SetRequestDelay(0)
+	running, maxRunning := int32(0), int32(0)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := acquireRequest(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * 5)
+			atomic.AddInt32(&running, -1)
+			release()
+		}()
+	}
+	wg.Wait()
+	if maxRunning != 2 {
+		t.Errorf("wrong number of concurrent requests: %v != %v", maxRunning, 2)
+	}
+
+	SetMaxConcurrentRequests(0)
+	SetRequestDelay(time.Millisecond * 20)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := acquireRequest(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if d := time.Since(start); d < time.Millisecond*40 {
+		t.Errorf("requests were not delayed: %v", d)
+	}
+
+	SetMaxConcurrentRequests(1)
+	release, err := acquireRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, err := acquireRequest(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestTimeWindows(t *testing.T) {
+	windows, err := ParseTimeWindows("22:00-06:00, 12:00-13:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[1].Start != 12*time.Hour || windows[1].End != 13*time.Hour+30*time.Minute {
+		t.Fatalf("wrong windows: %+v", windows)
+	}
+	tests := []struct {
+		time  string
+		until time.Duration
+	}{
+		{"23:00", 0},
+		{"03:00", 0},
+		{"06:00", 6 * time.Hour},
+		{"12:30", 0},
+		{"13:30", 8*time.Hour + 30*time.Minute},
+		{"21:59", time.Minute},
+	}
+	for _, tt := range tests {
+		clock, _ := time.Parse("15:04", tt.time)
+		at := time.Date(2022, 4, 12, clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+		if until := UntilWindow(at, windows); until != tt.until {
+			t.Errorf("wrong duration until window at %v: %v != %v", tt.time, until, tt.until)
+		}
+	}
+	if UntilWindow(time.Now(), nil) != 0 {
+		t.Errorf("expected no wait without windows")
+	}
+	for _, w := range []string{"22:00", "22:00-25:00", "a-b"} {
+		if _, err := ParseTimeWindows(w); err == nil {
+			t.Errorf("expected error for window %q", w)
+		}
+	}
+}
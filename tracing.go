@@ -0,0 +1,61 @@
+package ethstore
+
+import (
+	"context"
+	"sync"
+)
+
+var tracer Tracer = noopTracer{}
+var tracerMu = sync.Mutex{}
+
+// Tracer starts the spans of the calculation pipeline, it mirrors the tracer of OpenTelemetry so that a tracer
+// provider can be injected with a small adapter, e.g.:
+//
+//	func (t otelTracer) Start(ctx context.Context, name string, attributes map[string]string) (context.Context, ethstore.Span) {
+//		ctx, span := t.tracer.Start(ctx, name)
+//		for k, v := range attributes {
+//			span.SetAttributes(attribute.String(k, v))
+//		}
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span)
+}
+
+type Span interface {
+	RecordError(err error)
+	End()
+}
+
+// SetTracer sets the tracer of the calculation pipeline, nil disables tracing.
+func SetTracer(t Tracer) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	if t == nil {
+		t = noopTracer{}
+	}
+	tracer = t
+}
+
+func GetTracer() Tracer {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	return tracer
+}
+
+// StartSpan starts a span with the configured tracer.
+func StartSpan(ctx context.Context, name string, attributes map[string]string) (context.Context, Span) {
+	return GetTracer().Start(ctx, name, attributes)
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) RecordError(err error) {}
+
+func (noopSpan) End() {}
@@ -0,0 +1,78 @@
+package ethstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans map[string]int
+	ended map[string]int
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans[name]++
+	return ctx, &recordingSpan{tracer: t, name: name}
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	name   string
+}
+
+func (s *recordingSpan) RecordError(err error) {}
+
+func (s *recordingSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.ended[s.name]++
+}
+
+func TestTracing(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	tracer := &recordingTracer{spans: map[string]int{}, ended: map[string]int{}}
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	_, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{
+		"ethstore.Calculate":  1,
+		"ethstore.validators": 1,
+		"ethstore.blocks":     225,
+		"ethstore.compute":    1,
+	}
+	for name, count := range expected {
+		if tracer.spans[name] != count {
+			t.Errorf("wrong number of %v spans: %v != %v", name, tracer.spans[name], count)
+		}
+	}
+	// the spans of the last epochs might still be ending
+	for i := 0; i < 100; i++ {
+		tracer.mu.Lock()
+		ended := tracer.ended["ethstore.blocks"]
+		tracer.mu.Unlock()
+		if ended == 225 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	for name, count := range tracer.spans {
+		if tracer.ended[name] != count {
+			t.Errorf("not all %v spans ended: %v != %v", name, tracer.ended[name], count)
+		}
+	}
+}
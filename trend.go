@@ -0,0 +1,92 @@
+package ethstore
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// TrendSummary holds trend statistics of the apr over a range of days.
+type TrendSummary struct {
+	FromDay         decimal.Decimal `json:"fromDay"`
+	ToDay           decimal.Decimal `json:"toDay"`
+	Days            decimal.Decimal `json:"days"`
+	MeanApr         decimal.Decimal `json:"meanApr"`
+	SlopePerDay     decimal.Decimal `json:"slopePerDay"`
+	Volatility      decimal.Decimal `json:"volatility"`
+	SmoothingWindow decimal.Decimal `json:"smoothingWindow"`
+	MaxDrawdown     decimal.Decimal `json:"maxDrawdown"`
+}
+
+// Trend computes the trend statistics of the apr of the given days: the slope of the least-squares regression line of
+// the apr per day, the volatility (standard deviation) of the daily apr and the largest relative decline of the apr
+// smoothed with a moving average over the given number of days from a previous peak.
+func Trend(days []*Day, smoothingWindow int) (*TrendSummary, error) {
+	if len(days) < 2 {
+		return nil, fmt.Errorf("at least 2 days are required to compute a trend, got %v", len(days))
+	}
+	if smoothingWindow < 1 {
+		return nil, fmt.Errorf("invalid smoothing window: %v", smoothingWindow)
+	}
+	sorted := append([]*Day{}, days...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Day.LessThan(sorted[j].Day) })
+
+	xs := make([]float64, len(sorted))
+	aprs := make([]float64, len(sorted))
+	meanX, meanApr := 0.0, 0.0
+	for i, d := range sorted {
+		xs[i] = d.Day.InexactFloat64()
+		aprs[i] = d.Apr.InexactFloat64()
+		meanX += xs[i]
+		meanApr += aprs[i]
+	}
+	n := float64(len(sorted))
+	meanX /= n
+	meanApr /= n
+
+	covariance, varianceX, varianceApr := 0.0, 0.0, 0.0
+	for i := range sorted {
+		covariance += (xs[i] - meanX) * (aprs[i] - meanApr)
+		varianceX += (xs[i] - meanX) * (xs[i] - meanX)
+		varianceApr += (aprs[i] - meanApr) * (aprs[i] - meanApr)
+	}
+	slope := 0.0
+	if varianceX > 0 {
+		slope = covariance / varianceX
+	}
+
+	// the first smoothed value is the average of the first smoothingWindow days
+	maxDrawdown, peak := 0.0, math.Inf(-1)
+	windowSum := 0.0
+	for i := range aprs {
+		windowSum += aprs[i]
+		if i >= smoothingWindow {
+			windowSum -= aprs[i-smoothingWindow]
+		}
+		if i < smoothingWindow-1 {
+			continue
+		}
+		smoothed := windowSum / float64(smoothingWindow)
+		if smoothed > peak {
+			peak = smoothed
+		}
+		if peak > 0 {
+			if drawdown := (peak - smoothed) / peak; drawdown > maxDrawdown {
+				maxDrawdown = drawdown
+			}
+		}
+	}
+
+	return &TrendSummary{
+		FromDay:         sorted[0].Day,
+		ToDay:           sorted[len(sorted)-1].Day,
+		Days:            decimal.NewFromInt(int64(len(sorted))),
+		MeanApr:         decimal.NewFromFloat(meanApr),
+		SlopePerDay:     decimal.NewFromFloat(slope),
+		Volatility:      decimal.NewFromFloat(math.Sqrt(varianceApr / (n - 1))),
+		SmoothingWindow: decimal.NewFromInt(int64(smoothingWindow)),
+		MaxDrawdown:     decimal.NewFromFloat(maxDrawdown),
+	}, nil
+}
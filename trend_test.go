@@ -0,0 +1,52 @@
+package ethstore
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTrend(t *testing.T) {
+	days := func(aprs ...float64) []*Day {
+		res := make([]*Day, len(aprs))
+		// reversed to check that the days are sorted
+		for i, apr := range aprs {
+			res[len(aprs)-1-i] = &Day{Day: decimal.NewFromInt(int64(100 + i)), Apr: decimal.NewFromFloat(apr)}
+		}
+		return res
+	}
+	almostEqual := func(d decimal.Decimal, f float64) bool {
+		return math.Abs(d.InexactFloat64()-f) < 1e-12
+	}
+
+	s, err := Trend(days(0.04, 0.041, 0.042, 0.043, 0.044), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.FromDay.Equal(decimal.NewFromInt(100)) || !s.ToDay.Equal(decimal.NewFromInt(104)) || !s.Days.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("wrong range: %+v", s)
+	}
+	if !almostEqual(s.SlopePerDay, 0.001) || !almostEqual(s.MeanApr, 0.042) || !almostEqual(s.MaxDrawdown, 0) {
+		t.Errorf("wrong trend of rising apr: %+v", s)
+	}
+	if !almostEqual(s.Volatility, math.Sqrt(0.0000025)) {
+		t.Errorf("wrong volatility: %v", s.Volatility)
+	}
+
+	// the smoothed apr is 0.05, 0.05, 0.04, 0.045
+	s, err = Trend(days(0.05, 0.05, 0.05, 0.03, 0.06), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(s.MaxDrawdown, 0.2) {
+		t.Errorf("wrong drawdown: %v != %v", s.MaxDrawdown, 0.2)
+	}
+
+	if _, err := Trend(days(0.05), 1); err == nil {
+		t.Errorf("expected error for a single day")
+	}
+	if _, err := Trend(days(0.05, 0.05), 0); err == nil {
+		t.Errorf("expected error for invalid smoothing window")
+	}
+}
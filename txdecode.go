@@ -0,0 +1,82 @@
+package ethstore
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+)
+
+// maxTxDecodeErrorExamples is the maximum number of example slots of TxDecodeErrors.
+const maxTxDecodeErrorExamples = 10
+
+// TxDecodeErrors counts the transactions of a day that could not be decoded or have a type that is unknown to this
+// version. The fees of these transactions are still accounted, their hash is taken from the raw transaction.
+type TxDecodeErrors struct {
+	// DecodeErrors is the number of transactions of execution payloads that could not be decoded.
+	DecodeErrors decimal.Decimal `json:"decodeErrors"`
+	// UnexpectedTypes is the number of receipts per unknown tx type, e.g. "0x3".
+	UnexpectedTypes map[string]decimal.Decimal `json:"unexpectedTypes,omitempty"`
+	Slots           decimal.Decimal            `json:"slots"`
+	// ExampleSlots are the first slots of the day with errors.
+	ExampleSlots []uint64 `json:"exampleSlots"`
+}
+
+var knownTxTypes = map[uint64]bool{
+	gethTypes.LegacyTxType:     true,
+	gethTypes.AccessListTxType: true,
+	gethTypes.DynamicFeeTxType: true,
+}
+
+// unexpectedTxTypes counts the receipts per tx type that is unknown to this version.
+func unexpectedTxTypes(receipts []*TxReceipt) map[string]int64 {
+	unexpectedTypes := map[string]int64{}
+	for _, r := range receipts {
+		if !knownTxTypes[uint64(r.Type)] {
+			unexpectedTypes[r.Type.String()]++
+		}
+	}
+	return unexpectedTypes
+}
+
+// add records the decode errors and the receipts with unknown tx types (see unexpectedTxTypes) of the block at the
+// given slot.
+func (e *TxDecodeErrors) add(slot uint64, decodeErrors int, unexpectedTypes map[string]int64) {
+	if decodeErrors == 0 && len(unexpectedTypes) == 0 {
+		return
+	}
+	log.Printf("block at slot %v has %v transactions that could not be decoded and receipts of unexpected tx types: %v", slot, decodeErrors, unexpectedTypes)
+
+	e.DecodeErrors = e.DecodeErrors.Add(decimal.NewFromInt(int64(decodeErrors)))
+	for t, n := range unexpectedTypes {
+		if e.UnexpectedTypes == nil {
+			e.UnexpectedTypes = map[string]decimal.Decimal{}
+		}
+		e.UnexpectedTypes[t] = e.UnexpectedTypes[t].Add(decimal.NewFromInt(n))
+	}
+	e.Slots = e.Slots.Add(decimal.NewFromInt(1))
+	// blocks are processed concurrently, keeping the first slots makes the examples independent of the order
+	e.ExampleSlots = append(e.ExampleSlots, slot)
+	sort.Slice(e.ExampleSlots, func(i, j int) bool { return e.ExampleSlots[i] < e.ExampleSlots[j] })
+	if len(e.ExampleSlots) > maxTxDecodeErrorExamples {
+		e.ExampleSlots = e.ExampleSlots[:maxTxDecodeErrorExamples]
+	}
+}
+
+func (e *TxDecodeErrors) String() string {
+	return fmt.Sprintf("%v decode errors, unexpected types: %v in %v slots (e.g. %v)", e.DecodeErrors, e.UnexpectedTypes, e.Slots, e.ExampleSlots)
+}
+
+func (e *TxDecodeErrors) clone() *TxDecodeErrors {
+	c := *e
+	c.ExampleSlots = append([]uint64{}, e.ExampleSlots...)
+	if e.UnexpectedTypes != nil {
+		c.UnexpectedTypes = make(map[string]decimal.Decimal, len(e.UnexpectedTypes))
+		for t, n := range e.UnexpectedTypes {
+			c.UnexpectedTypes[t] = n
+		}
+	}
+	return &c
+}
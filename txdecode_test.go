@@ -0,0 +1,48 @@
+package ethstore
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+func TestExecutionBlockWithUndecodableTx(t *testing.T) {
+	// a transaction of an unknown type is hashed from its raw encoding instead of failing the block
+	tx := bellatrix.Transaction{0x05, 0xc0}
+	exec := executionBlockFromPayload(&bellatrix.ExecutionPayload{Transactions: []bellatrix.Transaction{tx}}, nil)
+	if exec.TxDecodeErrors != 1 {
+		t.Errorf("wrong number of decode errors: %v", exec.TxDecodeErrors)
+	}
+	if len(exec.TxHashes) != 1 || exec.TxHashes[0] != crypto.Keccak256Hash(tx) {
+		t.Errorf("wrong tx hashes: %v", exec.TxHashes)
+	}
+}
+
+func TestTxDecodeErrors(t *testing.T) {
+	e := &TxDecodeErrors{}
+	e.add(100, 0, unexpectedTxTypes([]*TxReceipt{{Type: 0}, {Type: 2}}))
+	if e.Slots.IsPositive() {
+		t.Fatalf("unexpected errors of a block with known tx types: %v", e)
+	}
+	for slot := uint64(120); slot > 100; slot-- {
+		e.add(slot, 1, unexpectedTxTypes([]*TxReceipt{{Type: 2}, {Type: hexutil.Uint64(3)}}))
+	}
+	if !e.DecodeErrors.Equal(decimal.NewFromInt(20)) || !e.Slots.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("wrong number of decode errors or slots: %v", e)
+	}
+	if n := e.UnexpectedTypes["0x3"]; !n.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("wrong number of unexpected tx types: %v", e.UnexpectedTypes)
+	}
+	if len(e.ExampleSlots) != maxTxDecodeErrorExamples || e.ExampleSlots[0] != 101 || e.ExampleSlots[maxTxDecodeErrorExamples-1] != 110 {
+		t.Errorf("wrong example slots: %v", e.ExampleSlots)
+	}
+
+	c := e.clone()
+	c.add(1, 1, nil)
+	if e.ExampleSlots[0] != 101 || !e.DecodeErrors.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("clone modified the original: %v", e)
+	}
+}
@@ -0,0 +1,117 @@
+package ethstore
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// transferGas is the gas used by a plain eth transfer to an account without code.
+const transferGas = 21000
+
+var txFeeBreakdown bool
+var txFeeBreakdownMu = sync.Mutex{}
+
+// SetTxFeeBreakdown enables splitting the tx fees of the blocks of the eth.store validators by the type of their
+// transactions (legacy, access list, dynamic fee, blob) and by their kind (deployment, transfer, contract call).
+//
+// Deprecated: use WithTxFeeBreakdown.
+func SetTxFeeBreakdown(enabled bool) {
+	txFeeBreakdownMu.Lock()
+	defer txFeeBreakdownMu.Unlock()
+	txFeeBreakdown = enabled
+}
+
+func GetTxFeeBreakdown() bool {
+	txFeeBreakdownMu.Lock()
+	defer txFeeBreakdownMu.Unlock()
+	return txFeeBreakdown
+}
+
+// TxFees is the number of transactions and the sum of their priority fees in a category of a TxFeeBreakdown.
+type TxFees struct {
+	Transactions decimal.Decimal `json:"transactions"`
+	FeesWei      decimal.Decimal `json:"feesWei"`
+}
+
+// TxFeeBreakdown splits the tx fees of the blocks of the eth.store validators by tx type and by a heuristic of the kind
+// of the transactions: a transaction without recipient deploys a contract, a transaction that uses exactly 21000 gas is
+// a plain transfer and any other transaction calls a contract. The fees of each split add up to TxFeesSumWei of the day.
+type TxFeeBreakdown struct {
+	ByType map[string]TxFees `json:"byType"`
+	ByKind map[string]TxFees `json:"byKind"`
+}
+
+var txTypeNames = map[uint64]string{
+	0: "legacy",
+	1: "accessList",
+	2: "dynamicFee",
+	3: "blob",
+	4: "setCode",
+}
+
+func txTypeName(t uint64) string {
+	if name, exists := txTypeNames[t]; exists {
+		return name
+	}
+	return fmt.Sprintf("%#x", t)
+}
+
+func txKind(r *TxReceipt) string {
+	switch {
+	case r.ContractAddress != nil || r.To == nil:
+		return "deployment"
+	case r.GasUsed == transferGas:
+		return "transfer"
+	default:
+		return "contractCall"
+	}
+}
+
+// add adds the priority fees of the receipts of a block with the given base fee.
+func (b *TxFeeBreakdown) add(receipts []*TxReceipt, baseFeePerGas *big.Int) {
+	for _, r := range receipts {
+		priorityFeePerGas := new(big.Int).Sub(r.EffectiveGasPrice.ToInt(), baseFeePerGas)
+		fee := decimal.NewFromBigInt(new(big.Int).Mul(priorityFeePerGas, new(big.Int).SetUint64(uint64(r.GasUsed))), 0)
+		for _, split := range []struct {
+			fees map[string]TxFees
+			key  string
+		}{{b.ByType, txTypeName(uint64(r.Type))}, {b.ByKind, txKind(r)}} {
+			f := split.fees[split.key]
+			f.Transactions = f.Transactions.Add(decimal.NewFromInt(1))
+			f.FeesWei = f.FeesWei.Add(fee)
+			split.fees[split.key] = f
+		}
+	}
+}
+
+func (b *TxFeeBreakdown) String() string {
+	format := func(fees map[string]TxFees) string {
+		keys := make([]string, 0, len(fees))
+		for k := range fees {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%v: %v (%v txs)", k, fees[k].FeesWei, fees[k].Transactions))
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprintf("byType: %v, byKind: %v", format(b.ByType), format(b.ByKind))
+}
+
+func (b *TxFeeBreakdown) clone() *TxFeeBreakdown {
+	c := &TxFeeBreakdown{ByType: make(map[string]TxFees, len(b.ByType)), ByKind: make(map[string]TxFees, len(b.ByKind))}
+	for k, f := range b.ByType {
+		c.ByType[k] = f
+	}
+	for k, f := range b.ByKind {
+		c.ByKind[k] = f
+	}
+	return c
+}
@@ -0,0 +1,78 @@
+package ethstore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shopspring/decimal"
+)
+
+func TestTxFeeBreakdown(t *testing.T) {
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	contract := common.HexToAddress("0xc3511006c04ef1d78af4c8e0e74ec18a6e64ff9e")
+	receipt := func(txType, gasUsed uint64, to, contractAddress *common.Address) *TxReceipt {
+		return &TxReceipt{Type: hexutil.Uint64(txType), GasUsed: hexutil.Uint64(gasUsed), EffectiveGasPrice: (*hexutil.Big)(big.NewInt(15)), To: to, ContractAddress: contractAddress}
+	}
+	b := &TxFeeBreakdown{ByType: map[string]TxFees{}, ByKind: map[string]TxFees{}}
+	b.add([]*TxReceipt{
+		receipt(0, transferGas, &to, nil),
+		receipt(2, 50000, &to, nil),
+		receipt(2, 100000, nil, &contract),
+		receipt(3, 30000, &to, nil),
+		receipt(5, 40000, &to, nil),
+	}, big.NewInt(10))
+
+	// every transaction pays a priority fee of 5 wei per gas
+	expected := []struct {
+		fees         map[string]TxFees
+		key          string
+		transactions int64
+		gasUsed      int64
+	}{
+		{b.ByType, "legacy", 1, transferGas},
+		{b.ByType, "dynamicFee", 2, 150000},
+		{b.ByType, "blob", 1, 30000},
+		{b.ByType, "0x5", 1, 40000},
+		{b.ByKind, "transfer", 1, transferGas},
+		{b.ByKind, "deployment", 1, 100000},
+		{b.ByKind, "contractCall", 3, 120000},
+	}
+	for _, e := range expected {
+		f := e.fees[e.key]
+		if !f.Transactions.Equal(decimal.NewFromInt(e.transactions)) || !f.FeesWei.Equal(decimal.NewFromInt(5*e.gasUsed)) {
+			t.Errorf("wrong fees of %v: %+v", e.key, f)
+		}
+	}
+	if len(b.ByType) != 4 || len(b.ByKind) != 3 {
+		t.Errorf("wrong categories: %v", b)
+	}
+}
+
+func TestTxFeeBreakdownOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	SetTxFeeBreakdown(true)
+	defer SetTxFeeBreakdown(false)
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := day.TxFeeBreakdown
+	if b == nil {
+		t.Fatal("no tx fee breakdown")
+	}
+	// all mocked transactions are dynamic fee contract calls with a priority fee of 90 wei per gas, the gas used of the
+	// mocked receipts does not add up to the gas used of their blocks, so the fees differ from the tx fees of the day
+	f, exists := b.ByType["dynamicFee"]
+	if !exists || len(b.ByType) != 1 || len(b.ByKind) != 1 || !b.ByKind["contractCall"].FeesWei.Equal(f.FeesWei) {
+		t.Fatalf("wrong categories: %v", b)
+	}
+	if expected := f.Transactions.Mul(decimal.NewFromInt(90 * (1e11 + 23080))); !f.FeesWei.Equal(expected) || !f.Transactions.Equal(day.ProposalsActual) {
+		t.Errorf("wrong fees: %v (%v txs) != %v (%v txs)", f.FeesWei, f.Transactions, expected, day.ProposalsActual)
+	}
+}
@@ -0,0 +1,94 @@
+package ethstore
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// txFeeCacheEntry holds the decoded transactions of an execution block and, once the receipts of the block were
+// requested, the fees its transactions paid to the proposer. Entries are replaced and never modified.
+type txFeeCacheEntry struct {
+	exec *executionBlock
+	// txFeesWei is nil if the receipts of the block were not requested yet
+	txFeesWei *big.Int
+	// unexpectedTxTypes are the counts of the receipts with tx types unknown to this version
+	unexpectedTxTypes map[string]int64
+}
+
+var txFeeCache *lru.Cache
+var txFeeCacheSize int
+var txFeeCacheMu = sync.Mutex{}
+
+// SetTxFeeCacheSize sets the number of execution blocks whose decoded transactions and tx fees are kept in memory,
+// keyed by block hash, so that calculating a day again (e.g. with the previous methodology of a transition) neither
+// decodes the transactions nor requests the receipts of its blocks again. A day of mainnet has 7200 blocks. The cache
+// is disabled if blocks is 0.
+func SetTxFeeCacheSize(blocks int) {
+	txFeeCacheMu.Lock()
+	defer txFeeCacheMu.Unlock()
+	txFeeCacheSize = blocks
+	txFeeCache = nil
+	if blocks > 0 {
+		txFeeCache, _ = lru.New(blocks)
+	}
+}
+
+func GetTxFeeCacheSize() int {
+	txFeeCacheMu.Lock()
+	defer txFeeCacheMu.Unlock()
+	return txFeeCacheSize
+}
+
+func getTxFeeCacheEntry(hash common.Hash) *txFeeCacheEntry {
+	txFeeCacheMu.Lock()
+	defer txFeeCacheMu.Unlock()
+	if txFeeCache == nil {
+		return nil
+	}
+	e, found := txFeeCache.Get(hash)
+	if !found {
+		return nil
+	}
+	return e.(*txFeeCacheEntry)
+}
+
+// cachedExecutionBlock returns the decoded execution block with the given hash, decode is called and its result
+// cached if it is not cached.
+func cachedExecutionBlock(hash common.Hash, decode func() (*executionBlock, error)) (*executionBlock, error) {
+	if e := getTxFeeCacheEntry(hash); e != nil {
+		return e.exec, nil
+	}
+	exec, err := decode()
+	if err != nil {
+		return nil, err
+	}
+	txFeeCacheMu.Lock()
+	defer txFeeCacheMu.Unlock()
+	if txFeeCache != nil {
+		txFeeCache.ContainsOrAdd(hash, &txFeeCacheEntry{exec: exec})
+	}
+	return exec, nil
+}
+
+// cachedTxFees returns the cached tx fees of the execution block and the counts of its unexpected tx types, ok is
+// false if the receipts of the block were not requested yet.
+func cachedTxFees(exec *executionBlock) (txFeesWei *big.Int, unexpectedTypes map[string]int64, ok bool) {
+	e := getTxFeeCacheEntry(exec.BlockHash)
+	if e == nil || e.txFeesWei == nil {
+		return nil, nil, false
+	}
+	return new(big.Int).Set(e.txFeesWei), e.unexpectedTxTypes, true
+}
+
+// cacheTxFees caches the tx fees of the execution block that were calculated from its receipts.
+func cacheTxFees(exec *executionBlock, txFeesWei *big.Int, unexpectedTypes map[string]int64) {
+	txFeeCacheMu.Lock()
+	defer txFeeCacheMu.Unlock()
+	if txFeeCache == nil {
+		return
+	}
+	txFeeCache.Add(exec.BlockHash, &txFeeCacheEntry{exec: exec, txFeesWei: new(big.Int).Set(txFeesWei), unexpectedTxTypes: unexpectedTypes})
+}
@@ -0,0 +1,97 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gobitfly/eth.store/fixture"
+)
+
+func TestCachedExecutionBlock(t *testing.T) {
+	defer SetTxFeeCacheSize(0)
+	decodes := 0
+	decode := func() (*executionBlock, error) {
+		decodes++
+		return &executionBlock{BlockHash: common.HexToHash("0x01"), BaseFeePerGas: big.NewInt(7)}, nil
+	}
+	hash := common.HexToHash("0x01")
+
+	// without cache every block is decoded
+	for i := 0; i < 2; i++ {
+		if _, err := cachedExecutionBlock(hash, decode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if decodes != 2 {
+		t.Errorf("wrong number of decodes without cache: %v", decodes)
+	}
+
+	SetTxFeeCacheSize(10)
+	decodes = 0
+	exec, _ := cachedExecutionBlock(hash, decode)
+	if _, _, ok := cachedTxFees(exec); ok {
+		t.Errorf("unexpected tx fees before the receipts were requested")
+	}
+	cacheTxFees(exec, big.NewInt(1000), map[string]int64{"0x5": 1})
+	exec, _ = cachedExecutionBlock(hash, decode)
+	if decodes != 1 {
+		t.Errorf("wrong number of decodes with cache: %v", decodes)
+	}
+	fees, types, ok := cachedTxFees(exec)
+	if !ok || fees.Int64() != 1000 || types["0x5"] != 1 {
+		t.Errorf("wrong cached tx fees: %v %v %v", fees, types, ok)
+	}
+	// the cached fees are not modified by the caller
+	fees.SetInt64(0)
+	if fees, _, _ := cachedTxFees(exec); fees.Int64() != 1000 {
+		t.Errorf("cached tx fees were modified: %v", fees)
+	}
+}
+
+func TestTxFeeCache(t *testing.T) {
+	f, err := fixture.Generate(fixture.Scenario{Day: 10, Validators: 16, ConsensusRewardGwei: 3200000, TxFeeGwei: 10000, BaseFeeGwei: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var receiptRequests int32
+	el := f.ExecutionHandler()
+	elServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if bytes.Contains(body, []byte("eth_getTransactionReceipt")) {
+			atomic.AddInt32(&receiptRequests, 1)
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		el.ServeHTTP(w, r)
+	}))
+	defer elServer.Close()
+	bnServer := httptest.NewServer(f.BeaconHandler())
+	defer bnServer.Close()
+
+	SetTxFeeCacheSize(fixture.SlotsPerDay)
+	defer SetTxFeeCacheSize(0)
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&receiptRequests); n != fixture.SlotsPerDay {
+		t.Errorf("wrong number of receipt requests: %v", n)
+	}
+	atomic.StoreInt32(&receiptRequests, 0)
+	cachedDay, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&receiptRequests); n != 0 {
+		t.Errorf("receipts were requested again for %v blocks", n)
+	}
+	if !cachedDay.TxFeesSumWei.Equal(day.TxFeesSumWei) || !cachedDay.TxFeesSumWei.Equal(f.Expected.TxFeesSumWei) || !cachedDay.Apr.Equal(day.Apr) {
+		t.Errorf("wrong day with cached tx fees: %v %v != %v %v", cachedDay.TxFeesSumWei, cachedDay.Apr, day.TxFeesSumWei, day.Apr)
+	}
+}
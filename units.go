@@ -0,0 +1,168 @@
+package ethstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Unit is a unit of the monetary values of the json output.
+type Unit string
+
+const (
+	UnitWei  Unit = "wei"
+	UnitGwei Unit = "gwei"
+	UnitEth  Unit = "eth"
+)
+
+// unitSuffixes are the suffixes of the json keys of monetary values and the number of wei of their unit.
+var unitSuffixes = []struct {
+	suffix string
+	wei    decimal.Decimal
+}{
+	{"Gwei", decimal.New(1, 9)},
+	{"Eth", decimal.New(1, 18)},
+	{"Wei", decimal.New(1, 0)},
+}
+
+// ParseUnit parses the unit "wei", "gwei" or "eth".
+func ParseUnit(unitStr string) (Unit, error) {
+	switch u := Unit(strings.ToLower(unitStr)); u {
+	case UnitWei, UnitGwei, UnitEth:
+		return u, nil
+	default:
+		return "", fmt.Errorf("unknown unit %q, expected \"wei\", \"gwei\" or \"eth\"", unitStr)
+	}
+}
+
+func (u Unit) suffix() string {
+	switch u {
+	case UnitGwei:
+		return "Gwei"
+	case UnitEth:
+		return "Eth"
+	default:
+		return "Wei"
+	}
+}
+
+func (u Unit) wei() decimal.Decimal {
+	switch u {
+	case UnitGwei:
+		return decimal.New(1, 9)
+	case UnitEth:
+		return decimal.New(1, 18)
+	default:
+		return decimal.New(1, 0)
+	}
+}
+
+// MarshalJSONInUnit marshals v to json and expresses all monetary values in the given unit, e.g. consensusRewardsGwei
+// and consensusRewardsWei both become consensusRewardsEth. Values that are given in wei take precedence over the same
+// value in gwei, since they are exact.
+func MarshalJSONInUnit(v interface{}, u Unit, indent string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	tree, err = convertUnits(tree, u)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(tree, "", indent)
+}
+
+// convertUnits converts the monetary values of the decoded json value, numbers must be decoded as json.Number.
+func convertUnits(v interface{}, u Unit) (interface{}, error) {
+	switch vv := v.(type) {
+	case []interface{}:
+		for i := range vv {
+			converted, err := convertUnits(vv[i], u)
+			if err != nil {
+				return nil, err
+			}
+			vv[i] = converted
+		}
+		return vv, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(vv))
+		for key := range vv {
+			keys = append(keys, key)
+		}
+		// keys in wei are converted last, so they overwrite the same values in gwei
+		sort.SliceStable(keys, func(i, j int) bool {
+			return !strings.HasSuffix(keys[i], "Wei") && strings.HasSuffix(keys[j], "Wei")
+		})
+		converted := make(map[string]interface{}, len(vv))
+		for _, key := range keys {
+			value, err := convertUnits(vv[key], u)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range unitSuffixes {
+				if !strings.HasSuffix(key, s.suffix) || value == nil {
+					continue
+				}
+				value, err = convertValue(value, s.wei, u.wei())
+				if err != nil {
+					return nil, fmt.Errorf("error converting %v: %w", key, err)
+				}
+				key = strings.TrimSuffix(key, s.suffix) + u.suffix()
+				break
+			}
+			converted[key] = value
+		}
+		return converted, nil
+	default:
+		return v, nil
+	}
+}
+
+// convertValue converts a monetary value from one unit to another, both given in wei. Values are kept as strings or
+// numbers, lists and maps of values are converted element-wise. The precision is one wei.
+func convertValue(v interface{}, from, to decimal.Decimal) (interface{}, error) {
+	switch vv := v.(type) {
+	case string:
+		d, err := decimal.NewFromString(vv)
+		if err != nil {
+			return nil, err
+		}
+		return d.Mul(from).DivRound(to, 18).String(), nil
+	case json.Number:
+		d, err := decimal.NewFromString(vv.String())
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(d.Mul(from).DivRound(to, 18).String()), nil
+	case []interface{}:
+		for i := range vv {
+			converted, err := convertValue(vv[i], from, to)
+			if err != nil {
+				return nil, err
+			}
+			vv[i] = converted
+		}
+		return vv, nil
+	case map[string]interface{}:
+		for key := range vv {
+			converted, err := convertValue(vv[key], from, to)
+			if err != nil {
+				return nil, err
+			}
+			vv[key] = converted
+		}
+		return vv, nil
+	default:
+		return nil, fmt.Errorf("not a monetary value: %v", v)
+	}
+}
@@ -0,0 +1,56 @@
+package ethstore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMarshalJSONInUnit(t *testing.T) {
+	penalties := decimal.NewFromInt(5)
+	d := &Day{
+		Day:                     decimal.NewFromInt(10),
+		Apr:                     decimal.NewFromFloat(0.05),
+		ConsensusRewardsGwei:    decimal.RequireFromString("1.5"),
+		ConsensusRewardsWei:     decimal.NewFromInt(1500000001),
+		TxFeesSumWei:            decimal.NewFromInt(1),
+		InactivityPenaltiesGwei: &penalties,
+		DepositMismatches:       []DepositMismatch{{Slot: 1, AmountGwei: 32e9}},
+	}
+	tests := []struct {
+		unit     Unit
+		expected map[string]string
+	}{
+		{UnitEth, map[string]string{"consensusRewardsEth": "0.000000001500000001", "txFeesSumEth": "0.000000000000000001", "inactivityPenaltiesEth": "0.000000005", "effectiveBalanceEth": "0", "apr": "0.05"}},
+		{UnitGwei, map[string]string{"consensusRewardsGwei": "1.500000001", "txFeesSumGwei": "0.000000001", "inactivityPenaltiesGwei": "5"}},
+		{UnitWei, map[string]string{"consensusRewardsWei": "1500000001", "txFeesSumWei": "1", "inactivityPenaltiesWei": "5000000000"}},
+	}
+	for _, tt := range tests {
+		data, err := MarshalJSONInUnit(d, tt.unit, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res map[string]interface{}
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatal(err)
+		}
+		for key, value := range tt.expected {
+			if res[key] != value {
+				t.Errorf("wrong %v in %v: %v != %v", key, tt.unit, res[key], value)
+			}
+		}
+		for _, key := range []string{"consensusRewardsGwei", "consensusRewardsWei", "consensusRewardsEth"} {
+			if _, exists := tt.expected[key]; !exists && res[key] != nil {
+				t.Errorf("unexpected %v in %v", key, tt.unit)
+			}
+		}
+		mismatch := res["depositMismatches"].([]interface{})[0].(map[string]interface{})
+		if amount := mismatch["amount"+tt.unit.suffix()]; amount == nil {
+			t.Errorf("no amount of deposit mismatch in %v: %v", tt.unit, mismatch)
+		}
+	}
+	if _, err := ParseUnit("ether"); err == nil {
+		t.Errorf("expected error of unknown unit")
+	}
+}
@@ -0,0 +1,288 @@
+package ethstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// maxValidatorsChunkSize is the largest number of indices requested at once, the ids of more indices would exceed the
+// length of the url that beacon nodes accept.
+const maxValidatorsChunkSize = 1000
+const minValidatorsChunkSize = 10
+
+// validatorsChunkSizes holds the size of the chunks the validators are requested in for the beacon nodes that do not
+// respond with all validators at once, keyed by address.
+var validatorsChunkSizes = map[string]int{}
+var validatorsChunkSizesMu = &sync.Mutex{}
+
+func getValidatorsChunkSize(address string) int {
+	validatorsChunkSizesMu.Lock()
+	defer validatorsChunkSizesMu.Unlock()
+	return validatorsChunkSizes[address]
+}
+
+func setValidatorsChunkSize(address string, size int) {
+	validatorsChunkSizesMu.Lock()
+	defer validatorsChunkSizesMu.Unlock()
+	validatorsChunkSizes[address] = size
+}
+
+// requestValidators requests all validators of the state. Some beacon nodes fail to respond with all validators or cap
+// the size of the response, then the validators are requested in chunks of index ranges and the chunk size is used for
+// the following requests to the node.
+func requestValidators(ctx context.Context, client BeaconClient, stateID string) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+	address := client.Address()
+	if _, ok := client.(*HTTPBeaconClient); ok && calculateOptionsOf(ctx).getSSZStates() && !calculateOptionsOf(ctx).getLowMemory() && getContentType(address, sszStateEndpoint) != contentTypeJSON {
+		vals, err := requestValidatorsSSZ(ctx, client, stateID)
+		if err == nil {
+			return vals, nil
+		}
+		if ctx.Err() != nil || errors.Is(err, ErrDecodeTimeout) {
+			return nil, fmt.Errorf("error getting state for slot %v: %w", stateID, err)
+		}
+		log.Printf("error getting state for slot %v as ssz, requesting the validators as json: %v", stateID, err)
+		if errors.Is(err, errContentType) {
+			setContentType(address, sszStateEndpoint, contentTypeJSON)
+		}
+	}
+	if size := getValidatorsChunkSize(address); size > 0 {
+		return requestValidatorsChunked(ctx, address, stateID, size)
+	}
+	if GetMaxResponseSize() > 0 {
+		// the validators of a whole state exceed any practical maximum response size, chunks stay within it
+		return requestValidatorsChunked(ctx, address, stateID, maxValidatorsChunkSize)
+	}
+
+	var vals map[phase0.ValidatorIndex]*v1.Validator
+	var err error
+	if _, ok := client.(*HTTPBeaconClient); ok {
+		vals, err = requestValidatorsStream(ctx, address, stateID)
+	} else {
+		var release func()
+		release, err = acquireRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		vals, err = client.Validators(ctx, stateID, nil)
+		release()
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("error getting validators for slot %v: %w", stateID, err)
+		}
+		log.Printf("error getting all validators for slot %v, requesting them in chunks: %v", stateID, err)
+		return requestValidatorsChunked(ctx, address, stateID, maxValidatorsChunkSize)
+	}
+
+	// a capped response holds the validators with the lowest indices, it is complete if there is no validator with the
+	// next index
+	next, err := requestValidatorsRange(ctx, address, stateID, uint64(len(vals)), 1)
+	if err != nil {
+		if calculateOptionsOf(ctx).getDebugLevel() > 0 {
+			log.Printf("DEBUG eth.store: error checking if the validators for slot %v are complete: %v", stateID, err)
+		}
+		return vals, nil
+	}
+	if len(next) > 0 {
+		log.Printf("response of validators for slot %v is capped at %v validators, requesting them in chunks", stateID, len(vals))
+		size := len(vals)
+		if size > maxValidatorsChunkSize {
+			size = maxValidatorsChunkSize
+		}
+		return requestValidatorsChunked(ctx, address, stateID, size)
+	}
+	return vals, nil
+}
+
+// requestValidatorsStream requests all validators of the state as json and decodes them one at a time while the
+// response is read. The validators of mainnet are a response of several hundred MB, decoding it at once holds the
+// response, the decoded response and the map of the validators in memory at the same time.
+func requestValidatorsStream(ctx context.Context, address, stateID string) (vals map[phase0.ValidatorIndex]*v1.Validator, err error) {
+	withRetries(ctx, fmt.Sprintf("validators for slot %v", stateID), func() (int, error) {
+		var status int
+		status, vals, err = requestValidatorsStreamOnce(ctx, address, stateID)
+		return status, err
+	})
+	return vals, err
+}
+
+func requestValidatorsStreamOnce(ctx context.Context, address, stateID string) (int, map[phase0.ValidatorIndex]*v1.Validator, error) {
+	release, err := acquireRequest(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer release()
+	endpoints := failoverEndpoints(address)
+	for i, endpoint := range endpoints {
+		status, vals, err := requestValidatorsStreamEndpoint(ctx, endpoint, stateID)
+		if i == len(endpoints)-1 || !retryOnNextEndpoint(ctx, status, err) {
+			if err == nil {
+				failoverAnswered(address, endpoint)
+			}
+			return status, vals, err
+		}
+		log.Printf("error requesting validators for slot %v from consensus node %v, trying next endpoint: %v", stateID, endpoint, err)
+	}
+	return 0, nil, fmt.Errorf("no endpoints for %v", address)
+}
+
+func requestValidatorsStreamEndpoint(ctx context.Context, endpoint, stateID string) (int, map[phase0.ValidatorIndex]*v1.Validator, error) {
+	ctx, cancel := context.WithTimeout(ctx, calculateOptionsOf(ctx).getConsTimeout())
+	defer cancel()
+	path := fmt.Sprintf("/eth/v1/beacon/states/%s/validators", stateID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+path, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return res.StatusCode, nil, fmt.Errorf("status %v: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+	// the validators are decoded while the response is read, so the size of the response is limited while decoding
+	body, err := limitResponse(path, res.ContentLength, res.Body)
+	if err != nil {
+		return res.StatusCode, nil, err
+	}
+	vals := map[phase0.ValidatorIndex]*v1.Validator{}
+	err = decodeValidatorsStream(body, func(v *v1.Validator) {
+		vals[v.Index] = v
+	})
+	if err != nil {
+		return res.StatusCode, nil, fmt.Errorf("error parsing validators for slot %v: %w", stateID, err)
+	}
+	return res.StatusCode, vals, nil
+}
+
+// decodeValidatorsStream decodes the validators of the data array of a validators response one at a time and passes
+// them to add, the other fields of the response are skipped.
+func decodeValidatorsStream(r io.Reader, add func(v *v1.Validator)) error {
+	dec := json.NewDecoder(r)
+	expectDelim := func(delim json.Delim) error {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if d, ok := t.(json.Delim); !ok || d != delim {
+			return fmt.Errorf("expected %v, got %v", delim, t)
+		}
+		return nil
+	}
+	if err := expectDelim('{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "data" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := expectDelim('['); err != nil {
+			return err
+		}
+		for dec.More() {
+			v := &v1.Validator{}
+			if err := dec.Decode(v); err != nil {
+				return err
+			}
+			add(v)
+		}
+		if err := expectDelim(']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim('}')
+}
+
+// requestValidatorsChunked requests the validators of the state in chunks of consecutive indices until a chunk is
+// empty. The chunk size is halved if the node fails to respond and reduced to the number of validators it responds
+// with if that is smaller, since the node may cap the response.
+func requestValidatorsChunked(ctx context.Context, address, stateID string, size int) (map[phase0.ValidatorIndex]*v1.Validator, error) {
+	if size < minValidatorsChunkSize {
+		size = minValidatorsChunkSize
+	}
+	vals := map[phase0.ValidatorIndex]*v1.Validator{}
+	// confirmed is the largest chunk size the node has responded to completely
+	confirmed := size
+	start := uint64(0)
+	for {
+		chunk, err := requestValidatorsRange(ctx, address, stateID, start, size)
+		if err != nil {
+			if ctx.Err() != nil || size <= minValidatorsChunkSize {
+				return nil, fmt.Errorf("error getting validators %v to %v for slot %v: %w", start, start+uint64(size)-1, stateID, err)
+			}
+			size /= 2
+			confirmed = size
+			if calculateOptionsOf(ctx).getDebugLevel() > 0 {
+				log.Printf("DEBUG eth.store: error getting validators %v for slot %v, reducing chunk size to %v: %v", start, stateID, size, err)
+			}
+			continue
+		}
+		if len(chunk) == 0 {
+			break
+		}
+		for _, v := range chunk {
+			vals[v.Index] = v
+			if uint64(v.Index) >= start {
+				start = uint64(v.Index) + 1
+			}
+		}
+		if len(chunk) == size {
+			confirmed = size
+		} else {
+			// either the last chunk or the node caps the response, the next chunk tells
+			size = len(chunk)
+		}
+	}
+	setValidatorsChunkSize(address, confirmed)
+	if calculateOptionsOf(ctx).getDebugLevel() > 0 {
+		log.Printf("DEBUG eth.store: got %v validators for slot %v in chunks of %v", len(vals), stateID, confirmed)
+	}
+	return vals, nil
+}
+
+// requestValidatorsRange requests the validators with the indices [start, start+size) of the state, validators outside
+// of the range are ignored in case the node ignores the filter.
+func requestValidatorsRange(ctx context.Context, address, stateID string, start uint64, size int) ([]*v1.Validator, error) {
+	ids := make([]string, size)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%d", start+uint64(i))
+	}
+	var res struct {
+		Data []*v1.Validator `json:"data"`
+	}
+	_, err := getBeaconJson(ctx, address, fmt.Sprintf("/eth/v1/beacon/states/%s/validators?id=%s", stateID, strings.Join(ids, ",")), &res)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]*v1.Validator, 0, len(res.Data))
+	for _, v := range res.Data {
+		if v != nil && uint64(v.Index) >= start && uint64(v.Index) < start+uint64(size) {
+			vals = append(vals, v)
+		}
+	}
+	return vals, nil
+}
@@ -0,0 +1,117 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestRequestValidatorsChunked(t *testing.T) {
+	const numValidators = 250
+	validatorJson := func(index int) string {
+		return fmt.Sprintf(`{"index":"%d","balance":"32000000000","status":"active_ongoing","validator":{"pubkey":"%#096x","withdrawal_credentials":"0x%064x","effective_balance":"32000000000","slashed":false,"activation_eligibility_epoch":"0","activation_epoch":"0","exit_epoch":"18446744073709551615","withdrawable_epoch":"18446744073709551615"}}`, index, index, index)
+	}
+	tests := []struct {
+		name string
+		// responseCap is the number of validators the node responds with at most, maxIds the number of ids it accepts
+		responseCap, maxIds int
+		size                int
+		chunkSize           int
+	}{
+		{name: "no limits", size: 100, chunkSize: 100},
+		{name: "capped response", responseCap: 30, size: 100, chunkSize: 30},
+		{name: "limited ids", maxIds: 40, size: 100, chunkSize: 25},
+		{name: "capped response and limited ids", responseCap: 20, maxIds: 60, size: 100, chunkSize: 20},
+	}
+	for _, tt := range tests {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.URL.Path != "/eth/v1/beacon/states/100/validators" {
+				t.Errorf("%v: unexpected request: %v", tt.name, r.URL.Path)
+				return
+			}
+			ids := strings.Split(r.URL.Query().Get("id"), ",")
+			if tt.maxIds > 0 && len(ids) > tt.maxIds {
+				w.WriteHeader(http.StatusRequestURITooLong)
+				return
+			}
+			data := []string{}
+			for _, id := range ids {
+				index, err := strconv.Atoi(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if index < numValidators && (tt.responseCap == 0 || len(data) < tt.responseCap) {
+					data = append(data, validatorJson(index))
+				}
+			}
+			w.Write([]byte(fmt.Sprintf(`{"data":[%s]}`, strings.Join(data, ","))))
+		}))
+
+		vals, err := requestValidatorsChunked(context.Background(), server.URL, "100", tt.size)
+		server.Close()
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if len(vals) != numValidators {
+			t.Errorf("%v: wrong number of validators: %v", tt.name, len(vals))
+		}
+		for i := 0; i < numValidators; i++ {
+			if _, exists := vals[phase0.ValidatorIndex(i)]; !exists {
+				t.Errorf("%v: missing validator %v", tt.name, i)
+				break
+			}
+		}
+		if getValidatorsChunkSize(server.URL) != tt.chunkSize {
+			t.Errorf("%v: wrong chunk size: %v, expected %v", tt.name, getValidatorsChunkSize(server.URL), tt.chunkSize)
+		}
+		if requests > 2*numValidators/tt.chunkSize+5 {
+			t.Errorf("%v: too many requests: %v", tt.name, requests)
+		}
+	}
+
+	// a node that fails to respond to the smallest chunk
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	if _, err := requestValidatorsChunked(context.Background(), server.URL, "100", 100); err == nil {
+		t.Errorf("expected error if the node fails to respond")
+	}
+}
+
+func TestDecodeValidatorsStream(t *testing.T) {
+	validatorJson := func(index int) string {
+		return fmt.Sprintf(`{"index":"%d","balance":"32000000000","status":"active_ongoing","validator":{"pubkey":"%#096x","withdrawal_credentials":"0x%064x","effective_balance":"32000000000","slashed":false,"activation_eligibility_epoch":"0","activation_epoch":"0","exit_epoch":"18446744073709551615","withdrawable_epoch":"18446744073709551615"}}`, index, index, index)
+	}
+	// the fields besides data are skipped, including nested ones
+	response := fmt.Sprintf(`{"execution_optimistic":false,"meta":{"ids":[1,2]},"data":[%s,%s,%s],"finalized":true}`, validatorJson(0), validatorJson(1), validatorJson(7))
+	indices := []phase0.ValidatorIndex{}
+	err := decodeValidatorsStream(strings.NewReader(response), func(v *v1.Validator) {
+		if v.Validator == nil || v.Validator.EffectiveBalance != 32000000000 {
+			t.Errorf("wrong validator: %+v", v)
+		}
+		indices = append(indices, v.Index)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(indices) != "[0 1 7]" {
+		t.Errorf("wrong validators: %v", indices)
+	}
+
+	for _, response := range []string{`[]`, `{"data":{}}`, `{"data":[{"index":"x"}]}`, `{"data":[` + validatorJson(0)} {
+		if err := decodeValidatorsStream(strings.NewReader(response), func(v *v1.Validator) {}); err == nil {
+			t.Errorf("expected error for response %v", response)
+		}
+	}
+}
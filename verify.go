@@ -0,0 +1,134 @@
+package ethstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// InputHash identifies the inputs of the calculation of a day: the methodology version and the roots of the states at
+// the first slot of the day and the first slot of the next day, which commit to all balances and blocks of the day.
+func InputHash(day uint64, methodologyVersion int, startStateRoot, endStateRoot phase0.Root) string {
+	h := sha256.New()
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, day)
+	h.Write(b)
+	binary.BigEndian.PutUint64(b, uint64(methodologyVersion))
+	h.Write(b)
+	h.Write(startStateRoot[:])
+	h.Write(endStateRoot[:])
+	return fmt.Sprintf("%#x", h.Sum(nil))
+}
+
+func getStateRoot(ctx context.Context, client BeaconClient, slot uint64) (phase0.Root, error) {
+	root, err := client.BeaconStateRoot(ctx, fmt.Sprintf("%d", slot))
+	if err != nil {
+		return phase0.Root{}, fmt.Errorf("error getting state root at slot %v: %w", slot, err)
+	}
+	// a response without a root is decoded as the zero root
+	if root == nil || *root == (phase0.Root{}) {
+		return phase0.Root{}, fmt.Errorf("no state root at slot %v", slot)
+	}
+	return *root, nil
+}
+
+func parseRoot(s string) (phase0.Root, error) {
+	var root phase0.Root
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != len(root) {
+		return root, fmt.Errorf("invalid root: %v", s)
+	}
+	copy(root[:], b)
+	return root, nil
+}
+
+// VerifyDay re-derives the input hash of a stored day from its recorded state roots and checks that the stored values
+// are consistent with each other. If bnAddress is not empty the recorded state roots are also compared to the ones of
+// the beacon node. It returns a description of every mismatch.
+func VerifyDay(ctx context.Context, bnAddress string, d *Day) ([]string, error) {
+	mismatches := []string{}
+	day := uint64(d.Day.IntPart())
+
+	if d.Unverified {
+		return append(mismatches, "imported day, recalculate it to verify it"), nil
+	}
+	if d.InputHash == "" {
+		return append(mismatches, "no input hash recorded"), nil
+	}
+	if d.Provenance == nil {
+		mismatches = append(mismatches, "no provenance recorded")
+	} else if d.Provenance.MethodologyVersion != MethodologyVersion {
+		mismatches = append(mismatches, fmt.Sprintf("methodology version %v differs from current methodology version %v", d.Provenance.MethodologyVersion, MethodologyVersion))
+	}
+
+	startStateRoot, err := parseRoot(d.StartStateRoot)
+	if err != nil {
+		return append(mismatches, fmt.Sprintf("invalid start state root: %v", err)), nil
+	}
+	endStateRoot, err := parseRoot(d.EndStateRoot)
+	if err != nil {
+		return append(mismatches, fmt.Sprintf("invalid end state root: %v", err)), nil
+	}
+	methodologyVersion := MethodologyVersion
+	if d.Provenance != nil {
+		methodologyVersion = d.Provenance.MethodologyVersion
+	}
+	if h := InputHash(day, methodologyVersion, startStateRoot, endStateRoot); h != d.InputHash {
+		mismatches = append(mismatches, fmt.Sprintf("input hash %v does not match recorded state roots (%v)", d.InputHash, h))
+	}
+
+	mismatches = append(mismatches, consistencyMismatches(d)...)
+
+	if bnAddress == "" {
+		return mismatches, nil
+	}
+	// days are verified with the boundary they were calculated with
+	boundary, err := dayBoundaryOf(d)
+	if err != nil {
+		return append(mismatches, err.Error()), nil
+	}
+	nodeStartStateRoot, nodeEndStateRoot, err := nodeStateRoots(ctx, bnAddress, day, boundary)
+	if err != nil {
+		return nil, err
+	}
+	if nodeStartStateRoot != startStateRoot {
+		mismatches = append(mismatches, fmt.Sprintf("start state root %v does not match beacon node (%#x)", d.StartStateRoot, nodeStartStateRoot))
+	}
+	if nodeEndStateRoot != endStateRoot {
+		mismatches = append(mismatches, fmt.Sprintf("end state root %v does not match beacon node (%#x)", d.EndStateRoot, nodeEndStateRoot))
+	}
+	return mismatches, nil
+}
+
+// dayBoundaryOf returns the boundary a stored day was calculated with.
+func dayBoundaryOf(d *Day) (DayBoundary, error) {
+	if d.Provenance == nil || d.Provenance.DayBoundary == "" {
+		return GenesisDayBoundary{}, nil
+	}
+	return ParseDayBoundary(d.Provenance.DayBoundary)
+}
+
+// nodeStateRoots returns the state roots of the beacon node at the start and at the end of the given day.
+func nodeStateRoots(ctx context.Context, bnAddress string, day uint64, boundary DayBoundary) (phase0.Root, phase0.Root, error) {
+	client, err := newConsClient(ctx, bnAddress)
+	if err != nil {
+		return phase0.Root{}, phase0.Root{}, err
+	}
+	timing, err := getChainTiming(ctx, client)
+	if err != nil {
+		return phase0.Root{}, phase0.Root{}, err
+	}
+	startStateRoot, err := getStateRoot(ctx, client, boundary.FirstSlot(day, timing))
+	if err != nil {
+		return phase0.Root{}, phase0.Root{}, err
+	}
+	endStateRoot, err := getStateRoot(ctx, client, boundary.FirstSlot(day+1, timing))
+	if err != nil {
+		return phase0.Root{}, phase0.Root{}, err
+	}
+	return startStateRoot, endStateRoot, nil
+}
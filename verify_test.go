@@ -0,0 +1,97 @@
+package ethstore
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestVerifyDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, _, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.InputHash == "" || day.StartStateRoot == "" || day.EndStateRoot == "" {
+		t.Fatalf("no input hash recorded: %+v", day)
+	}
+
+	// days are verified after they have been stored
+	stored := func(modify func(d *Day)) *Day {
+		dayJson, err := json.Marshal(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := &Day{}
+		err = json.Unmarshal(dayJson, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if modify != nil {
+			modify(d)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name      string
+		bnAddress string
+		modify    func(d *Day)
+		mismatch  string
+	}{
+		{name: "valid day", bnAddress: bnServer.URL},
+		{name: "valid day offline"},
+		{name: "legacy day", modify: func(d *Day) { d.InputHash = "" }, mismatch: "no input hash recorded"},
+		{name: "imported day", modify: func(d *Day) { d.Unverified = true }, mismatch: "imported day"},
+		{name: "corrupted input hash", modify: func(d *Day) { d.InputHash = "0x00" }, mismatch: "does not match recorded state roots"},
+		{name: "corrupted state root", modify: func(d *Day) { d.StartStateRoot = "0x01" }, mismatch: "invalid start state root"},
+		{
+			name: "methodology drift",
+			modify: func(d *Day) {
+				d.Provenance.MethodologyVersion = 0
+				root, _ := parseRoot(d.StartStateRoot)
+				endRoot, _ := parseRoot(d.EndStateRoot)
+				d.InputHash = InputHash(10, 0, root, endRoot)
+			},
+			mismatch: "differs from current methodology version",
+		},
+		{name: "corrupted apr", modify: func(d *Day) { d.Apr = d.Apr.Add(decimal.New(1, -9)) }, mismatch: "apr"},
+		{name: "corrupted apr bps", modify: func(d *Day) { d.AprBps = d.Apr.Shift(2) }, mismatch: "aprBps"},
+		{name: "corrupted tx fees", modify: func(d *Day) { d.TxFeesSumWei = d.TxFeesSumWei.Add(decimal.NewFromInt(1)) }, mismatch: "totalRewardsWei"},
+		{name: "corrupted consensus rewards in wei", modify: func(d *Day) { d.ConsensusRewardsWei = d.ConsensusRewardsWei.Add(decimal.NewFromInt(1)) }, mismatch: "consensusRewardsWei"},
+		{name: "corrupted balance", modify: func(d *Day) { d.EndBalanceGwei = d.EndBalanceGwei.Add(decimal.NewFromInt(1)) }, mismatch: "consensusRewardsGwei"},
+		{
+			name:      "reorged state",
+			bnAddress: bnServer.URL,
+			modify: func(d *Day) {
+				d.EndStateRoot = "0x0000000000000000000000000000000000000000000000000000000000000001"
+				root, _ := parseRoot(d.StartStateRoot)
+				endRoot, _ := parseRoot(d.EndStateRoot)
+				d.InputHash = InputHash(10, MethodologyVersion, root, endRoot)
+			},
+			mismatch: "end state root",
+		},
+	}
+	for _, tt := range tests {
+		mismatches, err := VerifyDay(context.Background(), tt.bnAddress, stored(tt.modify))
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if tt.mismatch == "" {
+			if len(mismatches) > 0 {
+				t.Errorf("%v: unexpected mismatches: %v", tt.name, mismatches)
+			}
+			continue
+		}
+		if len(mismatches) != 1 || !strings.Contains(mismatches[0], tt.mismatch) {
+			t.Errorf("%v: wrong mismatches: %v (expected: %v)", tt.name, mismatches, tt.mismatch)
+		}
+	}
+}
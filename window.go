@@ -0,0 +1,49 @@
+package ethstore
+
+import (
+	"fmt"
+	"time"
+)
+
+// DayWindow is the window of slots a day has been calculated over, it is reported with every day so that a window
+// that is off by a slot or an epoch shows in the output. The window covers the slots [FirstSlot, LastSlot], StartTime
+// is the start of the first slot and EndTime the end of the last slot in UTC. ExpectedBlocks is the number of slots of
+// the window, each of which has a block unless it is missed. Truncated is set if the window ends at the finalized slot
+// before the end of the day.
+type DayWindow struct {
+	DayBoundary    string    `json:"dayBoundary"`
+	FirstSlot      uint64    `json:"firstSlot"`
+	LastSlot       uint64    `json:"lastSlot"`
+	FirstEpoch     uint64    `json:"firstEpoch"`
+	LastEpoch      uint64    `json:"lastEpoch"`
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	ExpectedBlocks uint64    `json:"expectedBlocks"`
+	Truncated      bool      `json:"truncated,omitempty"`
+}
+
+// newDayWindow returns the window of the slots [firstSlot, endSlot), dayEndSlot is the first slot of the next day.
+func newDayWindow(boundary DayBoundary, firstSlot, endSlot, dayEndSlot uint64, t *ChainTiming) *DayWindow {
+	slotTime := func(slot uint64) time.Time {
+		return t.Genesis.Add(time.Duration(slot*t.SecondsPerSlot) * time.Second).UTC()
+	}
+	return &DayWindow{
+		DayBoundary:    boundary.String(),
+		FirstSlot:      firstSlot,
+		LastSlot:       endSlot - 1,
+		FirstEpoch:     firstSlot / t.SlotsPerEpoch,
+		LastEpoch:      (endSlot - 1) / t.SlotsPerEpoch,
+		StartTime:      slotTime(firstSlot),
+		EndTime:        slotTime(endSlot),
+		ExpectedBlocks: endSlot - firstSlot,
+		Truncated:      endSlot < dayEndSlot,
+	}
+}
+
+func (w *DayWindow) String() string {
+	s := fmt.Sprintf("slots %v-%v, epochs %v-%v, %v - %v, %v expected blocks, %v boundary", w.FirstSlot, w.LastSlot, w.FirstEpoch, w.LastEpoch, w.StartTime.Format(time.RFC3339), w.EndTime.Format(time.RFC3339), w.ExpectedBlocks, w.DayBoundary)
+	if w.Truncated {
+		s += ", truncated at the finalized slot"
+	}
+	return s
+}
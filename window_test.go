@@ -0,0 +1,25 @@
+package ethstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDayWindow(t *testing.T) {
+	timing := &ChainTiming{Genesis: time.Unix(1606824023, 0), SecondsPerSlot: 12, SlotsPerEpoch: 32}
+	// the window of a day that is not finalized completely ends at the finalized slot
+	w := newDayWindow(GenesisDayBoundary{}, 72000, 75000, 79200, timing)
+	if w.LastSlot != 74999 || w.LastEpoch != 2343 || w.ExpectedBlocks != 3000 || !w.Truncated {
+		t.Errorf("wrong window: %+v", w)
+	}
+	expected := "slots 72000-74999, epochs 2250-2343, 2020-12-11T12:00:23Z - 2020-12-11T22:00:23Z, 3000 expected blocks, genesis boundary, truncated at the finalized slot"
+	if w.String() != expected {
+		t.Errorf("wrong window: %v != %v", w, expected)
+	}
+
+	// the days of the utc boundary do not start at an epoch boundary
+	w = newDayWindow(UTCDayBoundary{}, 3599, 3599+7200, 3599+7200, timing)
+	if w.FirstEpoch != 112 || w.LastEpoch != 337 || w.ExpectedBlocks != 7200 || w.Truncated || w.StartTime != time.Date(2020, 12, 2, 0, 0, 11, 0, time.UTC) {
+		t.Errorf("wrong window: %+v", w)
+	}
+}
@@ -0,0 +1,59 @@
+package ethstore
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// WithdrawalGroup holds the validators of a day that share a withdrawal address.
+type WithdrawalGroup struct {
+	WithdrawalAddress    string          `json:"withdrawalAddress"`
+	Validators           decimal.Decimal `json:"validators"`
+	EffectiveBalanceGwei decimal.Decimal `json:"effectiveBalanceGwei"`
+	ConsensusRewardsGwei decimal.Decimal `json:"consensusRewardsGwei"`
+	ConsensusRewardsWei  decimal.Decimal `json:"consensusRewardsWei"`
+	TxFeesSumWei         decimal.Decimal `json:"txFeesSumWei"`
+	TotalRewardsWei      decimal.Decimal `json:"totalRewardsWei"`
+	Apr                  decimal.Decimal `json:"apr"`
+}
+
+// WithdrawalAddress returns the execution address of 0x01 and 0x02 withdrawal credentials, validators with bls (0x00)
+// withdrawal credentials have no withdrawal address and are grouped by their credentials.
+func WithdrawalAddress(credentials string) string {
+	if len(credentials) == 66 && (strings.HasPrefix(credentials, "0x01") || strings.HasPrefix(credentials, "0x02")) {
+		return "0x" + credentials[26:]
+	}
+	return credentials
+}
+
+// GroupByWithdrawalAddress groups the per-validator days returned by Calculate by the withdrawal address of the
+// validators, the groups are sorted by withdrawal address.
+func GroupByWithdrawalAddress(validatorDays map[uint64]*Day) []WithdrawalGroup {
+	groupsByAddress := map[string]*WithdrawalGroup{}
+	for _, d := range validatorDays {
+		address := WithdrawalAddress(d.WithdrawalCredentials)
+		g, exists := groupsByAddress[address]
+		if !exists {
+			g = &WithdrawalGroup{WithdrawalAddress: address}
+			groupsByAddress[address] = g
+		}
+		g.Validators = g.Validators.Add(decimal.NewFromInt(1))
+		g.EffectiveBalanceGwei = g.EffectiveBalanceGwei.Add(d.EffectiveBalanceGwei)
+		g.ConsensusRewardsGwei = g.ConsensusRewardsGwei.Add(d.ConsensusRewardsGwei)
+		g.ConsensusRewardsWei = g.ConsensusRewardsWei.Add(gweiToWei(d.ConsensusRewardsGwei))
+		g.TxFeesSumWei = g.TxFeesSumWei.Add(d.TxFeesSumWei)
+		g.TotalRewardsWei = g.TotalRewardsWei.Add(d.TotalRewardsWei)
+	}
+
+	groups := make([]WithdrawalGroup, 0, len(groupsByAddress))
+	for _, g := range groupsByAddress {
+		g.Apr = groupApr(g.TotalRewardsWei, g.EffectiveBalanceGwei)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].WithdrawalAddress < groups[j].WithdrawalAddress
+	})
+	return groups
+}
@@ -0,0 +1,77 @@
+package ethstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWithdrawalAddress(t *testing.T) {
+	tests := []struct {
+		credentials string
+		address     string
+	}{
+		{"0x010000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		{"0x020000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		{"0x00f50428677c2c6285d6a0e4a7ae3d06fb1e4e5b9d7b93bdd2bd6d0b3ec3e5f1", "0x00f50428677c2c6285d6a0e4a7ae3d06fb1e4e5b9d7b93bdd2bd6d0b3ec3e5f1"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if a := WithdrawalAddress(tt.credentials); a != tt.address {
+			t.Errorf("wrong withdrawal address for %v: %v != %v", tt.credentials, a, tt.address)
+		}
+	}
+}
+
+func TestGroupByWithdrawalAddress(t *testing.T) {
+	validatorDay := func(credentials string, rewardsGwei int64) *Day {
+		return &Day{
+			WithdrawalCredentials: credentials,
+			EffectiveBalanceGwei:  decimal.NewFromInt(32e9),
+			ConsensusRewardsGwei:  decimal.NewFromInt(rewardsGwei),
+			TxFeesSumWei:          decimal.Zero,
+			TotalRewardsWei:       decimal.NewFromInt(rewardsGwei).Mul(decimal.NewFromInt(1e9)),
+		}
+	}
+	groups := GroupByWithdrawalAddress(map[uint64]*Day{
+		0: validatorDay("0x010000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", 3000000),
+		1: validatorDay("0x020000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", 4000000),
+		2: validatorDay("0x0100000000000000000000000000000000000000000000000000000000000001", 2000000),
+	})
+	if len(groups) != 2 {
+		t.Fatalf("wrong number of groups: %v != %v", len(groups), 2)
+	}
+	if groups[0].WithdrawalAddress != "0x0000000000000000000000000000000000000001" || !groups[0].Validators.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("wrong first group: %+v", groups[0])
+	}
+	g := groups[1]
+	if !g.Validators.Equal(decimal.NewFromInt(2)) || !g.ConsensusRewardsGwei.Equal(decimal.NewFromInt(7000000)) || !g.EffectiveBalanceGwei.Equal(decimal.NewFromInt(64e9)) {
+		t.Errorf("wrong second group: %+v", g)
+	}
+	if !g.Apr.Equal(groupApr(g.TotalRewardsWei, g.EffectiveBalanceGwei)) {
+		t.Errorf("wrong apr: %v", g.Apr)
+	}
+}
+
+func TestGroupByWithdrawalAddressOfCalculatedDay(t *testing.T) {
+	bnServer, elServer := newEthstoreMockServers(t, nil)
+	defer bnServer.Close()
+	defer elServer.Close()
+
+	day, validatorDays, err := Calculate(context.Background(), bnServer.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validators, totalRewardsWei := decimal.Zero, decimal.Zero
+	for _, g := range GroupByWithdrawalAddress(validatorDays) {
+		validators = validators.Add(g.Validators)
+		totalRewardsWei = totalRewardsWei.Add(g.TotalRewardsWei)
+	}
+	if !validators.Equal(day.Validators) {
+		t.Errorf("wrong number of grouped validators: %v != %v", validators, day.Validators)
+	}
+	if !totalRewardsWei.Equal(day.TotalRewardsWei) {
+		t.Errorf("wrong grouped rewards: %v != %v", totalRewardsWei, day.TotalRewardsWei)
+	}
+}
@@ -0,0 +1,48 @@
+package ethstore
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// Withdrawal is a withdrawal of the execution payload of a capella or later block, it is credited to the withdrawal
+// address on the execution layer and deducted from the balance of the validator on the consensus layer.
+type Withdrawal struct {
+	Index          uint64
+	ValidatorIndex phase0.ValidatorIndex
+	Address        bellatrix.ExecutionAddress
+	AmountGwei     phase0.Gwei
+}
+
+type withdrawalJSON struct {
+	Index          string `json:"index"`
+	ValidatorIndex string `json:"validator_index"`
+	Address        string `json:"address"`
+	Amount         string `json:"amount"`
+}
+
+func (w withdrawalJSON) decode() (Withdrawal, error) {
+	var withdrawal Withdrawal
+	var err error
+	withdrawal.Index, err = strconv.ParseUint(w.Index, 10, 64)
+	if err != nil {
+		return withdrawal, fmt.Errorf("invalid index of withdrawal: %w", err)
+	}
+	index, err := strconv.ParseUint(w.ValidatorIndex, 10, 64)
+	if err != nil {
+		return withdrawal, fmt.Errorf("invalid validator_index of withdrawal: %w", err)
+	}
+	withdrawal.ValidatorIndex = phase0.ValidatorIndex(index)
+	if err := decodeHexInto(w.Address, withdrawal.Address[:]); err != nil {
+		return withdrawal, fmt.Errorf("invalid address of withdrawal: %w", err)
+	}
+	amount, err := strconv.ParseUint(w.Amount, 10, 64)
+	if err != nil {
+		return withdrawal, fmt.Errorf("invalid amount of withdrawal: %w", err)
+	}
+	withdrawal.AmountGwei = phase0.Gwei(amount)
+	return withdrawal, nil
+}
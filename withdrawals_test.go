@@ -0,0 +1,112 @@
+package ethstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gobitfly/eth.store/fixture"
+)
+
+func TestDecodeForkFields(t *testing.T) {
+	commitment := "0x" + strings.Repeat("ab", 48)
+	block := &SignedBeaconBlock{Fork: "deneb"}
+	err := decodeForkFields(block, []byte(fmt.Sprintf(`{"message":{"body":{"execution_payload":{"withdrawals":[{"index":"1","validator_index":"7","address":"0x8b0c2c4c8eb078bc6c01f48523764c8942c0c6c4","amount":"16000000"}],"blob_gas_used":"262144","excess_blob_gas":"0"},"bls_to_execution_changes":[{"message":{"validator_index":"9","from_bls_pubkey":"0x%096x","to_execution_address":"0x8b0c2c4c8eb078bc6c01f48523764c8942c0c6c4"},"signature":"0x%0192x"}],"blob_kzg_commitments":["%[3]s","%[3]s"]}}}`, 1, 2, commitment)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Capella.Withdrawals) != 1 || block.Capella.Withdrawals[0].ValidatorIndex != 7 || block.Capella.Withdrawals[0].AmountGwei != 16000000 || block.Capella.Withdrawals[0].Index != 1 {
+		t.Errorf("wrong withdrawals: %+v", block.Capella.Withdrawals)
+	}
+	if fmt.Sprintf("%#x", block.Capella.Withdrawals[0].Address[:]) != "0x8b0c2c4c8eb078bc6c01f48523764c8942c0c6c4" {
+		t.Errorf("wrong withdrawal address: %#x", block.Capella.Withdrawals[0].Address)
+	}
+	if len(block.Capella.BLSToExecutionChanges) != 1 || block.Capella.BLSToExecutionChanges[0].ValidatorIndex != 9 || block.Capella.BLSToExecutionChanges[0].FromBLSPubkey[47] != 1 {
+		t.Errorf("wrong bls to execution changes: %+v", block.Capella.BLSToExecutionChanges)
+	}
+	if len(block.Deneb.BlobKZGCommitments) != 2 || fmt.Sprintf("%#x", block.Deneb.BlobKZGCommitments[1][:]) != commitment || block.Deneb.BlobGasUsed != 2*gasPerBlob {
+		t.Errorf("wrong deneb fields: %+v", block.Deneb)
+	}
+
+	// bellatrix blocks have no fields of later forks
+	block = &SignedBeaconBlock{Fork: "bellatrix"}
+	if err := decodeForkFields(block, []byte(`{}`)); err != nil || block.Capella != nil || block.Deneb != nil {
+		t.Errorf("wrong fields of bellatrix block: %+v %v", block, err)
+	}
+	for _, data := range []string{
+		`{"message":{"body":{"execution_payload":{"withdrawals":[{"index":"1","validator_index":"7","address":"0x8b0c2c4c8eb078bc6c01f48523764c8942c0c6c4","amount":"x"}]}}}}`,
+		`{"message":{"body":{"execution_payload":{"withdrawals":[{"index":"1","validator_index":"7","address":"0x8b0c","amount":"1"}]}}}}`,
+	} {
+		if err := decodeForkFields(&SignedBeaconBlock{Fork: "capella"}, []byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+	if err := decodeForkFields(&SignedBeaconBlock{Fork: "deneb"}, []byte(`{"message":{"body":{"execution_payload":{}}}}`)); err == nil {
+		t.Errorf("expected error for deneb block without blob gas")
+	}
+}
+
+func TestWithdrawalsOfBlocks(t *testing.T) {
+	f, err := fixture.Generate(fixture.Scenario{
+		Day:                 10,
+		Validators:          16,
+		ConsensusRewardGwei: 3200000,
+		TxFeeGwei:           10000,
+		Withdrawals:         []fixture.Withdrawal{{Validator: 6, Slot: 3000, AmountGwei: 15000}, {Validator: 4, Slot: 3001, AmountGwei: 1e9}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstSlot := uint64(10 * fixture.SlotsPerDay)
+
+	// the withdrawals are part of the decoded block
+	bnServer, elServer := f.Servers()
+	defer bnServer.Close()
+	defer elServer.Close()
+	block, err := getSignedBeaconBlock(context.Background(), bnServer.URL, firstSlot+3000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Fork != "capella" || block.Capella == nil || len(block.Capella.Withdrawals) != 1 || block.Capella.Withdrawals[0].ValidatorIndex != 6 || block.Capella.Withdrawals[0].AmountGwei != 15000 {
+		t.Errorf("wrong withdrawals of block: %+v", block.Capella)
+	}
+
+	// every block is requested once and the full block of the second withdrawal is unavailable, its withdrawal is
+	// taken from the execution block of the blinded block
+	bn := f.BeaconHandler()
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == fmt.Sprintf("/eth/v2/beacon/blocks/%d", firstSlot+3001) {
+			http.Error(w, `{"code":500,"message":"payload unavailable"}`, http.StatusInternalServerError)
+			return
+		}
+		bn.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+	defer SetRetryPolicy(DefaultRetryPolicy)
+
+	day, _, err := Calculate(context.Background(), server.URL, elServer.URL, "10", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !day.WithdrawalsSumGwei.Equal(f.Expected.WithdrawalsSumGwei) || !day.Apr.Equal(f.Expected.Apr) {
+		t.Errorf("wrong withdrawals: %v != %v, apr: %v != %v", day.WithdrawalsSumGwei, f.Expected.WithdrawalsSumGwei, day.Apr, f.Expected.Apr)
+	}
+	for path, n := range requests {
+		if strings.HasPrefix(path, "/eth/v2/beacon/blocks/") && n > 1 {
+			t.Errorf("block requested %v times: %v", n, path)
+		}
+	}
+	if requests[fmt.Sprintf("/eth/v1/beacon/blinded_blocks/%d", firstSlot+3001)] != 1 {
+		t.Errorf("blinded block not requested: %v", requests)
+	}
+}
@@ -0,0 +1,337 @@
+package ethstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// the styles of the cells of the workbook, indexes into the cellXfs of xlsxStyles
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleDate
+	xlsxStylePercent
+	xlsxStyleHeader
+)
+
+// xlsxMaxRows is the maximum number of rows of a sheet that excel opens.
+const xlsxMaxRows = 1 << 20
+
+// xlsxCell is a cell of a row of a sheet, a cell without a number is a string.
+type xlsxCell struct {
+	number *decimal.Decimal
+	str    string
+	style  int
+}
+
+func xlsxNumber(d decimal.Decimal, style int) xlsxCell {
+	return xlsxCell{number: &d, style: style}
+}
+
+func xlsxString(s string) xlsxCell {
+	return xlsxCell{str: s}
+}
+
+// xlsxDate returns the date as the serial number of excel, the days since 1899-12-30.
+func xlsxDate(t time.Time) xlsxCell {
+	return xlsxNumber(decimal.NewFromInt(t.Unix()).Div(decimal.NewFromInt(86400)).Add(decimal.NewFromInt(25569)).Round(6), xlsxStyleDate)
+}
+
+// xlsxChart is a chart of columns of the summary sheet over the days.
+type xlsxChart struct {
+	title   string
+	stacked bool
+	// columns are the indexes of the columns of the series
+	columns []int
+	percent bool
+}
+
+// xlsxFile is a part of the zip-archive of a workbook.
+type xlsxFile struct {
+	name    string
+	content string
+}
+
+var xlsxSummaryHeader = []string{"day", "date", "validators", "apr", "effectiveBalanceGwei", "consensusRewardsGwei", "txFeesSumWei", "totalRewardsWei", "consensusRewardsEth", "txFeesEth", "totalRewardsEth"}
+
+var xlsxSummaryCharts = []xlsxChart{
+	{title: "APR", columns: []int{3}, percent: true},
+	{title: "Rewards (ETH)", stacked: true, columns: []int{8, 9}},
+}
+
+var xlsxValidatorsHeader = []string{"day", "validator", "pubkey", "entity", "effectiveBalanceGwei", "consensusRewardsGwei", "txFeesSumWei", "totalRewardsWei", "apr"}
+
+// WriteXLSX writes an excel workbook with a summary sheet of the days and charts of their apr and rewards, a sheet
+// with the per-validator days is added if validatorDays are given.
+func WriteXLSX(w io.Writer, days []*Day, validatorDays map[uint64]map[uint64]*Day) error {
+	summary := [][]xlsxCell{xlsxHeader(xlsxSummaryHeader)}
+	for _, d := range days {
+		summary = append(summary, []xlsxCell{
+			xlsxNumber(d.Day, xlsxStyleDefault),
+			xlsxDate(d.DayTime),
+			xlsxNumber(d.Validators, xlsxStyleDefault),
+			xlsxNumber(d.Apr, xlsxStylePercent),
+			xlsxNumber(d.EffectiveBalanceGwei, xlsxStyleDefault),
+			xlsxNumber(d.ConsensusRewardsGwei, xlsxStyleDefault),
+			xlsxNumber(d.TxFeesSumWei, xlsxStyleDefault),
+			xlsxNumber(d.TotalRewardsWei, xlsxStyleDefault),
+			xlsxNumber(gweiToWei(d.ConsensusRewardsGwei).Shift(-18), xlsxStyleDefault),
+			xlsxNumber(d.TxFeesSumWei.Shift(-18), xlsxStyleDefault),
+			xlsxNumber(d.TotalRewardsWei.Shift(-18), xlsxStyleDefault),
+		})
+	}
+	sheets := []string{"Summary"}
+	sheetRows := [][][]xlsxCell{summary}
+
+	if len(validatorDays) > 0 {
+		rows := [][]xlsxCell{xlsxHeader(xlsxValidatorsHeader)}
+		dayNumbers := make([]uint64, 0, len(validatorDays))
+		for day := range validatorDays {
+			dayNumbers = append(dayNumbers, day)
+		}
+		sort.Slice(dayNumbers, func(i, j int) bool { return dayNumbers[i] < dayNumbers[j] })
+		for _, day := range dayNumbers {
+			indexes := make([]uint64, 0, len(validatorDays[day]))
+			for index := range validatorDays[day] {
+				indexes = append(indexes, index)
+			}
+			sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+			for _, index := range indexes {
+				v := validatorDays[day][index]
+				rows = append(rows, []xlsxCell{
+					xlsxNumber(decimal.NewFromInt(int64(day)), xlsxStyleDefault),
+					xlsxNumber(decimal.NewFromInt(int64(index)), xlsxStyleDefault),
+					xlsxString(v.Pubkey),
+					xlsxString(v.Entity),
+					xlsxNumber(v.EffectiveBalanceGwei, xlsxStyleDefault),
+					xlsxNumber(v.ConsensusRewardsGwei, xlsxStyleDefault),
+					xlsxNumber(v.TxFeesSumWei, xlsxStyleDefault),
+					xlsxNumber(v.TotalRewardsWei, xlsxStyleDefault),
+					xlsxNumber(v.Apr, xlsxStylePercent),
+				})
+			}
+		}
+		if len(rows) > xlsxMaxRows {
+			return fmt.Errorf("too many per-validator days for a sheet: %v (max: %v)", len(rows)-1, xlsxMaxRows-1)
+		}
+		sheets = append(sheets, "Validators")
+		sheetRows = append(sheetRows, rows)
+	}
+
+	// charts of an empty summary would reference no cells
+	var charts []xlsxChart
+	if len(days) > 0 {
+		charts = xlsxSummaryCharts
+	}
+
+	files := []xlsxFile{
+		{"[Content_Types].xml", xlsxContentTypes(len(sheets), len(charts))},
+		{"_rels/.rels", xlsxRels([]string{"officeDocument"}, []string{"xl/workbook.xml"})},
+		{"xl/workbook.xml", xlsxWorkbook(sheets)},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	types, targets := []string{"styles"}, []string{"styles.xml"}
+	for i := range sheets {
+		types, targets = append(types, "worksheet"), append(targets, fmt.Sprintf("worksheets/sheet%d.xml", i+1))
+	}
+	files = append(files, xlsxFile{"xl/_rels/workbook.xml.rels", xlsxRels(types, targets)})
+	for i, rows := range sheetRows {
+		drawing := i == 0 && len(charts) > 0
+		files = append(files, xlsxFile{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxSheet(rows, drawing)})
+	}
+	if len(charts) > 0 {
+		files = append(files, xlsxFile{"xl/worksheets/_rels/sheet1.xml.rels", xlsxRels([]string{"drawing"}, []string{"../drawings/drawing1.xml"})})
+		files = append(files, xlsxFile{"xl/drawings/drawing1.xml", xlsxDrawing(len(charts), len(xlsxSummaryHeader)+1)})
+		chartTypes, chartTargets := []string{}, []string{}
+		for i, c := range charts {
+			chartTypes, chartTargets = append(chartTypes, "chart"), append(chartTargets, fmt.Sprintf("../charts/chart%d.xml", i+1))
+			files = append(files, xlsxFile{fmt.Sprintf("xl/charts/chart%d.xml", i+1), xlsxChartSpace(c, sheets[0], len(days))})
+		}
+		files = append(files, xlsxFile{"xl/drawings/_rels/drawing1.xml.rels", xlsxRels(chartTypes, chartTargets)})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(fw, xml.Header+f.content)
+		if err != nil {
+			return fmt.Errorf("error writing %v: %w", f.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+func xlsxHeader(names []string) []xlsxCell {
+	row := make([]xlsxCell, len(names))
+	for i, name := range names {
+		row[i] = xlsxCell{str: name, style: xlsxStyleHeader}
+	}
+	return row
+}
+
+// xlsxColumn returns the name of the column with the given index, e.g. "A" for 0 and "AA" for 26.
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+func xlsxEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxMainNamespace = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+const xlsxRelationshipsNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+func xlsxSheet(rows [][]xlsxCell, drawing bool) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<worksheet xmlns="%s" xmlns:r="%s">`, xlsxMainNamespace, xlsxRelationshipsNamespace)
+	// the header row stays visible while scrolling
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := fmt.Sprintf("%s%d", xlsxColumn(c), r+1)
+			if cell.number != nil {
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.style, cell.number.String())
+			} else {
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t>%s</t></is></c>`, ref, cell.style, xlsxEscape(cell.str))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+	if drawing {
+		b.WriteString(`<drawing r:id="rId1"/>`)
+	}
+	b.WriteString(`</worksheet>`)
+	return b.String()
+}
+
+func xlsxWorkbook(sheets []string) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<workbook xmlns="%s" xmlns:r="%s"><sheets>`, xlsxMainNamespace, xlsxRelationshipsNamespace)
+	for i, name := range sheets {
+		// the relationship of the styles is rId1
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(name), i+1, i+2)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+// xlsxRels returns relationships with the ids rId1, rId2, ... of the given types and targets.
+func xlsxRels(types, targets []string) string {
+	var b bytes.Buffer
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, t := range types {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="%s/%s" Target="%s"/>`, i+1, xlsxRelationshipsNamespace, t, targets[i])
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func xlsxContentTypes(sheets, charts int) string {
+	var b bytes.Buffer
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	if charts > 0 {
+		b.WriteString(`<Override PartName="/xl/drawings/drawing1.xml" ContentType="application/vnd.openxmlformats-officedocument.drawing+xml"/>`)
+	}
+	for i := 1; i <= charts; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/charts/chart%d.xml" ContentType="application/vnd.openxmlformats-officedocument.drawingml.chart+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+// xlsxStyles holds the cellXfs of the xlsxStyle constants: default, date (numFmt 22), percent (numFmt 10) and bold.
+const xlsxStyles = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="10" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// xlsxDrawing places the given number of charts below each other, starting at the given column.
+func xlsxDrawing(charts, column int) string {
+	var b bytes.Buffer
+	b.WriteString(`<xdr:wsDr xmlns:xdr="http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">`)
+	for i := 0; i < charts; i++ {
+		fromRow, toRow := 1+i*18, 17+i*18
+		b.WriteString(`<xdr:twoCellAnchor>`)
+		fmt.Fprintf(&b, `<xdr:from><xdr:col>%d</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>%d</xdr:row><xdr:rowOff>0</xdr:rowOff></xdr:from>`, column, fromRow)
+		fmt.Fprintf(&b, `<xdr:to><xdr:col>%d</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>%d</xdr:row><xdr:rowOff>0</xdr:rowOff></xdr:to>`, column+9, toRow)
+		fmt.Fprintf(&b, `<xdr:graphicFrame macro=""><xdr:nvGraphicFramePr><xdr:cNvPr id="%d" name="Chart %d"/><xdr:cNvGraphicFramePr/></xdr:nvGraphicFramePr>`, i+2, i+1)
+		b.WriteString(`<xdr:xfrm><a:off x="0" y="0"/><a:ext cx="0" cy="0"/></xdr:xfrm>`)
+		fmt.Fprintf(&b, `<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/chart"><c:chart xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:r="%s" r:id="rId%d"/></a:graphicData></a:graphic>`, xlsxRelationshipsNamespace, i+1)
+		b.WriteString(`</xdr:graphicFrame><xdr:clientData/></xdr:twoCellAnchor>`)
+	}
+	b.WriteString(`</xdr:wsDr>`)
+	return b.String()
+}
+
+// xlsxChartSpace returns a line chart or a stacked column chart of the given columns of the sheet over its days.
+func xlsxChartSpace(c xlsxChart, sheet string, days int) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<c:chartSpace xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="%s"><c:chart>`, xlsxRelationshipsNamespace)
+	fmt.Fprintf(&b, `<c:title><c:tx><c:rich><a:bodyPr/><a:p><a:r><a:t>%s</a:t></a:r></a:p></c:rich></c:tx><c:overlay val="0"/></c:title>`, xlsxEscape(c.title))
+	b.WriteString(`<c:autoTitleDeleted val="0"/><c:plotArea><c:layout/>`)
+	if c.stacked {
+		b.WriteString(`<c:barChart><c:barDir val="col"/><c:grouping val="stacked"/><c:varyColors val="0"/>`)
+	} else {
+		b.WriteString(`<c:lineChart><c:grouping val="standard"/><c:varyColors val="0"/>`)
+	}
+	for i, column := range c.columns {
+		col := xlsxColumn(column)
+		fmt.Fprintf(&b, `<c:ser><c:idx val="%d"/><c:order val="%d"/><c:tx><c:strRef><c:f>%s!$%s$1</c:f></c:strRef></c:tx>`, i, i, sheet, col)
+		if c.stacked {
+			b.WriteString(`<c:invertIfNegative val="0"/>`)
+		}
+		fmt.Fprintf(&b, `<c:cat><c:numRef><c:f>%s!$A$2:$A$%d</c:f></c:numRef></c:cat>`, sheet, days+1)
+		fmt.Fprintf(&b, `<c:val><c:numRef><c:f>%s!$%s$2:$%s$%d</c:f></c:numRef></c:val>`, sheet, col, col, days+1)
+		if !c.stacked {
+			b.WriteString(`<c:smooth val="0"/>`)
+		}
+		b.WriteString(`</c:ser>`)
+	}
+	if c.stacked {
+		b.WriteString(`<c:gapWidth val="50"/><c:overlap val="100"/><c:axId val="1"/><c:axId val="2"/></c:barChart>`)
+	} else {
+		b.WriteString(`<c:marker val="1"/><c:axId val="1"/><c:axId val="2"/></c:lineChart>`)
+	}
+	b.WriteString(`<c:catAx><c:axId val="1"/><c:scaling><c:orientation val="minMax"/></c:scaling><c:delete val="0"/><c:axPos val="b"/><c:crossAx val="2"/></c:catAx>`)
+	b.WriteString(`<c:valAx><c:axId val="2"/><c:scaling><c:orientation val="minMax"/></c:scaling><c:delete val="0"/><c:axPos val="l"/><c:majorGridlines/>`)
+	if c.percent {
+		b.WriteString(`<c:numFmt formatCode="0.00%" sourceLinked="0"/>`)
+	}
+	b.WriteString(`<c:crossAx val="1"/></c:valAx></c:plotArea>`)
+	b.WriteString(`<c:legend><c:legendPos val="b"/><c:overlay val="0"/></c:legend><c:plotVisOnly val="1"/></c:chart></c:chartSpace>`)
+	return b.String()
+}
@@ -0,0 +1,154 @@
+package ethstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	days := []*Day{}
+	for i := int64(0); i < 3; i++ {
+		days = append(days, &Day{
+			Day:                  decimal.NewFromInt(100 + i),
+			DayTime:              time.Date(2021, 3, 12+int(i), 12, 0, 0, 0, time.UTC),
+			Validators:           decimal.NewFromInt(2),
+			Apr:                  decimal.RequireFromString("0.0512"),
+			EffectiveBalanceGwei: decimal.NewFromInt(64e9),
+			ConsensusRewardsGwei: decimal.NewFromInt(8e6),
+			TxFeesSumWei:         decimal.NewFromInt(1),
+			TotalRewardsWei:      decimal.RequireFromString("8000000000000001"),
+		})
+	}
+	validatorDays := map[uint64]map[uint64]*Day{
+		100: {
+			7: {Pubkey: "0x07", Entity: "Pool <A> & B", Apr: decimal.RequireFromString("0.05")},
+			3: {Pubkey: "0x03"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		days          []*Day
+		validatorDays map[uint64]map[uint64]*Day
+		parts         []string
+	}{
+		{"summary", days, nil, []string{"xl/worksheets/sheet1.xml", "xl/charts/chart2.xml"}},
+		{"validators", days, validatorDays, []string{"xl/worksheets/sheet2.xml", "xl/charts/chart1.xml"}},
+		{"empty", nil, nil, []string{"xl/worksheets/sheet1.xml"}},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := WriteXLSX(&b, tt.days, tt.validatorDays); err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		parts := map[string]string{}
+		for _, f := range zr.File {
+			r, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts[f.Name] = string(content)
+			// every part must be well-formed
+			d := xml.NewDecoder(bytes.NewReader(content))
+			for {
+				_, err := d.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%v: invalid xml of %v: %v", tt.name, f.Name, err)
+				}
+			}
+		}
+		for _, p := range tt.parts {
+			if _, exists := parts[p]; !exists {
+				t.Errorf("%v: missing part %v", tt.name, p)
+			}
+		}
+		// every relationship must point to an existing part
+		for name, content := range parts {
+			if !strings.HasSuffix(name, ".rels") {
+				continue
+			}
+			var rels struct {
+				Relationship []struct {
+					Target string `xml:"Target,attr"`
+				}
+			}
+			if err := xml.Unmarshal([]byte(content), &rels); err != nil {
+				t.Fatal(err)
+			}
+			dir := path.Dir(path.Dir(name))
+			for _, r := range rels.Relationship {
+				if _, exists := parts[path.Join(dir, r.Target)]; !exists {
+					t.Errorf("%v: relationship of %v to missing part %v", tt.name, name, r.Target)
+				}
+			}
+		}
+		if tt.days == nil && strings.Contains(parts["[Content_Types].xml"], "chart") {
+			t.Errorf("%v: unexpected charts", tt.name)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := WriteXLSX(&b, days, validatorDays); err != nil {
+		t.Fatal(err)
+	}
+	zr, _ := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	sheets := map[string]string{}
+	for _, f := range zr.File {
+		r, _ := f.Open()
+		content, _ := ioutil.ReadAll(r)
+		r.Close()
+		sheets[f.Name] = string(content)
+	}
+	for _, expected := range []string{
+		`<c r="A2" s="0"><v>100</v></c>`,
+		// 2021-03-12 12:00 UTC
+		`<c r="B2" s="1"><v>44267.5</v></c>`,
+		`<c r="D4" s="2"><v>0.0512</v></c>`,
+		// the rewards in ETH are exact
+		`<c r="K2" s="0"><v>0.008000000000000001</v></c>`,
+		`<drawing r:id="rId1"/>`,
+	} {
+		if !strings.Contains(sheets["xl/worksheets/sheet1.xml"], expected) {
+			t.Errorf("summary sheet does not contain %v", expected)
+		}
+	}
+	validators := sheets["xl/worksheets/sheet2.xml"]
+	if !strings.Contains(validators, `<t>Pool &lt;A&gt; &amp; B</t>`) {
+		t.Errorf("validators sheet does not contain the escaped entity")
+	}
+	if strings.Index(validators, `<t>0x03</t>`) > strings.Index(validators, `<t>0x07</t>`) {
+		t.Errorf("validators are not sorted by index")
+	}
+	if !strings.Contains(sheets["xl/charts/chart1.xml"], `<c:f>Summary!$D$2:$D$4</c:f>`) {
+		t.Errorf("apr chart does not reference the apr of the days: %v", sheets["xl/charts/chart1.xml"])
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for index, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if xlsxColumn(index) != name {
+			t.Errorf("wrong name of column %v: %v != %v", index, xlsxColumn(index), name)
+		}
+	}
+}